@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/gob"
 	"flag"
 	"fmt"
@@ -11,10 +12,15 @@ import (
 
 	"github.com/aidisapp/musiqcity_v2/internal/config"
 	"github.com/aidisapp/musiqcity_v2/internal/driver"
+	"github.com/aidisapp/musiqcity_v2/internal/emails"
 	"github.com/aidisapp/musiqcity_v2/internal/handlers"
 	"github.com/aidisapp/musiqcity_v2/internal/helpers"
+	"github.com/aidisapp/musiqcity_v2/internal/mailer"
 	"github.com/aidisapp/musiqcity_v2/internal/models"
+	"github.com/aidisapp/musiqcity_v2/internal/outbox"
 	"github.com/aidisapp/musiqcity_v2/internal/render"
+	"github.com/aidisapp/musiqcity_v2/internal/repository/dbrepo"
+	"github.com/aidisapp/musiqcity_v2/internal/settings"
 	"github.com/alexedwards/scs/v2"
 	"github.com/joho/godotenv"
 )
@@ -23,6 +29,7 @@ var app config.AppConfig
 var session *scs.SessionManager
 var infoLog *log.Logger
 var errorLog *log.Logger
+var dispatcher *outbox.Dispatcher
 
 func main() {
 
@@ -39,9 +46,10 @@ func main() {
 		log.Fatal(err)
 	}
 
-	// Close database connection when main function finish running and the mail server if mail has finsished sending
-	defer connectedDB.SQL.Close()
-	defer close(app.MailChannel)
+	// Close database connection when main function finish running
+	if connectedDB != nil {
+		defer connectedDB.SQL.Close()
+	}
 
 	// Listening for mail
 	fmt.Println("Listening for mail...")
@@ -67,6 +75,7 @@ func run() (*driver.DB, error) {
 	gob.Register(models.Room{})
 	gob.Register(models.Restriction{})
 	gob.Register(models.TodoList{})
+	gob.Register(models.Bookings{})
 	gob.Register(make(map[string]int))
 
 	err := godotenv.Load()
@@ -76,9 +85,23 @@ func run() (*driver.DB, error) {
 
 	dbURI := os.Getenv("DBURI")
 
+	// Manage links let anyone holding one cancel a booking, so never sign them with a missing or guessable key
+	manageTokenSecret := os.Getenv("JWTSECRET")
+	if err := helpers.CheckManageTokenSecret(manageTokenSecret); err != nil {
+		return nil, fmt.Errorf("JWTSECRET: %w", err)
+	}
+
 	// Read flags
 	inProduction := flag.Bool("production", true, "App is in production")
 	useCache := flag.Bool("cache", true, "Use template cache")
+	cancellationWindow := flag.Duration("cancel-window", 48*time.Hour, "How long before the start date customers can still cancel")
+	rescheduleWindow := flag.Duration("reschedule-window", 72*time.Hour, "How long before the start date customers can still reschedule")
+	repoType := flag.String("repo", "postgres", "Where data is stored (postgres, memory). Memory data is lost on exit")
+	mailBackend := flag.String("mailer", mailer.BackendSendinblue, "How mail is sent (sendinblue, smtp, file)")
+	mailDir := flag.String("mail-dir", "./tmp/mail", "Where the file mailer writes .eml files")
+	mailRedirect := flag.String("mail-redirect", "", "Send every email to this address instead of the customer")
+	staging := flag.Bool("staging", false, "App is running in staging, mail can't reach real customers")
+	mailWorkers := flag.Int("mail-workers", 4, "How many emails are sent at the same time")
 	// dbHost := flag.String("dbhost", "", "Database host")
 	// dbName := flag.String("dbname", "", "Database name")
 	// dbUser := flag.String("dbuser", "", "Database user")
@@ -93,16 +116,46 @@ func run() (*driver.DB, error) {
 	// 	os.Exit(1)
 	// }
 
-	if dbURI == "" {
+	if *repoType != "postgres" && *repoType != "memory" {
+		fmt.Println("Unknown repo type, use postgres or memory")
+		os.Exit(1)
+	}
+
+	if dbURI == "" && *repoType == "postgres" {
 		fmt.Println("Missing flag dependencies, attach the flag dependencies in your batch file")
 		os.Exit(1)
 	}
 
 	app.InProduction = *inProduction
 	app.UseCache = *useCache
+	app.CancellationWindow = *cancellationWindow
+	app.RescheduleWindow = *rescheduleWindow
+	app.ManageTokenSecret = []byte(manageTokenSecret)
+
+	appMailer, err := mailer.New(mailer.Config{
+		Backend:          *mailBackend,
+		SenderName:       "MusiqCity",
+		SendinblueAPIKey: os.Getenv("SENDINBLUE_API_KEY"),
+		SMTPHost:         os.Getenv("SMTP_HOST"),
+		SMTPPort:         os.Getenv("SMTP_PORT"),
+		SMTPUsername:     os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:     os.Getenv("SMTP_PASSWORD"),
+		Dir:              *mailDir,
+		RedirectTo:       *mailRedirect,
+		Staging:          *staging,
+	})
+	if err != nil {
+		log.Fatal("Cannot set up the mailer: ", err)
+	}
+
+	app.Mailer = appMailer
+
+	emailTemplates, err := emails.New("./email-template")
+	if err != nil {
+		log.Fatal("Cannot parse the email templates: ", err)
+	}
 
-	mailChannel := make(chan models.MailData)
-	app.MailChannel = mailChannel
+	app.Emails = emailTemplates
 
 	infoLog = log.New(os.Stdout, "INFO\t", log.Ldate|log.Ltime)
 	app.InfoLog = infoLog
@@ -118,16 +171,6 @@ func run() (*driver.DB, error) {
 
 	app.Session = session
 
-	// Connect to database
-	log.Println("Connecting to database...")
-	// connectionString := fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=%s", *dbHost, *dbPort, *dbName, *dbUser, *dbPassword, *dbSSL)
-	connectedDB, err := driver.ConnectSQL(dbURI)
-	if err != nil {
-		log.Fatal("Cannot connect to database. Closing application")
-	}
-
-	log.Println("Connected to database")
-
 	tc, err := render.CreateTemplateCache()
 	if err != nil {
 		log.Fatal("Cannot create template cache")
@@ -137,11 +180,42 @@ func run() (*driver.DB, error) {
 	app.TemplateCache = tc
 
 	// Variable to reference our app
-	repo := handlers.NewRepo(&app, connectedDB)
+	var repo *handlers.Repository
+	var connectedDB *driver.DB
+
+	if *repoType == "memory" {
+		log.Printf("Using the in-memory repository, log in as %s / %s\n", dbrepo.MemoryAdminEmail, dbrepo.MemoryAdminPassword)
+		repo = handlers.NewMemoryRepo(&app)
+	} else {
+		// Connect to database
+		log.Println("Connecting to database...")
+		// connectionString := fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=%s", *dbHost, *dbPort, *dbName, *dbUser, *dbPassword, *dbSSL)
+		connectedDB, err = driver.ConnectSQL(dbURI)
+		if err != nil {
+			log.Fatal("Cannot connect to database. Closing application")
+		}
+
+		log.Println("Connected to database")
+
+		repo = handlers.NewRepo(&app, connectedDB)
+	}
 
 	// Pass the repo variable back to the new handler
 	handlers.NewHandlers(repo)
 
+	// Load the settings admins can change, anything not saved yet keeps its default
+	settingsValues, err := repo.DB.AllSettings(context.Background())
+	if err != nil {
+		log.Fatal("Cannot load the settings: ", err)
+	}
+
+	app.Settings = settings.NewCache(settings.FromValues(settingsValues))
+	go refreshSettings(repo.DB, time.Minute)
+
+	// Send the emails the handlers put in the outbox
+	dispatcher = outbox.NewDispatcher(repo.DB, app.Mailer, infoLog, errorLog)
+	dispatcher.Workers = *mailWorkers
+
 	// Render the NewTemplates and add a reference to the AppConfig
 	render.NewRenderer(&app)
 
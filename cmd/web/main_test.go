@@ -1,8 +1,16 @@
 package main
 
-import "testing"
+import (
+	"os"
+	"testing"
+)
 
 func TestRun(t *testing.T) {
+	// run connects to postgres, so it needs a database to run against
+	if os.Getenv("DBURI") == "" {
+		t.Skip("DBURI is not set")
+	}
+
 	_, err := run()
 	if err != nil {
 		t.Error("Failed run()")
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/aidisapp/musiqcity_v2/internal/helpers"
+	"github.com/aidisapp/musiqcity_v2/internal/roles"
 	"github.com/justinas/nosurf"
 )
 
@@ -25,9 +26,10 @@ func SessionLoad(next http.Handler) http.Handler {
 	return session.LoadAndSave(next)
 }
 
+// Auth only lets users whose role grants some permission into the admin area
 func Auth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !helpers.IsAdmin(r) {
+		if !roles.CanAccessAdmin(helpers.Role(r)) {
 			session.Put(r.Context(), "error", "You do not have access to this page!!!")
 			http.Redirect(w, r, "/", http.StatusSeeOther)
 			return
@@ -36,3 +38,37 @@ func Auth(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// Verified only lets logged in users who have verified their email through
+func Verified(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !helpers.IsAuthenticated(r) {
+			session.Put(r.Context(), "warning", "You must be logged in")
+			http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+			return
+		}
+
+		if !helpers.IsVerified(r) {
+			session.Put(r.Context(), "error", "Kindly verify your email to continue")
+			http.Redirect(w, r, "/user/resend-verification", http.StatusSeeOther)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequirePermission only lets users whose role has permission through. Everyone else is sent back to the dashboard
+func RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !helpers.Can(r, permission) {
+				session.Put(r.Context(), "error", "You do not have permission to view that page")
+				http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+
+	"github.com/aidisapp/musiqcity_v2/internal/roles"
 )
 
 func TestNoSurf(testPointer *testing.T) {
@@ -30,3 +32,27 @@ func TestSessionLoad(testPointer *testing.T) {
 		testPointer.Error(fmt.Sprintf("type is not http.Handler but is %T", handlerType))
 	}
 }
+
+func TestVerified(testPointer *testing.T) {
+	var handlerObject myHandler
+	testHandler := Verified(&handlerObject)
+
+	switch handlerType := testHandler.(type) {
+	case http.Handler:
+		// do nothing
+	default:
+		testPointer.Error(fmt.Sprintf("type is not http.Handler but is %T", handlerType))
+	}
+}
+
+func TestRequirePermission(testPointer *testing.T) {
+	var handlerObject myHandler
+	testHandler := RequirePermission(roles.ManageBookings)(&handlerObject)
+
+	switch handlerType := testHandler.(type) {
+	case http.Handler:
+		// do nothing
+	default:
+		testPointer.Error(fmt.Sprintf("type is not http.Handler but is %T", handlerType))
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/aidisapp/musiqcity_v2/internal/config"
 	"github.com/aidisapp/musiqcity_v2/internal/handlers"
+	"github.com/aidisapp/musiqcity_v2/internal/roles"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
@@ -20,10 +21,21 @@ func routes(_ *config.AppConfig) http.Handler {
 	mux.Get("/", handlers.Repo.Home)
 	mux.Get("/artists", handlers.Repo.ArtistsPage)
 	mux.Get("/artists/{id}", handlers.Repo.SingleArtist)
+	mux.Get("/genres/{slug}", handlers.Repo.GenrePage)
+	mux.Post("/artists/{id}/book", handlers.Repo.PostArtistBooking)
+	mux.Get("/booking-summary", handlers.Repo.BookingSummary)
+
+	mux.Get("/manage", handlers.Repo.Manage)
+	mux.Post("/manage/cancel", handlers.Repo.PostManageCancel)
+	mux.Post("/manage/reschedule", handlers.Repo.PostManageReschedule)
 	mux.Get("/about", handlers.Repo.About)
 	mux.Get("/contact", handlers.Repo.Contact)
 
 	mux.Post("/reservation-json", handlers.Repo.AvailabilityJSON)
+	mux.Post("/artist-availability-json", handlers.Repo.ArtistAvailabilityJSON)
+	mux.Post("/available-artists-json", handlers.Repo.AvailableArtistsJSON)
+	mux.Get("/artists/{id}/unavailable-dates", handlers.Repo.ArtistUnavailableDatesJSON)
+	mux.Get("/api/search/suggest", handlers.Repo.SearchSuggest)
 
 	mux.Get("/make-reservation", handlers.Repo.MakeReservation)
 	mux.Post("/make-reservation", handlers.Repo.PostMakeReservation)
@@ -34,52 +46,141 @@ func routes(_ *config.AppConfig) http.Handler {
 	mux.Get("/user/signup", handlers.Repo.Signup)
 	mux.Post("/user/signup", handlers.Repo.PostSignup)
 	mux.Get("/verify-email", handlers.Repo.VerifyUserEmail)
+	mux.Get("/user/resend-verification", handlers.Repo.ResendVerification)
+	mux.Post("/user/resend-verification", handlers.Repo.PostResendVerification)
+	mux.Get("/user/forgot-password", handlers.Repo.ForgotPassword)
+	mux.Post("/user/forgot-password", handlers.Repo.PostForgotPassword)
+	mux.Get("/user/reset-password", handlers.Repo.ResetPassword)
+	mux.Post("/user/reset-password", handlers.Repo.PostResetPassword)
 	mux.Get("/user/logout", handlers.Repo.Logout)
-	mux.Get("/user/list-service", handlers.Repo.ListService)
-	mux.Post("/user/list-service", handlers.Repo.PostListService)
+
+	mux.Group(func(mux chi.Router) {
+		mux.Use(Verified)
+
+		mux.Get("/user/list-service", handlers.Repo.ListService)
+		mux.Post("/user/list-service", handlers.Repo.PostListService)
+		mux.Handle("/user/artists", http.RedirectHandler("/dashboard/artist", http.StatusMovedPermanently))
+		mux.Get("/user/bookings", handlers.Repo.UserBookings)
+
+		// the artist dashboard, each handler checks the artist belongs to the user
+		mux.Route("/dashboard/artist", func(mux chi.Router) {
+			mux.Get("/", handlers.Repo.UserArtists)
+			mux.Get("/{id}", handlers.Repo.UserArtist)
+			mux.Post("/{id}", handlers.Repo.PostUserArtist)
+			mux.Post("/{id}/submit", handlers.Repo.PostUserSubmitArtist)
+
+			mux.Get("/{id}/options", handlers.Repo.UserArtistOptions)
+			mux.Post("/{id}/options", handlers.Repo.PostUserArtistOptions)
+			mux.Get("/{id}/options/{optionID}", handlers.Repo.UserArtistOption)
+			mux.Post("/{id}/options/{optionID}", handlers.Repo.PostUserArtistOption)
+
+			mux.Get("/{id}/bookings", handlers.Repo.UserArtistBookings)
+			mux.Get("/{id}/bookings/{bookingID}", handlers.Repo.UserArtistBooking)
+			mux.Post("/{id}/bookings/{bookingID}/status", handlers.Repo.PostUserArtistBookingStatus)
+
+			mux.Get("/{id}/availability", handlers.Repo.UserArtistAvailability)
+			mux.Post("/{id}/availability", handlers.Repo.PostUserArtistAvailability)
+			mux.Post("/{id}/availability/{blockID}/delete", handlers.Repo.PostUserDeleteArtistBlock)
+		})
+	})
 
 	fileServer := http.FileServer(http.Dir("./static/"))
 	mux.Handle("/static/*", http.StripPrefix("/static", fileServer))
 
 	mux.Route("/admin", func(mux chi.Router) {
-		// Use the Auth middleware
+		// Use the Auth middleware, then each group needs its own permission
 		mux.Use(Auth)
 		mux.Get("/dashboard", handlers.Repo.AdminDashboard)
 
-		mux.Get("/reservations-calendar", handlers.Repo.AdminReservationsCalendar)
-		mux.Post("/reservations-calendar", handlers.Repo.AdminPostReservationsCalendar)
-
-		mux.Get("/rooms", handlers.Repo.AdminAllRooms)
-		mux.Get("/rooms/{id}", handlers.Repo.AdminSingleRoom)
-		mux.Post("/rooms/{id}", handlers.Repo.PostAdminSingleRoom)
-		mux.Get("/rooms/new-room", handlers.Repo.AdminNewRoom)
-		mux.Post("/rooms/new-room", handlers.Repo.PostAdminNewRoom)
-		mux.Get("/delete-room/{id}", handlers.Repo.AdminDeleteRoom)
-
-		mux.Get("/process-reservation/{src}/{id}/do", handlers.Repo.AdminProcessReservation)
-		mux.Get("/delete-reservation/{src}/{id}/do", handlers.Repo.AdminDeleteReservation)
-
 		mux.Get("/todo-list", handlers.Repo.AdminTodoList)
 		mux.Post("/todo-list", handlers.Repo.PostAdminTodoList)
 		mux.Get("/delete-todo/{id}", handlers.Repo.AdminDeleteTodo)
 
-		// Recent ----------
-		mux.Get("/artists", handlers.Repo.AdminAllArtists)
-		mux.Get("/artists/new-artist", handlers.Repo.AdminNewArtist)
-		mux.Post("/artists/new-artist", handlers.Repo.PostAdminNewArtist)
-		mux.Get("/artists/{id}", handlers.Repo.AdminSingleArtist)
-		mux.Post("/artists/{id}", handlers.Repo.PostAdminSingleArtist)
-
-		mux.Get("/all-bookings", handlers.Repo.AdminAllBookings)
-		mux.Get("/new-bookings", handlers.Repo.AdminNewBookings)
-
-		mux.Get("/booking-options", handlers.Repo.AdminAllOptions)
-		mux.Get("/booking-options/new-option", handlers.Repo.AdminNewOption)
-		mux.Post("/booking-options/new-option", handlers.Repo.PostAdminNewOption)
-		mux.Get("/booking-options/{id}", handlers.Repo.AdminSingleOption)
-		mux.Post("/booking-options/{id}", handlers.Repo.PostAdminSingleOption)
-
-		mux.Post("/create-booking", handlers.Repo.PostMakeReservation)
+		mux.Group(func(mux chi.Router) {
+			mux.Use(RequirePermission(roles.ManageRooms))
+
+			mux.Get("/reservations-calendar", handlers.Repo.AdminReservationsCalendar)
+			mux.Post("/reservations-calendar", handlers.Repo.AdminPostReservationsCalendar)
+			mux.Post("/reservations-calendar/blocks", handlers.Repo.AdminPostRoomBlocks)
+
+			mux.Get("/rooms", handlers.Repo.AdminAllRooms)
+			mux.Get("/rooms/{id}", handlers.Repo.AdminSingleRoom)
+			mux.Post("/rooms/{id}", handlers.Repo.PostAdminSingleRoom)
+			mux.Get("/rooms/new-room", handlers.Repo.AdminNewRoom)
+			mux.Post("/rooms/new-room", handlers.Repo.PostAdminNewRoom)
+			mux.Get("/delete-room/{id}", handlers.Repo.AdminDeleteRoom)
+
+			mux.Get("/reservations/{src}/{id}/show", handlers.Repo.AdminShowReservation)
+			mux.Post("/reservations/{src}/{id}/status", handlers.Repo.AdminPostReservationStatus)
+			mux.Get("/delete-reservation/{src}/{id}/do", handlers.Repo.AdminDeleteReservation)
+
+			mux.Post("/create-booking", handlers.Repo.PostMakeReservation)
+		})
+
+		mux.Group(func(mux chi.Router) {
+			mux.Use(RequirePermission(roles.ManageBookings))
+
+			mux.Get("/all-bookings", handlers.Repo.AdminAllBookings)
+			mux.Get("/new-bookings", handlers.Repo.AdminNewBookings)
+			mux.Get("/bookings/{src}/{id}/show", handlers.Repo.AdminShowBooking)
+			mux.Post("/bookings/{src}/{id}", handlers.Repo.PostAdminShowBooking)
+			mux.Post("/bookings/{src}/{id}/status", handlers.Repo.AdminPostBookingStatus)
+
+			mux.Get("/artists-calendar", handlers.Repo.AdminArtistsCalendar)
+			mux.Post("/artists-calendar", handlers.Repo.AdminPostArtistsCalendar)
+			mux.Post("/artists-calendar/blocks", handlers.Repo.AdminPostArtistBlocks)
+		})
+
+		mux.Group(func(mux chi.Router) {
+			mux.Use(RequirePermission(roles.ManageArtists))
+
+			mux.Get("/artists", handlers.Repo.AdminAllArtists)
+			mux.Get("/artists/pending", handlers.Repo.AdminPendingArtists)
+			mux.Get("/artists/new-artist", handlers.Repo.AdminNewArtist)
+			mux.Post("/artists/new-artist", handlers.Repo.PostAdminNewArtist)
+			mux.Get("/artists/{id}", handlers.Repo.AdminSingleArtist)
+			mux.Post("/artists/{id}", handlers.Repo.PostAdminSingleArtist)
+			mux.Post("/artists/{id}/status", handlers.Repo.AdminPostArtistStatus)
+
+			mux.Get("/genres", handlers.Repo.AdminGenres)
+			mux.Post("/genres", handlers.Repo.PostAdminGenres)
+			mux.Get("/genres/{id}", handlers.Repo.AdminGenre)
+			mux.Post("/genres/{id}", handlers.Repo.PostAdminGenre)
+			mux.Post("/genres/{id}/merge", handlers.Repo.AdminPostMergeGenre)
+			mux.Post("/genres/{id}/delete", handlers.Repo.AdminPostDeleteGenre)
+
+			mux.Get("/booking-options", handlers.Repo.AdminAllOptions)
+			mux.Get("/booking-options/new-option", handlers.Repo.AdminNewOption)
+			mux.Post("/booking-options/new-option", handlers.Repo.PostAdminNewOption)
+			mux.Get("/booking-options/{id}", handlers.Repo.AdminSingleOption)
+			mux.Post("/booking-options/{id}", handlers.Repo.PostAdminSingleOption)
+		})
+
+		mux.Group(func(mux chi.Router) {
+			mux.Use(RequirePermission(roles.ManageSettings))
+
+			mux.Get("/outbox", handlers.Repo.AdminOutbox)
+			mux.Post("/outbox/{id}/resend", handlers.Repo.AdminPostResendEmail)
+
+			mux.Get("/settings", handlers.Repo.AdminSettings)
+			mux.Post("/settings", handlers.Repo.AdminPostSettings)
+
+			mux.Get("/emails", handlers.Repo.AdminEmails)
+			mux.Get("/emails/{name}", handlers.Repo.AdminEmailPreview)
+			mux.Post("/emails/{name}/send", handlers.Repo.AdminPostTestEmail)
+		})
+
+		mux.Group(func(mux chi.Router) {
+			mux.Use(RequirePermission(roles.ManageUsers))
+
+			mux.Get("/users", handlers.Repo.AdminUsers)
+			mux.Get("/users/{id}", handlers.Repo.AdminShowUser)
+			mux.Post("/users/{id}/role", handlers.Repo.AdminPostUserRole)
+			mux.Post("/users/{id}/resend-verification", handlers.Repo.AdminPostUserVerification)
+			mux.Post("/users/{id}/reset-password", handlers.Repo.AdminPostUserPasswordReset)
+			mux.Post("/users/{id}/disabled", handlers.Repo.AdminPostUserDisabled)
+			mux.Post("/users/{id}/lock", handlers.Repo.AdminPostUserLock)
+		})
 	})
 
 	return mux
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/aidisapp/musiqcity_v2/internal/repository"
+	"github.com/aidisapp/musiqcity_v2/internal/settings"
+)
+
+// refreshSettings reloads the settings every interval, so changes saved through another server are picked up
+func refreshSettings(db repository.DatabaseRepo, interval time.Duration) {
+	for range time.Tick(interval) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		values, err := db.AllSettings(ctx)
+		cancel()
+
+		if err != nil {
+			errorLog.Println("reloading the settings:", err)
+			continue
+		}
+
+		app.Settings.Set(settings.FromValues(values))
+	}
+}
@@ -0,0 +1,161 @@
+package calendar
+
+import (
+	"errors"
+	"time"
+)
+
+// Repeat rules understood by Expand
+const (
+	RepeatNone         = "none"
+	RepeatWeekly       = "weekly"
+	RepeatMonthly      = "monthly"
+	RepeatFirstWeekend = "first_weekend"
+)
+
+// Kinds of cells rendered on the admin calendars
+const (
+	KindFree    = "free"
+	KindBlock   = "block"
+	KindBooking = "booking"
+)
+
+// maxOccurrences stops a recurring block from expanding forever
+const maxOccurrences = 370
+
+var ErrInvalidRange = errors.New("the end date can't be before the start date")
+var ErrInvalidRepeat = errors.New("unknown repeat rule")
+
+// DateRange is an inclusive range of days
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Entry is a booking, reservation or block shown on a calendar
+type Entry struct {
+	ID    int
+	Kind  string
+	Label string
+	Start time.Time
+	End   time.Time
+}
+
+// Cell is one column of a calendar row. Span is the number of days the cell covers
+type Cell struct {
+	Day   int
+	Date  string
+	Span  int
+	Kind  string
+	ID    int
+	Label string
+}
+
+// Expand turns a block from start to end into every occurrence of the repeat rule up to and including until
+func Expand(start, end time.Time, repeat string, until time.Time) ([]DateRange, error) {
+	start = truncateDay(start)
+	end = truncateDay(end)
+	until = truncateDay(until)
+
+	if end.Before(start) {
+		return nil, ErrInvalidRange
+	}
+
+	if repeat == "" || repeat == RepeatNone {
+		return []DateRange{{Start: start, End: end}}, nil
+	}
+
+	if until.Before(start) {
+		return nil, ErrInvalidRange
+	}
+
+	var ranges []DateRange
+	days := int(end.Sub(start).Hours() / 24)
+
+	switch repeat {
+	case RepeatWeekly:
+		for d := start; !d.After(until) && len(ranges) < maxOccurrences; d = d.AddDate(0, 0, 7) {
+			ranges = append(ranges, DateRange{Start: d, End: d.AddDate(0, 0, days)})
+		}
+	case RepeatMonthly:
+		for i := 0; len(ranges) < maxOccurrences; i++ {
+			d := start.AddDate(0, i, 0)
+			if d.After(until) {
+				break
+			}
+			// skip months that don't have the day, e.g. the 31st
+			if d.Day() != start.Day() {
+				continue
+			}
+			ranges = append(ranges, DateRange{Start: d, End: d.AddDate(0, 0, days)})
+		}
+	case RepeatFirstWeekend:
+		month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+		for ; !month.After(until) && len(ranges) < maxOccurrences; month = month.AddDate(0, 1, 0) {
+			saturday := month.AddDate(0, 0, (int(time.Saturday)-int(month.Weekday())+7)%7)
+			if saturday.Before(start) || saturday.After(until) {
+				continue
+			}
+			ranges = append(ranges, DateRange{Start: saturday, End: saturday.AddDate(0, 0, 1)})
+		}
+	default:
+		return nil, ErrInvalidRepeat
+	}
+
+	return ranges, nil
+}
+
+// MonthCells lays the entries out over the days of the month starting at firstOfMonth. Consecutive days
+// that belong to the same entry are merged into a single cell so the calendar shows contiguous ranges
+func MonthCells(firstOfMonth time.Time, entries []Entry) []Cell {
+	firstOfMonth = truncateDay(firstOfMonth)
+	lastOfMonth := firstOfMonth.AddDate(0, 1, -1)
+
+	// work out which entry owns each day, bookings take priority over blocks
+	owners := make([]*Entry, lastOfMonth.Day())
+	for i := range entries {
+		entry := &entries[i]
+		for d := truncateDay(entry.Start); !d.After(truncateDay(entry.End)); d = d.AddDate(0, 0, 1) {
+			if d.Before(firstOfMonth) || d.After(lastOfMonth) {
+				continue
+			}
+			current := owners[d.Day()-1]
+			if current == nil || (current.Kind != KindBooking && entry.Kind == KindBooking) {
+				owners[d.Day()-1] = entry
+			}
+		}
+	}
+
+	var cells []Cell
+	for i := 0; i < len(owners); i++ {
+		day := firstOfMonth.AddDate(0, 0, i)
+		owner := owners[i]
+
+		if owner == nil {
+			cells = append(cells, Cell{Day: i + 1, Date: day.Format("2006-01-02"), Span: 1, Kind: KindFree})
+			continue
+		}
+
+		span := 1
+		for i+span < len(owners) && owners[i+span] == owner {
+			span++
+		}
+
+		cells = append(cells, Cell{
+			Day:   i + 1,
+			Date:  day.Format("2006-01-02"),
+			Span:  span,
+			Kind:  owner.Kind,
+			ID:    owner.ID,
+			Label: owner.Label,
+		})
+		i += span - 1
+	}
+
+	return cells
+}
+
+// truncateDay drops the time of day from t
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
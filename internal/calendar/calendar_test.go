@@ -0,0 +1,119 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return t
+}
+
+func TestExpand_None(t *testing.T) {
+	ranges, err := Expand(date("2050-01-03"), date("2050-01-05"), RepeatNone, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ranges) != 1 || !ranges[0].Start.Equal(date("2050-01-03")) || !ranges[0].End.Equal(date("2050-01-05")) {
+		t.Errorf("expected one range from 2050-01-03 to 2050-01-05, got %v", ranges)
+	}
+}
+
+func TestExpand_InvalidRange(t *testing.T) {
+	_, err := Expand(date("2050-01-05"), date("2050-01-03"), RepeatNone, time.Time{})
+	if err != ErrInvalidRange {
+		t.Errorf("expected ErrInvalidRange, got %v", err)
+	}
+
+	_, err = Expand(date("2050-01-05"), date("2050-01-05"), RepeatWeekly, date("2050-01-01"))
+	if err != ErrInvalidRange {
+		t.Errorf("expected ErrInvalidRange when until is before start, got %v", err)
+	}
+
+	_, err = Expand(date("2050-01-05"), date("2050-01-05"), "fortnightly", date("2050-02-01"))
+	if err != ErrInvalidRepeat {
+		t.Errorf("expected ErrInvalidRepeat, got %v", err)
+	}
+}
+
+func TestExpand_Weekly(t *testing.T) {
+	// 2050-01-03 is a Monday
+	ranges, err := Expand(date("2050-01-03"), date("2050-01-03"), RepeatWeekly, date("2050-01-31"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ranges) != 5 {
+		t.Fatalf("expected 5 mondays in january 2050, got %d", len(ranges))
+	}
+
+	for _, r := range ranges {
+		if r.Start.Weekday() != time.Monday || !r.Start.Equal(r.End) {
+			t.Errorf("expected single day monday blocks, got %v", r)
+		}
+	}
+}
+
+func TestExpand_Monthly(t *testing.T) {
+	ranges, err := Expand(date("2050-01-31"), date("2050-01-31"), RepeatMonthly, date("2050-05-31"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// february and april have no 31st
+	if len(ranges) != 3 {
+		t.Errorf("expected 3 occurrences, got %d: %v", len(ranges), ranges)
+	}
+}
+
+func TestExpand_FirstWeekend(t *testing.T) {
+	ranges, err := Expand(date("2050-01-01"), date("2050-01-01"), RepeatFirstWeekend, date("2050-03-31"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ranges) != 3 {
+		t.Fatalf("expected 3 weekends, got %d", len(ranges))
+	}
+
+	for _, r := range ranges {
+		if r.Start.Weekday() != time.Saturday || r.End.Weekday() != time.Sunday || r.Start.Day() > 7 {
+			t.Errorf("expected the first saturday and sunday of the month, got %v", r)
+		}
+	}
+}
+
+func TestMonthCells(t *testing.T) {
+	entries := []Entry{
+		{ID: 1, Kind: KindBlock, Label: "Holiday", Start: date("2050-01-03"), End: date("2050-01-06")},
+		{ID: 2, Kind: KindBooking, Start: date("2050-01-05"), End: date("2050-01-07")},
+		{ID: 3, Kind: KindBlock, Start: date("2049-12-30"), End: date("2050-01-01")},
+	}
+
+	cells := MonthCells(date("2050-01-01"), entries)
+
+	total := 0
+	for _, c := range cells {
+		total += c.Span
+	}
+	if total != 31 {
+		t.Errorf("expected cells to cover 31 days, got %d", total)
+	}
+
+	expected := []Cell{
+		{Day: 1, Span: 1, Kind: KindBlock, ID: 3},
+		{Day: 2, Span: 1, Kind: KindFree},
+		{Day: 3, Span: 2, Kind: KindBlock, ID: 1},
+		{Day: 5, Span: 3, Kind: KindBooking, ID: 2},
+		{Day: 8, Span: 1, Kind: KindFree},
+	}
+
+	for i, e := range expected {
+		c := cells[i]
+		if c.Day != e.Day || c.Span != e.Span || c.Kind != e.Kind || c.ID != e.ID {
+			t.Errorf("cell %d: expected %+v, got %+v", i, e, c)
+		}
+	}
+}
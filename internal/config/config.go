@@ -3,8 +3,11 @@ package config
 import (
 	"html/template"
 	"log"
+	"time"
 
-	"github.com/aidisapp/musiqcity_v2/internal/models"
+	"github.com/aidisapp/musiqcity_v2/internal/emails"
+	"github.com/aidisapp/musiqcity_v2/internal/mailer"
+	"github.com/aidisapp/musiqcity_v2/internal/settings"
 	"github.com/alexedwards/scs/v2"
 )
 
@@ -15,5 +18,14 @@ type AppConfig struct {
 	ErrorLog      *log.Logger
 	InProduction  bool
 	Session       *scs.SessionManager
-	MailChannel   chan models.MailData
+	Mailer        mailer.Mailer
+	Emails        *emails.Templates
+	Settings      *settings.Cache
+
+	// How long before the start date customers can still cancel or reschedule
+	CancellationWindow time.Duration
+	RescheduleWindow   time.Duration
+
+	// Key that signs the cancel and reschedule links in customer emails
+	ManageTokenSecret []byte
 }
@@ -0,0 +1,254 @@
+package emails
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"path/filepath"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/aidisapp/musiqcity_v2/internal/models"
+)
+
+// Email is the data of one named email template. Every template has its own data type, so a handler can't
+// render a template with data it doesn't expect
+type Email interface {
+	Template() string
+}
+
+// Templates holds the parsed email templates. Each template name.email.html is the HTML body and
+// name.email.txt defines the "subject" and the plain-text body, both wrapped in the basic layouts
+type Templates struct {
+	html map[string]*htmltemplate.Template
+	text map[string]*texttemplate.Template
+}
+
+var functions = map[string]any{
+	"humanDate": func(t time.Time) string {
+		return t.Format("2006-01-02")
+	},
+}
+
+// New parses every email template in dir
+func New(dir string) (*Templates, error) {
+	pages, err := filepath.Glob(filepath.Join(dir, "*.email.html"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("no email templates found in %s", dir)
+	}
+
+	t := &Templates{
+		html: make(map[string]*htmltemplate.Template),
+		text: make(map[string]*texttemplate.Template),
+	}
+
+	for _, page := range pages {
+		name := strings.TrimSuffix(filepath.Base(page), ".email.html")
+
+		html, err := htmltemplate.New(name).Funcs(functions).ParseFiles(filepath.Join(dir, "basic.layout.html"), page)
+		if err != nil {
+			return nil, err
+		}
+
+		text, err := texttemplate.New(name).Funcs(functions).ParseFiles(filepath.Join(dir, "basic.layout.txt"), filepath.Join(dir, name+".email.txt"))
+		if err != nil {
+			return nil, err
+		}
+
+		if text.Lookup("subject") == nil {
+			return nil, fmt.Errorf("email template %s has no subject", name)
+		}
+
+		t.html[name] = html
+		t.text[name] = text
+	}
+
+	return t, nil
+}
+
+// Render builds the message for data, with its subject and both of its bodies
+func (t *Templates) Render(from, to string, data Email) (models.MailData, error) {
+	name := data.Template()
+
+	html, ok := t.html[name]
+	if !ok {
+		return models.MailData{}, fmt.Errorf("email template %s does not exist", name)
+	}
+	text := t.text[name]
+
+	var subject, textBody, htmlBody bytes.Buffer
+
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return models.MailData{}, err
+	}
+	if err := text.ExecuteTemplate(&textBody, "layout", data); err != nil {
+		return models.MailData{}, err
+	}
+	if err := html.ExecuteTemplate(&htmlBody, "layout", data); err != nil {
+		return models.MailData{}, err
+	}
+
+	return models.MailData{
+		To:       to,
+		From:     from,
+		Subject:  strings.Join(strings.Fields(subject.String()), " "),
+		Content:  htmlBody.String(),
+		Text:     strings.TrimSpace(textBody.String()) + "\n",
+		Template: name,
+	}, nil
+}
+
+// ReservationConfirmation is sent to a customer who made a reservation
+type ReservationConfirmation struct {
+	FirstName string
+	RoomName  string
+	StartDate time.Time
+	EndDate   time.Time
+	ManageURL string
+}
+
+func (ReservationConfirmation) Template() string { return "reservation-confirmation" }
+
+// BookingConfirmation is sent to a customer who booked an artist
+type BookingConfirmation struct {
+	FirstName  string
+	ArtistName string
+	Option     string
+	StartDate  time.Time
+	EndDate    time.Time
+	ManageURL  string
+}
+
+func (BookingConfirmation) Template() string { return "booking-confirmation" }
+
+// Rescheduled is sent to a customer who moved their reservation or booking to new dates
+type Rescheduled struct {
+	FirstName string
+	Kind      string
+	StartDate time.Time
+	EndDate   time.Time
+	ManageURL string
+}
+
+func (Rescheduled) Template() string { return "rescheduled" }
+
+// StatusChanged is sent to a customer when their reservation or booking moves to a new status
+type StatusChanged struct {
+	FirstName string
+	Kind      string
+	StartDate time.Time
+	EndDate   time.Time
+	Status    string
+	Message   string
+}
+
+func (StatusChanged) Template() string { return "status-changed" }
+
+// Detail is one labelled line of an admin notification
+type Detail struct {
+	Label string
+	Value string
+}
+
+// AdminNotification tells the admin that something happened, Event is used as the subject
+type AdminNotification struct {
+	Event   string
+	Summary string
+	Details []Detail
+}
+
+func (AdminNotification) Template() string { return "admin-notification" }
+
+// VerifyEmail is sent to a new user with the link that verifies their email address
+type VerifyEmail struct {
+	FirstName string
+	LastName  string
+	VerifyURL string
+	// ValidFor says how long the link works, like "48 hours"
+	ValidFor string
+}
+
+func (VerifyEmail) Template() string { return "verify-email" }
+
+// EmailVerified is sent to a user once their email address is verified
+type EmailVerified struct {
+	FirstName string
+}
+
+func (EmailVerified) Template() string { return "email-verified" }
+
+// PasswordReset is sent to a user who asked to reset their password, with the link that lets them choose a new one
+type PasswordReset struct {
+	FirstName string
+	ResetURL  string
+	// ValidFor says how long the link works, like "1 hour"
+	ValidFor string
+}
+
+func (PasswordReset) Template() string { return "password-reset" }
+
+// ListingReviewed is sent to the owner of an artist listing when an admin approves, rejects or suspends it
+type ListingReviewed struct {
+	FirstName  string
+	ArtistName string
+	Status     string
+	// Reason is why the listing was rejected or suspended, empty when it was approved
+	Reason    string
+	ManageURL string
+}
+
+func (ListingReviewed) Template() string { return "listing-reviewed" }
+
+// Names returns the names of every parsed template, sorted
+func (t *Templates) Names() []string {
+	names := make([]string, 0, len(t.html))
+	for name := range t.html {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Samples returns every email filled with made up data, so admins can preview the templates and send test copies
+func Samples() []Email {
+	start := time.Now().AddDate(0, 1, 0).Truncate(24 * time.Hour)
+	end := start.AddDate(0, 0, 2)
+	manageURL := "https://musiqcity.com/manage?token=sample"
+
+	return []Email{
+		AdminNotification{
+			Event:   "New Booking",
+			Summary: "There is a new booking from Ama Mensah",
+			Details: []Detail{
+				{Label: "Booking Dates", Value: fmt.Sprintf("%s, to %s", start.Format("2006-01-02"), end.Format("2006-01-02"))},
+				{Label: "Artist", Value: "The Lagos Horns"},
+				{Label: "Booking Option", Value: "Full Band (1500)"},
+				{Label: "Customer Email", Value: "ama@example.com"},
+			},
+		},
+		BookingConfirmation{FirstName: "Ama", ArtistName: "The Lagos Horns", Option: "Full Band", StartDate: start, EndDate: end, ManageURL: manageURL},
+		EmailVerified{FirstName: "Ama"},
+		ListingReviewed{FirstName: "Ama", ArtistName: "The Accra Choir", Status: "Rejected", Reason: "Please, add a description of your act.", ManageURL: "https://musiqcity.com/dashboard/artist/1"},
+		PasswordReset{FirstName: "Ama", ResetURL: "https://musiqcity.com/user/reset-password?token=sample", ValidFor: "1 hour"},
+		Rescheduled{FirstName: "Ama", Kind: "booking", StartDate: start, EndDate: end, ManageURL: manageURL},
+		ReservationConfirmation{FirstName: "Ama", RoomName: "Studio A", StartDate: start, EndDate: end, ManageURL: manageURL},
+		StatusChanged{FirstName: "Ama", Kind: "reservation", StartDate: start, EndDate: end, Status: "Confirmed", Message: "Everything is set. We look forward to seeing you."},
+		VerifyEmail{FirstName: "Ama", LastName: "Mensah", VerifyURL: "https://musiqcity.com/verify-email?token=sample", ValidFor: "48 hours"},
+	}
+}
+
+// Sample returns the sample data of the named template
+func Sample(name string) (Email, bool) {
+	for _, sample := range Samples() {
+		if sample.Template() == name {
+			return sample, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,119 @@
+package emails
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var startDate = time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// every template with data that exercises all of its fields
+var theTests = []struct {
+	data            Email
+	expectedSubject string
+	expectedText    string
+}{
+	{ReservationConfirmation{FirstName: "Ama", RoomName: "Studio A", StartDate: startDate, EndDate: startDate.AddDate(0, 0, 2), ManageURL: "https://musiqcity.com/manage?token=abc"},
+		"Reservation Confirmation", "reservation of Studio A from 2050-01-01, to 2050-01-03"},
+	{BookingConfirmation{FirstName: "Ama", ArtistName: "DJ Kofi", Option: "Full Set", StartDate: startDate, EndDate: startDate, ManageURL: "https://musiqcity.com/manage?token=abc"},
+		"Booking Confirmation", "booking of DJ Kofi (Full Set)"},
+	{Rescheduled{FirstName: "Ama", Kind: "booking", StartDate: startDate, EndDate: startDate, ManageURL: "https://musiqcity.com/manage?token=abc"},
+		"Your booking has been rescheduled", "Your booking is now from 2050-01-01"},
+	{StatusChanged{FirstName: "Ama", Kind: "reservation", StartDate: startDate, EndDate: startDate, Status: "Confirmed", Message: "Everything is set."},
+		"Your reservation is Confirmed", "is now Confirmed.\n\nEverything is set."},
+	{AdminNotification{Event: "New Booking", Summary: "There is a new booking from Ama Mensah", Details: []Detail{{Label: "Artist", Value: "DJ Kofi"}}},
+		"New Booking", "Artist: DJ Kofi"},
+	{VerifyEmail{FirstName: "Ama", LastName: "Mensah", VerifyURL: "https://musiqcity.com/verify-email?token=abc", ValidFor: "48 hours"},
+		"Verify Your Email", "https://musiqcity.com/verify-email?token=abc\n\nThe link can only be used once and expires in 48 hours."},
+	{EmailVerified{FirstName: "Ama"},
+		"Email Verified", "Your email has been verified."},
+	{PasswordReset{FirstName: "Ama", ResetURL: "https://musiqcity.com/user/reset-password?token=abc", ValidFor: "1 hour"},
+		"Reset Your Password", "https://musiqcity.com/user/reset-password?token=abc\n\nThe link can only be used once and expires in 1 hour."},
+}
+
+func TestRender(t *testing.T) {
+	templates, err := New("./../../email-template")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range theTests {
+		name := e.data.Template()
+
+		mail, err := templates.Render("from@example.com", "to@example.com", e.data)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+
+		if mail.Subject != e.expectedSubject {
+			t.Errorf("%s: expected subject %q but got %q", name, e.expectedSubject, mail.Subject)
+		}
+		if !strings.Contains(mail.Text, e.expectedText) {
+			t.Errorf("%s: expected the text to contain %q but got:\n%s", name, e.expectedText, mail.Text)
+		}
+		if strings.Contains(mail.Text, "<") {
+			t.Errorf("%s: expected no HTML in the text but got:\n%s", name, mail.Text)
+		}
+		if !strings.Contains(mail.Content, "<container>") {
+			t.Errorf("%s: expected the HTML to use the layout", name)
+		}
+		if mail.To != "to@example.com" || mail.From != "from@example.com" || mail.Template != name {
+			t.Errorf("%s: unexpected addresses or template in %+v", name, mail)
+		}
+	}
+}
+
+func TestRenderEscapesHTML(t *testing.T) {
+	templates, err := New("./../../email-template")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mail, err := templates.Render("from@example.com", "to@example.com", EmailVerified{FirstName: `<script>alert("hi")</script>`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(mail.Content, "<script>") {
+		t.Errorf("expected the name to be escaped in the HTML but got:\n%s", mail.Content)
+	}
+	if !strings.Contains(mail.Text, `Hi <script>alert("hi")</script>,`) {
+		t.Errorf("expected the name as it is in the text but got:\n%s", mail.Text)
+	}
+}
+
+func TestMissingTemplates(t *testing.T) {
+	_, err := New(t.TempDir())
+	if err == nil {
+		t.Error("expected an error for a directory without email templates")
+	}
+}
+
+func TestSamples(t *testing.T) {
+	templates, err := New("./../../email-template")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, sample := range Samples() {
+		names = append(names, sample.Template())
+
+		if _, err := templates.Render("from@example.com", "to@example.com", sample); err != nil {
+			t.Errorf("%s: %v", sample.Template(), err)
+		}
+	}
+
+	if strings.Join(names, ",") != strings.Join(templates.Names(), ",") {
+		t.Errorf("expected one sample for each of %v but got %v", templates.Names(), names)
+	}
+
+	if _, ok := Sample("verify-email"); !ok {
+		t.Error("expected a sample for verify-email")
+	}
+	if _, ok := Sample("nope"); ok {
+		t.Error("expected no sample for an unknown template")
+	}
+}
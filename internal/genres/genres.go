@@ -0,0 +1,64 @@
+package genres
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slug turns a genre name into the part of its url, and is also how spellings of a genre are compared:
+// "Hip Hop", "hip-hop" and "HIP HOP!" all have the slug "hip-hop". The migration that created the genres
+// table computes slugs the same way
+func Slug(name string) string {
+	var b strings.Builder
+
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+
+	return b.String()
+}
+
+// Split parses a list of genres typed into a form, separated by commas, semicolons, slashes or bars.
+// Names are trimmed, and names without a slug or with the slug of an earlier one are left out
+func Split(s string) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return strings.ContainsRune(",;/|\n", r)
+	})
+
+	for _, part := range parts {
+		name := strings.Join(strings.Fields(part), " ")
+		slug := Slug(name)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// Aliases splits s like Split into the aliases of the genre called name, leaving out spellings of the name itself
+func Aliases(name, s string) []string {
+	var aliases []string
+
+	for _, alias := range Split(s) {
+		if Slug(alias) != Slug(name) {
+			aliases = append(aliases, alias)
+		}
+	}
+
+	return aliases
+}
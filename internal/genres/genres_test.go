@@ -0,0 +1,42 @@
+package genres
+
+import (
+	"reflect"
+	"testing"
+)
+
+var slugTests = []struct {
+	name     string
+	expected string
+}{
+	{"Afrobeat", "afrobeat"},
+	{"Hip Hop", "hip-hop"},
+	{"  hip-hop!", "hip-hop"},
+	{"R&B", "r-b"},
+	{"Musique Française", "musique-française"},
+	{"---", ""},
+}
+
+func TestSlug(t *testing.T) {
+	for _, e := range slugTests {
+		if got := Slug(e.name); got != e.expected {
+			t.Errorf("%q: expected %q but got %q", e.name, e.expected, got)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	got := Split(" Afrobeat,afro beat; Hip  Hop / hip-hop | ,R&B")
+	expected := []string{"Afrobeat", "afro beat", "Hip Hop", "R&B"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v but got %v", expected, got)
+	}
+}
+
+func TestAliases(t *testing.T) {
+	got := Aliases("Afrobeat", "Afro-Beat, afrobeat, Afrobeats")
+	expected := []string{"Afro-Beat", "Afrobeats"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v but got %v", expected, got)
+	}
+}
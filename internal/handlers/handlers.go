@@ -1,25 +1,39 @@
 package handlers
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/aidisapp/musiqcity_v2/internal/calendar"
 	"github.com/aidisapp/musiqcity_v2/internal/config"
+	"github.com/aidisapp/musiqcity_v2/internal/emails"
 	"github.com/aidisapp/musiqcity_v2/internal/driver"
 	"github.com/aidisapp/musiqcity_v2/internal/forms"
+	"github.com/aidisapp/musiqcity_v2/internal/genres"
 	"github.com/aidisapp/musiqcity_v2/internal/helpers"
+	"github.com/aidisapp/musiqcity_v2/internal/listing"
 	"github.com/aidisapp/musiqcity_v2/internal/models"
+	"github.com/aidisapp/musiqcity_v2/internal/outbox"
+	"github.com/aidisapp/musiqcity_v2/internal/ratelimit"
 	"github.com/aidisapp/musiqcity_v2/internal/render"
 	"github.com/aidisapp/musiqcity_v2/internal/repository"
 	"github.com/aidisapp/musiqcity_v2/internal/repository/dbrepo"
+	"github.com/aidisapp/musiqcity_v2/internal/roles"
+	"github.com/aidisapp/musiqcity_v2/internal/settings"
+	"github.com/aidisapp/musiqcity_v2/internal/status"
 	"github.com/go-chi/chi/v5"
-	"github.com/golang-jwt/jwt"
 	"github.com/joho/godotenv"
 )
 
@@ -29,21 +43,35 @@ var Repo *Repository
 type Repository struct {
 	App *config.AppConfig
 	DB  repository.DatabaseRepo
+
+	// linkLimiter limits how often each address can ask for password reset and verification links
+	linkLimiter *ratelimit.Limiter
 }
 
 // This function creates a new repository
 func NewRepo(appConfig *config.AppConfig, dbConnectionPool *driver.DB) *Repository {
 	return &Repository{
-		App: appConfig,
-		DB:  dbrepo.NewPostgresRepo(dbConnectionPool.SQL, appConfig),
+		App:         appConfig,
+		DB:          dbrepo.NewPostgresRepo(dbConnectionPool.SQL, appConfig),
+		linkLimiter: ratelimit.New(linkRequestLimit, linkRequestWindow),
+	}
+}
+
+// This function creates a new repository that keeps its data in memory
+func NewMemoryRepo(appConfig *config.AppConfig) *Repository {
+	return &Repository{
+		App:         appConfig,
+		DB:          dbrepo.NewMemoryRepo(appConfig),
+		linkLimiter: ratelimit.New(linkRequestLimit, linkRequestWindow),
 	}
 }
 
 // This function creates a new repository
 func NewTestRepo(appConfig *config.AppConfig) *Repository {
 	return &Repository{
-		App: appConfig,
-		DB:  dbrepo.NewTestRepo(appConfig),
+		App:         appConfig,
+		DB:          dbrepo.NewTestRepo(appConfig),
+		linkLimiter: ratelimit.New(linkRequestLimit, linkRequestWindow),
 	}
 }
 
@@ -54,7 +82,7 @@ func NewHandlers(r *Repository) {
 
 // This function handles the Home page and renders the template
 func (m *Repository) Home(w http.ResponseWriter, r *http.Request) {
-	artists, err := m.DB.AllArtists()
+	artists, _, err := m.DB.SearchArtists(r.Context(), repository.ArtistFilter{Sort: repository.SortNewest, Limit: homeArtists})
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
@@ -67,1049 +95,4471 @@ func (m *Repository) Home(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// This function handles the Home page and renders the template
+// homeArtists is how many of the newest artists the home page shows
+const homeArtists = 12
+
+// artistsPerPage is the size of a page of artist search results
+const artistsPerPage = 12
+
+// maxArtistPages caps the page asked for, so that its offset can't overflow
+const maxArtistPages = 10000
+
+// priceBand is a range of prices the artists page can filter by. A zero Min or Max is open ended
+type priceBand struct {
+	Value string
+	Label string
+	Min   int
+	Max   int
+}
+
+var priceBands = []priceBand{
+	{Value: "under-500", Label: "Under 500", Max: 499},
+	{Value: "500-1000", Label: "500 to 1,000", Min: 500, Max: 1000},
+	{Value: "1000-2500", Label: "1,001 to 2,500", Min: 1001, Max: 2500},
+	{Value: "over-2500", Label: "Over 2,500", Min: 2501},
+}
+
+// artistSort is a link that orders the artists page
+type artistSort struct {
+	Label  string
+	URL    string
+	Active bool
+}
+
+// Handles the artists page. The query string filters, orders and pages the artists, and every filter is optional
 func (m *Repository) ArtistsPage(w http.ResponseWriter, r *http.Request) {
-	artists, err := m.DB.AllArtists()
-	if err != nil {
-		helpers.ServerError(w, err)
-		return
+	query := r.URL.Query()
+
+	filter := repository.ArtistFilter{
+		Query: strings.TrimSpace(query.Get("q")),
+		Genre: genres.Slug(query.Get("genre")),
+		City:  strings.TrimSpace(query.Get("city")),
+		Sort:  query.Get("sort"),
+		Limit: artistsPerPage,
 	}
 
-	data := make(map[string]interface{})
-	data["artists"] = artists
-	render.Template(w, r, "artists.page.html", &models.TemplateData{
-		Data: data,
-	})
-}
+	for _, band := range priceBands {
+		if band.Value == query.Get("price") {
+			filter.MinPrice = band.Min
+			filter.MaxPrice = band.Max
+		}
+	}
 
-// This function handles the single room(Luxery) page and renders the template
-func (m *Repository) SingleArtist(w http.ResponseWriter, r *http.Request) {
-	urlParams := strings.Split(r.RequestURI, "/")
-	id, err := strconv.Atoi(urlParams[2])
+	if date, err := time.Parse("2006-01-02", query.Get("date")); err == nil {
+		filter.AvailableFrom = date
+		filter.AvailableTo = date
+	} else {
+		query.Del("date")
+	}
+
+	// a search is ordered by relevance unless another order was picked
+	switch filter.Sort {
+	case repository.SortName, repository.SortNewest, repository.SortPrice:
+	case repository.SortRelevance:
+		if filter.Query == "" {
+			filter.Sort = repository.SortName
+		}
+	default:
+		filter.Sort = repository.SortName
+		if filter.Query != "" {
+			filter.Sort = repository.SortRelevance
+		}
+	}
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	page = min(page, maxArtistPages)
+	filter.Offset = (page - 1) * artistsPerPage
+
+	artists, total, err := m.DB.SearchArtists(r.Context(), filter)
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	artist, err := m.DB.GetArtistByID(id)
+	allGenres, err := m.DB.AllGenres(r.Context())
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	options, err := m.DB.AllArtistBookingOptions(id)
-	if err != nil {
-		helpers.ServerError(w, err)
+	// pageURL keeps the filters of this page and changes one query parameter
+	pageURL := func(key, value string) string {
+		values := url.Values{}
+		for k, v := range query {
+			values[k] = v
+		}
+		values.Set(key, value)
+		if key != "page" {
+			values.Del("page")
+		}
+		return "/artists?" + values.Encode()
+	}
+
+	var sorts []artistSort
+	for _, x := range []struct{ value, label string }{
+		{repository.SortRelevance, "Relevance"},
+		{repository.SortName, "Name"},
+		{repository.SortNewest, "Newest"},
+		{repository.SortPrice, "Price"},
+	} {
+		// relevance only means something for a search
+		if x.value == repository.SortRelevance && filter.Query == "" {
+			continue
+		}
+		sorts = append(sorts, artistSort{Label: x.label, URL: pageURL("sort", x.value), Active: x.value == filter.Sort})
+	}
+
+	pages := max(1, (total+artistsPerPage-1)/artistsPerPage)
+
+	// a page past the last one goes to the last page
+	if page > pages {
+		http.Redirect(w, r, pageURL("page", strconv.Itoa(pages)), http.StatusSeeOther)
 		return
 	}
 
 	data := make(map[string]interface{})
-	data["artist"] = artist
-	data["options"] = options
-
-	render.Template(w, r, "single-artist.page.html", &models.TemplateData{
-		Data: data,
-	})
-}
+	data["artists"] = artists
+	data["genres"] = allGenres
+	data["price_bands"] = priceBands
+	data["sorts"] = sorts
 
-// This function handles the About page and renders the template
-func (m *Repository) About(w http.ResponseWriter, r *http.Request) {
-	render.Template(w, r, "about.page.html", &models.TemplateData{})
-}
+	stringMap := make(map[string]string)
+	stringMap["q"] = filter.Query
+	stringMap["genre"] = filter.Genre
+	stringMap["city"] = filter.City
+	stringMap["price"] = query.Get("price")
+	stringMap["date"] = query.Get("date")
+	if page > 1 {
+		stringMap["previous_url"] = pageURL("page", strconv.Itoa(page-1))
+	}
+	if page < pages {
+		stringMap["next_url"] = pageURL("page", strconv.Itoa(page+1))
+	}
 
-// This function handles the Contact page and renders the template
-func (m *Repository) Contact(w http.ResponseWriter, r *http.Request) {
-	render.Template(w, r, "contact.page.html", &models.TemplateData{})
-}
+	intMap := make(map[string]int)
+	intMap["page"] = page
+	intMap["pages"] = pages
+	intMap["total"] = total
 
-// Availability json, to handle availability request and send back json
-type jsonResponse struct {
-	Ok        bool   `json:"ok"`
-	Message   string `json:"message"`
-	StartDate string `json:"start_date"`
-	EndDate   string `json:"end_date"`
-	RoomID    string `json:"room_id"`
+	render.Template(w, r, "artists.page.html", &models.TemplateData{
+		StringMap: stringMap,
+		IntMap:    intMap,
+		Data:      data,
+	})
 }
 
-// This function checks if the dates entered in a single room search has availability
-func (m *Repository) AvailabilityJSON(w http.ResponseWriter, r *http.Request) {
-	err := r.ParseForm()
+// Handles the landing page of a genre, which lists its approved artists. The slug of an alias redirects to
+// the page of the genre
+func (m *Repository) GenrePage(w http.ResponseWriter, r *http.Request) {
+	slug, err := url.PathUnescape(chi.URLParam(r, "slug"))
 	if err != nil {
-		response := jsonResponse{
-			Ok:      false,
-			Message: "Internal server error",
-		}
+		slug = ""
+	}
 
-		out, _ := json.MarshalIndent(response, "", "    ")
-		w.Header().Set("Content-Type", "application/json")
-		w.Write(out)
+	genre, err := m.DB.GetGenreBySlug(r.Context(), slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		m.App.Session.Put(r.Context(), "error", "That genre does not exist")
+		http.Redirect(w, r, "/artists", http.StatusSeeOther)
+		return
+	} else if err != nil {
+		helpers.ServerError(w, err)
 		return
 	}
 
-	startDate, err := time.Parse("2006-01-02", r.Form.Get("start"))
-	if err != nil {
-		helpers.ServerError(w, err)
+	genreURL := "/genres/" + url.PathEscape(genre.Slug)
+	if genre.Slug != slug {
+		if r.URL.RawQuery != "" {
+			genreURL += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, genreURL, http.StatusMovedPermanently)
 		return
 	}
 
-	endDate, err := time.Parse("2006-01-02", r.Form.Get("end"))
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	page = min(page, maxArtistPages)
+
+	artists, total, err := m.DB.SearchArtists(r.Context(), repository.ArtistFilter{
+		Genre:  genre.Slug,
+		Limit:  artistsPerPage,
+		Offset: (page - 1) * artistsPerPage,
+	})
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	roomId, _ := strconv.Atoi(r.Form.Get("room_id"))
-
-	available, err := m.DB.SearchAvailabilityByDatesByRoomID(startDate, endDate, roomId)
-	if err != nil {
-		response := jsonResponse{
-			Ok:      false,
-			Message: "Error connecting to the database",
-		}
+	pages := max(1, (total+artistsPerPage-1)/artistsPerPage)
 
-		out, _ := json.MarshalIndent(response, "", "    ")
-		w.Header().Set("Content-Type", "application/json")
-		w.Write(out)
+	if page > pages {
+		http.Redirect(w, r, fmt.Sprintf("%s?page=%d", genreURL, pages), http.StatusSeeOther)
 		return
 	}
 
-	response := jsonResponse{
-		Ok:        available,
-		Message:   "",
-		StartDate: r.Form.Get("start"),
-		EndDate:   r.Form.Get("end"),
-		RoomID:    r.Form.Get("room_id"),
+	data := make(map[string]interface{})
+	data["genre"] = genre
+	data["artists"] = artists
+
+	stringMap := make(map[string]string)
+	stringMap["search_url"] = "/artists?genre=" + url.QueryEscape(genre.Slug)
+	if page > 1 {
+		stringMap["previous_url"] = fmt.Sprintf("%s?page=%d", genreURL, page-1)
+	}
+	if page < pages {
+		stringMap["next_url"] = fmt.Sprintf("%s?page=%d", genreURL, page+1)
 	}
 
-	out, _ := json.MarshalIndent(response, "", "    ")
+	intMap := make(map[string]int)
+	intMap["page"] = page
+	intMap["pages"] = pages
+	intMap["total"] = total
 
-	// Tell the browser the type of file in the header
-	w.Header().Set("Content-Type", "Application/json")
-	w.Write(out)
+	render.Template(w, r, "genre.page.html", &models.TemplateData{
+		StringMap: stringMap,
+		IntMap:    intMap,
+		Data:      data,
+	})
 }
 
-// This function handles the make reservation page and renders the template
-func (m *Repository) MakeReservation(w http.ResponseWriter, r *http.Request) {
-	reservationInSession, ok := m.App.Session.Get(r.Context(), "reservation").(models.Reservation)
-	if !ok {
-		m.App.Session.Put(r.Context(), "error", "Can't get reservation from session")
-		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+// This function handles the single room(Luxery) page and renders the template
+func (m *Repository) SingleArtist(w http.ResponseWriter, r *http.Request) {
+	urlParams := strings.Split(r.RequestURI, "/")
+	id, err := strconv.Atoi(urlParams[2])
+	if err != nil {
+		helpers.ServerError(w, err)
 		return
 	}
 
-	room, err := m.DB.GetRoomByID(reservationInSession.RoomID)
-	if err != nil {
-		m.App.Session.Put(r.Context(), "error", "Can't find room")
-		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+	// artists whose listing isn't approved look the same as missing ones
+	artist, err := m.DB.GetArtistByID(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		m.App.Session.Put(r.Context(), "error", "That artist does not exist")
+		http.Redirect(w, r, "/artists", http.StatusSeeOther)
+		return
+	} else if err != nil {
+		helpers.ServerError(w, err)
 		return
 	}
 
-	reservationInSession.Room.RoomName = room.RoomName
-
-	startDate := reservationInSession.StartDate.Format("2006-01-02")
-	endDate := reservationInSession.EndDate.Format("2006-01-02")
-
-	stringMap := make(map[string]string)
-	stringMap["start_date"] = startDate
-	stringMap["end_date"] = endDate
+	options, err := m.DB.AllArtistBookingOptions(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
 
 	data := make(map[string]interface{})
-	data["reservation"] = reservationInSession
-
-	m.App.Session.Put(r.Context(), "reservation", reservationInSession)
+	data["artist"] = artist
+	data["options"] = options
+	data["booking"] = models.Bookings{}
 
-	render.Template(w, r, "make-reservation.page.html", &models.TemplateData{
-		Form:      forms.New(nil),
-		Data:      data,
-		StringMap: stringMap,
+	render.Template(w, r, "single-artist.page.html", &models.TemplateData{
+		Data: data,
+		Form: forms.New(nil),
 	})
 }
 
-// This function POST the reservation and store them in the database
-func (m *Repository) PostMakeReservation(w http.ResponseWriter, r *http.Request) {
-	reservation, ok := m.App.Session.Get(r.Context(), "reservation").(models.Reservation)
-	if !ok {
-		m.App.Session.Put(r.Context(), "error", "Can't get reservation from session")
-		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+// This function POST the artist booking form, checks availability and stores the booking in the database
+func (m *Repository) PostArtistBooking(w http.ResponseWriter, r *http.Request) {
+	artistID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "Invalid artist")
+		http.Redirect(w, r, "/artists", http.StatusSeeOther)
 		return
 	}
 
-	err := r.ParseForm()
+	err = r.ParseForm()
 	if err != nil {
 		m.App.Session.Put(r.Context(), "error", "Can't parse form")
-		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+		http.Redirect(w, r, fmt.Sprintf("/artists/%d", artistID), http.StatusSeeOther)
 		return
 	}
 
-	reservation.FirstName = r.Form.Get("first_name")
-	reservation.LastName = r.Form.Get("last_name")
-	reservation.Email = r.Form.Get("email")
-	reservation.Phone = r.Form.Get("phone")
+	artist, err := m.DB.GetArtistByID(r.Context(), artistID)
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "Can't find artist")
+		http.Redirect(w, r, "/artists", http.StatusSeeOther)
+		return
+	}
 
-	form := forms.New(r.PostForm)
+	options, err := m.DB.AllArtistBookingOptions(r.Context(), artistID)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	booking := models.Bookings{
+		FirstName:     r.Form.Get("first_name"),
+		LastName:      r.Form.Get("last_name"),
+		Email:         r.Form.Get("email"),
+		Phone:         r.Form.Get("phone"),
+		EventLocation: r.Form.Get("event_location"),
+		Message:       r.Form.Get("message"),
+		ArtistID:      artistID,
+		Artist:        artist,
+		UserID:        m.App.Session.GetInt(r.Context(), "user_id"),
+	}
 
 	// Form validations
-	form.Required("first_name", "last_name", "email", "phone")
+	form := forms.New(r.PostForm)
+	form.Required("first_name", "last_name", "email", "phone", "booking_option_id", "start_date", "end_date")
 	form.MinLength("first_name", 3, 30)
 	form.MinLength("last_name", 3, 30)
 	form.IsEmail("email")
 
+	startDate, err := time.Parse("2006-01-02", r.Form.Get("start_date"))
+	if err != nil {
+		form.Errors.Add("start_date", "Invalid date")
+	}
+
+	endDate, err := time.Parse("2006-01-02", r.Form.Get("end_date"))
+	if err != nil {
+		form.Errors.Add("end_date", "Invalid date")
+	}
+
+	if !startDate.IsZero() && !endDate.IsZero() {
+		if endDate.Before(startDate) {
+			form.Errors.Add("end_date", "End date can't be before the start date")
+		}
+
+		today := time.Now().Truncate(24 * time.Hour)
+		if startDate.Before(today) {
+			form.Errors.Add("start_date", "Start date can't be in the past")
+		}
+	}
+
+	booking.StartDate = startDate
+	booking.EndDate = endDate
+
+	// The selected option must belong to the artist being booked
+	optionID, _ := strconv.Atoi(r.Form.Get("booking_option_id"))
+	for _, option := range options {
+		if option.ID == optionID {
+			booking.BookingOptionID = option.ID
+			booking.BookingOption = option
+		}
+	}
+
+	if booking.BookingOptionID == 0 {
+		form.Errors.Add("booking_option_id", "Please select a booking option")
+	}
+
 	if !form.Valid() {
 		data := make(map[string]interface{})
-		data["reservation"] = reservation
+		data["artist"] = artist
+		data["options"] = options
+		data["booking"] = booking
 		m.App.Session.Put(r.Context(), "error", "Invalid form input")
-		render.Template(w, r, "make-reservation.page.html", &models.TemplateData{
+		render.Template(w, r, "single-artist.page.html", &models.TemplateData{
 			Form: form,
 			Data: data,
 		})
 		return
 	}
 
-	newReservationId, err := m.DB.InsertReservation(reservation)
+	available, err := m.DB.SearchAvailabilityByDatesByArtistID(r.Context(), booking.StartDate, booking.EndDate, artistID)
 	if err != nil {
-		m.App.Session.Put(r.Context(), "error", "Can't insert into database")
-		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+		m.App.Session.Put(r.Context(), "error", "Can't check artist availability")
+		http.Redirect(w, r, fmt.Sprintf("/artists/%d", artistID), http.StatusSeeOther)
 		return
 	}
 
-	restriction := models.RoomRestriction{
-		StartDate:     reservation.StartDate,
-		EndDate:       reservation.EndDate,
-		RoomID:        reservation.RoomID,
-		ReservationID: newReservationId,
-		RestrictionID: 1,
+	if !available {
+		m.App.Session.Put(r.Context(), "error", fmt.Sprintf("%s is not available from %s to %s. Please, choose other dates", artist.Name, booking.StartDate.Format("2006-01-02"), booking.EndDate.Format("2006-01-02")))
+		http.Redirect(w, r, fmt.Sprintf("/artists/%d", artistID), http.StatusSeeOther)
+		return
 	}
 
-	err = m.DB.InsertRoomRestriction(restriction)
+	// save the booking and queue its emails together, so nobody is emailed about a booking that wasn't saved
+	err = m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		newBookingID, err := repo.InsertBooking(r.Context(), booking)
+		if err != nil {
+			return err
+		}
+		booking.ID = newBookingID
+
+		return m.enqueueEmails(r.Context(), repo, bookingEmails(booking, artist)...)
+	})
+	if errors.Is(err, repository.ErrUnavailable) {
+		m.App.Session.Put(r.Context(), "error", fmt.Sprintf("Sorry, %s was just booked for those dates by someone else. Please, choose other dates", artist.Name))
+		http.Redirect(w, r, fmt.Sprintf("/artists/%d", artistID), http.StatusSeeOther)
+		return
+	}
 	if err != nil {
-		m.App.Session.Put(r.Context(), "error", "Can't insert room restriction!")
-		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+		m.App.Session.Put(r.Context(), "error", "Can't insert booking into database")
+		http.Redirect(w, r, fmt.Sprintf("/artists/%d", artistID), http.StatusSeeOther)
 		return
 	}
 
-	// Send email notification to customer
-	htmlBody := fmt.Sprintf(`
-	<strong>Thank you for making a reservation</strong><br />
-	<p>Dear %s, </p>
-	<p>This is to confirm your reservation from %s, to %s. </p>
-	<p>We hope to see you soon</p>
-	`, reservation.FirstName, reservation.StartDate.Format("2006-01-02"), reservation.EndDate.Format("2006-01-02"))
+	m.App.Session.Put(r.Context(), "booking", booking)
 
-	message := models.MailData{
-		To:       reservation.Email,
-		From:     "prosperdevstack@gmail.com",
-		Subject:  "Reservation Confirmation",
-		Content:  htmlBody,
-		Template: "basic.html",
-	}
+	http.Redirect(w, r, "/booking-summary", http.StatusSeeOther)
+}
+
+// bookingEmails returns the confirmation for the customer and the notification for the admin of a new booking
+func bookingEmails(booking models.Bookings, artist models.Artist) []outgoingEmail {
+	customerEmail := outgoingEmail{to: booking.Email, data: emails.BookingConfirmation{
+		FirstName:  booking.FirstName,
+		ArtistName: artist.Name,
+		Option:     booking.BookingOption.Title,
+		StartDate:  booking.StartDate,
+		EndDate:    booking.EndDate,
+		ManageURL:  manageURL(manageBooking, booking.ID, booking.EndDate),
+	}}
+
+	adminEmail := adminNotification(settings.EventNewBooking, emails.AdminNotification{
+		Event:   "New Booking",
+		Summary: fmt.Sprintf("There is a new booking from %s %s", booking.FirstName, booking.LastName),
+		Details: []emails.Detail{
+			{Label: "Booking Dates", Value: fmt.Sprintf("%s, to %s", booking.StartDate.Format("2006-01-02"), booking.EndDate.Format("2006-01-02"))},
+			{Label: "Artist", Value: artist.Name},
+			{Label: "Booking Option", Value: fmt.Sprintf("%s (%s)", booking.BookingOption.Title, booking.BookingOption.Price)},
+			{Label: "Customer Email", Value: booking.Email},
+		},
+	})
 
-	m.App.MailChannel <- message
+	return []outgoingEmail{customerEmail, adminEmail}
+}
 
-	// Send email notification to admin
-	htmlBody = fmt.Sprintf(`
-	<strong>Hello, Admin</strong><br />
-	<p>There is a new reservation from %s %s, </p>
-	<p>Reservation Dates: %s, to %s. </p>
-	<p>Room: %s. </p>
-	<p>Customer Email: %s</p>
-	`, reservation.FirstName, reservation.LastName, reservation.StartDate.Format("2006-01-02"), reservation.EndDate.Format("2006-01-02"), reservation.Room.RoomName, reservation.Email)
+// Kinds of records customers can manage from the link in their confirmation email
+const (
+	manageReservation = "reservation"
+	manageBooking     = "booking"
+)
 
-	message = models.MailData{
-		To:      "atu.prosper@gmail.com",
-		From:    "prosperdevstack@gmail.com",
-		Subject: "New Reservation",
-		Content: htmlBody,
+// manageURL returns the cancel and reschedule link added to confirmation emails. The link stops working
+// the day after the end date
+func manageURL(kind string, id int, endDate time.Time) string {
+	token, err := helpers.GenerateManageToken(kind, id, endDate.AddDate(0, 0, 1))
+	if err != nil {
+		log.Println(err)
+		return ""
 	}
 
-	m.App.MailChannel <- message
-	// End of emails
-
-	m.App.Session.Put(r.Context(), "reservation", reservation)
+	err = godotenv.Load()
+	if err != nil {
+		log.Println("Error loading .env file")
+	}
+	frontendURL := os.Getenv("FRONTEND_URL")
 
-	http.Redirect(w, r, "/reservation-summary", http.StatusSeeOther)
+	return fmt.Sprintf("%s/manage?token=%s", frontendURL, url.QueryEscape(token))
 }
 
-// This function displays the reservation summary page
-func (m *Repository) ReservationSummary(w http.ResponseWriter, r *http.Request) {
-	reservation, ok := m.App.Session.Get(r.Context(), "reservation").(models.Reservation)
-	if !ok || reservation.FirstName == "" || reservation.LastName == "" || reservation.Email == "" || reservation.Phone == "" {
-		m.App.ErrorLog.Println("Cannot get reservation from session")
-		m.App.Session.Put(r.Context(), "error", "<h5>Can't get reservation from session!</h5><br /> Please, select an available room and make a reservation")
-		http.Redirect(w, r, "/", http.StatusSeeOther)
-		return
-	}
+// manageable holds the parts of a reservation or booking the customer manage page works with
+type manageable struct {
+	Kind      string
+	ID        int
+	Title     string
+	FirstName string
+	LastName  string
+	Email     string
+	Status    string
+	StartDate time.Time
+	EndDate   time.Time
+}
 
-	startDate := reservation.StartDate.Format("2006-01-02")
-	endDate := reservation.EndDate.Format("2006-01-02")
+// loadManageable fetches the reservation or booking a manage token was issued for
+func (m *Repository) loadManageable(ctx context.Context, kind string, id int) (manageable, error) {
+	item := manageable{Kind: kind, ID: id}
 
-	stringMap := make(map[string]string)
-	stringMap["start_date"] = startDate
-	stringMap["end_date"] = endDate
-
-	data := make(map[string]interface{})
-	data["reservation"] = reservation
+	switch kind {
+	case manageReservation:
+		reservation, err := m.DB.GetReservationByID(ctx, id)
+		if err != nil {
+			return item, err
+		}
+		item = reservationManageable(reservation)
+	case manageBooking:
+		booking, err := m.DB.GetBookingByID(ctx, id)
+		if err != nil {
+			return item, err
+		}
+		item = bookingManageable(booking)
+	default:
+		return item, helpers.ErrInvalidManageToken
+	}
 
-	render.Template(w, r, "reservation-summary.page.html", &models.TemplateData{
-		Data:      data,
-		StringMap: stringMap,
-	})
+	return item, nil
+}
 
-	m.App.Session.Remove(r.Context(), "reservation")
+// reservationManageable returns the parts of a reservation the customer manage page works with
+func reservationManageable(reservation models.Reservation) manageable {
+	return manageable{
+		Kind:      manageReservation,
+		ID:        reservation.ID,
+		Title:     reservation.Room.RoomName,
+		FirstName: reservation.FirstName,
+		LastName:  reservation.LastName,
+		Email:     reservation.Email,
+		Status:    reservation.Status,
+		StartDate: reservation.StartDate,
+		EndDate:   reservation.EndDate,
+	}
 }
 
-// This function handles the Admin Login page and renders the template
-func (m *Repository) Login(w http.ResponseWriter, r *http.Request) {
-	userExists := m.App.Session.GetInt(r.Context(), "user_id")
-	if userExists > 0 {
-		m.App.Session.Put(r.Context(), "warning", "You are already logged in")
-		http.Redirect(w, r, "/", http.StatusSeeOther)
-		return
+// bookingManageable returns the parts of a booking the customer manage page works with
+func bookingManageable(booking models.Bookings) manageable {
+	return manageable{
+		Kind:      manageBooking,
+		ID:        booking.ID,
+		Title:     booking.Artist.Name,
+		FirstName: booking.FirstName,
+		LastName:  booking.LastName,
+		Email:     booking.Email,
+		Status:    booking.Status,
+		StartDate: booking.StartDate,
+		EndDate:   booking.EndDate,
 	}
+}
 
-	render.Template(w, r, "login.page.html", &models.TemplateData{
-		Form: forms.New(nil),
-	})
+// canCancel reports whether the customer may still cancel
+func (m *Repository) canCancel(item manageable) bool {
+	return status.Transition(item.Status, status.Cancelled) == nil && time.Until(item.StartDate) >= m.App.CancellationWindow
 }
 
-// This function handles user details and authentication
-func (m *Repository) PostLogin(w http.ResponseWriter, r *http.Request) {
-	_ = m.App.Session.Destroy(r.Context())
-	_ = m.App.Session.RenewToken(r.Context())
+// canReschedule reports whether the customer may still move the dates
+func (m *Repository) canReschedule(item manageable) bool {
+	return !status.IsFinal(item.Status) && time.Until(item.StartDate) >= m.App.RescheduleWindow
+}
 
-	err := r.ParseForm()
+// manageFromForm reads the token posted by the manage page forms and loads what it was issued for
+func (m *Repository) manageFromForm(r *http.Request) (manageable, string, error) {
+	token := r.Form.Get("token")
+
+	kind, id, err := helpers.ParseManageToken(token)
 	if err != nil {
-		m.App.Session.Put(r.Context(), "error", "Can't parse form")
-		http.Redirect(w, r, "/user/login", http.StatusSeeOther)
-		return
+		return manageable{}, token, err
 	}
 
-	email := r.Form.Get("email")
-	password := r.Form.Get("password")
-
-	form := forms.New(r.PostForm)
-	form.Required("email", "password")
-	form.IsEmail("email")
+	item, err := m.loadManageable(r.Context(), kind, id)
+	return item, token, err
+}
 
-	if !form.Valid() {
-		stringMap := make(map[string]string)
-		stringMap["email"] = email
-		m.App.Session.Put(r.Context(), "error", "Invalid inputs")
-		render.Template(w, r, "login.page.html", &models.TemplateData{
-			Form:      form,
-			StringMap: stringMap,
-		})
+// Handles the customer manage page linked from confirmation emails
+func (m *Repository) Manage(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
 
+	kind, id, err := helpers.ParseManageToken(token)
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
 	}
 
-	id, _, access_level, err := m.DB.Authenticate(email, password)
+	item, err := m.loadManageable(r.Context(), kind, id)
 	if err != nil {
-		log.Println(err)
-
-		m.App.Session.Put(r.Context(), "error", "Invalid email/password")
-		http.Redirect(w, r, "/user/login", http.StatusSeeOther)
-
+		m.App.Session.Put(r.Context(), "error", fmt.Sprintf("We could not find your %s", kind))
+		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
 	}
 
-	m.App.Session.Put(r.Context(), "user_id", id)
-	m.App.Session.Put(r.Context(), "access_level", access_level)
-	m.App.Session.Put(r.Context(), "flash", "Login Successful")
-	http.Redirect(w, r, "/", http.StatusSeeOther)
-}
+	data := make(map[string]interface{})
+	data["item"] = item
 
-// This function handles the Signup page and renders the template
-func (m *Repository) Signup(w http.ResponseWriter, r *http.Request) {
-	userExists := m.App.Session.GetInt(r.Context(), "user_id")
-	if userExists > 0 {
-		m.App.Session.Put(r.Context(), "warning", "You are already logged in")
-		http.Redirect(w, r, "/", http.StatusSeeOther)
-		return
+	stringMap := make(map[string]string)
+	stringMap["token"] = token
+
+	intMap := make(map[string]int)
+	intMap["cancellation_hours"] = int(m.App.CancellationWindow.Hours())
+	intMap["reschedule_hours"] = int(m.App.RescheduleWindow.Hours())
+	if m.canCancel(item) {
+		intMap["can_cancel"] = 1
+	}
+	if m.canReschedule(item) {
+		intMap["can_reschedule"] = 1
 	}
 
-	render.Template(w, r, "signup.page.html", &models.TemplateData{
-		Form: forms.New(nil),
+	render.Template(w, r, "manage.page.html", &models.TemplateData{
+		Data:      data,
+		StringMap: stringMap,
+		IntMap:    intMap,
+		Form:      forms.New(nil),
 	})
 }
 
-// This function handles the posting of user signup form
-func (m *Repository) PostSignup(w http.ResponseWriter, r *http.Request) {
-	_ = m.App.Session.Destroy(r.Context())
-	_ = m.App.Session.RenewToken(r.Context())
-
-	user := models.User{}
-	stringMap := make(map[string]string)
-
+// Handles the cancel button on the customer manage page
+func (m *Repository) PostManageCancel(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
-		m.App.Session.Put(r.Context(), "error", "Can't parse form")
-		http.Redirect(w, r, "/user/signup", http.StatusSeeOther)
+		helpers.ServerError(w, err)
 		return
 	}
 
-	user.FirstName = r.Form.Get("first_name")
-	user.LastName = r.Form.Get("last_name")
-	user.Email = r.Form.Get("email")
-	user.Password = r.Form.Get("password")
-	user.AccessLevel = 0
-
-	form := forms.New(r.PostForm)
-	form.Required("first_name", "last_name", "email", "password")
-	form.MinLength("first_name", 3, 30)
-	form.MinLength("last_name", 3, 30)
-	form.IsEmail("email")
-
-	if !form.Valid() {
-		stringMap["first_name"] = user.FirstName
-		stringMap["last_name"] = user.LastName
-		stringMap["email"] = user.Email
-		m.App.Session.Put(r.Context(), "error", "Invalid inputs")
-		render.Template(w, r, "signup.page.html", &models.TemplateData{
-			Form:      form,
-			StringMap: stringMap,
-		})
-
+	item, token, err := m.manageFromForm(r)
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", helpers.ErrInvalidManageToken.Error())
+		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
 	}
 
-	userExist, err := m.DB.CheckIfUserEmailExist(user.Email)
-	if err != nil {
-		helpers.ServerError(w, err)
+	redirectURL := "/manage?token=" + url.QueryEscape(token)
+
+	if !m.canCancel(item) {
+		m.App.Session.Put(r.Context(), "error", fmt.Sprintf("This %s can no longer be cancelled online. Please, contact us", item.Kind))
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
 		return
 	}
 
-	if userExist {
-		log.Println("Email exist")
-		stringMap["first_name"] = user.FirstName
-		stringMap["last_name"] = user.LastName
-		stringMap["email"] = user.Email
-		m.App.Session.Put(r.Context(), "error", "Email exist. Kindly use the forgot password form to reset your password")
-		render.Template(w, r, "signup.page.html", &models.TemplateData{
-			Form:      form,
-			StringMap: stringMap,
-		})
+	adminEmail := adminNotification(adminEvent(item.Kind), emails.AdminNotification{
+		Event:   fmt.Sprintf("Cancelled %s", item.Kind),
+		Summary: fmt.Sprintf("%s %s has cancelled %s #%d (%s)", item.FirstName, item.LastName, item.Kind, item.ID, item.Title),
+		Details: []emails.Detail{
+			{Label: "Dates", Value: fmt.Sprintf("%s, to %s", item.StartDate.Format("2006-01-02"), item.EndDate.Format("2006-01-02"))},
+		},
+	})
+
+	err = m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		// a customer cancellation is recorded in the history without a user
+		var err error
+		if item.Kind == manageReservation {
+			err = repo.UpdateReservationStatus(r.Context(), item.ID, item.Status, status.Cancelled, 0)
+		} else {
+			err = repo.UpdateBookingStatus(r.Context(), item.ID, item.Status, status.Cancelled, 0)
+		}
+		if err != nil {
+			return err
+		}
+
+		customerEmail := statusEmail(item.Email, item.FirstName, item.Kind, item.StartDate, item.EndDate, status.Cancelled)
 
+		return m.enqueueEmails(r.Context(), repo, customerEmail, adminEmail)
+	})
+	if errors.Is(err, status.ErrStaleStatus) {
+		m.App.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
 		return
 	}
-
-	newUserID, err := m.DB.InsertUser(user)
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	jwtToken, err := helpers.GenerateJWTToken(newUserID)
+	m.App.Session.Put(r.Context(), "flash", fmt.Sprintf("Your %s has been cancelled", item.Kind))
+	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+}
+
+// Handles the reschedule form on the customer manage page
+func (m *Repository) PostManageReschedule(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	user.Token = jwtToken
-	m.App.Session.Put(r.Context(), "user", user)
-
-	// Load the env file and get the frontendURL
-	err = godotenv.Load()
+	item, token, err := m.manageFromForm(r)
 	if err != nil {
-		log.Println("Error loading .env file")
+		m.App.Session.Put(r.Context(), "error", helpers.ErrInvalidManageToken.Error())
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
 	}
-	frontendURL := os.Getenv("FRONTEND_URL")
 
-	// Send email notification to user
-	htmlBody := fmt.Sprintf(`
-	<strong>Verify Your Account</strong><br />
-	<p>Dear %s %s, </p>
-	<p>Welcome to MusiqCity.</p>
-	<strong>Kindly click the link below</strong>
-	<a href="%s/verify-email?userid=%d&token=%s", target="_blank">Verify Account</a>
-	<p>We hope to see you soon</p>
-	`, user.FirstName, user.LastName, frontendURL, newUserID, jwtToken)
+	redirectURL := "/manage?token=" + url.QueryEscape(token)
 
-	message := models.MailData{
-		To:      user.Email,
-		From:    "prosperdevstack@gmail.com",
-		Subject: "Verify Your Email",
-		Content: htmlBody,
+	if !m.canReschedule(item) {
+		m.App.Session.Put(r.Context(), "error", fmt.Sprintf("This %s can no longer be rescheduled online. Please, contact us", item.Kind))
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
 	}
 
-	m.App.MailChannel <- message
-	// End of emails
-
-	m.App.Session.Put(r.Context(), "flash", "Sign up Successful!!! <br /> Please, check your email and verify your account to continue")
-	http.Redirect(w, r, "/", http.StatusSeeOther)
-}
-
-// Load the static page and verify user email
-func (m *Repository) VerifyUserEmail(w http.ResponseWriter, r *http.Request) {
-	render.Template(w, r, "verfy-email.page.html", &models.TemplateData{})
-
-	// get the user id and JWT token string from the url request
-	userID, _ := strconv.Atoi(r.URL.Query().Get("userid"))
-	tokenString := r.URL.Query().Get("token")
-
-	user, err := m.DB.GetUserByID(userID)
+	startDate, err := time.Parse("2006-01-02", r.Form.Get("start_date"))
 	if err != nil {
-		// helpers.ServerError(w, err)
-		m.App.Session.Put(r.Context(), "error", "Unable to fetch your account from our server. <br />Please, contact support")
-		http.Redirect(w, r, "/", http.StatusSeeOther)
+		m.App.Session.Put(r.Context(), "error", "Invalid start date")
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
 		return
 	}
 
-	// Load the env file and get the JWT secret
-	err = godotenv.Load()
+	endDate, err := time.Parse("2006-01-02", r.Form.Get("end_date"))
 	if err != nil {
-		log.Println("Error loading .env file")
+		m.App.Session.Put(r.Context(), "error", "Invalid end date")
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+
+	if endDate.Before(startDate) {
+		m.App.Session.Put(r.Context(), "error", "The end date can't be before the start date")
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
 	}
-	jwtSecret := os.Getenv("JWTSECRET")
 
-	// Parse and verify the JWT token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Provide the secret key used for signing the token
-		return []byte(jwtSecret), nil
+	// the new dates have to respect the same window as the old ones
+	if time.Until(startDate) < m.App.RescheduleWindow {
+		m.App.Session.Put(r.Context(), "error", fmt.Sprintf("The new start date must be at least %d hours from now", int(m.App.RescheduleWindow.Hours())))
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+
+	customerEmail := outgoingEmail{to: item.Email, data: emails.Rescheduled{
+		FirstName: item.FirstName,
+		Kind:      item.Kind,
+		StartDate: startDate,
+		EndDate:   endDate,
+		ManageURL: manageURL(item.Kind, item.ID, endDate),
+	}}
+
+	adminEmail := adminNotification(adminEvent(item.Kind), emails.AdminNotification{
+		Event:   fmt.Sprintf("Rescheduled %s", item.Kind),
+		Summary: fmt.Sprintf("%s %s has rescheduled %s #%d (%s)", item.FirstName, item.LastName, item.Kind, item.ID, item.Title),
+		Details: []emails.Detail{
+			{Label: "Old Dates", Value: fmt.Sprintf("%s, to %s", item.StartDate.Format("2006-01-02"), item.EndDate.Format("2006-01-02"))},
+			{Label: "New Dates", Value: fmt.Sprintf("%s, to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))},
+		},
+	})
+
+	var available bool
+	err = m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		var err error
+		if item.Kind == manageReservation {
+			available, err = repo.RescheduleReservation(r.Context(), item.ID, startDate, endDate)
+		} else {
+			available, err = repo.RescheduleBooking(r.Context(), item.ID, startDate, endDate)
+		}
+		if err != nil || !available {
+			return err
+		}
+
+		return m.enqueueEmails(r.Context(), repo, customerEmail, adminEmail)
 	})
 	if err != nil {
-		// Handle token parsing or verification errors
-		http.Error(w, "Unable to parse token", http.StatusBadRequest)
-		m.App.Session.Put(r.Context(), "error", fmt.Sprintf("Unable to parse token. Error: %s", err))
-		http.Redirect(w, r, "/", http.StatusSeeOther)
+		helpers.ServerError(w, err)
 		return
 	}
 
-	// If the token is valid
-	if token.Valid {
-		// Update the user's account status as verified
-		user.AccessLevel = 1
-		err = m.DB.UpdateUserAccessLevel(user)
-		if err != nil {
-			// helpers.ServerError(w, err)
-			m.App.Session.Put(r.Context(), "error", "Unable to update user's access level. Please contact support")
-			http.Redirect(w, r, "/", http.StatusSeeOther)
-			return
-		}
-	} else {
-		http.Error(w, "Invalid token", http.StatusBadRequest)
-		m.App.Session.Put(r.Context(), "error", fmt.Sprintf("Invalid token. Error: %s", err))
-		http.Redirect(w, r, "/", http.StatusSeeOther)
+	if !available {
+		m.App.Session.Put(r.Context(), "error", fmt.Sprintf("%s is not available from %s to %s. Please, choose other dates", item.Title, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")))
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
 		return
 	}
 
-	// Handle successful email verification, Send email notification to user
-	htmlBody := fmt.Sprintf(`
-	<strong>Successful</strong><br />
-	<p>Hi %s, </p>
-	<h3>Your email has been verified.</h3>
-	<p>You can now login to your account.</p>
-	<strong>Note:<strong>
-	<p>You may still need to verify your address and identity before you can list your services on our website. <br />
-	Go to your account dashboard and verify your account by providing the required verification documents.</p>
-	<p>We hope to see you soon</p>
-	`, user.FirstName)
+	m.App.Session.Put(r.Context(), "flash", fmt.Sprintf("Your %s has been moved to %s - %s", item.Kind, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")))
 
-	message := models.MailData{
-		To:      user.Email,
-		From:    "prosperdevstack@gmail.com",
-		Subject: "Email Verified",
-		Content: htmlBody,
+	// the old token expires with the old end date, so send the customer on with a fresh one
+	newToken, err := helpers.GenerateManageToken(item.Kind, item.ID, endDate.AddDate(0, 0, 1))
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
 	}
 
-	m.App.MailChannel <- message
-	// End of emails
-
-	m.App.Session.Put(r.Context(), "flash", "Email Verification Successful!!! <br /> Please, login")
-	http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+	http.Redirect(w, r, "/manage?token="+url.QueryEscape(newToken), http.StatusSeeOther)
 }
 
-// This function logs out the user
-func (m *Repository) Logout(w http.ResponseWriter, r *http.Request) {
-	_ = m.App.Session.Destroy(r.Context())
-	_ = m.App.Session.RenewToken(r.Context())
-
-	m.App.Session.Put(r.Context(), "warning", "You have logged out of your account")
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+// customerBooking is a row of the customer bookings page. ManageURL is empty when the booking can't be
+// cancelled or rescheduled online any more
+type customerBooking struct {
+	models.Bookings
+	ManageURL string
 }
 
-// Handles the admin dashborad
-func (m *Repository) AdminDashboard(w http.ResponseWriter, r *http.Request) {
-	render.Template(w, r, "admin-dashboard.page.html", &models.TemplateData{})
+// customerReservation is a room reservation row of the customer bookings page, like customerBooking
+type customerReservation struct {
+	models.Reservation
+	ManageURL string
 }
 
-// Handles the processing of revervation
-func (m *Repository) AdminProcessReservation(w http.ResponseWriter, r *http.Request) {
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
-	src := chi.URLParam(r, "src")
+// Handles the bookings page of a logged in customer, split into upcoming and past bookings, with their
+// room reservations below
+func (m *Repository) UserBookings(w http.ResponseWriter, r *http.Request) {
+	userID := m.App.Session.GetInt(r.Context(), "user_id")
+
+	bookings, err := m.DB.AllBookingsByUserID(r.Context(), userID)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
 
-	reservation, err := m.DB.GetReservationByID(id)
+	reservations, err := m.DB.AllReservationsByUserID(r.Context(), userID)
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	if reservation.Processed == 0 {
-		err = m.DB.UpdateProcessedForReservation(id, 1)
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var upcoming, past []customerBooking
+	for _, booking := range bookings {
+		row := customerBooking{Bookings: booking}
+
+		if booking.EndDate.Before(today) {
+			past = append(past, row)
+			continue
+		}
+
+		row.ManageURL, err = m.customerManageURL(bookingManageable(booking))
 		if err != nil {
 			helpers.ServerError(w, err)
 			return
 		}
-		m.App.Session.Put(r.Context(), "flash", "<strong>Successful!!!</strong><br><br> <p>Reservation is now marked as Processed</p>")
-	} else {
-		err = m.DB.UpdateProcessedForReservation(id, 0)
+
+		upcoming = append(upcoming, row)
+	}
+
+	var upcomingReservations, pastReservations []customerReservation
+	for _, reservation := range reservations {
+		row := customerReservation{Reservation: reservation}
+
+		if reservation.EndDate.Before(today) {
+			pastReservations = append(pastReservations, row)
+			continue
+		}
+
+		row.ManageURL, err = m.customerManageURL(reservationManageable(reservation))
 		if err != nil {
 			helpers.ServerError(w, err)
 			return
 		}
-		m.App.Session.Put(r.Context(), "flash", "<strong>Successful!!!</strong><br><br> <p>Reservation is now marked as Not Processed</p>")
+
+		upcomingReservations = append(upcomingReservations, row)
 	}
 
-	year := r.URL.Query().Get("y")
-	month := r.URL.Query().Get("m")
+	// the most recent past bookings come first
+	slices.Reverse(past)
+	slices.Reverse(pastReservations)
 
-	if year == "" {
-		http.Redirect(w, r, fmt.Sprintf("/admin/%s-reservations", src), http.StatusSeeOther)
-	} else {
-		http.Redirect(w, r, fmt.Sprintf("/admin/reservations-calendar?y=%s&m=%s", year, month), http.StatusSeeOther)
-	}
+	data := make(map[string]interface{})
+	data["upcoming"] = upcoming
+	data["past"] = past
+	data["upcoming_reservations"] = upcomingReservations
+	data["past_reservations"] = pastReservations
 
+	render.Template(w, r, "user-bookings.page.html", &models.TemplateData{
+		Data: data,
+	})
 }
 
-// Handles the deleting of revervation
-func (m *Repository) AdminDeleteReservation(w http.ResponseWriter, r *http.Request) {
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
-	src := chi.URLParam(r, "src")
+// customerManageURL returns the link to the customer manage page of item, or nothing when it can't be
+// cancelled or rescheduled online any more
+func (m *Repository) customerManageURL(item manageable) (string, error) {
+	if !m.canCancel(item) && !m.canReschedule(item) {
+		return "", nil
+	}
 
-	err := m.DB.DeleteReservation(id)
+	token, err := helpers.GenerateManageToken(item.Kind, item.ID, item.EndDate.AddDate(0, 0, 1))
 	if err != nil {
-		helpers.ServerError(w, err)
-		return
+		return "", err
 	}
 
-	year := r.URL.Query().Get("y")
-	month := r.URL.Query().Get("m")
-
-	m.App.Session.Put(r.Context(), "flash", "<strong>Successful!!!</strong><br><br> <p>Reservation Deleted</p>")
-
-	if year == "" {
-		http.Redirect(w, r, fmt.Sprintf("/admin/%s-reservations", src), http.StatusSeeOther)
-	} else {
-		http.Redirect(w, r, fmt.Sprintf("/admin/reservations-calendar?y=%s&m=%s", year, month), http.StatusSeeOther)
-	}
+	return "/manage?token=" + url.QueryEscape(token), nil
 }
 
-// Handles the reservations-calendar route
-func (m *Repository) AdminReservationsCalendar(w http.ResponseWriter, r *http.Request) {
-	now := time.Now()
-
-	if r.URL.Query().Get("y") != "" {
-		month, _ := strconv.Atoi(r.URL.Query().Get("m"))
-		year, _ := strconv.Atoi(r.URL.Query().Get("y"))
-
-		now = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+// This function displays the booking summary page
+func (m *Repository) BookingSummary(w http.ResponseWriter, r *http.Request) {
+	booking, ok := m.App.Session.Get(r.Context(), "booking").(models.Bookings)
+	if !ok || booking.ID == 0 {
+		m.App.ErrorLog.Println("Cannot get booking from session")
+		m.App.Session.Put(r.Context(), "error", "<h5>Can't get booking from session!</h5><br /> Please, select an artist and make a booking")
+		http.Redirect(w, r, "/artists", http.StatusSeeOther)
+		return
 	}
 
+	stringMap := make(map[string]string)
+	stringMap["start_date"] = booking.StartDate.Format("2006-01-02")
+	stringMap["end_date"] = booking.EndDate.Format("2006-01-02")
+
 	data := make(map[string]interface{})
-	data["now"] = now
+	data["booking"] = booking
+
+	render.Template(w, r, "booking-summary.page.html", &models.TemplateData{
+		Data:      data,
+		StringMap: stringMap,
+	})
+
+	m.App.Session.Remove(r.Context(), "booking")
+}
+
+// This function handles the About page and renders the template
+func (m *Repository) About(w http.ResponseWriter, r *http.Request) {
+	render.Template(w, r, "about.page.html", &models.TemplateData{})
+}
+
+// This function handles the Contact page and renders the template
+func (m *Repository) Contact(w http.ResponseWriter, r *http.Request) {
+	render.Template(w, r, "contact.page.html", &models.TemplateData{})
+}
+
+// Availability json, to handle availability request and send back json
+type jsonResponse struct {
+	Ok        bool   `json:"ok"`
+	Message   string `json:"message"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	RoomID    string `json:"room_id"`
+}
+
+// This function checks if the dates entered in a single room search has availability
+func (m *Repository) AvailabilityJSON(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		response := jsonResponse{
+			Ok:      false,
+			Message: "Internal server error",
+		}
+
+		out, _ := json.MarshalIndent(response, "", "    ")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(out)
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", r.Form.Get("start"))
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", r.Form.Get("end"))
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	roomId, _ := strconv.Atoi(r.Form.Get("room_id"))
+
+	available, err := m.DB.SearchAvailabilityByDatesByRoomID(r.Context(), startDate, endDate, roomId)
+	if err != nil {
+		response := jsonResponse{
+			Ok:      false,
+			Message: "Error connecting to the database",
+		}
+
+		out, _ := json.MarshalIndent(response, "", "    ")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(out)
+		return
+	}
+
+	response := jsonResponse{
+		Ok:        available,
+		Message:   "",
+		StartDate: r.Form.Get("start"),
+		EndDate:   r.Form.Get("end"),
+		RoomID:    r.Form.Get("room_id"),
+	}
+
+	out, _ := json.MarshalIndent(response, "", "    ")
+
+	// Tell the browser the type of file in the header
+	w.Header().Set("Content-Type", "Application/json")
+	w.Write(out)
+}
+
+// writeJSON marshals the payload and writes it to the browser as json
+func writeJSON(w http.ResponseWriter, payload interface{}) {
+	writeJSONStatus(w, http.StatusOK, payload)
+}
+
+// writeJSONStatus writes payload as json with the status code status
+func writeJSONStatus(w http.ResponseWriter, status int, payload interface{}) {
+	out, _ := json.MarshalIndent(payload, "", "    ")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(out)
+}
+
+// Artist availability json, to handle artist availability request and send back json
+type artistJsonResponse struct {
+	Ok        bool   `json:"ok"`
+	Message   string `json:"message"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	ArtistID  string `json:"artist_id"`
+}
+
+// This function checks if the dates entered for a single artist has availability
+func (m *Repository) ArtistAvailabilityJSON(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		writeJSONStatus(w, http.StatusBadRequest, artistJsonResponse{
+			Ok:      false,
+			Message: "Invalid form",
+		})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", r.Form.Get("start"))
+	if err != nil {
+		writeJSONStatus(w, http.StatusBadRequest, artistJsonResponse{
+			Ok:      false,
+			Message: "Invalid start date",
+		})
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", r.Form.Get("end"))
+	if err != nil || endDate.Before(startDate) {
+		writeJSONStatus(w, http.StatusBadRequest, artistJsonResponse{
+			Ok:      false,
+			Message: "Invalid end date",
+		})
+		return
+	}
+
+	artistID, err := strconv.Atoi(r.Form.Get("artist_id"))
+	if err != nil {
+		writeJSONStatus(w, http.StatusBadRequest, artistJsonResponse{
+			Ok:      false,
+			Message: "Invalid artist",
+		})
+		return
+	}
+
+	// only approved artists can be booked, the others answer like artists that don't exist
+	_, err = m.DB.GetArtistByID(r.Context(), artistID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeJSONStatus(w, http.StatusNotFound, artistJsonResponse{
+			Ok:      false,
+			Message: "Artist not found",
+		})
+		return
+	}
+	if err != nil {
+		writeJSONStatus(w, http.StatusInternalServerError, artistJsonResponse{
+			Ok:      false,
+			Message: "Error connecting to the database",
+		})
+		return
+	}
+
+	available, err := m.DB.SearchAvailabilityByDatesByArtistID(r.Context(), startDate, endDate, artistID)
+	if err != nil {
+		writeJSONStatus(w, http.StatusInternalServerError, artistJsonResponse{
+			Ok:      false,
+			Message: "Error connecting to the database",
+		})
+		return
+	}
+
+	message := ""
+	if !available {
+		message = "Artist is not available for the selected dates"
+	}
+
+	writeJSON(w, artistJsonResponse{
+		Ok:        available,
+		Message:   message,
+		StartDate: r.Form.Get("start"),
+		EndDate:   r.Form.Get("end"),
+		ArtistID:  r.Form.Get("artist_id"),
+	})
+}
+
+// availableArtist is the json representation of an artist in availability searches
+type availableArtist struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	Genres        string `json:"genres"`
+	City          string `json:"city"`
+	Logo          string `json:"logo"`
+	FeaturedImage string `json:"featured_image"`
+}
+
+// Available artists json, lists all artists free between the start and end dates
+type availableArtistsJsonResponse struct {
+	Ok        bool              `json:"ok"`
+	Message   string            `json:"message"`
+	StartDate string            `json:"start_date"`
+	EndDate   string            `json:"end_date"`
+	Artists   []availableArtist `json:"artists"`
+}
+
+// This function returns the artists, optionally filtered by genre and city, that are free between the dates entered
+func (m *Repository) AvailableArtistsJSON(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		writeJSON(w, availableArtistsJsonResponse{
+			Ok:      false,
+			Message: "Internal server error",
+		})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", r.Form.Get("start"))
+	if err != nil {
+		writeJSON(w, availableArtistsJsonResponse{
+			Ok:      false,
+			Message: "Invalid start date",
+		})
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", r.Form.Get("end"))
+	if err != nil || endDate.Before(startDate) {
+		writeJSON(w, availableArtistsJsonResponse{
+			Ok:      false,
+			Message: "Invalid end date",
+		})
+		return
+	}
+
+	genre := genres.Slug(r.Form.Get("genre"))
+	city := strings.TrimSpace(r.Form.Get("city"))
+
+	artists, _, err := m.DB.SearchArtists(r.Context(), repository.ArtistFilter{
+		Genre:         genre,
+		City:          city,
+		AvailableFrom: startDate,
+		AvailableTo:   endDate,
+	})
+	if err != nil {
+		writeJSON(w, availableArtistsJsonResponse{
+			Ok:      false,
+			Message: "Error connecting to the database",
+		})
+		return
+	}
+
+	response := availableArtistsJsonResponse{
+		Ok:        true,
+		StartDate: r.Form.Get("start"),
+		EndDate:   r.Form.Get("end"),
+		Artists:   []availableArtist{},
+	}
+
+	for _, artist := range artists {
+		response.Artists = append(response.Artists, availableArtist{
+			ID:            artist.ID,
+			Name:          artist.Name,
+			Genres:        artist.Genres,
+			City:          artist.City,
+			Logo:          artist.Logo,
+			FeaturedImage: artist.FeaturedImage,
+		})
+	}
+
+	writeJSON(w, response)
+}
+
+// suggestLimit is how many artists the search box suggests
+const suggestLimit = 8
+
+// Search suggestions json, the artists the search box offers while the user types. Name and snippet are HTML
+// with the matched words in <mark> tags
+type suggestJsonResponse struct {
+	Ok      bool              `json:"ok"`
+	Message string            `json:"message"`
+	Query   string            `json:"query"`
+	Results []suggestedArtist `json:"results"`
+}
+
+type suggestedArtist struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Genres  string `json:"genres"`
+	City    string `json:"city"`
+	Snippet string `json:"snippet"`
+	URL     string `json:"url"`
+}
+
+// This function suggests artists for the search box. Queries shorter than two characters get no suggestions
+func (m *Repository) SearchSuggest(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	response := suggestJsonResponse{
+		Ok:      true,
+		Query:   q,
+		Results: []suggestedArtist{},
+	}
+
+	if len([]rune(q)) < 2 {
+		writeJSON(w, response)
+		return
+	}
+
+	suggestions, err := m.DB.SuggestArtists(r.Context(), q, suggestLimit)
+	if err != nil {
+		writeJSON(w, suggestJsonResponse{
+			Ok:      false,
+			Message: "Error connecting to the database",
+		})
+		return
+	}
+
+	for _, x := range suggestions {
+		response.Results = append(response.Results, suggestedArtist{
+			ID:      x.ID,
+			Name:    x.NameHTML,
+			Genres:  x.Genres,
+			City:    x.City,
+			Snippet: x.SnippetHTML,
+			URL:     fmt.Sprintf("/artists/%d", x.ID),
+		})
+	}
+
+	writeJSON(w, response)
+}
+
+// Unavailable dates json, lists the days an artist can't be booked so the front end can grey them out
+type unavailableDatesJsonResponse struct {
+	Ok       bool     `json:"ok"`
+	Message  string   `json:"message"`
+	ArtistID int      `json:"artist_id"`
+	Dates    []string `json:"dates"`
+}
+
+// maxUnavailableDatesSpan is the longest window of unavailable dates one request can ask for
+const maxUnavailableDatesSpan = 366 * 24 * time.Hour
+
+// This function returns every booked or blocked day for an artist between start and end (defaults to the next year).
+// The window can't be longer than about a year
+func (m *Repository) ArtistUnavailableDatesJSON(w http.ResponseWriter, r *http.Request) {
+	artistID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSONStatus(w, http.StatusBadRequest, unavailableDatesJsonResponse{
+			Ok:      false,
+			Message: "Invalid artist",
+		})
+		return
+	}
+
+	startDate := time.Now().Truncate(24 * time.Hour)
+	if r.URL.Query().Get("start") != "" {
+		startDate, err = time.Parse("2006-01-02", r.URL.Query().Get("start"))
+		if err != nil {
+			writeJSONStatus(w, http.StatusBadRequest, unavailableDatesJsonResponse{
+				Ok:      false,
+				Message: "Invalid start date",
+			})
+			return
+		}
+	}
+
+	endDate := startDate.AddDate(1, 0, 0)
+	if r.URL.Query().Get("end") != "" {
+		endDate, err = time.Parse("2006-01-02", r.URL.Query().Get("end"))
+		if err != nil || endDate.Before(startDate) {
+			writeJSONStatus(w, http.StatusBadRequest, unavailableDatesJsonResponse{
+				Ok:      false,
+				Message: "Invalid end date",
+			})
+			return
+		}
+	}
+
+	if endDate.Sub(startDate) > maxUnavailableDatesSpan {
+		writeJSONStatus(w, http.StatusBadRequest, unavailableDatesJsonResponse{
+			Ok:      false,
+			Message: "Please, ask for a year of dates at most",
+		})
+		return
+	}
+
+	restrictions, err := m.DB.GetRestrictionsForCurrentArtist(r.Context(), artistID, startDate, endDate)
+	if err != nil {
+		writeJSONStatus(w, http.StatusInternalServerError, unavailableDatesJsonResponse{
+			Ok:      false,
+			Message: "Error connecting to the database",
+		})
+		return
+	}
+
+	response := unavailableDatesJsonResponse{
+		Ok:       true,
+		ArtistID: artistID,
+		Dates:    []string{},
+	}
+
+	// expand every restriction into the single days that fall inside the requested window. Restrictions
+	// can be much longer than the window, so only the days inside it are walked
+	seen := make(map[string]bool)
+	for _, restriction := range restrictions {
+		from, to := restriction.StartDate, restriction.EndDate
+		if from.Before(startDate) {
+			from = startDate
+		}
+		if to.After(endDate) {
+			to = endDate
+		}
+
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			day := d.Format("2006-01-02")
+			if !seen[day] {
+				seen[day] = true
+				response.Dates = append(response.Dates, day)
+			}
+		}
+	}
+
+	writeJSON(w, response)
+}
+
+// This function handles the make reservation page and renders the template
+func (m *Repository) MakeReservation(w http.ResponseWriter, r *http.Request) {
+	reservationInSession, ok := m.App.Session.Get(r.Context(), "reservation").(models.Reservation)
+	if !ok {
+		m.App.Session.Put(r.Context(), "error", "Can't get reservation from session")
+		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+		return
+	}
+
+	room, err := m.DB.GetRoomByID(r.Context(), reservationInSession.RoomID)
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "Can't find room")
+		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+		return
+	}
+
+	reservationInSession.Room.RoomName = room.RoomName
+
+	startDate := reservationInSession.StartDate.Format("2006-01-02")
+	endDate := reservationInSession.EndDate.Format("2006-01-02")
+
+	stringMap := make(map[string]string)
+	stringMap["start_date"] = startDate
+	stringMap["end_date"] = endDate
+
+	data := make(map[string]interface{})
+	data["reservation"] = reservationInSession
+
+	m.App.Session.Put(r.Context(), "reservation", reservationInSession)
+
+	render.Template(w, r, "make-reservation.page.html", &models.TemplateData{
+		Form:      forms.New(nil),
+		Data:      data,
+		StringMap: stringMap,
+	})
+}
+
+// This function POST the reservation and store them in the database
+func (m *Repository) PostMakeReservation(w http.ResponseWriter, r *http.Request) {
+	reservation, ok := m.App.Session.Get(r.Context(), "reservation").(models.Reservation)
+	if !ok {
+		m.App.Session.Put(r.Context(), "error", "Can't get reservation from session")
+		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "Can't parse form")
+		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+		return
+	}
+
+	reservation.FirstName = r.Form.Get("first_name")
+	reservation.LastName = r.Form.Get("last_name")
+	reservation.Email = r.Form.Get("email")
+	reservation.Phone = r.Form.Get("phone")
+	reservation.UserID = m.App.Session.GetInt(r.Context(), "user_id")
+
+	form := forms.New(r.PostForm)
+
+	// Form validations
+	form.Required("first_name", "last_name", "email", "phone")
+	form.MinLength("first_name", 3, 30)
+	form.MinLength("last_name", 3, 30)
+	form.IsEmail("email")
+
+	if !form.Valid() {
+		data := make(map[string]interface{})
+		data["reservation"] = reservation
+		m.App.Session.Put(r.Context(), "error", "Invalid form input")
+		render.Template(w, r, "make-reservation.page.html", &models.TemplateData{
+			Form: form,
+			Data: data,
+		})
+		return
+	}
+
+	// save the reservation, the restriction that blocks its dates and its emails together,
+	// so a failed restriction insert doesn't leave an orphan reservation
+	err = m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		id, err := repo.InsertReservation(r.Context(), reservation)
+		if err != nil {
+			return err
+		}
+
+		restriction := models.RoomRestriction{
+			StartDate:     reservation.StartDate,
+			EndDate:       reservation.EndDate,
+			RoomID:        reservation.RoomID,
+			ReservationID: id,
+			RestrictionID: 1,
+		}
+
+		err = repo.InsertRoomRestriction(r.Context(), restriction)
+		if err != nil {
+			return err
+		}
+
+		return m.enqueueEmails(r.Context(), repo, reservationEmails(reservation, id)...)
+	})
+	if errors.Is(err, repository.ErrUnavailable) {
+		m.App.Session.Put(r.Context(), "error", "Sorry, those dates were just booked by someone else. Please, choose other dates")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "Can't save your reservation, please try again")
+		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "reservation", reservation)
+
+	http.Redirect(w, r, "/reservation-summary", http.StatusSeeOther)
+}
+
+// reservationEmails returns the confirmation for the customer and the notification for the admin of a new reservation
+func reservationEmails(reservation models.Reservation, newReservationId int) []outgoingEmail {
+	customerEmail := outgoingEmail{to: reservation.Email, data: emails.ReservationConfirmation{
+		FirstName: reservation.FirstName,
+		RoomName:  reservation.Room.RoomName,
+		StartDate: reservation.StartDate,
+		EndDate:   reservation.EndDate,
+		ManageURL: manageURL(manageReservation, newReservationId, reservation.EndDate),
+	}}
+
+	adminEmail := adminNotification(settings.EventNewReservation, emails.AdminNotification{
+		Event:   "New Reservation",
+		Summary: fmt.Sprintf("There is a new reservation from %s %s", reservation.FirstName, reservation.LastName),
+		Details: []emails.Detail{
+			{Label: "Reservation Dates", Value: fmt.Sprintf("%s, to %s", reservation.StartDate.Format("2006-01-02"), reservation.EndDate.Format("2006-01-02"))},
+			{Label: "Room", Value: reservation.Room.RoomName},
+			{Label: "Customer Email", Value: reservation.Email},
+		},
+	})
+
+	return []outgoingEmail{customerEmail, adminEmail}
+}
+
+// This function displays the reservation summary page
+func (m *Repository) ReservationSummary(w http.ResponseWriter, r *http.Request) {
+	reservation, ok := m.App.Session.Get(r.Context(), "reservation").(models.Reservation)
+	if !ok || reservation.FirstName == "" || reservation.LastName == "" || reservation.Email == "" || reservation.Phone == "" {
+		m.App.ErrorLog.Println("Cannot get reservation from session")
+		m.App.Session.Put(r.Context(), "error", "<h5>Can't get reservation from session!</h5><br /> Please, select an available room and make a reservation")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	startDate := reservation.StartDate.Format("2006-01-02")
+	endDate := reservation.EndDate.Format("2006-01-02")
+
+	stringMap := make(map[string]string)
+	stringMap["start_date"] = startDate
+	stringMap["end_date"] = endDate
+
+	data := make(map[string]interface{})
+	data["reservation"] = reservation
+
+	render.Template(w, r, "reservation-summary.page.html", &models.TemplateData{
+		Data:      data,
+		StringMap: stringMap,
+	})
+
+	m.App.Session.Remove(r.Context(), "reservation")
+}
+
+// This function handles the Admin Login page and renders the template
+func (m *Repository) Login(w http.ResponseWriter, r *http.Request) {
+	userExists := m.App.Session.GetInt(r.Context(), "user_id")
+	if userExists > 0 {
+		m.App.Session.Put(r.Context(), "warning", "You are already logged in")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	render.Template(w, r, "login.page.html", &models.TemplateData{
+		Form: forms.New(nil),
+	})
+}
+
+// This function handles user details and authentication
+func (m *Repository) PostLogin(w http.ResponseWriter, r *http.Request) {
+	_ = m.App.Session.Destroy(r.Context())
+	_ = m.App.Session.RenewToken(r.Context())
+
+	err := r.ParseForm()
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "Can't parse form")
+		http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+		return
+	}
+
+	email := r.Form.Get("email")
+	password := r.Form.Get("password")
+
+	form := forms.New(r.PostForm)
+	form.Required("email", "password")
+	form.IsEmail("email")
+
+	if !form.Valid() {
+		stringMap := make(map[string]string)
+		stringMap["email"] = email
+		m.App.Session.Put(r.Context(), "error", "Invalid inputs")
+		render.Template(w, r, "login.page.html", &models.TemplateData{
+			Form:      form,
+			StringMap: stringMap,
+		})
+
+		return
+	}
+
+	user, err := m.DB.Authenticate(r.Context(), email, password)
+	if err != nil {
+		log.Println(err)
+
+		m.App.Session.Put(r.Context(), "error", "Invalid email/password")
+		http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+
+		return
+	}
+
+	if user.Disabled() {
+		m.App.Session.Put(r.Context(), "error", "This account has been disabled. Please contact us if you think this is a mistake")
+		http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+		return
+	}
+
+	if user.Locked(time.Now()) {
+		m.App.Session.Put(r.Context(), "error", fmt.Sprintf("This account is locked until %s", user.LockedUntil.Format("2006-01-02 15:04")))
+		http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+		return
+	}
+
+	if !user.Verified {
+		m.App.Session.Put(r.Context(), "error", "Kindly verify your email before logging in. Check your inbox, or request a new verification link below")
+		http.Redirect(w, r, "/user/resend-verification", http.StatusSeeOther)
+		return
+	}
+
+	// bookings made as a guest with the same email show up in the account from now on
+	_, err = m.DB.ClaimBookings(r.Context(), user.ID, user.Email)
+	if err != nil {
+		m.App.ErrorLog.Println(err)
+	}
+
+	m.App.Session.Put(r.Context(), "user_id", user.ID)
+	m.App.Session.Put(r.Context(), "role", user.Role)
+	m.App.Session.Put(r.Context(), "verified", user.Verified)
+	m.App.Session.Put(r.Context(), "flash", "Login Successful")
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// This function handles the Signup page and renders the template
+func (m *Repository) Signup(w http.ResponseWriter, r *http.Request) {
+	userExists := m.App.Session.GetInt(r.Context(), "user_id")
+	if userExists > 0 {
+		m.App.Session.Put(r.Context(), "warning", "You are already logged in")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	render.Template(w, r, "signup.page.html", &models.TemplateData{
+		Form: forms.New(nil),
+	})
+}
+
+// This function handles the posting of user signup form
+func (m *Repository) PostSignup(w http.ResponseWriter, r *http.Request) {
+	_ = m.App.Session.Destroy(r.Context())
+	_ = m.App.Session.RenewToken(r.Context())
+
+	user := models.User{}
+	stringMap := make(map[string]string)
+
+	err := r.ParseForm()
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "Can't parse form")
+		http.Redirect(w, r, "/user/signup", http.StatusSeeOther)
+		return
+	}
+
+	user.FirstName = r.Form.Get("first_name")
+	user.LastName = r.Form.Get("last_name")
+	user.Email = r.Form.Get("email")
+	user.Password = r.Form.Get("password")
+	user.Role = roles.Customer
+
+	form := forms.New(r.PostForm)
+	form.Required("first_name", "last_name", "email", "password")
+	form.MinLength("first_name", 3, 30)
+	form.MinLength("last_name", 3, 30)
+	form.IsEmail("email")
+
+	if !form.Valid() {
+		stringMap["first_name"] = user.FirstName
+		stringMap["last_name"] = user.LastName
+		stringMap["email"] = user.Email
+		m.App.Session.Put(r.Context(), "error", "Invalid inputs")
+		render.Template(w, r, "signup.page.html", &models.TemplateData{
+			Form:      form,
+			StringMap: stringMap,
+		})
+
+		return
+	}
+
+	userExist, err := m.DB.CheckIfUserEmailExist(r.Context(), user.Email)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	if userExist {
+		log.Println("Email exist")
+		stringMap["first_name"] = user.FirstName
+		stringMap["last_name"] = user.LastName
+		stringMap["email"] = user.Email
+		m.App.Session.Put(r.Context(), "error", "Email exist. Kindly use the forgot password form to reset your password")
+		render.Template(w, r, "signup.page.html", &models.TemplateData{
+			Form:      form,
+			StringMap: stringMap,
+		})
+
+		return
+	}
+
+	// Load the env file and get the frontendURL
+	err = godotenv.Load()
+	if err != nil {
+		log.Println("Error loading .env file")
+	}
+	frontendURL := os.Getenv("FRONTEND_URL")
+
+	// the user is only saved if the verification email could be queued
+	err = m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		newUserID, err := repo.InsertUser(r.Context(), user)
+		if err != nil {
+			return err
+		}
+
+		user.ID = newUserID
+
+		return m.queueVerification(r.Context(), repo, user, frontendURL)
+	})
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "user", user)
+
+	m.App.Session.Put(r.Context(), "flash", fmt.Sprintf("Sign up Successful!!! <br /> Please, check your email and verify your account within %s to continue", verificationValidFor()))
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// queueVerification stores a new verification link for user, revoking their earlier ones, and queues the email with it
+func (m *Repository) queueVerification(ctx context.Context, repo repository.DatabaseRepo, user models.User, frontendURL string) error {
+	token, tokenHash, err := helpers.GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	err = repo.InsertEmailVerification(ctx, models.EmailVerification{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(verificationTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	return m.enqueueEmails(ctx, repo, verifyEmail(user, token, frontendURL))
+}
+
+// verificationValidFor says how long verification links work
+func verificationValidFor() string {
+	return fmt.Sprintf("%d hours", int(verificationTTL.Hours()))
+}
+
+// verifyEmail returns the email with the link a user opens to verify their email address
+func verifyEmail(user models.User, token, frontendURL string) outgoingEmail {
+	return outgoingEmail{to: user.Email, data: emails.VerifyEmail{
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		VerifyURL: fmt.Sprintf("%s/verify-email?token=%s", frontendURL, url.QueryEscape(token)),
+		ValidFor:  verificationValidFor(),
+	}}
+}
+
+// Verifies a user's email with the link from their verification email. Each link works once
+func (m *Repository) VerifyUserEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+
+	var user models.User
+	err := m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		userID, err := repo.VerifyEmail(r.Context(), helpers.HashToken(token))
+		if err != nil {
+			return err
+		}
+
+		user, err = repo.GetUserByID(r.Context(), userID)
+		if err != nil {
+			return err
+		}
+
+		// the email is now proven to be theirs, so their guest bookings are too
+		_, err = repo.ClaimBookings(r.Context(), user.ID, user.Email)
+		if err != nil {
+			return err
+		}
+
+		return m.enqueueEmails(r.Context(), repo, emailVerifiedEmail(user))
+	})
+	if errors.Is(err, repository.ErrInvalidVerificationToken) {
+		m.App.Session.Put(r.Context(), "error", "This verification link is invalid or has expired. Please, request a new one")
+		http.Redirect(w, r, "/user/resend-verification", http.StatusSeeOther)
+		return
+	} else if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", "Email Verification Successful!!! <br /> Please, login")
+	http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+}
+
+// This function handles the page where users ask for a new verification email
+func (m *Repository) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	render.Template(w, r, "resend-verification.page.html", &models.TemplateData{
+		Form: forms.New(nil),
+	})
+}
+
+// This function handles the posting of the resend verification form. Like the forgot password form it answers
+// the same way for every email, so it can't be used to find out who has an account
+func (m *Repository) PostResendVerification(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "Can't parse form")
+		http.Redirect(w, r, "/user/resend-verification", http.StatusSeeOther)
+		return
+	}
+
+	email := r.Form.Get("email")
+
+	form := forms.New(r.PostForm)
+	form.Required("email")
+	form.IsEmail("email")
+
+	if !form.Valid() {
+		stringMap := make(map[string]string)
+		stringMap["email"] = email
+		m.App.Session.Put(r.Context(), "error", "Invalid inputs")
+		render.Template(w, r, "resend-verification.page.html", &models.TemplateData{
+			Form:      form,
+			StringMap: stringMap,
+		})
+
+		return
+	}
+
+	if !m.linkLimiter.Allow(clientIP(r)) {
+		m.App.Session.Put(r.Context(), "error", "Too many verification requests. Please try again later")
+		http.Redirect(w, r, "/user/resend-verification", http.StatusSeeOther)
+		return
+	}
+
+	sent := fmt.Sprintf("If that email belongs to an account that still needs verifying, we have sent it a new link. It expires in %s", verificationValidFor())
+
+	user, err := m.DB.GetUserByEmail(r.Context(), email)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && user.Verified) {
+		m.App.Session.Put(r.Context(), "flash", sent)
+		http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+		return
+	} else if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	recent, err := m.DB.CountEmailVerifications(r.Context(), user.ID, time.Now().Add(-time.Hour))
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	if recent >= maxLinkEmails {
+		log.Printf("Verification email for user #%d not sent, %d were sent in the last hour", user.ID, recent)
+		m.App.Session.Put(r.Context(), "flash", sent)
+		http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+		return
+	}
+
+	// Load the env file and get the frontendURL
+	err = godotenv.Load()
+	if err != nil {
+		log.Println("Error loading .env file")
+	}
+	frontendURL := os.Getenv("FRONTEND_URL")
+
+	err = m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		return m.queueVerification(r.Context(), repo, user, frontendURL)
+	})
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", sent)
+	http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+}
+
+// emailVerifiedEmail returns the email that tells a user their email address is verified
+func emailVerifiedEmail(user models.User) outgoingEmail {
+	return outgoingEmail{to: user.Email, data: emails.EmailVerified{FirstName: user.FirstName}}
+}
+
+// Password reset links stop working after passwordResetTTL and verification links after verificationTTL. Each
+// account can be sent maxLinkEmails of each an hour, and each address can post the forms that send them
+// linkRequestLimit times per linkRequestWindow
+const (
+	passwordResetTTL  = time.Hour
+	verificationTTL   = 48 * time.Hour
+	maxLinkEmails     = 3
+	linkRequestLimit  = 10
+	linkRequestWindow = 15 * time.Minute
+)
+
+// This function handles the Forgot Password page and renders the template
+func (m *Repository) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	render.Template(w, r, "forgot-password.page.html", &models.TemplateData{
+		Form: forms.New(nil),
+	})
+}
+
+// This function handles the posting of the forgot password form. It answers the same way whether or not the
+// email belongs to an account, so the form can't be used to find out who has one
+func (m *Repository) PostForgotPassword(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "Can't parse form")
+		http.Redirect(w, r, "/user/forgot-password", http.StatusSeeOther)
+		return
+	}
+
+	email := r.Form.Get("email")
+
+	form := forms.New(r.PostForm)
+	form.Required("email")
+	form.IsEmail("email")
+
+	if !form.Valid() {
+		stringMap := make(map[string]string)
+		stringMap["email"] = email
+		m.App.Session.Put(r.Context(), "error", "Invalid inputs")
+		render.Template(w, r, "forgot-password.page.html", &models.TemplateData{
+			Form:      form,
+			StringMap: stringMap,
+		})
+
+		return
+	}
+
+	if !m.linkLimiter.Allow(clientIP(r)) {
+		m.App.Session.Put(r.Context(), "error", "Too many password reset requests. Please try again later")
+		http.Redirect(w, r, "/user/forgot-password", http.StatusSeeOther)
+		return
+	}
+
+	const sent = "If an account exists for that email, we have sent it a link to reset your password"
+
+	user, err := m.DB.GetUserByEmail(r.Context(), email)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && user.Disabled()) {
+		m.App.Session.Put(r.Context(), "flash", sent)
+		http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+		return
+	} else if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	recent, err := m.DB.CountPasswordResets(r.Context(), user.ID, time.Now().Add(-time.Hour))
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	if recent >= maxLinkEmails {
+		log.Printf("Password reset for user #%d not sent, %d were sent in the last hour", user.ID, recent)
+		m.App.Session.Put(r.Context(), "flash", sent)
+		http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+		return
+	}
+
+	// Load the env file and get the frontendURL
+	err = godotenv.Load()
+	if err != nil {
+		log.Println("Error loading .env file")
+	}
+	frontendURL := os.Getenv("FRONTEND_URL")
+
+	err = m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		return m.queuePasswordReset(r.Context(), repo, user, frontendURL)
+	})
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", sent)
+	http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+}
+
+// queuePasswordReset stores a new password reset link for user and queues the email that sends it to them
+func (m *Repository) queuePasswordReset(ctx context.Context, repo repository.DatabaseRepo, user models.User, frontendURL string) error {
+	token, tokenHash, err := helpers.GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	err = repo.InsertPasswordReset(ctx, models.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	return m.enqueueEmails(ctx, repo, passwordResetEmail(user, token, frontendURL))
+}
+
+// passwordResetEmail returns the email with the link a user opens to choose a new password
+func passwordResetEmail(user models.User, token, frontendURL string) outgoingEmail {
+	return outgoingEmail{to: user.Email, data: emails.PasswordReset{
+		FirstName: user.FirstName,
+		ResetURL:  fmt.Sprintf("%s/user/reset-password?token=%s", frontendURL, url.QueryEscape(token)),
+		ValidFor:  fmt.Sprintf("%d minutes", int(passwordResetTTL.Minutes())),
+	}}
+}
+
+// clientIP returns the address a request came from, without its port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// This function handles the Reset Password page the emailed link opens
+func (m *Repository) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+
+	_, err := m.DB.GetPasswordReset(r.Context(), helpers.HashToken(token))
+	if errors.Is(err, repository.ErrInvalidResetToken) {
+		m.App.Session.Put(r.Context(), "error", "This reset link is invalid or has expired. Please request a new one")
+		http.Redirect(w, r, "/user/forgot-password", http.StatusSeeOther)
+		return
+	} else if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	// the token is in the url, keep it out of the Referer of anything the page loads
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	stringMap := make(map[string]string)
+	stringMap["token"] = token
+
+	render.Template(w, r, "reset-password.page.html", &models.TemplateData{
+		Form:      forms.New(nil),
+		StringMap: stringMap,
+	})
+}
+
+// This function handles the posting of the reset password form. Once the password is changed the user is
+// logged out of every session, in case someone else was using their account
+func (m *Repository) PostResetPassword(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "Can't parse form")
+		http.Redirect(w, r, "/user/forgot-password", http.StatusSeeOther)
+		return
+	}
+
+	token := r.Form.Get("token")
+	password := r.Form.Get("password")
+
+	form := forms.New(r.PostForm)
+	form.Required("password", "confirm_password")
+	// bcrypt only uses the first 72 bytes of a password
+	form.MinLength("password", 8, 72)
+	if password != r.Form.Get("confirm_password") {
+		form.Errors.Add("confirm_password", "The passwords do not match")
+	}
+
+	if !form.Valid() {
+		stringMap := make(map[string]string)
+		stringMap["token"] = token
+		m.App.Session.Put(r.Context(), "error", "Invalid inputs")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		render.Template(w, r, "reset-password.page.html", &models.TemplateData{
+			Form:      form,
+			StringMap: stringMap,
+		})
+
+		return
+	}
+
+	userID, err := m.DB.ResetPassword(r.Context(), helpers.HashToken(token), password)
+	if errors.Is(err, repository.ErrInvalidResetToken) {
+		m.App.Session.Put(r.Context(), "error", "This reset link is invalid or has expired. Please request a new one")
+		http.Redirect(w, r, "/user/forgot-password", http.StatusSeeOther)
+		return
+	} else if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	err = m.logoutUser(r.Context(), userID)
+	if err != nil {
+		log.Println(err)
+	}
+
+	_ = m.App.Session.Destroy(r.Context())
+	_ = m.App.Session.RenewToken(r.Context())
+
+	m.App.Session.Put(r.Context(), "flash", "Your password has been reset. Please, login with your new password")
+	http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+}
+
+// logoutUser destroys every session userID is logged in with
+func (m *Repository) logoutUser(ctx context.Context, userID int) error {
+	return m.App.Session.Iterate(ctx, func(ctx context.Context) error {
+		if m.App.Session.GetInt(ctx, "user_id") != userID {
+			return nil
+		}
+		return m.App.Session.Destroy(ctx)
+	})
+}
+
+// This function logs out the user
+func (m *Repository) Logout(w http.ResponseWriter, r *http.Request) {
+	_ = m.App.Session.Destroy(r.Context())
+	_ = m.App.Session.RenewToken(r.Context())
+
+	m.App.Session.Put(r.Context(), "warning", "You have logged out of your account")
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// Handles the admin dashborad
+func (m *Repository) AdminDashboard(w http.ResponseWriter, r *http.Request) {
+	render.Template(w, r, "admin-dashboard.page.html", &models.TemplateData{})
+}
+
+// Handles the single reservation route
+func (m *Repository) AdminShowReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	stringMap := make(map[string]string)
+	stringMap["src"] = chi.URLParam(r, "src")
+	stringMap["year"] = r.URL.Query().Get("y")
+	stringMap["month"] = r.URL.Query().Get("m")
+
+	reservation, err := m.DB.GetReservationByID(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	history, err := m.DB.GetReservationStatusHistory(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["reservation"] = reservation
+	data["history"] = history
+	data["next_statuses"] = status.Next(reservation.Status)
+
+	render.Template(w, r, "admin-single-reservation.page.html", &models.TemplateData{
+		StringMap: stringMap,
+		Data:      data,
+		Form:      forms.New(nil),
+	})
+}
+
+// Handles moving a reservation to a new status
+func (m *Repository) AdminPostReservationStatus(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	src := chi.URLParam(r, "src")
+	year := r.Form.Get("year")
+	month := r.Form.Get("month")
+
+	redirectURL := fmt.Sprintf("/admin/reservations/%s/%d/show?y=%s&m=%s", src, id, year, month)
+
+	reservation, err := m.DB.GetReservationByID(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	newStatus := r.Form.Get("status")
+	err = status.Transition(reservation.Status, newStatus)
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+
+	userID := m.App.Session.GetInt(r.Context(), "user_id")
+	err = m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		err := repo.UpdateReservationStatus(r.Context(), id, reservation.Status, newStatus, userID)
+		if err != nil {
+			return err
+		}
+
+		return m.enqueueEmails(r.Context(), repo, statusEmail(reservation.Email, reservation.FirstName, "reservation", reservation.StartDate, reservation.EndDate, newStatus))
+	})
+	if errors.Is(err, status.ErrStaleStatus) {
+		m.App.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", fmt.Sprintf("Reservation is now marked as %s", status.Label(newStatus)))
+	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+}
+
+// statusEmailMessages holds the extra line sent to customers for each status
+var statusEmailMessages = map[string]string{
+	status.Quoted:      "We have prepared a quote for you. Please reply to this email to accept it.",
+	status.Confirmed:   "Everything is set. We look forward to seeing you.",
+	status.DepositPaid: "We have received your deposit, thank you.",
+	status.Completed:   "Thank you for choosing us, we hope to see you again soon.",
+	status.Cancelled:   "If you did not ask for this cancellation, please contact us.",
+	status.NoShow:      "We missed you. Please contact us if you would like to make a new booking.",
+}
+
+// statusEmail lets the customer know that their reservation or booking has moved to a new status
+func statusEmail(email, firstName, kind string, startDate, endDate time.Time, newStatus string) outgoingEmail {
+	return outgoingEmail{to: email, data: emails.StatusChanged{
+		FirstName: firstName,
+		Kind:      kind,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Status:    status.Label(newStatus),
+		Message:   statusEmailMessages[newStatus],
+	}}
+}
+
+// outgoingEmail is an email template with its data and recipient, rendered when it is queued. Admin
+// notifications name an event instead of a recipient and go to the recipients set for that event
+type outgoingEmail struct {
+	to    string
+	event string
+	data  emails.Email
+}
+
+// adminNotification returns a notification for the admins who follow event
+func adminNotification(event string, data emails.AdminNotification) outgoingEmail {
+	return outgoingEmail{event: event, data: data}
+}
+
+// adminEvent returns the notification event that changes to a reservation or booking are sent under
+func adminEvent(kind string) string {
+	if kind == manageReservation {
+		return settings.EventNewReservation
+	}
+	return settings.EventNewBooking
+}
+
+// renderEmail renders data for to, sent from the address in the settings
+func (m *Repository) renderEmail(to string, data emails.Email) (models.MailData, error) {
+	current := m.App.Settings.Get()
+
+	message, err := m.App.Emails.Render(current.SenderEmail, to, data)
+	if err != nil {
+		return message, err
+	}
+
+	message.FromName = current.SenderName
+	message.ReplyTo = current.ReplyTo
+
+	return message, nil
+}
+
+// enqueueEmails renders messages and adds them to the outbox through repo, so they are only sent if a
+// transaction around repo commits
+func (m *Repository) enqueueEmails(ctx context.Context, repo repository.DatabaseRepo, messages ...outgoingEmail) error {
+	for _, message := range messages {
+		recipients := []string{message.to}
+		if message.event != "" {
+			recipients = m.App.Settings.Get().Recipients(message.event)
+		}
+
+		for _, to := range recipients {
+			mail, err := m.renderEmail(to, message.data)
+			if err != nil {
+				return err
+			}
+
+			err = repo.EnqueueEmail(ctx, mail)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Handles the deleting of revervation
+func (m *Repository) AdminDeleteReservation(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	src := chi.URLParam(r, "src")
+
+	err := m.DB.DeleteReservation(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	year := r.URL.Query().Get("y")
+	month := r.URL.Query().Get("m")
+
+	m.App.Session.Put(r.Context(), "flash", "<strong>Successful!!!</strong><br><br> <p>Reservation Deleted</p>")
+
+	if year == "" {
+		http.Redirect(w, r, fmt.Sprintf("/admin/%s-reservations", src), http.StatusSeeOther)
+	} else {
+		http.Redirect(w, r, fmt.Sprintf("/admin/reservations-calendar?y=%s&m=%s", year, month), http.StatusSeeOther)
+	}
+}
+
+// Handles the reservations-calendar route
+func (m *Repository) AdminReservationsCalendar(w http.ResponseWriter, r *http.Request) {
+	data, stringMap, intMap, firstOfMonth := calendarMonthData(r)
+	lastOfMonth := firstOfMonth.AddDate(0, 1, -1)
+
+	rooms, err := m.DB.AllRooms(r.Context())
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data["rooms"] = rooms
+
+	for _, x := range rooms {
+		// get all the restrictions for the current room
+		restrictions, err := m.DB.GetRestrictionsForCurrentRoom(r.Context(), x.ID, firstOfMonth, lastOfMonth)
+		if err != nil {
+			helpers.ServerError(w, err)
+			return
+		}
+
+		var entries []calendar.Entry
+		blockMap := make(map[string]int)
+
+		for _, y := range restrictions {
+			if y.ReservationID > 0 {
+				// it's a reservation
+				entries = append(entries, calendar.Entry{ID: y.ReservationID, Kind: calendar.KindBooking, Start: y.StartDate, End: y.EndDate})
+			} else {
+				// it's a block
+				entries = append(entries, calendar.Entry{ID: y.ID, Kind: calendar.KindBlock, Label: y.Reason, Start: y.StartDate, End: y.EndDate})
+				blockMap[strconv.Itoa(y.ID)] = y.ID
+			}
+		}
+
+		data[fmt.Sprintf("cells_%d", x.ID)] = calendar.MonthCells(firstOfMonth, entries)
+
+		m.App.Session.Put(r.Context(), fmt.Sprintf("block_map_%d", x.ID), blockMap)
+	}
+
+	render.Template(w, r, "admin-reservations-calendar.page.html", &models.TemplateData{
+		StringMap: stringMap,
+		Data:      data,
+		IntMap:    intMap,
+	})
+}
+
+// Handles the reservation calendar POST route
+func (m *Repository) AdminPostReservationsCalendar(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	year, _ := strconv.Atoi(r.Form.Get("year"))
+	month, _ := strconv.Atoi(r.Form.Get("month"))
+
+	//Process changes
+	rooms, err := m.DB.AllRooms(r.Context())
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	form := forms.New(r.PostForm)
+
+	for _, x := range rooms {
+		// Get the block map from the session. Any block in the map that was unchecked in the posted data
+		// is a block we need to remove.
+		curMap, ok := m.App.Session.Get(r.Context(), fmt.Sprintf("block_map_%d", x.ID)).(map[string]int)
+		if !ok {
+			continue
+		}
+
+		for name, value := range curMap {
+			if value > 0 && !form.Has(fmt.Sprintf("remove_block_%d_%s", x.ID, name)) {
+				// delete the restriction by id
+				err := m.DB.DeleteBlockByID(r.Context(), value)
+				if err != nil {
+					log.Println(err)
+				}
+				m.App.Session.Put(r.Context(), "flash", "Block removed successfully")
+			}
+		}
+	}
+
+	// now handle new single day blocks
+	for name := range r.PostForm {
+		if strings.HasPrefix(name, "add_block") {
+			exploded := strings.Split(name, "_")
+			roomID, _ := strconv.Atoi(exploded[2])
+			t, err := time.Parse("2006-01-2", exploded[3])
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			// insert a new block
+			err = m.DB.InsertBlockForRoom(r.Context(), roomID, t, t, "")
+			if err != nil {
+				log.Println(err)
+			}
+			m.App.Session.Put(r.Context(), "flash", "Reservation Block Updated")
+		}
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/reservations-calendar?y=%d&m=%d", year, month), http.StatusSeeOther)
+}
+
+// Handles the date range block form on the reservations calendar
+func (m *Repository) AdminPostRoomBlocks(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	redirectURL := fmt.Sprintf("/admin/reservations-calendar?y=%s&m=%s", r.Form.Get("year"), r.Form.Get("month"))
+
+	roomID, err := strconv.Atoi(r.Form.Get("room_id"))
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "Invalid room selected")
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+
+	ranges, reason, err := blockRangesFromForm(r)
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+
+	// add every occurrence or none of them
+	added, skipped := 0, 0
+	err = m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		for _, dates := range ranges {
+			// don't block over days that already have a reservation or block
+			available, err := repo.SearchAvailabilityByDatesByRoomID(r.Context(), dates.Start, dates.End, roomID)
+			if err != nil {
+				return err
+			}
+
+			if !available {
+				skipped++
+				continue
+			}
+
+			err = repo.InsertBlockForRoom(r.Context(), roomID, dates.Start, dates.End, reason)
+			if err != nil {
+				return err
+			}
+			added++
+		}
+		return nil
+	})
+	if errors.Is(err, repository.ErrUnavailable) {
+		m.App.Session.Put(r.Context(), "error", "Some of those dates were just booked, no blocks were added. Please, try again")
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", blockSummary(added, skipped))
+	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+}
+
+// Handles the all-rooms route
+func (m *Repository) AdminAllRooms(w http.ResponseWriter, r *http.Request) {
+	rooms, err := m.DB.AllRooms(r.Context())
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["rooms"] = rooms
+
+	render.Template(w, r, "admin-all-rooms.page.html", &models.TemplateData{
+		Data: data,
+	})
+}
+
+// Handles the single-room route
+func (m *Repository) AdminSingleRoom(w http.ResponseWriter, r *http.Request) {
+	urlParams := strings.Split(r.RequestURI, "/")
+	id, err := strconv.Atoi(urlParams[3])
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	room, err := m.DB.GetRoomByID(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["room"] = room
+
+	render.Template(w, r, "admin-single-room.page.html", &models.TemplateData{
+		Data: data,
+		Form: forms.New(nil),
+	})
+}
+
+// Handles the single-room route for POST
+func (m *Repository) PostAdminSingleRoom(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	urlParams := strings.Split(r.RequestURI, "/")
+	id, err := strconv.Atoi(urlParams[3])
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	room, err := m.DB.GetRoomByID(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	room.RoomName = r.Form.Get("room_name")
+	room.Price = r.Form.Get("price")
+	room.ImageSource = r.Form.Get("image_src")
+	room.Description = r.Form.Get("description")
+
+	form := forms.New(r.PostForm)
+	form.Required("room_name", "price", "image_src", "description")
+	form.MinLength("room_name", 5, 30)
+	form.MinLength("description", 5, 20000)
+
+	if !form.Valid() {
+		data := make(map[string]interface{})
+		data["room"] = room
+		m.App.Session.Put(r.Context(), "error", "Invalid inputs")
+		render.Template(w, r, "admin-single-room.page.html", &models.TemplateData{
+			Form: form,
+			Data: data,
+		})
+
+		return
+	}
+
+	err = m.DB.UpdateRoom(r.Context(), room)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", "Reservation Updated")
+	http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+}
+
+// Handles the new-room route to create a new room
+func (m *Repository) AdminNewRoom(w http.ResponseWriter, r *http.Request) {
+	render.Template(w, r, "admin-new-room.page.html", &models.TemplateData{
+		Form: forms.New(nil),
+	})
+}
+
+// This function POST the new room form and store them in the database
+func (m *Repository) PostAdminNewRoom(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	var room models.Room
+
+	room.RoomName = r.Form.Get("room_name")
+	room.Price = r.Form.Get("price")
+	room.ImageSource = r.Form.Get("image_src")
+	room.Description = r.Form.Get("description")
+
+	// Form validations
+	form := forms.New(r.PostForm)
+	form.Required("room_name", "price", "image_src", "description")
+	form.MinLength("room_name", 5, 30)
+	form.MinLength("description", 5, 20000)
+
+	if !form.Valid() {
+		data := make(map[string]interface{})
+		data["room"] = room
+		m.App.Session.Put(r.Context(), "error", "Invalid form input")
+		render.Template(w, r, "admin-new-room.page.html", &models.TemplateData{
+			Form: form,
+			Data: data,
+		})
+		return
+	}
+
+	// Insert new room here
+	err = m.DB.InsertRoom(r.Context(), room)
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "Can't insert into database")
+		helpers.ServerError(w, err)
+		http.Redirect(w, r, "/admin/rooms", http.StatusTemporaryRedirect)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", "Room Created Successfully!!!")
+	http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+}
+
+func (m *Repository) AdminDeleteRoom(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	err := m.DB.DeleteRoom(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", "<strong>Successful!!!</strong><br><br> <p>Room Deleted</p>")
+	http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+}
+
+// Handles the admin todo list route
+func (m *Repository) AdminTodoList(w http.ResponseWriter, r *http.Request) {
+	userID := m.App.Session.GetInt(r.Context(), "user_id")
+
+	todoList, err := m.DB.GetTodoListByUserID(r.Context(), userID)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["todo_list"] = todoList
+
+	render.Template(w, r, "admin-todo.page.html", &models.TemplateData{
+		Form: forms.New(nil),
+		Data: data,
+	})
+}
+
+// Handles the Post for todo list route
+func (m *Repository) PostAdminTodoList(w http.ResponseWriter, r *http.Request) {
+	userID := m.App.Session.GetInt(r.Context(), "user_id")
+
+	var todoList models.TodoList
+
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	todoList.Todo = r.Form.Get("todo_list")
+	todoList.UserID = userID
+
+	form := forms.New(r.PostForm)
+	form.Required("todo_list")
+	form.MinLength("todo_list", 5, 255)
+
+	if !form.Valid() {
+		data := make(map[string]interface{})
+		data["todo_list"] = todoList
+		m.App.Session.Put(r.Context(), "error", "Invalid form input. <br> must be less than 250 and more than 5 characters")
+		render.Template(w, r, "admin-todo.page.html", &models.TemplateData{
+			Form: form,
+			Data: data,
+		})
+		return
+	}
+
+	// Insert new todo here
+	err = m.DB.InsertTodoList(r.Context(), todoList)
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "Can't insert into database")
+		helpers.ServerError(w, err)
+		http.Redirect(w, r, "/admin/todo-list", http.StatusTemporaryRedirect)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", "Todo Created Successfully!!!")
+	http.Redirect(w, r, "/admin/todo-list", http.StatusSeeOther)
+}
+
+// AdminDeleteTodo deletes Todo from the database and
+func (m *Repository) AdminDeleteTodo(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	err := m.DB.DeleteTodo(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", "<strong>Successful!!!</strong><br><br> <p>Todo Deleted</p>")
+	http.Redirect(w, r, "/admin/todo-list", http.StatusSeeOther)
+}
+
+// Recent ------------------------------------------
+
+// Handles the all-artists route
+func (m *Repository) AdminAllArtists(w http.ResponseWriter, r *http.Request) {
+	filter := r.URL.Query().Get("status")
+	if !listing.Valid(filter) {
+		filter = ""
+	}
+
+	artists, err := m.DB.AllArtistsByStatus(r.Context(), filter)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["artists"] = artists
+	data["statuses"] = listing.All
+
+	stringMap := make(map[string]string)
+	stringMap["status"] = filter
+
+	render.Template(w, r, "admin-all-artists.page.html", &models.TemplateData{
+		StringMap: stringMap,
+		Data:      data,
+	})
+}
+
+// Handles the new-artist route to create a new artist
+func (m *Repository) AdminNewArtist(w http.ResponseWriter, r *http.Request) {
+	allGenres, err := m.DB.AllGenres(r.Context())
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["genres"] = allGenres
+
+	render.Template(w, r, "admin-new-artist.page.html", &models.TemplateData{
+		Form: forms.New(nil),
+		Data: data,
+	})
+}
+
+// This function POST the new artist form and store them in the database
+func (m *Repository) PostAdminNewArtist(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	allGenres, err := m.DB.AllGenres(r.Context())
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	artist, form := artistFromForm(r, models.Artist{Status: listing.Approved}, allGenres)
+
+	if !form.Valid() {
+		data := make(map[string]interface{})
+		data["artist"] = artist
+		data["genres"] = allGenres
+		m.App.Session.Put(r.Context(), "error", "Invalid form input")
+		render.Template(w, r, "admin-new-artist.page.html", &models.TemplateData{
+			Form: form,
+			Data: data,
+		})
+		return
+	}
+
+	// Insert new artist here
+	_, err = m.DB.CreateArtist(r.Context(), artist)
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "Can't insert new artist into database")
+		helpers.ServerError(w, err)
+		http.Redirect(w, r, "/admin/artists", http.StatusTemporaryRedirect)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", "Artist Created Successfully!!!")
+	http.Redirect(w, r, "/admin/artists", http.StatusSeeOther)
+}
+
+// Handles the single-room route
+func (m *Repository) AdminSingleArtist(w http.ResponseWriter, r *http.Request) {
+	urlParams := strings.Split(r.RequestURI, "/")
+	id, err := strconv.Atoi(urlParams[3])
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	artist, err := m.DB.GetArtistByIDAnyStatus(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	allGenres, err := m.DB.AllGenres(r.Context())
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["artist"] = artist
+	data["genres"] = allGenres
+	data["next_statuses"] = listing.Next(artist.Status)
+
+	if artist.UserID > 0 {
+		owner, err := m.DB.GetUserByID(r.Context(), artist.UserID)
+		if err != nil {
+			helpers.ServerError(w, err)
+			return
+		}
+		data["owner"] = owner
+	}
+
+	render.Template(w, r, "admin-single-artist.page.html", &models.TemplateData{
+		Data: data,
+		Form: forms.New(nil),
+	})
+}
+
+// Handles the single-artist route for POST
+func (m *Repository) PostAdminSingleArtist(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	urlParams := strings.Split(r.RequestURI, "/")
+	id, err := strconv.Atoi(urlParams[3])
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	artist, err := m.DB.GetArtistByIDAnyStatus(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	allGenres, err := m.DB.AllGenres(r.Context())
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	artist, form := artistFromForm(r, artist, allGenres)
+
+	if !form.Valid() {
+		data := make(map[string]interface{})
+		data["artist"] = artist
+		data["genres"] = allGenres
+		data["next_statuses"] = listing.Next(artist.Status)
+		m.App.Session.Put(r.Context(), "error", "Invalid inputs")
+		render.Template(w, r, "admin-single-artist.page.html", &models.TemplateData{
+			Form: form,
+			Data: data,
+		})
+
+		return
+	}
+
+	err = m.DB.UpdateArtist(r.Context(), artist)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", "Artist Updated Successsfully!!!")
+	http.Redirect(w, r, "/admin/artists", http.StatusSeeOther)
+}
+
+// Handles the queue of artist listings waiting for review
+func (m *Repository) AdminPendingArtists(w http.ResponseWriter, r *http.Request) {
+	artists, err := m.DB.AllArtistsByStatus(r.Context(), listing.Pending)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	owners := make(map[int]models.User)
+	for _, artist := range artists {
+		if _, ok := owners[artist.UserID]; ok || artist.UserID == 0 {
+			continue
+		}
+
+		owner, err := m.DB.GetUserByID(r.Context(), artist.UserID)
+		if err != nil {
+			helpers.ServerError(w, err)
+			return
+		}
+		owners[owner.ID] = owner
+	}
+
+	data := make(map[string]interface{})
+	data["artists"] = artists
+	data["owners"] = owners
+
+	render.Template(w, r, "admin-pending-artists.page.html", &models.TemplateData{
+		Data: data,
+	})
+}
+
+// Handles approving, rejecting and suspending artist listings. The owner is emailed about the change
+func (m *Repository) AdminPostArtistStatus(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	redirectURL := fmt.Sprintf("/admin/artists/%d", id)
+	if r.Form.Get("queue") != "" {
+		redirectURL = "/admin/artists/pending"
+	}
+
+	artist, err := m.DB.GetArtistByIDAnyStatus(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	newStatus := r.Form.Get("status")
+	err = listing.Transition(artist.Status, newStatus)
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+
+	reason := strings.TrimSpace(r.Form.Get("reason"))
+	if listing.NeedsReason(newStatus) && reason == "" {
+		m.App.Session.Put(r.Context(), "error", "Please, give the owner a reason")
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+	if !listing.NeedsReason(newStatus) {
+		reason = ""
+	}
+
+	godotenv.Load()
+	frontendURL := os.Getenv("FRONTEND_URL")
+
+	err = m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		err := repo.UpdateArtistStatus(r.Context(), id, artist.Status, newStatus, reason)
+		if err != nil {
+			return err
+		}
+
+		if artist.UserID == 0 {
+			return nil
+		}
+
+		owner, err := repo.GetUserByID(r.Context(), artist.UserID)
+		if err != nil {
+			return err
+		}
+
+		return m.enqueueEmails(r.Context(), repo, outgoingEmail{to: owner.Email, data: emails.ListingReviewed{
+			FirstName:  owner.FirstName,
+			ArtistName: artist.Name,
+			Status:     listing.Label(newStatus),
+			Reason:     reason,
+			ManageURL:  fmt.Sprintf("%s/dashboard/artist", frontendURL),
+		}})
+	})
+	if errors.Is(err, listing.ErrStaleStatus) {
+		m.App.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	log.Printf("user #%d moved the listing of artist #%d from %s to %s", m.App.Session.GetInt(r.Context(), "user_id"), id, artist.Status, newStatus)
+
+	m.App.Session.Put(r.Context(), "flash", fmt.Sprintf("%s is now %s", artist.Name, listing.Label(newStatus)))
+	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+}
+
+// Handles the genres admins manage, with the form to add one
+func (m *Repository) AdminGenres(w http.ResponseWriter, r *http.Request) {
+	m.renderAdminGenres(w, r, models.Genre{}, forms.New(nil))
+}
+
+// Handles adding a genre. Its aliases are typed separated by commas
+func (m *Repository) PostAdminGenres(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	genre, form := genreFromForm(r, models.Genre{})
+	if !form.Valid() {
+		m.App.Session.Put(r.Context(), "error", "Invalid form input")
+		m.renderAdminGenres(w, r, genre, form)
+		return
+	}
+
+	_, err = m.DB.CreateGenre(r.Context(), genre)
+	if errors.Is(err, repository.ErrGenreTaken) {
+		form.Errors.Add("name", err.Error())
+		m.App.Session.Put(r.Context(), "error", err.Error())
+		m.renderAdminGenres(w, r, genre, form)
+		return
+	} else if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", fmt.Sprintf("%s was added", genre.Name))
+	http.Redirect(w, r, "/admin/genres", http.StatusSeeOther)
+}
+
+// renderAdminGenres renders the genres page with the form to add genre
+func (m *Repository) renderAdminGenres(w http.ResponseWriter, r *http.Request, genre models.Genre, form *forms.Form) {
+	allGenres, err := m.DB.AllGenres(r.Context())
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["genres"] = allGenres
+	data["genre"] = genre
+
+	stringMap := make(map[string]string)
+	stringMap["aliases"] = strings.Join(genre.Aliases, ", ")
+
+	render.Template(w, r, "admin-genres.page.html", &models.TemplateData{
+		StringMap: stringMap,
+		Data:      data,
+		Form:      form,
+	})
+}
+
+// Handles the page where admins rename a genre, change its aliases, merge it into another genre or delete it
+func (m *Repository) AdminGenre(w http.ResponseWriter, r *http.Request) {
+	genre, ok := m.adminGenre(w, r)
+	if !ok {
+		return
+	}
+
+	m.renderAdminGenre(w, r, genre, forms.New(nil))
+}
+
+// Handles the posting of the genre form. The artists with the genre show its new name straight away
+func (m *Repository) PostAdminGenre(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	genre, ok := m.adminGenre(w, r)
+	if !ok {
+		return
+	}
+
+	genre, form := genreFromForm(r, genre)
+	if !form.Valid() {
+		m.App.Session.Put(r.Context(), "error", "Invalid form input")
+		m.renderAdminGenre(w, r, genre, form)
+		return
+	}
+
+	err = m.DB.UpdateGenre(r.Context(), genre)
+	if errors.Is(err, repository.ErrGenreTaken) {
+		form.Errors.Add("name", err.Error())
+		m.App.Session.Put(r.Context(), "error", err.Error())
+		m.renderAdminGenre(w, r, genre, form)
+		return
+	} else if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", fmt.Sprintf("%s was saved", genre.Name))
+	http.Redirect(w, r, "/admin/genres", http.StatusSeeOther)
+}
+
+// Handles merging a genre into another one, for spellings that were added as genres of their own. The
+// artists move to the other genre, which keeps the merged name and aliases as its aliases
+func (m *Repository) AdminPostMergeGenre(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	genre, ok := m.adminGenre(w, r)
+	if !ok {
+		return
+	}
+
+	intoID, err := strconv.Atoi(r.Form.Get("into"))
+	if err != nil || intoID == genre.ID {
+		m.App.Session.Put(r.Context(), "error", "Pick another genre to merge into")
+		http.Redirect(w, r, fmt.Sprintf("/admin/genres/%d", genre.ID), http.StatusSeeOther)
+		return
+	}
+
+	into, err := m.DB.GetGenreByID(r.Context(), intoID)
+	if errors.Is(err, sql.ErrNoRows) {
+		m.App.Session.Put(r.Context(), "error", "Pick another genre to merge into")
+		http.Redirect(w, r, fmt.Sprintf("/admin/genres/%d", genre.ID), http.StatusSeeOther)
+		return
+	} else if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	err = m.DB.MergeGenre(r.Context(), genre.ID, into.ID)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", fmt.Sprintf("%s was merged into %s", genre.Name, into.Name))
+	http.Redirect(w, r, fmt.Sprintf("/admin/genres/%d", into.ID), http.StatusSeeOther)
+}
+
+// Handles deleting a genre, which is taken off its artists
+func (m *Repository) AdminPostDeleteGenre(w http.ResponseWriter, r *http.Request) {
+	genre, ok := m.adminGenre(w, r)
+	if !ok {
+		return
+	}
+
+	err := m.DB.DeleteGenre(r.Context(), genre.ID)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", fmt.Sprintf("%s was deleted", genre.Name))
+	http.Redirect(w, r, "/admin/genres", http.StatusSeeOther)
+}
+
+// adminGenre loads the genre in the url, or sends the admin back to the genres when there is none
+func (m *Repository) adminGenre(w http.ResponseWriter, r *http.Request) (models.Genre, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "That genre does not exist")
+		http.Redirect(w, r, "/admin/genres", http.StatusSeeOther)
+		return models.Genre{}, false
+	}
+
+	genre, err := m.DB.GetGenreByID(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		m.App.Session.Put(r.Context(), "error", "That genre does not exist")
+		http.Redirect(w, r, "/admin/genres", http.StatusSeeOther)
+		return models.Genre{}, false
+	} else if err != nil {
+		helpers.ServerError(w, err)
+		return models.Genre{}, false
+	}
+
+	return genre, true
+}
+
+// renderAdminGenre renders the page of a genre with the genres it can be merged into
+func (m *Repository) renderAdminGenre(w http.ResponseWriter, r *http.Request, genre models.Genre, form *forms.Form) {
+	allGenres, err := m.DB.AllGenres(r.Context())
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["genre"] = genre
+	data["genres"] = allGenres
+
+	stringMap := make(map[string]string)
+	stringMap["aliases"] = strings.Join(genre.Aliases, ", ")
+
+	render.Template(w, r, "admin-genre.page.html", &models.TemplateData{
+		StringMap: stringMap,
+		Data:      data,
+		Form:      form,
+	})
+}
+
+// genreFromForm copies the genre form into genre and validates it
+func genreFromForm(r *http.Request, genre models.Genre) (models.Genre, *forms.Form) {
+	genre.Name = strings.Join(strings.Fields(r.Form.Get("name")), " ")
+	genre.Aliases = genres.Aliases(genre.Name, r.Form.Get("aliases"))
+
+	form := forms.New(r.PostForm)
+	form.Required("name")
+	form.MinLength("name", 2, 50)
+	if form.Valid() && genres.Slug(genre.Name) == "" {
+		form.Errors.Add("name", "The name needs a letter or a number")
+	}
+
+	return genre, form
+}
+
+// Handles the all-bookings route. The list can be filtered with the status query parameter
+func (m *Repository) AdminAllBookings(w http.ResponseWriter, r *http.Request) {
+	var bookings []models.Bookings
+	var err error
+
+	filter := r.URL.Query().Get("status")
+	if status.Valid(filter) {
+		bookings, err = m.DB.AllBookingsByStatus(r.Context(), filter)
+	} else {
+		filter = ""
+		bookings, err = m.DB.AllBookings(r.Context())
+	}
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["bookings"] = bookings
+	data["statuses"] = status.All
+
+	stringMap := make(map[string]string)
+	stringMap["status"] = filter
+
+	render.Template(w, r, "admin-all-bookings.page.html", &models.TemplateData{
+		StringMap: stringMap,
+		Data:      data,
+	})
+}
+
+// Handles the new-reservations route
+func (m *Repository) AdminNewBookings(w http.ResponseWriter, r *http.Request) {
+	bookings, err := m.DB.AllNewBookings(r.Context())
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["bookings"] = bookings
+
+	render.Template(w, r, "admin-new-bookings.page.html", &models.TemplateData{
+		Data: data,
+	})
+}
+
+// Handles the single booking route
+func (m *Repository) AdminShowBooking(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	src := chi.URLParam(r, "src")
+
+	stringMap := make(map[string]string)
+	stringMap["src"] = src
+	stringMap["year"] = r.URL.Query().Get("y")
+	stringMap["month"] = r.URL.Query().Get("m")
+
+	booking, err := m.DB.GetBookingByID(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	history, err := m.DB.GetBookingStatusHistory(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["booking"] = booking
+	data["history"] = history
+	data["next_statuses"] = status.Next(booking.Status)
+
+	render.Template(w, r, "admin-single-booking.page.html", &models.TemplateData{
+		StringMap: stringMap,
+		Data:      data,
+		Form:      forms.New(nil),
+	})
+}
+
+// Handles the single booking route for POST
+func (m *Repository) PostAdminShowBooking(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	src := chi.URLParam(r, "src")
+
+	booking, err := m.DB.GetBookingByID(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	booking.FirstName = r.Form.Get("first_name")
+	booking.LastName = r.Form.Get("last_name")
+	booking.Email = r.Form.Get("email")
+	booking.Phone = r.Form.Get("phone")
+	booking.EventLocation = r.Form.Get("event_location")
+	booking.Message = r.Form.Get("message")
+
+	form := forms.New(r.PostForm)
+	form.Required("first_name", "last_name", "email", "phone")
+	form.MinLength("first_name", 3, 30)
+	form.MinLength("last_name", 3, 30)
+	form.IsEmail("email")
+
+	if !form.Valid() {
+		stringMap := make(map[string]string)
+		stringMap["src"] = src
+		stringMap["year"] = r.Form.Get("year")
+		stringMap["month"] = r.Form.Get("month")
+
+		data := make(map[string]interface{})
+		data["booking"] = booking
+		data["next_statuses"] = status.Next(booking.Status)
+		m.App.Session.Put(r.Context(), "error", "Invalid inputs")
+		render.Template(w, r, "admin-single-booking.page.html", &models.TemplateData{
+			StringMap: stringMap,
+			Form:      form,
+			Data:      data,
+		})
+		return
+	}
+
+	err = m.DB.UpdateBooking(r.Context(), booking)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", "Booking Updated Successfully!!!")
+
+	year := r.Form.Get("year")
+	month := r.Form.Get("month")
+
+	if src == "cal" && year != "" {
+		http.Redirect(w, r, fmt.Sprintf("/admin/artists-calendar?y=%s&m=%s", year, month), http.StatusSeeOther)
+	} else {
+		http.Redirect(w, r, fmt.Sprintf("/admin/%s-bookings", src), http.StatusSeeOther)
+	}
+}
+
+// Handles moving a booking to a new status
+func (m *Repository) AdminPostBookingStatus(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	src := chi.URLParam(r, "src")
+	year := r.Form.Get("year")
+	month := r.Form.Get("month")
+
+	redirectURL := fmt.Sprintf("/admin/bookings/%s/%d/show?y=%s&m=%s", src, id, year, month)
+
+	booking, err := m.DB.GetBookingByID(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	newStatus := r.Form.Get("status")
+	err = status.Transition(booking.Status, newStatus)
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+
+	userID := m.App.Session.GetInt(r.Context(), "user_id")
+	err = m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		err := repo.UpdateBookingStatus(r.Context(), id, booking.Status, newStatus, userID)
+		if err != nil {
+			return err
+		}
+
+		return m.enqueueEmails(r.Context(), repo, statusEmail(booking.Email, booking.FirstName, "booking", booking.StartDate, booking.EndDate, newStatus))
+	})
+	if errors.Is(err, status.ErrStaleStatus) {
+		m.App.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", fmt.Sprintf("Booking is now marked as %s", status.Label(newStatus)))
+	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+}
+
+// Handles the artists-calendar route
+func (m *Repository) AdminArtistsCalendar(w http.ResponseWriter, r *http.Request) {
+	data, stringMap, intMap, firstOfMonth := calendarMonthData(r)
+	lastOfMonth := firstOfMonth.AddDate(0, 1, -1)
+
+	artists, err := m.DB.AllArtistsByStatus(r.Context(), "")
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data["artists"] = artists
+
+	for _, x := range artists {
+		// get all the restrictions for the current artist
+		restrictions, err := m.DB.GetRestrictionsForCurrentArtist(r.Context(), x.ID, firstOfMonth, lastOfMonth)
+		if err != nil {
+			helpers.ServerError(w, err)
+			return
+		}
+
+		var entries []calendar.Entry
+		blockMap := make(map[string]int)
+
+		for _, y := range restrictions {
+			if y.BookingID > 0 {
+				// it's a booking
+				entries = append(entries, calendar.Entry{ID: y.BookingID, Kind: calendar.KindBooking, Start: y.StartDate, End: y.EndDate})
+			} else {
+				// it's a block
+				entries = append(entries, calendar.Entry{ID: y.ID, Kind: calendar.KindBlock, Label: y.Reason, Start: y.StartDate, End: y.EndDate})
+				blockMap[strconv.Itoa(y.ID)] = y.ID
+			}
+		}
+
+		data[fmt.Sprintf("cells_%d", x.ID)] = calendar.MonthCells(firstOfMonth, entries)
+
+		m.App.Session.Put(r.Context(), fmt.Sprintf("artist_block_map_%d", x.ID), blockMap)
+	}
+
+	render.Template(w, r, "admin-artists-calendar.page.html", &models.TemplateData{
+		StringMap: stringMap,
+		Data:      data,
+		IntMap:    intMap,
+	})
+}
+
+// Handles the artists calendar POST route
+func (m *Repository) AdminPostArtistsCalendar(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	year, _ := strconv.Atoi(r.Form.Get("year"))
+	month, _ := strconv.Atoi(r.Form.Get("month"))
+
+	//Process changes
+	artists, err := m.DB.AllArtistsByStatus(r.Context(), "")
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	form := forms.New(r.PostForm)
+	changed, failed := false, 0
+
+	for _, x := range artists {
+		// Get the block map from the session. Any block in the map that was unchecked in the posted data
+		// is a block we need to remove.
+		curMap, ok := m.App.Session.Get(r.Context(), fmt.Sprintf("artist_block_map_%d", x.ID)).(map[string]int)
+		if !ok {
+			continue
+		}
+
+		for name, value := range curMap {
+			if value > 0 && !form.Has(fmt.Sprintf("remove_block_%d_%s", x.ID, name)) {
+				err := m.DB.DeleteArtistBlockByID(r.Context(), value)
+				if err != nil {
+					log.Println(err)
+					failed++
+					continue
+				}
+				changed = true
+			}
+		}
+	}
+
+	// now handle new single day blocks, named add_block_{artist id}_{date}
+	for name := range r.PostForm {
+		if !strings.HasPrefix(name, "add_block_") {
+			continue
+		}
+
+		exploded := strings.Split(name, "_")
+		if len(exploded) != 4 {
+			continue
+		}
+
+		artistID, err := strconv.Atoi(exploded[2])
+		if err != nil || artistID < 1 {
+			continue
+		}
+
+		t, err := time.Parse("2006-01-2", exploded[3])
+		if err != nil {
+			continue
+		}
+
+		// insert a new block
+		err = m.DB.InsertBlockForArtist(r.Context(), artistID, t, t, "")
+		if err != nil {
+			log.Println(err)
+			failed++
+			continue
+		}
+		changed = true
+	}
+
+	if failed > 0 {
+		m.App.Session.Put(r.Context(), "error", fmt.Sprintf("%d change(s) to the calendar couldn't be saved. Please, try again", failed))
+	} else if changed {
+		m.App.Session.Put(r.Context(), "flash", "Artist Calendar Updated")
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/artists-calendar?y=%d&m=%d", year, month), http.StatusSeeOther)
+}
+
+// Handles the date range block form on the artists calendar
+func (m *Repository) AdminPostArtistBlocks(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	redirectURL := fmt.Sprintf("/admin/artists-calendar?y=%s&m=%s", r.Form.Get("year"), r.Form.Get("month"))
+
+	artistID, err := strconv.Atoi(r.Form.Get("artist_id"))
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "Invalid artist selected")
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+
+	ranges, reason, err := blockRangesFromForm(r)
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+
+	added, skipped, err := m.addArtistBlocks(r.Context(), artistID, ranges, reason)
+	if errors.Is(err, repository.ErrUnavailable) {
+		m.App.Session.Put(r.Context(), "error", "Some of those dates were just booked, no blocks were added. Please, try again")
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", blockSummary(added, skipped))
+	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+}
+
+// addArtistBlocks blocks every date range for an artist, or none of them. Ranges that already have a
+// booking or block are skipped and counted
+func (m *Repository) addArtistBlocks(ctx context.Context, artistID int, ranges []calendar.DateRange, reason string) (int, int, error) {
+	added, skipped := 0, 0
+	err := m.DB.WithTx(ctx, func(repo repository.DatabaseRepo) error {
+		for _, dates := range ranges {
+			available, err := repo.SearchAvailabilityByDatesByArtistID(ctx, dates.Start, dates.End, artistID)
+			if err != nil {
+				return err
+			}
+
+			if !available {
+				skipped++
+				continue
+			}
+
+			err = repo.InsertBlockForArtist(ctx, artistID, dates.Start, dates.End, reason)
+			if err != nil {
+				return err
+			}
+			added++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return added, skipped, nil
+}
+
+// calendarMonthData works out the month shown on an admin calendar from the y and m query parameters
+// and returns the template maps shared by the calendars along with the first day of that month
+func calendarMonthData(r *http.Request) (map[string]interface{}, map[string]string, map[string]int, time.Time) {
+	now := time.Now()
+
+	if r.URL.Query().Get("y") != "" {
+		month, _ := strconv.Atoi(r.URL.Query().Get("m"))
+		year, _ := strconv.Atoi(r.URL.Query().Get("y"))
+
+		now = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	data := make(map[string]interface{})
+	data["now"] = now
 
 	// get next and previous months/year
 	nextMonth := now.AddDate(0, 1, 0)
 	previousMonth := now.AddDate(0, -1, 0)
 
-	stringMap := make(map[string]string)
-	stringMap["this_month"] = now.Format("01")
-	stringMap["this_year"] = now.Format("2006")
-	stringMap["next_month"] = nextMonth.Format("01")
-	stringMap["next_year"] = nextMonth.Format("2006")
-	stringMap["previous_month"] = previousMonth.Format("01")
-	stringMap["previous_year"] = previousMonth.Format("2006")
+	stringMap := make(map[string]string)
+	stringMap["this_month"] = now.Format("01")
+	stringMap["this_year"] = now.Format("2006")
+	stringMap["next_month"] = nextMonth.Format("01")
+	stringMap["next_year"] = nextMonth.Format("2006")
+	stringMap["previous_month"] = previousMonth.Format("01")
+	stringMap["previous_year"] = previousMonth.Format("2006")
+
+	// Get the first and last day of the month
+	currentYear, currentMonth, _ := now.Date()
+	firstOfMonth := time.Date(currentYear, currentMonth, 1, 0, 0, 0, 0, time.UTC)
+	lastOfMonth := firstOfMonth.AddDate(0, 1, -1)
+
+	intMap := make(map[string]int)
+	intMap["days_in_month"] = lastOfMonth.Day()
+
+	return data, stringMap, intMap, firstOfMonth
+}
+
+// blockRangesFromForm reads the block range form and expands any repeat rule into the date ranges to block
+func blockRangesFromForm(r *http.Request) ([]calendar.DateRange, string, error) {
+	startDate, err := time.Parse("2006-01-02", r.Form.Get("block_start"))
+	if err != nil {
+		return nil, "", errors.New("Invalid block start date")
+	}
+
+	endDate := startDate
+	if r.Form.Get("block_end") != "" {
+		endDate, err = time.Parse("2006-01-02", r.Form.Get("block_end"))
+		if err != nil {
+			return nil, "", errors.New("Invalid block end date")
+		}
+	}
+
+	repeat := r.Form.Get("repeat")
+
+	var until time.Time
+	if repeat != "" && repeat != calendar.RepeatNone {
+		until, err = time.Parse("2006-01-02", r.Form.Get("repeat_until"))
+		if err != nil {
+			return nil, "", errors.New("Please, choose when the repeating block should end")
+		}
+	}
+
+	ranges, err := calendar.Expand(startDate, endDate, repeat, until)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return ranges, strings.TrimSpace(r.Form.Get("reason")), nil
+}
+
+// blockSummary builds the flash message shown after blocks have been added
+func blockSummary(added, skipped int) string {
+	message := fmt.Sprintf("%d block(s) added", added)
+	if skipped > 0 {
+		message += fmt.Sprintf(", %d skipped because the dates are already booked or blocked", skipped)
+	}
+	return message
+}
+
+// Handles the all-artists route
+func (m *Repository) AdminAllOptions(w http.ResponseWriter, r *http.Request) {
+	options, err := m.DB.AllBookingOptions(r.Context())
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["options"] = options
+
+	render.Template(w, r, "admin-all-options.page.html", &models.TemplateData{
+		Data: data,
+	})
+}
+
+// Handles the new-artist route to create a new artist
+func (m *Repository) AdminNewOption(w http.ResponseWriter, r *http.Request) {
+	artists, err := m.DB.AllArtistsByStatus(r.Context(), "")
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["artists"] = artists
+
+	render.Template(w, r, "admin-new-option.page.html", &models.TemplateData{
+		Form: forms.New(nil),
+		Data: data,
+	})
+}
+
+// This function POST the new artist form and store them in the database
+func (m *Repository) PostAdminNewOption(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	var option models.BookingOptions
+
+	option.Title = r.Form.Get("title")
+	option.Price = r.Form.Get("price")
+	option.Description = r.Form.Get("description")
+	artistIDStr := r.Form.Get("artist_id")
+	artistID, err := strconv.Atoi(artistIDStr)
+	if err != nil {
+		log.Printf("Error converting artist_id to integer: %v", err)
+	} else {
+		option.ArtistID = artistID
+	}
+
+	// Form validations
+	form := forms.New(r.PostForm)
+	form.Required("title", "price", "description", "artist_id")
+	form.MinLength("title", 5, 50)
+	form.MinLength("description", 5, 250)
+
+	if !form.Valid() {
+		data := make(map[string]interface{})
+		data["option"] = option
+		m.App.Session.Put(r.Context(), "error", "Invalid form input")
+		render.Template(w, r, "admin-new-option.page.html", &models.TemplateData{
+			Form: form,
+			Data: data,
+		})
+		return
+	}
+
+	// Insert new artist here
+	err = m.DB.CreateBookingOption(r.Context(), option)
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "Can't insert new booking option into database")
+		helpers.ServerError(w, err)
+		http.Redirect(w, r, "/admin/booking-options/new-option", http.StatusTemporaryRedirect)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", "Booking Option Created Successfully!!!")
+	http.Redirect(w, r, "/admin/booking-options/new-option", http.StatusSeeOther)
+}
+
+func (m *Repository) AdminSingleOption(w http.ResponseWriter, r *http.Request) {
+	urlParams := strings.Split(r.RequestURI, "/")
+	id, err := strconv.Atoi(urlParams[3])
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	artists, err := m.DB.AllArtistsByStatus(r.Context(), "")
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	option, err := m.DB.GetBookingOptionByID(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["option"] = option
+	data["artists"] = artists
+
+	render.Template(w, r, "admin-single-option.page.html", &models.TemplateData{
+		Data: data,
+		Form: forms.New(nil),
+	})
+}
+
+// Handles the single-option route for POST
+func (m *Repository) PostAdminSingleOption(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	urlParams := strings.Split(r.RequestURI, "/")
+	id, err := strconv.Atoi(urlParams[3])
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	artists, err := m.DB.AllArtistsByStatus(r.Context(), "")
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	option, err := m.DB.GetBookingOptionByID(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["artists"] = artists
+	data["option"] = option
+
+	option.Title = r.Form.Get("title")
+	option.Price = r.Form.Get("price")
+	option.Description = r.Form.Get("description")
+	artistIDStr := r.Form.Get("artist_id")
+	artistID, err := strconv.Atoi(artistIDStr)
+	if err != nil {
+		log.Printf("Error converting artist_id to integer: %v", err)
+		m.App.Session.Put(r.Context(), "error", "Invalid Artist selected")
+		http.Redirect(w, r, "/admin/booking-options", http.StatusSeeOther)
+	} else {
+		option.ArtistID = artistID
+	}
+
+	// Form validations
+	form := forms.New(r.PostForm)
+	form.Required("title", "price", "description", "artist_id")
+	form.MinLength("title", 5, 50)
+	form.MinLength("description", 5, 250)
+
+	if !form.Valid() {
+		m.App.Session.Put(r.Context(), "error", "Invalid inputs")
+		render.Template(w, r, "admin-single-option.page.html", &models.TemplateData{
+			Form: form,
+			Data: data,
+		})
+
+		return
+	}
+
+	err = m.DB.UpdateBookingOption(r.Context(), option)
+	if err != nil {
+		helpers.ServerError(w, err)
+		http.Redirect(w, r, "/admin/booking-options", http.StatusSeeOther)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", "Booking Option Updated Successsfully!!!")
+	http.Redirect(w, r, "/admin/booking-options", http.StatusSeeOther)
+}
+
+// Handles the outbox page, which lists queued, sent and dead emails
+func (m *Repository) AdminOutbox(w http.ResponseWriter, r *http.Request) {
+	filter := r.URL.Query().Get("status")
+	if !slices.Contains(outbox.All, filter) {
+		filter = ""
+	}
+
+	emails, err := m.DB.AllOutboxEmails(r.Context(), filter)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["emails"] = emails
+	data["statuses"] = outbox.All
+
+	stringMap := make(map[string]string)
+	stringMap["status"] = filter
+
+	render.Template(w, r, "admin-outbox.page.html", &models.TemplateData{
+		StringMap: stringMap,
+		Data:      data,
+	})
+}
+
+// Handles putting an email that was not sent back in the outbox
+func (m *Repository) AdminPostResendEmail(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
 
-	// Get the first and last day of the month
-	currentYear, currentMonth, _ := now.Date()
-	currentLocation := now.Location()
-	firstOfMonth := time.Date(currentYear, currentMonth, 1, 0, 0, 0, 0, currentLocation)
-	lastOfMonth := firstOfMonth.AddDate(0, 1, -1)
+	err = m.DB.ResendEmail(r.Context(), id)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
 
-	intMap := make(map[string]int)
-	intMap["days_in_month"] = lastOfMonth.Day()
+	m.App.Session.Put(r.Context(), "flash", fmt.Sprintf("Email #%d will be sent again shortly", id))
+	http.Redirect(w, r, "/admin/outbox", http.StatusSeeOther)
+}
+
+// settingsField is one admin recipients field of the settings page
+type settingsField struct {
+	Key   string
+	Label string
+}
+
+// settingsFields returns the admin recipients fields, one per notification event
+func settingsFields() []settingsField {
+	var fields []settingsField
+	for _, event := range settings.Events {
+		fields = append(fields, settingsField{Key: settings.RecipientsKey(event), Label: settings.Label(event)})
+	}
+	return fields
+}
+
+// Handles the settings page
+func (m *Repository) AdminSettings(w http.ResponseWriter, r *http.Request) {
+	data := make(map[string]interface{})
+	data["recipients"] = settingsFields()
+
+	render.Template(w, r, "admin-settings.page.html", &models.TemplateData{
+		StringMap: m.App.Settings.Get().Values(),
+		Data:      data,
+		Form:      forms.New(nil),
+	})
+}
 
-	rooms, err := m.DB.AllRooms()
+// Handles saving the settings page. The new settings are used straight away
+func (m *Repository) AdminPostSettings(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	data["rooms"] = rooms
+	values := map[string]string{
+		settings.KeySenderName:  strings.TrimSpace(r.Form.Get(settings.KeySenderName)),
+		settings.KeySenderEmail: strings.TrimSpace(r.Form.Get(settings.KeySenderEmail)),
+		settings.KeyReplyTo:     strings.TrimSpace(r.Form.Get(settings.KeyReplyTo)),
+	}
+	for _, field := range settingsFields() {
+		values[field.Key] = strings.Join(settings.SplitAddresses(r.Form.Get(field.Key)), ", ")
+	}
 
-	for _, x := range rooms {
-		// get the reservation and block maps
-		reservationMap := make(map[string]int)
-		blockMap := make(map[string]int)
+	newSettings := settings.FromValues(values)
 
-		for d := firstOfMonth; d.After(lastOfMonth) == false; d = d.AddDate(0, 0, 1) {
-			reservationMap[d.Format("2006-01-2")] = 0
-			blockMap[d.Format("2006-01-2")] = 0
-		}
+	form := forms.New(r.PostForm)
+	form.Required(settings.KeySenderName, settings.KeySenderEmail)
+	for key, problem := range newSettings.Validate() {
+		form.Errors.Add(key, problem)
+	}
 
-		// get all the restrictions for the current room
-		restrictions, err := m.DB.GetRestrictionsForCurrentRoom(x.ID, firstOfMonth, lastOfMonth)
+	if !form.Valid() {
+		data := make(map[string]interface{})
+		data["recipients"] = settingsFields()
+
+		m.App.Session.Put(r.Context(), "error", "Invalid form input")
+		render.Template(w, r, "admin-settings.page.html", &models.TemplateData{
+			StringMap: values,
+			Data:      data,
+			Form:      form,
+		})
+		return
+	}
+
+	err = m.DB.UpdateSettings(r.Context(), newSettings.Values())
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Settings.Set(newSettings)
+
+	m.App.Session.Put(r.Context(), "flash", "Settings saved")
+	http.Redirect(w, r, "/admin/settings", http.StatusSeeOther)
+}
+
+// Handles the list of email templates
+func (m *Repository) AdminEmails(w http.ResponseWriter, r *http.Request) {
+	var previews []models.MailData
+
+	for _, sample := range emails.Samples() {
+		preview, err := m.renderEmail("", sample)
 		if err != nil {
 			helpers.ServerError(w, err)
 			return
 		}
+		previews = append(previews, preview)
+	}
 
-		for _, y := range restrictions {
-			if y.ReservationID > 0 {
-				// it's a reservation
-				for d := y.StartDate; d.After(y.EndDate) == false; d = d.AddDate(0, 0, 1) {
-					reservationMap[d.Format("2006-01-2")] = y.ReservationID
-				}
-			} else {
-				// it's a block
-				blockMap[y.StartDate.Format("2006-01-2")] = y.ID
-			}
-		}
-		data[fmt.Sprintf("reservation_map_%d", x.ID)] = reservationMap
-		data[fmt.Sprintf("block_map_%d", x.ID)] = blockMap
+	data := make(map[string]interface{})
+	data["emails"] = previews
 
-		m.App.Session.Put(r.Context(), fmt.Sprintf("block_map_%d", x.ID), blockMap)
+	render.Template(w, r, "admin-emails.page.html", &models.TemplateData{
+		Data: data,
+	})
+}
+
+// Handles showing one email template rendered with sample data
+func (m *Repository) AdminEmailPreview(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	sample, ok := emails.Sample(name)
+	if !ok {
+		m.App.Session.Put(r.Context(), "error", "That email template does not exist")
+		http.Redirect(w, r, "/admin/emails", http.StatusSeeOther)
+		return
 	}
 
-	render.Template(w, r, "admin-reservations-calendar.page.html", &models.TemplateData{
-		StringMap: stringMap,
-		Data:      data,
-		IntMap:    intMap,
+	preview, err := m.renderEmail("", sample)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["email"] = preview
+
+	render.Template(w, r, "admin-email-preview.page.html", &models.TemplateData{
+		Data: data,
 	})
 }
 
-// Handles the reservation calendar POST route
-func (m *Repository) AdminPostReservationsCalendar(w http.ResponseWriter, r *http.Request) {
-	err := r.ParseForm()
+// Handles sending a test copy of an email template to the logged in admin
+func (m *Repository) AdminPostTestEmail(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	redirectURL := fmt.Sprintf("/admin/emails/%s", name)
+
+	sample, ok := emails.Sample(name)
+	if !ok {
+		m.App.Session.Put(r.Context(), "error", "That email template does not exist")
+		http.Redirect(w, r, "/admin/emails", http.StatusSeeOther)
+		return
+	}
+
+	user, err := m.DB.GetUserByID(r.Context(), m.App.Session.GetInt(r.Context(), "user_id"))
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	year, _ := strconv.Atoi(r.Form.Get("year"))
-	month, _ := strconv.Atoi(r.Form.Get("month"))
+	if user.Email == "" {
+		m.App.Session.Put(r.Context(), "error", "Your account has no email address to send the test to")
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
 
-	//Process changes
-	rooms, err := m.DB.AllRooms()
+	message, err := m.renderEmail(user.Email, sample)
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
+	message.Subject = "[Test] " + message.Subject
 
-	form := forms.New(r.PostForm)
+	// test copies skip the outbox, so the admin sees straight away if the mailer works
+	err = m.App.Mailer.Send(r.Context(), message)
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", fmt.Sprintf("Unable to send the test email: %s", err))
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
 
-	for _, x := range rooms {
-		// Get the block map from the session. Loop through entire map, if we have an entry in the map
-		// that does not exist in our posted data, and if the restriction id > 0, then it is a block we need to
-		// remove.
-		curMap := m.App.Session.Get(r.Context(), fmt.Sprintf("block_map_%d", x.ID)).(map[string]int)
-		for name, value := range curMap {
-			// ok will be false if the value is not in the map
-			if val, ok := curMap[name]; ok {
-				// only pay attention to values > 0, and that are not in the form post
-				// the rest are just placeholders for days without blocks
-				if val > 0 {
-					if !form.Has(fmt.Sprintf("remove_block_%d_%s", x.ID, name)) {
-						// delete the restriction by id
-						err := m.DB.DeleteBlockByID(value)
-						if err != nil {
-							log.Println(err)
-						}
-						m.App.Session.Put(r.Context(), "flash", "Block removed successfully")
-					}
-				}
-			}
-		}
+	m.App.Session.Put(r.Context(), "flash", fmt.Sprintf("A test copy was sent to %s", user.Email))
+	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+}
+
+// usersPerPage is how many users the admin users page lists at once
+const usersPerPage = 25
+
+// lockHours are the lengths of time, in hours, an admin can lock an account for
+var lockHours = []int{1, 24, 168}
+
+// Handles the admin users page, a page of users whose name or email contains the search
+func (m *Repository) AdminUsers(w http.ResponseWriter, r *http.Request) {
+	search := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
 	}
 
-	// now handle new blocks
-	for name := range r.PostForm {
-		if strings.HasPrefix(name, "add_block") {
-			exploded := strings.Split(name, "_")
-			roomID, _ := strconv.Atoi(exploded[2])
-			t, _ := time.Parse("2006-01-02", exploded[3])
-			// insert a new block
-			err := m.DB.InsertBlockForRoom(roomID, t)
-			if err != nil {
-				log.Println(err)
-			}
-			m.App.Session.Put(r.Context(), "flash", "Reservation Block Updated")
-		}
+	users, total, err := m.DB.AllUsers(r.Context(), search, usersPerPage, (page-1)*usersPerPage)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
 	}
 
-	http.Redirect(w, r, fmt.Sprintf("/admin/reservations-calendar?y=%d&m=%d", year, month), http.StatusSeeOther)
+	data := make(map[string]interface{})
+	data["users"] = users
+	data["now"] = time.Now()
+
+	intMap := make(map[string]int)
+	intMap["page"] = page
+	intMap["pages"] = max(1, (total+usersPerPage-1)/usersPerPage)
+	intMap["total"] = total
+
+	stringMap := make(map[string]string)
+	stringMap["q"] = search
+
+	render.Template(w, r, "admin-users.page.html", &models.TemplateData{
+		StringMap: stringMap,
+		IntMap:    intMap,
+		Data:      data,
+	})
 }
 
-// Handles the all-rooms route
-func (m *Repository) AdminAllRooms(w http.ResponseWriter, r *http.Request) {
-	rooms, err := m.DB.AllRooms()
+// Handles the admin page of a single user, with their bookings and artist profiles
+func (m *Repository) AdminShowUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := m.adminUser(w, r)
+	if !ok {
+		return
+	}
+
+	// only what is linked to the account, guest bookings with the same email count once the user claims them
+	bookings, err := m.DB.AllBookingsByUserID(r.Context(), user.ID)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	reservations, err := m.DB.AllReservationsByUserID(r.Context(), user.ID)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	artists, err := m.DB.AllArtistsByUserID(r.Context(), user.ID)
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
 	data := make(map[string]interface{})
-	data["rooms"] = rooms
+	data["user"] = user
+	data["bookings"] = bookings
+	data["reservations"] = reservations
+	data["artists"] = artists
+	data["roles"] = roles.All
+	data["lock_hours"] = lockHours
+	data["now"] = time.Now()
 
-	render.Template(w, r, "admin-all-rooms.page.html", &models.TemplateData{
-		Data: data,
+	intMap := make(map[string]int)
+	intMap["self"] = m.App.Session.GetInt(r.Context(), "user_id")
+
+	render.Template(w, r, "admin-single-user.page.html", &models.TemplateData{
+		IntMap: intMap,
+		Data:   data,
+		Form:   forms.New(nil),
 	})
 }
 
-// Handles the single-room route
-func (m *Repository) AdminSingleRoom(w http.ResponseWriter, r *http.Request) {
-	urlParams := strings.Split(r.RequestURI, "/")
-	id, err := strconv.Atoi(urlParams[3])
+// Handles changing the role of a user. They are logged out, so the new role applies from their next login
+func (m *Repository) AdminPostUserRole(w http.ResponseWriter, r *http.Request) {
+	user, ok := m.adminUserToChange(w, r)
+	if !ok {
+		return
+	}
+
+	role := r.Form.Get("role")
+	if !roles.Valid(role) {
+		m.App.Session.Put(r.Context(), "error", "Choose one of the listed roles")
+		http.Redirect(w, r, fmt.Sprintf("/admin/users/%d", user.ID), http.StatusSeeOther)
+		return
+	}
+
+	err := m.DB.UpdateUserRole(r.Context(), user.ID, role)
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	room, err := m.DB.GetRoomByID(id)
+	err = m.logoutUser(r.Context(), user.ID)
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	data := make(map[string]interface{})
-	data["room"] = room
+	log.Printf("User #%d changed the role of user #%d from %s to %s", m.App.Session.GetInt(r.Context(), "user_id"), user.ID, user.Role, role)
 
-	render.Template(w, r, "admin-single-room.page.html", &models.TemplateData{
-		Data: data,
-		Form: forms.New(nil),
+	m.App.Session.Put(r.Context(), "flash", fmt.Sprintf("%s %s is now %s", user.FirstName, user.LastName, roles.Label(role)))
+	http.Redirect(w, r, fmt.Sprintf("/admin/users/%d", user.ID), http.StatusSeeOther)
+}
+
+// Handles sending a user a new verification link
+func (m *Repository) AdminPostUserVerification(w http.ResponseWriter, r *http.Request) {
+	user, ok := m.adminUser(w, r)
+	if !ok {
+		return
+	}
+
+	if user.Verified {
+		m.App.Session.Put(r.Context(), "warning", "This user has already verified their email")
+		http.Redirect(w, r, fmt.Sprintf("/admin/users/%d", user.ID), http.StatusSeeOther)
+		return
+	}
+
+	// Load the env file and get the frontendURL
+	err := godotenv.Load()
+	if err != nil {
+		log.Println("Error loading .env file")
+	}
+	frontendURL := os.Getenv("FRONTEND_URL")
+
+	err = m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		return m.queueVerification(r.Context(), repo, user, frontendURL)
 	})
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", fmt.Sprintf("A new verification link was sent to %s", user.Email))
+	http.Redirect(w, r, fmt.Sprintf("/admin/users/%d", user.ID), http.StatusSeeOther)
 }
 
-// Handles the single-room route for POST
-func (m *Repository) PostAdminSingleRoom(w http.ResponseWriter, r *http.Request) {
-	err := r.ParseForm()
+// Handles forcing a user to choose a new password. Their current password stops working, they are logged
+// out everywhere and they are emailed a reset link
+func (m *Repository) AdminPostUserPasswordReset(w http.ResponseWriter, r *http.Request) {
+	user, ok := m.adminUserToChange(w, r)
+	if !ok {
+		return
+	}
+
+	// nobody knows the random password, so the reset link is the only way back in
+	password, _, err := helpers.GenerateToken()
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	urlParams := strings.Split(r.RequestURI, "/")
-	id, err := strconv.Atoi(urlParams[3])
+	// Load the env file and get the frontendURL
+	err = godotenv.Load()
+	if err != nil {
+		log.Println("Error loading .env file")
+	}
+	frontendURL := os.Getenv("FRONTEND_URL")
+
+	err = m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		err := repo.UpdatePassword(r.Context(), user.ID, password)
+		if err != nil {
+			return err
+		}
+
+		return m.queuePasswordReset(r.Context(), repo, user, frontendURL)
+	})
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	room, err := m.DB.GetRoomByID(id)
+	err = m.logoutUser(r.Context(), user.ID)
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	room.RoomName = r.Form.Get("room_name")
-	room.Price = r.Form.Get("price")
-	room.ImageSource = r.Form.Get("image_src")
-	room.Description = r.Form.Get("description")
+	log.Printf("User #%d forced a password reset for user #%d", m.App.Session.GetInt(r.Context(), "user_id"), user.ID)
 
-	form := forms.New(r.PostForm)
-	form.Required("room_name", "price", "image_src", "description")
-	form.MinLength("room_name", 5, 30)
-	form.MinLength("description", 5, 20000)
+	m.App.Session.Put(r.Context(), "flash", fmt.Sprintf("%s has been logged out and sent a link to choose a new password", user.Email))
+	http.Redirect(w, r, fmt.Sprintf("/admin/users/%d", user.ID), http.StatusSeeOther)
+}
 
-	if !form.Valid() {
-		data := make(map[string]interface{})
-		data["room"] = room
-		m.App.Session.Put(r.Context(), "error", "Invalid inputs")
-		render.Template(w, r, "admin-single-room.page.html", &models.TemplateData{
-			Form: form,
-			Data: data,
-		})
+// Handles disabling or enabling a user's account. Disabled users are logged out and can't log in
+func (m *Repository) AdminPostUserDisabled(w http.ResponseWriter, r *http.Request) {
+	user, ok := m.adminUserToChange(w, r)
+	if !ok {
+		return
+	}
+
+	disabled := r.Form.Get("disabled") == "true"
+
+	err := m.DB.SetUserDisabled(r.Context(), user.ID, disabled)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	message := fmt.Sprintf("The account of %s has been enabled", user.Email)
+	if disabled {
+		err = m.logoutUser(r.Context(), user.ID)
+		if err != nil {
+			helpers.ServerError(w, err)
+			return
+		}
+		message = fmt.Sprintf("The account of %s has been disabled", user.Email)
+	}
+
+	log.Printf("User #%d set disabled to %v for user #%d", m.App.Session.GetInt(r.Context(), "user_id"), disabled, user.ID)
+
+	m.App.Session.Put(r.Context(), "flash", message)
+	http.Redirect(w, r, fmt.Sprintf("/admin/users/%d", user.ID), http.StatusSeeOther)
+}
 
+// Handles locking a user's account for one of the lockHours, or unlocking it when hours is 0. Locked
+// users are logged out and can't log in until the lock ends
+func (m *Repository) AdminPostUserLock(w http.ResponseWriter, r *http.Request) {
+	user, ok := m.adminUserToChange(w, r)
+	if !ok {
 		return
 	}
 
-	err = m.DB.UpdateRoom(room)
-	if err != nil {
-		helpers.ServerError(w, err)
+	hours, err := strconv.Atoi(r.Form.Get("hours"))
+	if err != nil || (hours != 0 && !slices.Contains(lockHours, hours)) {
+		m.App.Session.Put(r.Context(), "error", "Choose how long to lock the account for")
+		http.Redirect(w, r, fmt.Sprintf("/admin/users/%d", user.ID), http.StatusSeeOther)
 		return
 	}
 
-	m.App.Session.Put(r.Context(), "flash", "Reservation Updated")
-	http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
-}
-
-// Handles the new-room route to create a new room
-func (m *Repository) AdminNewRoom(w http.ResponseWriter, r *http.Request) {
-	render.Template(w, r, "admin-new-room.page.html", &models.TemplateData{
-		Form: forms.New(nil),
-	})
-}
+	var until time.Time
+	if hours > 0 {
+		until = time.Now().Add(time.Duration(hours) * time.Hour)
+	}
 
-// This function POST the new room form and store them in the database
-func (m *Repository) PostAdminNewRoom(w http.ResponseWriter, r *http.Request) {
-	err := r.ParseForm()
+	err = m.DB.LockUser(r.Context(), user.ID, until)
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	var room models.Room
+	message := fmt.Sprintf("The account of %s has been unlocked", user.Email)
+	if hours > 0 {
+		err = m.logoutUser(r.Context(), user.ID)
+		if err != nil {
+			helpers.ServerError(w, err)
+			return
+		}
+		message = fmt.Sprintf("The account of %s is locked until %s", user.Email, until.Format("2006-01-02 15:04"))
+	}
 
-	room.RoomName = r.Form.Get("room_name")
-	room.Price = r.Form.Get("price")
-	room.ImageSource = r.Form.Get("image_src")
-	room.Description = r.Form.Get("description")
+	log.Printf("User #%d set the lock of user #%d to %d hours", m.App.Session.GetInt(r.Context(), "user_id"), user.ID, hours)
 
-	// Form validations
-	form := forms.New(r.PostForm)
-	form.Required("room_name", "price", "image_src", "description")
-	form.MinLength("room_name", 5, 30)
-	form.MinLength("description", 5, 20000)
+	m.App.Session.Put(r.Context(), "flash", message)
+	http.Redirect(w, r, fmt.Sprintf("/admin/users/%d", user.ID), http.StatusSeeOther)
+}
 
-	if !form.Valid() {
-		data := make(map[string]interface{})
-		data["room"] = room
-		m.App.Session.Put(r.Context(), "error", "Invalid form input")
-		render.Template(w, r, "admin-new-room.page.html", &models.TemplateData{
-			Form: form,
-			Data: data,
-		})
-		return
+// adminUser loads the user in the url. When there is none it redirects to the users page and returns false
+func (m *Repository) adminUser(w http.ResponseWriter, r *http.Request) (models.User, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "That user does not exist")
+		http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+		return models.User{}, false
 	}
 
-	// Insert new room here
-	err = m.DB.InsertRoom(room)
-	if err != nil {
-		m.App.Session.Put(r.Context(), "error", "Can't insert into database")
+	user, err := m.DB.GetUserByID(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		m.App.Session.Put(r.Context(), "error", "That user does not exist")
+		http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+		return models.User{}, false
+	} else if err != nil {
 		helpers.ServerError(w, err)
-		http.Redirect(w, r, "/admin/rooms", http.StatusTemporaryRedirect)
-		return
+		return models.User{}, false
 	}
 
-	m.App.Session.Put(r.Context(), "flash", "Room Created Successfully!!!")
-	http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+	return user, true
 }
 
-func (m *Repository) AdminDeleteRoom(w http.ResponseWriter, r *http.Request) {
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
-
-	err := m.DB.DeleteRoom(id)
+// adminUserToChange parses the form and loads the user in the url like adminUser, and also stops admins
+// changing their own role or access, so they can't lock themselves out
+func (m *Repository) adminUserToChange(w http.ResponseWriter, r *http.Request) (models.User, bool) {
+	err := r.ParseForm()
 	if err != nil {
 		helpers.ServerError(w, err)
-		return
+		return models.User{}, false
 	}
 
-	m.App.Session.Put(r.Context(), "flash", "<strong>Successful!!!</strong><br><br> <p>Room Deleted</p>")
-	http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
-}
+	user, ok := m.adminUser(w, r)
+	if !ok {
+		return user, false
+	}
 
-// Handles the admin todo list route
-func (m *Repository) AdminTodoList(w http.ResponseWriter, r *http.Request) {
-	userID := m.App.Session.GetInt(r.Context(), "user_id")
+	if user.ID == m.App.Session.GetInt(r.Context(), "user_id") {
+		m.App.Session.Put(r.Context(), "error", "You can't change your own account from here")
+		http.Redirect(w, r, fmt.Sprintf("/admin/users/%d", user.ID), http.StatusSeeOther)
+		return user, false
+	}
+
+	return user, true
+}
 
-	todoList, err := m.DB.GetTodoListByUserID(userID)
+// This function handles the ListService page and renders the template
+func (m *Repository) ListService(w http.ResponseWriter, r *http.Request) {
+	allGenres, err := m.DB.AllGenres(r.Context())
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
 	data := make(map[string]interface{})
-	data["todo_list"] = todoList
+	data["genres"] = allGenres
 
-	render.Template(w, r, "admin-todo.page.html", &models.TemplateData{
+	render.Template(w, r, "list-service.page.html", &models.TemplateData{
 		Form: forms.New(nil),
 		Data: data,
 	})
 }
 
-// Handles the Post for todo list route
-func (m *Repository) PostAdminTodoList(w http.ResponseWriter, r *http.Request) {
-	userID := m.App.Session.GetInt(r.Context(), "user_id")
-
-	var todoList models.TodoList
-
+// This function handles the posting of ListService form. The listing is owned by the logged in user and
+// stays pending until an admin reviews it, or is kept as a draft when the user saves it for later
+func (m *Repository) PostListService(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	todoList.Todo = r.Form.Get("todo_list")
-	todoList.UserID = userID
+	draft := r.Form.Get("draft") != ""
 
-	form := forms.New(r.PostForm)
-	form.Required("todo_list")
-	form.MinLength("todo_list", 5, 255)
+	allGenres, err := m.DB.AllGenres(r.Context())
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	artist, form := artistFromForm(r, models.Artist{
+		UserID: m.App.Session.GetInt(r.Context(), "user_id"),
+		Status: listing.Pending,
+	}, allGenres)
+	if draft {
+		artist.Status = listing.Draft
+	}
 
 	if !form.Valid() {
 		data := make(map[string]interface{})
-		data["todo_list"] = todoList
-		m.App.Session.Put(r.Context(), "error", "Invalid form input. <br> must be less than 250 and more than 5 characters")
-		render.Template(w, r, "admin-todo.page.html", &models.TemplateData{
+		data["artist"] = artist
+		data["genres"] = allGenres
+		m.App.Session.Put(r.Context(), "error", "Invalid form input")
+		render.Template(w, r, "list-service.page.html", &models.TemplateData{
 			Form: form,
 			Data: data,
 		})
 		return
 	}
 
-	// Insert new todo here
-	err = m.DB.InsertTodoList(todoList)
-	if err != nil {
-		m.App.Session.Put(r.Context(), "error", "Can't insert into database")
-		helpers.ServerError(w, err)
-		http.Redirect(w, r, "/admin/todo-list", http.StatusTemporaryRedirect)
-		return
-	}
-
-	m.App.Session.Put(r.Context(), "flash", "Todo Created Successfully!!!")
-	http.Redirect(w, r, "/admin/todo-list", http.StatusSeeOther)
-}
-
-// AdminDeleteTodo deletes Todo from the database and
-func (m *Repository) AdminDeleteTodo(w http.ResponseWriter, r *http.Request) {
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	// store the listing and let the admins know about it together
+	err = m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		id, err := repo.CreateArtist(r.Context(), artist)
+		if err != nil {
+			return err
+		}
+		artist.ID = id
 
-	err := m.DB.DeleteTodo(id)
+		if draft {
+			return nil
+		}
+		return m.enqueueEmails(r.Context(), repo, listingNotification(artist))
+	})
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	m.App.Session.Put(r.Context(), "flash", "<strong>Successful!!!</strong><br><br> <p>Todo Deleted</p>")
-	http.Redirect(w, r, "/admin/todo-list", http.StatusSeeOther)
-}
-
-// Recent ------------------------------------------
-
-// Handles the all-artists route
-func (m *Repository) AdminAllArtists(w http.ResponseWriter, r *http.Request) {
-	artists, err := m.DB.AllArtists()
-	if err != nil {
-		helpers.ServerError(w, err)
-		return
+	if draft {
+		m.App.Session.Put(r.Context(), "flash", "Your listing was saved as a draft. Send it for review when it is ready")
+	} else {
+		m.App.Session.Put(r.Context(), "flash", "Your listing was submitted and will be reviewed before it goes live")
 	}
-
-	data := make(map[string]interface{})
-	data["artists"] = artists
-
-	render.Template(w, r, "admin-all-artists.page.html", &models.TemplateData{
-		Data: data,
-	})
+	http.Redirect(w, r, "/dashboard/artist", http.StatusSeeOther)
 }
 
-// Handles the new-artist route to create a new artist
-func (m *Repository) AdminNewArtist(w http.ResponseWriter, r *http.Request) {
-	render.Template(w, r, "admin-new-artist.page.html", &models.TemplateData{
-		Form: forms.New(nil),
+// listingNotification tells the admins that a listing is waiting for review
+func listingNotification(artist models.Artist) outgoingEmail {
+	return adminNotification(settings.EventNewListing, emails.AdminNotification{
+		Event:   "New Artist Listing",
+		Summary: fmt.Sprintf("%s would like to be listed on MusiqCity", artist.Name),
+		Details: []emails.Detail{
+			{Label: "Listing", Value: fmt.Sprintf("#%d", artist.ID)},
+			{Label: "Genres", Value: artist.Genres},
+			{Label: "City", Value: artist.City},
+			{Label: "Phone", Value: artist.Phone},
+			{Label: "Email", Value: artist.Email},
+		},
 	})
 }
 
-// This function POST the new artist form and store them in the database
-func (m *Repository) PostAdminNewArtist(w http.ResponseWriter, r *http.Request) {
-	err := r.ParseForm()
-	if err != nil {
-		helpers.ServerError(w, err)
-		return
-	}
-
-	var artist models.Artist
-
+// artistFromForm copies the artist form into artist and validates it. The genres ticked are looked up in
+// allGenres, the genres artists pick from
+func artistFromForm(r *http.Request, artist models.Artist, allGenres []models.Genre) (models.Artist, *forms.Form) {
 	artist.Name = r.Form.Get("artist_name")
-	artist.Genres = r.Form.Get("genres")
 	artist.Description = r.Form.Get("description")
 	artist.Phone = r.Form.Get("phone")
 	artist.Email = r.Form.Get("email")
@@ -1121,46 +4571,51 @@ func (m *Repository) PostAdminNewArtist(w http.ResponseWriter, r *http.Request)
 	artist.Banner = r.Form.Get("banner")
 	artist.FeaturedImage = r.Form.Get("featured_image")
 
-	// Form validations
+	var names []string
+	artist.GenreList = nil
+	for _, genre := range allGenres {
+		if slices.Contains(r.Form["genres"], strconv.Itoa(genre.ID)) {
+			artist.GenreList = append(artist.GenreList, genre)
+			names = append(names, genre.Name)
+		}
+	}
+	artist.Genres = strings.Join(names, ", ")
+
 	form := forms.New(r.PostForm)
-	form.Required("artist_name", "genres", "description", "phone", "email")
+	form.Required("artist_name", "description", "phone", "email")
 	form.MinLength("artist_name", 5, 50)
 	form.MinLength("description", 5, 20000)
-
-	if !form.Valid() {
-		data := make(map[string]interface{})
-		data["artist"] = artist
-		m.App.Session.Put(r.Context(), "error", "Invalid form input")
-		render.Template(w, r, "admin-new-artist.page.html", &models.TemplateData{
-			Form: form,
-			Data: data,
-		})
-		return
+	if len(artist.GenreList) == 0 {
+		form.Errors.Add("genres", "Pick at least one genre")
 	}
 
-	// Insert new artist here
-	err = m.DB.CreateArtist(artist)
+	return artist, form
+}
+
+// Handles the page listing the artist profiles the logged in user owns
+func (m *Repository) UserArtists(w http.ResponseWriter, r *http.Request) {
+	artists, err := m.DB.AllArtistsByUserID(r.Context(), m.App.Session.GetInt(r.Context(), "user_id"))
 	if err != nil {
-		m.App.Session.Put(r.Context(), "error", "Can't insert new artist into database")
 		helpers.ServerError(w, err)
-		http.Redirect(w, r, "/admin/artists", http.StatusTemporaryRedirect)
 		return
 	}
 
-	m.App.Session.Put(r.Context(), "flash", "Artist Created Successfully!!!")
-	http.Redirect(w, r, "/admin/artists", http.StatusSeeOther)
+	data := make(map[string]interface{})
+	data["artists"] = artists
+
+	render.Template(w, r, "user-artists.page.html", &models.TemplateData{
+		Data: data,
+	})
 }
 
-// Handles the single-room route
-func (m *Repository) AdminSingleArtist(w http.ResponseWriter, r *http.Request) {
-	urlParams := strings.Split(r.RequestURI, "/")
-	id, err := strconv.Atoi(urlParams[3])
-	if err != nil {
-		helpers.ServerError(w, err)
+// Handles the page where owners edit the profile of their artist
+func (m *Repository) UserArtist(w http.ResponseWriter, r *http.Request) {
+	artist, ok := m.ownedArtist(w, r)
+	if !ok {
 		return
 	}
 
-	artist, err := m.DB.GetArtistByID(id)
+	allGenres, err := m.DB.AllGenres(r.Context())
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
@@ -1168,372 +4623,594 @@ func (m *Repository) AdminSingleArtist(w http.ResponseWriter, r *http.Request) {
 
 	data := make(map[string]interface{})
 	data["artist"] = artist
+	data["genres"] = allGenres
 
-	render.Template(w, r, "admin-single-artist.page.html", &models.TemplateData{
-		Data: data,
+	render.Template(w, r, "user-artist.page.html", &models.TemplateData{
 		Form: forms.New(nil),
+		Data: data,
 	})
 }
 
-// Handles the single-artist route for POST
-func (m *Repository) PostAdminSingleArtist(w http.ResponseWriter, r *http.Request) {
+// Handles the posting of the owner's artist profile form. The owner and listing status are kept
+func (m *Repository) PostUserArtist(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	urlParams := strings.Split(r.RequestURI, "/")
-	id, err := strconv.Atoi(urlParams[3])
-	if err != nil {
-		helpers.ServerError(w, err)
+	artist, ok := m.ownedArtist(w, r)
+	if !ok {
 		return
 	}
 
-	artist, err := m.DB.GetArtistByID(id)
+	allGenres, err := m.DB.AllGenres(r.Context())
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	artist.Name = r.Form.Get("artist_name")
-	artist.Genres = r.Form.Get("genres")
-	artist.Description = r.Form.Get("description")
-	artist.Phone = r.Form.Get("phone")
-	artist.Email = r.Form.Get("email")
-	artist.City = r.Form.Get("city")
-	artist.Facebook = r.Form.Get("facebook")
-	artist.Twitter = r.Form.Get("twitter")
-	artist.Youtube = r.Form.Get("youtube")
-	artist.Logo = r.Form.Get("logo")
-	artist.Banner = r.Form.Get("banner")
-	artist.FeaturedImage = r.Form.Get("featured_image")
-
-	form := forms.New(r.PostForm)
-	form.Required("artist_name", "genres", "description", "phone", "email")
-	form.MinLength("artist_name", 5, 50)
-	form.MinLength("description", 5, 20000)
+	before := artist
+	artist, form := artistFromForm(r, artist, allGenres)
 
 	if !form.Valid() {
 		data := make(map[string]interface{})
 		data["artist"] = artist
-		m.App.Session.Put(r.Context(), "error", "Invalid inputs")
-		render.Template(w, r, "admin-single-artist.page.html", &models.TemplateData{
+		data["genres"] = allGenres
+		m.App.Session.Put(r.Context(), "error", "Invalid form input")
+		render.Template(w, r, "user-artist.page.html", &models.TemplateData{
 			Form: form,
 			Data: data,
 		})
+		return
+	}
+
+	// changes to what the public sees of an approved listing are reviewed like a new listing
+	newStatus := artist.Status
+	if listingChanged(before, artist) {
+		newStatus = listing.AfterEdit(artist.Status)
+	}
+
+	err = m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		err := repo.UpdateArtist(r.Context(), artist)
+		if err != nil || newStatus == artist.Status {
+			return err
+		}
+
+		err = repo.UpdateArtistStatus(r.Context(), artist.ID, artist.Status, newStatus, "")
+		if err != nil {
+			return err
+		}
+
+		return m.enqueueEmails(r.Context(), repo, listingNotification(artist))
+	})
+	if errors.Is(err, listing.ErrStaleStatus) {
+		m.App.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, fmt.Sprintf("/dashboard/artist/%d", artist.ID), http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	if newStatus != artist.Status {
+		m.App.Session.Put(r.Context(), "flash", "Your changes were sent for review. Your listing is hidden until an admin approves them")
+	} else {
+		m.App.Session.Put(r.Context(), "flash", "Your profile was updated")
+	}
+	http.Redirect(w, r, fmt.Sprintf("/dashboard/artist/%d", artist.ID), http.StatusSeeOther)
+}
+
+// listingChanged reports whether an edit changed what the public sees of an artist's listing
+func listingChanged(before, after models.Artist) bool {
+	return before.Name != after.Name ||
+		before.Description != after.Description ||
+		before.Phone != after.Phone ||
+		before.Email != after.Email ||
+		before.City != after.City ||
+		before.Facebook != after.Facebook ||
+		before.Twitter != after.Twitter ||
+		before.Youtube != after.Youtube ||
+		before.Logo != after.Logo ||
+		before.Banner != after.Banner ||
+		before.FeaturedImage != after.FeaturedImage ||
+		before.Genres != after.Genres
+}
+
+// Handles the owner sending a draft or rejected listing for review
+func (m *Repository) PostUserSubmitArtist(w http.ResponseWriter, r *http.Request) {
+	artist, ok := m.ownedArtist(w, r)
+	if !ok {
+		return
+	}
 
+	err := listing.Transition(artist.Status, listing.Pending)
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "This listing can't be sent for review")
+		http.Redirect(w, r, "/dashboard/artist", http.StatusSeeOther)
 		return
 	}
 
-	err = m.DB.UpdateArtist(artist)
+	err = m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		err := repo.UpdateArtistStatus(r.Context(), artist.ID, artist.Status, listing.Pending, "")
+		if err != nil {
+			return err
+		}
+
+		return m.enqueueEmails(r.Context(), repo, listingNotification(artist))
+	})
+	if errors.Is(err, listing.ErrStaleStatus) {
+		m.App.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, "/dashboard/artist", http.StatusSeeOther)
+		return
+	}
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	m.App.Session.Put(r.Context(), "flash", "Artist Updated Successsfully!!!")
-	http.Redirect(w, r, "/admin/artists", http.StatusSeeOther)
+	m.App.Session.Put(r.Context(), "flash", fmt.Sprintf("%s was sent for review", artist.Name))
+	http.Redirect(w, r, "/dashboard/artist", http.StatusSeeOther)
 }
 
-// Handles the all-bookings route
-func (m *Repository) AdminAllBookings(w http.ResponseWriter, r *http.Request) {
-	bookings, err := m.DB.AllBookings()
+// Handles the page with the booking options of an owner's artist and the form to add one
+func (m *Repository) UserArtistOptions(w http.ResponseWriter, r *http.Request) {
+	artist, ok := m.ownedArtist(w, r)
+	if !ok {
+		return
+	}
+
+	m.renderUserArtistOptions(w, r, artist, models.BookingOptions{}, forms.New(nil))
+}
+
+// Handles the posting of a new booking option by the owner of an artist
+func (m *Repository) PostUserArtistOptions(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	data := make(map[string]interface{})
-	data["bookings"] = bookings
+	artist, ok := m.ownedArtist(w, r)
+	if !ok {
+		return
+	}
+
+	option, form := optionFromForm(r, models.BookingOptions{ArtistID: artist.ID})
 
-	render.Template(w, r, "admin-all-bookings.page.html", &models.TemplateData{
-		Data: data,
-	})
-}
+	if !form.Valid() {
+		m.App.Session.Put(r.Context(), "error", "Invalid form input")
+		m.renderUserArtistOptions(w, r, artist, option, form)
+		return
+	}
 
-// Handles the new-reservations route
-func (m *Repository) AdminNewBookings(w http.ResponseWriter, r *http.Request) {
-	bookings, err := m.DB.AllNewBookings()
+	err = m.DB.CreateBookingOption(r.Context(), option)
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	data := make(map[string]interface{})
-	data["bookings"] = bookings
-
-	render.Template(w, r, "admin-new-bookings.page.html", &models.TemplateData{
-		Data: data,
-	})
+	m.App.Session.Put(r.Context(), "flash", "Booking option added")
+	http.Redirect(w, r, fmt.Sprintf("/dashboard/artist/%d/options", artist.ID), http.StatusSeeOther)
 }
 
-// Handles the all-artists route
-func (m *Repository) AdminAllOptions(w http.ResponseWriter, r *http.Request) {
-	options, err := m.DB.AllBookingOptions()
+// renderUserArtistOptions renders the booking options page of an artist, with option in the new option form
+func (m *Repository) renderUserArtistOptions(w http.ResponseWriter, r *http.Request, artist models.Artist, option models.BookingOptions, form *forms.Form) {
+	options, err := m.DB.AllArtistBookingOptions(r.Context(), artist.ID)
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
 	data := make(map[string]interface{})
+	data["artist"] = artist
 	data["options"] = options
+	data["option"] = option
 
-	render.Template(w, r, "admin-all-options.page.html", &models.TemplateData{
+	render.Template(w, r, "user-artist-options.page.html", &models.TemplateData{
+		Form: form,
 		Data: data,
 	})
 }
 
-// Handles the new-artist route to create a new artist
-func (m *Repository) AdminNewOption(w http.ResponseWriter, r *http.Request) {
-	artists, err := m.DB.AllArtists()
-	if err != nil {
-		helpers.ServerError(w, err)
+// Handles the page where owners edit one of their artist's booking options
+func (m *Repository) UserArtistOption(w http.ResponseWriter, r *http.Request) {
+	artist, option, ok := m.ownedOption(w, r)
+	if !ok {
 		return
 	}
 
 	data := make(map[string]interface{})
-	data["artists"] = artists
+	data["artist"] = artist
+	data["option"] = option
 
-	render.Template(w, r, "admin-new-option.page.html", &models.TemplateData{
+	render.Template(w, r, "user-artist-option.page.html", &models.TemplateData{
 		Form: forms.New(nil),
 		Data: data,
 	})
 }
 
-// This function POST the new artist form and store them in the database
-func (m *Repository) PostAdminNewOption(w http.ResponseWriter, r *http.Request) {
+// Handles the posting of the owner's booking option form
+func (m *Repository) PostUserArtistOption(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	var option models.BookingOptions
-
-	option.Title = r.Form.Get("title")
-	option.Price = r.Form.Get("price")
-	option.Description = r.Form.Get("description")
-	artistIDStr := r.Form.Get("artist_id")
-	artistID, err := strconv.Atoi(artistIDStr)
-	if err != nil {
-		log.Printf("Error converting artist_id to integer: %v", err)
-	} else {
-		option.ArtistID = artistID
+	artist, option, ok := m.ownedOption(w, r)
+	if !ok {
+		return
 	}
 
-	// Form validations
-	form := forms.New(r.PostForm)
-	form.Required("title", "price", "description", "artist_id")
-	form.MinLength("title", 5, 50)
-	form.MinLength("description", 5, 250)
+	option, form := optionFromForm(r, option)
 
 	if !form.Valid() {
 		data := make(map[string]interface{})
+		data["artist"] = artist
 		data["option"] = option
 		m.App.Session.Put(r.Context(), "error", "Invalid form input")
-		render.Template(w, r, "admin-new-option.page.html", &models.TemplateData{
+		render.Template(w, r, "user-artist-option.page.html", &models.TemplateData{
 			Form: form,
 			Data: data,
 		})
 		return
 	}
 
-	// Insert new artist here
-	err = m.DB.CreateBookingOption(option)
+	err = m.DB.UpdateBookingOption(r.Context(), option)
 	if err != nil {
-		m.App.Session.Put(r.Context(), "error", "Can't insert new booking option into database")
 		helpers.ServerError(w, err)
-		http.Redirect(w, r, "/admin/booking-options/new-option", http.StatusTemporaryRedirect)
 		return
 	}
 
-	m.App.Session.Put(r.Context(), "flash", "Booking Option Created Successfully!!!")
-	http.Redirect(w, r, "/admin/booking-options/new-option", http.StatusSeeOther)
+	m.App.Session.Put(r.Context(), "flash", "Booking option updated")
+	http.Redirect(w, r, fmt.Sprintf("/dashboard/artist/%d/options", artist.ID), http.StatusSeeOther)
 }
 
-func (m *Repository) AdminSingleOption(w http.ResponseWriter, r *http.Request) {
-	urlParams := strings.Split(r.RequestURI, "/")
-	id, err := strconv.Atoi(urlParams[3])
-	if err != nil {
-		helpers.ServerError(w, err)
+// optionFromForm copies the booking option form into option and validates it
+func optionFromForm(r *http.Request, option models.BookingOptions) (models.BookingOptions, *forms.Form) {
+	option.Title = r.Form.Get("title")
+	option.Price = r.Form.Get("price")
+	option.Description = r.Form.Get("description")
+
+	form := forms.New(r.PostForm)
+	form.Required("title", "price", "description")
+	form.MinLength("title", 5, 50)
+	form.MinLength("description", 5, 250)
+
+	return option, form
+}
+
+// Handles the availability page of an owner's artist, with the upcoming bookings and blocks
+func (m *Repository) UserArtistAvailability(w http.ResponseWriter, r *http.Request) {
+	artist, ok := m.ownedArtist(w, r)
+	if !ok {
 		return
 	}
 
-	artists, err := m.DB.AllArtists()
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	restrictions, err := m.DB.GetRestrictionsForCurrentArtist(r.Context(), artist.ID, today, today.AddDate(2, 0, 0))
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	option, err := m.DB.GetBookingOptionByID(id)
+	data, stringMap, intMap, firstOfMonth := calendarMonthData(r)
+	lastOfMonth := firstOfMonth.AddDate(0, 1, -1)
+
+	monthRestrictions, err := m.DB.GetRestrictionsForCurrentArtist(r.Context(), artist.ID, firstOfMonth, lastOfMonth)
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	data := make(map[string]interface{})
-	data["option"] = option
-	data["artists"] = artists
+	var entries []calendar.Entry
+	for _, x := range monthRestrictions {
+		if x.BookingID > 0 {
+			entries = append(entries, calendar.Entry{ID: x.BookingID, Kind: calendar.KindBooking, Start: x.StartDate, End: x.EndDate})
+		} else {
+			entries = append(entries, calendar.Entry{ID: x.ID, Kind: calendar.KindBlock, Label: x.Reason, Start: x.StartDate, End: x.EndDate})
+		}
+	}
 
-	render.Template(w, r, "admin-single-option.page.html", &models.TemplateData{
-		Data: data,
-		Form: forms.New(nil),
+	data["artist"] = artist
+	data["restrictions"] = restrictions
+	data["cells"] = calendar.MonthCells(firstOfMonth, entries)
+
+	render.Template(w, r, "user-artist-availability.page.html", &models.TemplateData{
+		StringMap: stringMap,
+		IntMap:    intMap,
+		Data:      data,
 	})
 }
 
-// Handles the single-option route for POST
-func (m *Repository) PostAdminSingleOption(w http.ResponseWriter, r *http.Request) {
+// Handles the posting of a block range by the owner of an artist
+func (m *Repository) PostUserArtistAvailability(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	urlParams := strings.Split(r.RequestURI, "/")
-	id, err := strconv.Atoi(urlParams[3])
+	artist, ok := m.ownedArtist(w, r)
+	if !ok {
+		return
+	}
+
+	redirectURL := fmt.Sprintf("/dashboard/artist/%d/availability", artist.ID)
+
+	ranges, reason, err := blockRangesFromForm(r)
 	if err != nil {
-		helpers.ServerError(w, err)
+		m.App.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
 		return
 	}
 
-	artists, err := m.DB.AllArtists()
+	added, skipped, err := m.addArtistBlocks(r.Context(), artist.ID, ranges, reason)
+	if errors.Is(err, repository.ErrUnavailable) {
+		m.App.Session.Put(r.Context(), "error", "Some of those dates were just booked, no blocks were added. Please, try again")
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	option, err := m.DB.GetBookingOptionByID(id)
+	m.App.Session.Put(r.Context(), "flash", blockSummary(added, skipped))
+	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+}
+
+// Handles the removal of a block by the owner of an artist. Days held by a booking can't be removed here
+func (m *Repository) PostUserDeleteArtistBlock(w http.ResponseWriter, r *http.Request) {
+	artist, ok := m.ownedArtist(w, r)
+	if !ok {
+		return
+	}
+
+	redirectURL := fmt.Sprintf("/dashboard/artist/%d/availability", artist.ID)
+
+	blockID, err := strconv.Atoi(chi.URLParam(r, "blockID"))
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "That block does not exist")
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+
+	restrictions, err := m.DB.GetRestrictionsForCurrentArtist(r.Context(), artist.ID, time.Time{}, time.Now().AddDate(100, 0, 0))
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	data := make(map[string]interface{})
-	data["artists"] = artists
-	data["option"] = option
+	found := slices.ContainsFunc(restrictions, func(restriction models.ArtistRestriction) bool {
+		return restriction.ID == blockID && restriction.BookingID == 0
+	})
+	if !found {
+		m.App.Session.Put(r.Context(), "error", "That block does not exist")
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
 
-	option.Title = r.Form.Get("title")
-	option.Price = r.Form.Get("price")
-	option.Description = r.Form.Get("description")
-	artistIDStr := r.Form.Get("artist_id")
-	artistID, err := strconv.Atoi(artistIDStr)
+	err = m.DB.DeleteArtistBlockByID(r.Context(), blockID)
 	if err != nil {
-		log.Printf("Error converting artist_id to integer: %v", err)
-		m.App.Session.Put(r.Context(), "error", "Invalid Artist selected")
-		http.Redirect(w, r, "/admin/booking-options", http.StatusSeeOther)
-	} else {
-		option.ArtistID = artistID
+		helpers.ServerError(w, err)
+		return
 	}
 
-	// Form validations
-	form := forms.New(r.PostForm)
-	form.Required("title", "price", "description", "artist_id")
-	form.MinLength("title", 5, 50)
-	form.MinLength("description", 5, 250)
-
-	if !form.Valid() {
-		m.App.Session.Put(r.Context(), "error", "Invalid inputs")
-		render.Template(w, r, "admin-single-option.page.html", &models.TemplateData{
-			Form: form,
-			Data: data,
-		})
+	m.App.Session.Put(r.Context(), "flash", "Block removed")
+	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+}
 
+// Handles the incoming bookings of an owner's artist
+func (m *Repository) UserArtistBookings(w http.ResponseWriter, r *http.Request) {
+	artist, ok := m.ownedArtist(w, r)
+	if !ok {
 		return
 	}
 
-	err = m.DB.UpdateBookingOption(option)
+	bookings, err := m.DB.AllBookingsByArtistID(r.Context(), artist.ID)
 	if err != nil {
 		helpers.ServerError(w, err)
-		http.Redirect(w, r, "/admin/booking-options", http.StatusSeeOther)
 		return
 	}
 
-	m.App.Session.Put(r.Context(), "flash", "Booking Option Updated Successsfully!!!")
-	http.Redirect(w, r, "/admin/booking-options", http.StatusSeeOther)
+	data := make(map[string]interface{})
+	data["artist"] = artist
+	data["bookings"] = bookings
+
+	render.Template(w, r, "user-artist-bookings.page.html", &models.TemplateData{
+		Data: data,
+	})
 }
 
-// This function handles the ListService page and renders the template
-func (m *Repository) ListService(w http.ResponseWriter, r *http.Request) {
-	userExists := m.App.Session.GetInt(r.Context(), "user_id")
-	if userExists < 1 {
-		m.App.Session.Put(r.Context(), "warning", "You must be logged in")
-		http.Redirect(w, r, "/", http.StatusSeeOther)
+// Handles a single booking of an owner's artist
+func (m *Repository) UserArtistBooking(w http.ResponseWriter, r *http.Request) {
+	artist, booking, ok := m.ownedBooking(w, r)
+	if !ok {
 		return
 	}
-	
-	render.Template(w, r, "list-service.page.html", &models.TemplateData{
-		Form: forms.New(nil),
+
+	data := make(map[string]interface{})
+	data["artist"] = artist
+	data["booking"] = booking
+	data["can_answer"] = canAnswerBooking(booking.Status)
+
+	render.Template(w, r, "user-artist-booking.page.html", &models.TemplateData{
+		Data: data,
 	})
 }
 
-// This function handles the posting of ListService form
-func (m *Repository) PostListService(w http.ResponseWriter, r *http.Request) {
+// Handles the owner of an artist accepting or declining a booking. Accepting confirms it and declining
+// cancels it, and the customer is emailed either way
+func (m *Repository) PostUserArtistBookingStatus(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
 		helpers.ServerError(w, err)
 		return
 	}
 
-	var artist models.Artist
+	artist, booking, ok := m.ownedBooking(w, r)
+	if !ok {
+		return
+	}
 
-	artist.Name = r.Form.Get("artist_name")
-	artist.Genres = r.Form.Get("genres")
-	artist.Description = r.Form.Get("description")
-	artist.Phone = r.Form.Get("phone")
-	artist.Email = r.Form.Get("email")
-	artist.City = r.Form.Get("city")
-	artist.Facebook = r.Form.Get("facebook")
-	artist.Twitter = r.Form.Get("twitter")
-	artist.Youtube = r.Form.Get("youtube")
-	artist.Logo = r.Form.Get("logo")
-	artist.Banner = r.Form.Get("banner")
-	artist.FeaturedImage = r.Form.Get("featured_image")
+	redirectURL := fmt.Sprintf("/dashboard/artist/%d/bookings/%d", artist.ID, booking.ID)
+
+	var newStatus, verb, event string
+	switch r.Form.Get("decision") {
+	case "accept":
+		newStatus, verb, event = status.Confirmed, "accepted", "Booking Accepted"
+	case "decline":
+		newStatus, verb, event = status.Cancelled, "declined", "Booking Declined"
+	default:
+		m.App.Session.Put(r.Context(), "error", "Please, accept or decline the booking")
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
 
-	// Form validations
-	form := forms.New(r.PostForm)
-	form.Required("artist_name", "genres", "description", "phone", "email")
-	form.MinLength("artist_name", 5, 50)
-	form.MinLength("description", 5, 20000)
+	if !canAnswerBooking(booking.Status) {
+		m.App.Session.Put(r.Context(), "error", fmt.Sprintf("This booking is already %s", status.Label(booking.Status)))
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
 
-	if !form.Valid() {
-		data := make(map[string]interface{})
-		data["artist"] = artist
-		m.App.Session.Put(r.Context(), "error", "Invalid form input")
-		render.Template(w, r, "list-service.page.html", &models.TemplateData{
-			Form: form,
-			Data: data,
-		})
+	err = status.Transition(booking.Status, newStatus)
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
 		return
 	}
 
-	// Load the env file and get the frontendURL
-	// err = godotenv.Load()
-	// if err != nil {
-	// 	log.Println("Error loading .env file")
-	// }
-	// frontendURL := os.Getenv("FRONTEND_URL")
-
-	// Send email notification to user
-	// htmlBody := fmt.Sprintf(`
-	// <strong>Verify Your Account</strong><br />
-	// <p>Dear %s %s, </p>
-	// <p>Welcome to MusiqCity.</p>
-	// <strong>Kindly click the link below</strong>
-	// <a href="%s/verify-email?userid=%d&token=%s", target="_blank">Verify Account</a>
-	// <p>We hope to see you soon</p>
-	// `, user.FirstName, user.LastName, frontendURL, newUserID, jwtToken)
-
-	// message := models.MailData{
-	// 	To:      user.Email,
-	// 	From:    "prosperdevstack@gmail.com",
-	// 	Subject: "Verify Your Email",
-	// 	Content: htmlBody,
-	// }
-
-	// m.App.MailChannel <- message
-	// End of emails
-
-	m.App.Session.Put(r.Context(), "flash", "Sign up Successful!!! <br /> Please, check your email and verify your account to continue")
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	adminEmail := adminNotification(settings.EventNewBooking, emails.AdminNotification{
+		Event:   event,
+		Summary: fmt.Sprintf("%s has %s booking #%d from %s %s", artist.Name, verb, booking.ID, booking.FirstName, booking.LastName),
+		Details: []emails.Detail{
+			{Label: "Booking Dates", Value: fmt.Sprintf("%s, to %s", booking.StartDate.Format("2006-01-02"), booking.EndDate.Format("2006-01-02"))},
+			{Label: "Customer Email", Value: booking.Email},
+		},
+	})
+
+	userID := m.App.Session.GetInt(r.Context(), "user_id")
+	err = m.DB.WithTx(r.Context(), func(repo repository.DatabaseRepo) error {
+		err := repo.UpdateBookingStatus(r.Context(), booking.ID, booking.Status, newStatus, userID)
+		if err != nil {
+			return err
+		}
+
+		return m.enqueueEmails(r.Context(), repo,
+			statusEmail(booking.Email, booking.FirstName, "booking", booking.StartDate, booking.EndDate, newStatus),
+			adminEmail,
+		)
+	})
+	if errors.Is(err, status.ErrStaleStatus) {
+		m.App.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	m.App.Session.Put(r.Context(), "flash", fmt.Sprintf("Booking #%d was %s", booking.ID, verb))
+	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+}
+
+// canAnswerBooking reports whether the owner of an artist can still accept or decline a booking in state s
+func canAnswerBooking(s string) bool {
+	return s == status.Pending || s == status.Quoted
+}
+
+// ownedArtist loads the artist in the url and checks the logged in user owns it. If not, it sends the
+// user back to their artists and returns false
+func (m *Repository) ownedArtist(w http.ResponseWriter, r *http.Request) (models.Artist, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "That artist does not exist")
+		http.Redirect(w, r, "/dashboard/artist", http.StatusSeeOther)
+		return models.Artist{}, false
+	}
+
+	artist, err := m.DB.GetArtistByIDAnyStatus(r.Context(), id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		helpers.ServerError(w, err)
+		return models.Artist{}, false
+	}
+
+	// someone else's artist looks the same as a missing one
+	if err != nil || artist.UserID == 0 || artist.UserID != m.App.Session.GetInt(r.Context(), "user_id") {
+		m.App.Session.Put(r.Context(), "error", "That artist does not exist")
+		http.Redirect(w, r, "/dashboard/artist", http.StatusSeeOther)
+		return models.Artist{}, false
+	}
+
+	return artist, true
+}
+
+// ownedOption loads the artist and booking option in the url like ownedArtist, and checks the option
+// belongs to that artist
+func (m *Repository) ownedOption(w http.ResponseWriter, r *http.Request) (models.Artist, models.BookingOptions, bool) {
+	artist, ok := m.ownedArtist(w, r)
+	if !ok {
+		return artist, models.BookingOptions{}, false
+	}
+
+	optionsURL := fmt.Sprintf("/dashboard/artist/%d/options", artist.ID)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "optionID"))
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "That booking option does not exist")
+		http.Redirect(w, r, optionsURL, http.StatusSeeOther)
+		return artist, models.BookingOptions{}, false
+	}
+
+	option, err := m.DB.GetBookingOptionByID(r.Context(), id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		helpers.ServerError(w, err)
+		return artist, option, false
+	}
+
+	if err != nil || option.ArtistID != artist.ID {
+		m.App.Session.Put(r.Context(), "error", "That booking option does not exist")
+		http.Redirect(w, r, optionsURL, http.StatusSeeOther)
+		return artist, models.BookingOptions{}, false
+	}
+
+	return artist, option, true
+}
+
+// ownedBooking loads the artist and booking in the url like ownedArtist, and checks the booking is for
+// that artist
+func (m *Repository) ownedBooking(w http.ResponseWriter, r *http.Request) (models.Artist, models.Bookings, bool) {
+	artist, ok := m.ownedArtist(w, r)
+	if !ok {
+		return artist, models.Bookings{}, false
+	}
+
+	bookingsURL := fmt.Sprintf("/dashboard/artist/%d/bookings", artist.ID)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "bookingID"))
+	if err != nil {
+		m.App.Session.Put(r.Context(), "error", "That booking does not exist")
+		http.Redirect(w, r, bookingsURL, http.StatusSeeOther)
+		return artist, models.Bookings{}, false
+	}
+
+	booking, err := m.DB.GetBookingByID(r.Context(), id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		helpers.ServerError(w, err)
+		return artist, booking, false
+	}
+
+	if err != nil || booking.ArtistID != artist.ID {
+		m.App.Session.Put(r.Context(), "error", "That booking does not exist")
+		http.Redirect(w, r, bookingsURL, http.StatusSeeOther)
+		return artist, models.Bookings{}, false
+	}
+
+	return artist, booking, true
 }
@@ -3,18 +3,28 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/aidisapp/musiqcity_v2/internal/driver"
+	"github.com/aidisapp/musiqcity_v2/internal/helpers"
+	"github.com/aidisapp/musiqcity_v2/internal/listing"
+	"github.com/aidisapp/musiqcity_v2/internal/mailer"
 	"github.com/aidisapp/musiqcity_v2/internal/models"
+	"github.com/aidisapp/musiqcity_v2/internal/repository/dbrepo"
+	"github.com/aidisapp/musiqcity_v2/internal/roles"
+	"github.com/aidisapp/musiqcity_v2/internal/settings"
+	"github.com/aidisapp/musiqcity_v2/internal/status"
+	"github.com/go-chi/chi/v5"
 )
 
 type postData struct {
@@ -31,26 +41,33 @@ var theTests = []struct {
 	{"home", "/", "GET", http.StatusOK},
 	{"about", "/about", "GET", http.StatusOK},
 	{"contact", "/contact", "GET", http.StatusOK},
-	{"room", "/r", "/rooms/1", http.StatusOK},
-	{"reservation", "/reservation", "GET", http.StatusOK},
 	{"make res", "/make-reservation", "GET", http.StatusOK},
-	{"choose room", "/choose-room/1", "GET", http.StatusOK},
-	{"book room", "/book-room", "GET", http.StatusOK},
 	{"res summary", "/reservation-summary", "GET", http.StatusOK},
 	{"non-existent", "/green/eggs/and/ham", "GET", http.StatusNotFound},
 	{"login", "/user/login", "GET", http.StatusOK},
+	{"forgot password", "/user/forgot-password", "GET", http.StatusOK},
 	{"logout", "/user/logout", "GET", http.StatusOK},
-	{"dasboard", "/admin/dasboard", "GET", http.StatusOK},
-	{"new res", "/admin/new-reservations", "GET", http.StatusOK},
-	{"all res", "/admin/all-reservations", "GET", http.StatusOK},
+	{"dashboard", "/admin/dashboard", "GET", http.StatusOK},
+	{"new bookings", "/admin/new-bookings", "GET", http.StatusOK},
+	{"all bookings", "/admin/all-bookings", "GET", http.StatusOK},
 	{"res cal", "/admin/reservations-calendar", "GET", http.StatusOK},
 	{"show res cal with params", "/admin/reservations-calendar?y=2020&m=1", "GET", http.StatusOK},
 	{"single res", "/admin/reservations/new/1/show", "GET", http.StatusOK},
+	{"single res from all", "/admin/reservations/all/1/show", "GET", http.StatusOK},
+	{"single res from cal", "/admin/reservations/cal/1/show?y=2050&m=01", "GET", http.StatusOK},
+	{"single booking", "/admin/bookings/new/1/show", "GET", http.StatusOK},
 	{"admin rooms", "/admin/rooms", "GET", http.StatusOK},
 	{"new room", "/admin/rooms/new-room", "GET", http.StatusOK},
 	{"single room", "/admin/rooms/1", "GET", http.StatusOK},
 	{"new room", "/admin/rooms/new-room", "GET", http.StatusOK},
 	{"todo", "/admin/todo-list", "GET", http.StatusOK},
+	{"outbox", "/admin/outbox", "GET", http.StatusOK},
+	{"outbox dead", "/admin/outbox?status=dead", "GET", http.StatusOK},
+	{"email templates", "/admin/emails", "GET", http.StatusOK},
+	{"settings", "/admin/settings", "GET", http.StatusOK},
+	{"email preview", "/admin/emails/verify-email", "GET", http.StatusOK},
+	{"artists cal", "/admin/artists-calendar", "GET", http.StatusOK},
+	{"artists cal with params", "/admin/artists-calendar?y=2050&m=1", "GET", http.StatusOK},
 }
 
 func TestHandlers(testPointer *testing.T) {
@@ -114,9 +131,11 @@ var reservationTests = []struct {
 	},
 }
 
-// postReservationTests is the test data for hte PostReservation handler test
+// postReservationTests is the test data for the PostMakeReservation handler test. The dates and room come
+// from the reservation in the session, the form only has the customer details
 var postReservationTests = []struct {
 	name                 string
+	reservation          models.Reservation
 	postedData           url.Values
 	expectedResponseCode int
 	expectedLocation     string
@@ -124,114 +143,103 @@ var postReservationTests = []struct {
 }{
 	{
 		name: "valid-data",
+		reservation: models.Reservation{
+			RoomID:    1,
+			StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2050, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
 		postedData: url.Values{
-			"start_date": {"2050-01-01"},
-			"end_date":   {"2050-01-02"},
 			"first_name": {"Prosper"},
 			"last_name":  {"Atu"},
 			"email":      {"atu@prosper.com"},
 			"phone":      {"555-555-5555"},
-			"room_id":    {"1"},
 		},
 		expectedResponseCode: http.StatusSeeOther,
-		expectedHTML:         "",
 		expectedLocation:     "/reservation-summary",
 	},
 	{
-		name:                 "missing-post-body",
-		postedData:           nil,
-		expectedResponseCode: http.StatusSeeOther,
-		expectedHTML:         "",
-		expectedLocation:     "/",
-	},
-	{
-		name: "invalid-start-date",
+		name: "reservation-not-in-session",
 		postedData: url.Values{
-			"start_date": {"invalid"},
-			"end_date":   {"2050-01-02"},
 			"first_name": {"Prosper"},
 			"last_name":  {"Atu"},
 			"email":      {"atu@prosper.com"},
 			"phone":      {"555-555-5555"},
-			"room_id":    {"1"},
 		},
-		expectedResponseCode: http.StatusSeeOther,
-		expectedHTML:         "",
+		expectedResponseCode: http.StatusTemporaryRedirect,
 		expectedLocation:     "/",
 	},
 	{
-		name: "invalid-end-date",
-		postedData: url.Values{
-			"start_date": {"2050-01-01"},
-			"end_date":   {"end"},
-			"first_name": {"Prosper"},
-			"last_name":  {"Atu"},
-			"email":      {"atu@prosper.com"},
-			"phone":      {"555-555-5555"},
-			"room_id":    {"1"},
+		name: "missing-post-body",
+		reservation: models.Reservation{
+			RoomID:    1,
+			StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2050, 1, 2, 0, 0, 0, 0, time.UTC),
 		},
-		expectedResponseCode: http.StatusSeeOther,
-		expectedHTML:         "",
+		expectedResponseCode: http.StatusTemporaryRedirect,
 		expectedLocation:     "/",
 	},
 	{
-		name: "invalid-room-id",
+		name: "invalid-data",
+		reservation: models.Reservation{
+			RoomID:    1,
+			StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2050, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
 		postedData: url.Values{
-			"start_date": {"2050-01-01"},
-			"end_date":   {"2050-01-02"},
-			"first_name": {"Prosper"},
+			"first_name": {"P"},
 			"last_name":  {"Atu"},
 			"email":      {"atu@prosper.com"},
 			"phone":      {"555-555-5555"},
-			"room_id":    {"invalid"},
 		},
-		expectedResponseCode: http.StatusSeeOther,
-		expectedHTML:         "",
-		expectedLocation:     "/",
+		expectedResponseCode: http.StatusOK,
+		expectedHTML:         `action="/make-reservation"`,
 	},
 	{
-		name: "invalid-data",
+		name: "invalid-email",
+		reservation: models.Reservation{
+			RoomID:    1,
+			StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2050, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
 		postedData: url.Values{
-			"start_date": {"2050-01-01"},
-			"end_date":   {"2050-01-02"},
-			"first_name": {"P"},
+			"first_name": {"Prosper"},
 			"last_name":  {"Atu"},
-			"email":      {"atu@prosper.com"},
+			"email":      {"prosper"},
 			"phone":      {"555-555-5555"},
-			"room_id":    {"1"},
 		},
 		expectedResponseCode: http.StatusOK,
 		expectedHTML:         `action="/make-reservation"`,
-		expectedLocation:     "",
 	},
 	{
 		name: "database-insert-fails-reservation",
+		reservation: models.Reservation{
+			RoomID:    2,
+			StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2050, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
 		postedData: url.Values{
-			"start_date": {"2050-01-01"},
-			"end_date":   {"2050-01-02"},
 			"first_name": {"Prosper"},
 			"last_name":  {"Atu"},
 			"email":      {"atu@prosper.com"},
 			"phone":      {"555-555-5555"},
-			"room_id":    {"2"},
 		},
-		expectedResponseCode: http.StatusSeeOther,
-		expectedHTML:         "",
+		expectedResponseCode: http.StatusTemporaryRedirect,
 		expectedLocation:     "/",
 	},
 	{
 		name: "database-insert-fails-restriction",
+		reservation: models.Reservation{
+			RoomID:    1000,
+			StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2050, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
 		postedData: url.Values{
-			"start_date": {"2050-01-01"},
-			"end_date":   {"2050-01-02"},
-			"first_name": {"John"},
-			"last_name":  {"Smith"},
-			"email":      {"john@smith.com"},
+			"first_name": {"Prosper"},
+			"last_name":  {"Atu"},
+			"email":      {"atu@prosper.com"},
 			"phone":      {"555-555-5555"},
-			"room_id":    {"1000"},
 		},
-		expectedResponseCode: http.StatusSeeOther,
-		expectedHTML:         "",
+		expectedResponseCode: http.StatusTemporaryRedirect,
 		expectedLocation:     "/",
 	},
 }
@@ -318,20 +326,177 @@ func TestAvailabilityJSON(t *testing.T) {
 	}
 }
 
-// testPostAvailabilityData is data for the PostAvailability handler test, /make-reservation
+// testArtistAvailabilityJSONData is data for the ArtistAvailabilityJSON handler, /artist-availability-json route
+var testArtistAvailabilityJSONData = []struct {
+	name               string
+	postedData         url.Values
+	expectedOK         bool
+	expectedStatusCode int
+}{
+	{
+		name: "artist available",
+		postedData: url.Values{
+			"start":     {"2050-01-01"},
+			"end":       {"2050-01-02"},
+			"artist_id": {"1"},
+		},
+		expectedOK:         true,
+		expectedStatusCode: http.StatusOK,
+	},
+	{
+		name: "end before start",
+		postedData: url.Values{
+			"start":     {"2050-01-05"},
+			"end":       {"2050-01-02"},
+			"artist_id": {"1"},
+		},
+		expectedOK:         false,
+		expectedStatusCode: http.StatusBadRequest,
+	},
+	{
+		name: "invalid artist id",
+		postedData: url.Values{
+			"start":     {"2050-01-01"},
+			"end":       {"2050-01-02"},
+			"artist_id": {"fish"},
+		},
+		expectedOK:         false,
+		expectedStatusCode: http.StatusBadRequest,
+	},
+	{
+		name: "artist does not exist",
+		postedData: url.Values{
+			"start":     {"2050-01-01"},
+			"end":       {"2050-01-02"},
+			"artist_id": {"1001"},
+		},
+		expectedOK:         false,
+		expectedStatusCode: http.StatusNotFound,
+	},
+	{
+		name: "database query fails",
+		postedData: url.Values{
+			"start":     {"2060-01-01"},
+			"end":       {"2060-01-02"},
+			"artist_id": {"1"},
+		},
+		expectedOK:         false,
+		expectedStatusCode: http.StatusInternalServerError,
+	},
+	{
+		name:               "empty post body",
+		postedData:         nil,
+		expectedOK:         false,
+		expectedStatusCode: http.StatusBadRequest,
+	},
+}
+
+// TestArtistAvailabilityJSON tests the ArtistAvailabilityJSON handler
+func TestArtistAvailabilityJSON(t *testing.T) {
+	for _, e := range testArtistAvailabilityJSONData {
+		var req *http.Request
+		if e.postedData != nil {
+			req, _ = http.NewRequest("POST", "/artist-availability-json", strings.NewReader(e.postedData.Encode()))
+		} else {
+			req, _ = http.NewRequest("POST", "/artist-availability-json", nil)
+		}
+		ctx := getContext(req)
+		req = req.WithContext(ctx)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.ArtistAvailabilityJSON)
+		handler.ServeHTTP(rr, req)
+
+		var j artistJsonResponse
+		err := json.Unmarshal([]byte(rr.Body.String()), &j)
+		if err != nil {
+			t.Error("failed to parse json!")
+		}
+
+		if j.Ok != e.expectedOK {
+			t.Errorf("%s: expected %v but got %v", e.name, e.expectedOK, j.Ok)
+		}
+
+		if rr.Code != e.expectedStatusCode {
+			t.Errorf("%s: expected code %d but got %d", e.name, e.expectedStatusCode, rr.Code)
+		}
+	}
+}
+
+// TestArtistAvailabilityJSONUnapproved checks that listings the public can't see answer like missing artists
+func TestArtistAvailabilityJSONUnapproved(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	pendingID, _ := memoryRepo.DB.CreateArtist(context.Background(), models.Artist{Name: "The Accra Choir", UserID: 3, Status: listing.Pending})
+
+	for _, id := range []int{pendingID, 999} {
+		postedData := url.Values{
+			"start":     {"2050-01-01"},
+			"end":       {"2050-01-02"},
+			"artist_id": {strconv.Itoa(id)},
+		}
+		req, _ := http.NewRequest("POST", "/artist-availability-json", strings.NewReader(postedData.Encode()))
+		req = req.WithContext(getContext(req))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		http.HandlerFunc(memoryRepo.ArtistAvailabilityJSON).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotFound || strings.Contains(rr.Body.String(), `"ok": true`) {
+			t.Errorf("artist %d: expected a 404 but got %d %s", id, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+// TestAvailableArtistsJSON tests the AvailableArtistsJSON handler
+func TestAvailableArtistsJSON(t *testing.T) {
+	postedData := url.Values{
+		"start": {"2050-01-01"},
+		"end":   {"2050-01-02"},
+		"genre": {"Afrobeat"},
+		"city":  {"Lagos"},
+	}
+
+	req, _ := http.NewRequest("POST", "/available-artists-json", strings.NewReader(postedData.Encode()))
+	ctx := getContext(req)
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Repo.AvailableArtistsJSON)
+	handler.ServeHTTP(rr, req)
+
+	var j availableArtistsJsonResponse
+	err := json.Unmarshal([]byte(rr.Body.String()), &j)
+	if err != nil {
+		t.Error("failed to parse json!")
+	}
+
+	if !j.Ok {
+		t.Errorf("expected ok but got %s", j.Message)
+	}
+
+	if j.Artists == nil {
+		t.Error("expected an empty artists list, not null")
+	}
+}
+
+// TestArtistUnavailableDatesJSON tests the ArtistUnavailableDatesJSON handler
+// testPostAvailabilityData is data for the room availability search, the /reservation-json route
 var testPostAvailabilityData = []struct {
 	name               string
 	postedData         url.Values
 	expectedStatusCode int
-	expectedLocation   string
+	expectedOK         bool
 }{
 	{
 		name: "rooms not available",
 		postedData: url.Values{
-			"start": {"2050-01-01"},
-			"end":   {"2050-01-02"},
+			"start":   {"2050-01-01"},
+			"end":     {"2050-01-02"},
+			"room_id": {"1"},
 		},
-		expectedStatusCode: http.StatusSeeOther,
+		expectedStatusCode: http.StatusOK,
 	},
 	{
 		name: "rooms are available",
@@ -341,11 +506,12 @@ var testPostAvailabilityData = []struct {
 			"room_id": {"1"},
 		},
 		expectedStatusCode: http.StatusOK,
+		expectedOK:         true,
 	},
 	{
 		name:               "empty post body",
 		postedData:         url.Values{},
-		expectedStatusCode: http.StatusSeeOther,
+		expectedStatusCode: http.StatusInternalServerError,
 	},
 	{
 		name: "start date wrong format",
@@ -354,30 +520,32 @@ var testPostAvailabilityData = []struct {
 			"end":     {"2040-01-02"},
 			"room_id": {"1"},
 		},
-		expectedStatusCode: http.StatusSeeOther,
+		expectedStatusCode: http.StatusInternalServerError,
 	},
 	{
 		name: "end date wrong format",
 		postedData: url.Values{
-			"start": {"2040-01-01"},
-			"end":   {"invalid"},
+			"start":   {"2040-01-01"},
+			"end":     {"invalid"},
+			"room_id": {"1"},
 		},
-		expectedStatusCode: http.StatusSeeOther,
+		expectedStatusCode: http.StatusInternalServerError,
 	},
 	{
 		name: "database query fails",
 		postedData: url.Values{
-			"start": {"2060-01-01"},
-			"end":   {"2060-01-02"},
+			"start":   {"2060-01-01"},
+			"end":     {"2060-01-02"},
+			"room_id": {"1"},
 		},
-		expectedStatusCode: http.StatusSeeOther,
+		expectedStatusCode: http.StatusOK,
 	},
 }
 
-// TestPostAvailability tests the PostAvailabilityHandler
+// TestPostAvailability tests the status codes of the room availability search
 func TestPostAvailability(t *testing.T) {
 	for _, e := range testPostAvailabilityData {
-		req, _ := http.NewRequest("POST", "/make-reservation", strings.NewReader(e.postedData.Encode()))
+		req, _ := http.NewRequest("POST", "/reservation-json", strings.NewReader(e.postedData.Encode()))
 
 		// get the context with session
 		ctx := getContext(req)
@@ -385,15 +553,105 @@ func TestPostAvailability(t *testing.T) {
 
 		// set the request header
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
 		rr := httptest.NewRecorder()
 
-		// make our handler a http.HandlerFunc and call
-		handler := http.HandlerFunc(Repo.PostMakeReservation)
+		handler := http.HandlerFunc(Repo.AvailabilityJSON)
 		handler.ServeHTTP(rr, req)
 
 		if rr.Code != e.expectedStatusCode {
 			t.Errorf("%s gave wrong status code: got %d, wanted %d", e.name, rr.Code, e.expectedStatusCode)
 		}
+
+		if rr.Code != http.StatusOK {
+			continue
+		}
+
+		var j jsonResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &j); err != nil {
+			t.Errorf("%s: failed to parse json", e.name)
+		}
+
+		if j.Ok != e.expectedOK {
+			t.Errorf("%s: expected ok to be %v but got %v", e.name, e.expectedOK, j.Ok)
+		}
+	}
+}
+
+func TestArtistUnavailableDatesJSON(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	memoryRepo.DB.InsertBlockForArtist(context.Background(), 1, time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2070, 1, 1, 0, 0, 0, 0, time.UTC), "Touring")
+
+	var tests = []struct {
+		name         string
+		query        string
+		expectedCode int
+		expectedDays int
+	}{
+		{"inside a long block", "?start=2060-03-01&end=2060-03-10", http.StatusOK, 10},
+		{"a year", "?start=2060-01-01&end=2061-01-01", http.StatusOK, 367},
+		{"over a year", "?start=2050-01-01&end=2069-12-31", http.StatusBadRequest, 0},
+		{"end before start", "?start=2060-01-10&end=2060-01-01", http.StatusBadRequest, 0},
+	}
+
+	for _, e := range tests {
+		req, _ := http.NewRequest("GET", "/artists/1/unavailable-dates"+e.query, nil)
+		routeContext := chi.NewRouteContext()
+		routeContext.URLParams.Add("id", "1")
+		req = req.WithContext(context.WithValue(getContext(req), chi.RouteCtxKey, routeContext))
+		rr := httptest.NewRecorder()
+
+		http.HandlerFunc(memoryRepo.ArtistUnavailableDatesJSON).ServeHTTP(rr, req)
+
+		var j unavailableDatesJsonResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &j); err != nil {
+			t.Errorf("%s: failed to parse json", e.name)
+		}
+		if rr.Code != e.expectedCode || len(j.Dates) != e.expectedDays {
+			t.Errorf("%s: expected code %d and %d days but got %d and %d", e.name, e.expectedCode, e.expectedDays, rr.Code, len(j.Dates))
+		}
+	}
+}
+
+// TestPostMakeReservation tests the PostMakeReservation handler
+func TestPostMakeReservation(t *testing.T) {
+	for _, e := range postReservationTests {
+		var req *http.Request
+		if e.postedData != nil {
+			req, _ = http.NewRequest("POST", "/make-reservation", strings.NewReader(e.postedData.Encode()))
+		} else {
+			req, _ = http.NewRequest("POST", "/make-reservation", nil)
+		}
+
+		// get the context with session
+		ctx := getContext(req)
+		req = req.WithContext(ctx)
+		if e.reservation.RoomID > 0 {
+			session.Put(ctx, "reservation", e.reservation)
+		}
+
+		// set the request header
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		// make our handler a http.HandlerFunc and call
+		handler := http.HandlerFunc(Repo.PostMakeReservation)
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != e.expectedResponseCode {
+			t.Errorf("%s gave wrong status code: got %d, wanted %d", e.name, rr.Code, e.expectedResponseCode)
+		}
+
+		if e.expectedLocation != "" {
+			actualLoc, _ := rr.Result().Location()
+			if actualLoc == nil || actualLoc.String() != e.expectedLocation {
+				t.Errorf("failed %s: expected location %s, but got location %v", e.name, e.expectedLocation, actualLoc)
+			}
+		}
+
+		if e.expectedHTML != "" && !strings.Contains(rr.Body.String(), e.expectedHTML) {
+			t.Errorf("failed %s: expected to find %s but did not", e.name, e.expectedHTML)
+		}
 	}
 }
 
@@ -408,7 +666,11 @@ var reservationSummaryTests = []struct {
 	{
 		name: "res-in-session",
 		reservation: models.Reservation{
-			RoomID: 1,
+			FirstName: "Prosper",
+			LastName:  "Atu",
+			Email:     "atu@prosper.com",
+			Phone:     "555-555-5555",
+			RoomID:    1,
 			Room: models.Room{
 				ID:       1,
 				RoomName: "Generals Suit",
@@ -720,65 +982,283 @@ func TestPostReservationCalendar(t *testing.T) {
 	}
 }
 
-var adminProcessReservationTests = []struct {
-	name                 string
-	queryParams          string
-	expectedResponseCode int
-	expectedLocation     string
-}{
-	{
-		name:                 "process-reservation",
-		queryParams:          "",
-		expectedResponseCode: http.StatusSeeOther,
-		expectedLocation:     "",
-	},
-	{
-		name:                 "process-reservation-back-to-cal",
-		queryParams:          "?y=2021&m=12",
-		expectedResponseCode: http.StatusSeeOther,
-		expectedLocation:     "",
-	},
-}
+// artistsCalendarRequest sends a request to an artists calendar handler with the session in ctx, so the
+// block maps the calendar page stores are there for the POST
+func artistsCalendarRequest(ctx context.Context, handler http.HandlerFunc, method, target string, data url.Values) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, target, strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(ctx)
 
-func TestAdminProcessReservation(t *testing.T) {
-	for _, e := range adminProcessReservationTests {
-		req, _ := http.NewRequest("GET", fmt.Sprintf("/admin/process-reservation/cal/1/do%s", e.queryParams), nil)
-		ctx := getContext(req)
-		req = req.WithContext(ctx)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
 
-		rr := httptest.NewRecorder()
+	return rr
+}
 
-		handler := http.HandlerFunc(Repo.AdminProcessReservation)
-		handler.ServeHTTP(rr, req)
+func TestAdminArtistsCalendar(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	req, _ := http.NewRequest("GET", "/admin/artists-calendar", nil)
+	ctx := getContext(req)
+	day := time.Date(2050, time.March, 10, 0, 0, 0, 0, time.UTC)
+	month := url.Values{"year": {"2050"}, "month": {"3"}}
 
-		if rr.Code != http.StatusSeeOther {
-			t.Errorf("failed %s: expected code %d, but got %d", e.name, e.expectedResponseCode, rr.Code)
+	blocks := func() []models.ArtistRestriction {
+		restrictions, err := memoryRepo.DB.GetRestrictionsForCurrentArtist(context.Background(), 3, day, day)
+		if err != nil {
+			t.Fatal(err)
 		}
+		return restrictions
 	}
-}
 
-var adminDeleteReservationTests = []struct {
-	name                 string
-	queryParams          string
-	expectedResponseCode int
-	expectedLocation     string
-}{
-	{
-		name:                 "delete-reservation",
-		queryParams:          "",
-		expectedResponseCode: http.StatusSeeOther,
-		expectedLocation:     "",
-	},
-	{
-		name:                 "delete-reservation-back-to-cal",
-		queryParams:          "?y=2021&m=12",
-		expectedResponseCode: http.StatusSeeOther,
-		expectedLocation:     "",
-	},
-}
+	// adding a block
+	data := url.Values{"add_block_3_2050-03-10": {"1"}, "year": month["year"], "month": month["month"]}
+	rr := artistsCalendarRequest(ctx, memoryRepo.AdminPostArtistsCalendar, "POST", "/admin/artists-calendar", data)
+	if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/artists-calendar?y=2050&m=3" {
+		t.Errorf("adding a block: got code %d and location %q", rr.Code, rr.Header().Get("Location"))
+	}
+	if flash := session.PopString(ctx, "flash"); flash != "Artist Calendar Updated" {
+		t.Errorf("adding a block: unexpected flash %q", flash)
+	}
+	if len(blocks()) != 1 {
+		t.Fatalf("adding a block: expected one block but got %d", len(blocks()))
+	}
 
-func TestAdminDeleteReservation(t *testing.T) {
-	for _, e := range adminDeleteReservationTests {
+	// the calendar page lists the block and remembers it for the next POST
+	rr = artistsCalendarRequest(ctx, memoryRepo.AdminArtistsCalendar, "GET", "/admin/artists-calendar?y=2050&m=3", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("calendar page: expected code %d but got %d", http.StatusOK, rr.Code)
+	}
+	blockID := blocks()[0].ID
+	if !strings.Contains(rr.Body.String(), fmt.Sprintf("remove_block_3_%d", blockID)) {
+		t.Error("calendar page: the block isn't shown")
+	}
+
+	// blocking the same day again fails, and says so
+	rr = artistsCalendarRequest(ctx, memoryRepo.AdminPostArtistsCalendar, "POST", "/admin/artists-calendar", url.Values{
+		"add_block_3_2050-03-10":                  {"1"},
+		fmt.Sprintf("remove_block_3_%d", blockID): {"1"},
+	})
+	if rr.Code != http.StatusSeeOther {
+		t.Errorf("blocking twice: expected code %d but got %d", http.StatusSeeOther, rr.Code)
+	}
+	if session.PopString(ctx, "flash") != "" || session.PopString(ctx, "error") == "" {
+		t.Error("blocking twice: expected an error instead of a success flash")
+	}
+
+	// malformed keys are skipped instead of crashing the handler
+	rr = artistsCalendarRequest(ctx, memoryRepo.AdminPostArtistsCalendar, "POST", "/admin/artists-calendar", url.Values{
+		"add_block":                               {"1"},
+		"add_block_x":                             {"1"},
+		"add_block_x_2050-03-11":                  {"1"},
+		"add_block_3_not-a-date":                  {"1"},
+		"add_block_3_2050-03-11_extra":            {"1"},
+		fmt.Sprintf("remove_block_3_%d", blockID): {"1"},
+	})
+	if rr.Code != http.StatusSeeOther {
+		t.Errorf("malformed keys: expected code %d but got %d", http.StatusSeeOther, rr.Code)
+	}
+	if flash, e := session.PopString(ctx, "flash"), session.PopString(ctx, "error"); flash != "" || e != "" {
+		t.Errorf("malformed keys: expected nothing to change but got flash %q and error %q", flash, e)
+	}
+	if len(blocks()) != 1 {
+		t.Errorf("malformed keys: expected the block to stay but got %d blocks", len(blocks()))
+	}
+
+	// removing the block by unticking it
+	rr = artistsCalendarRequest(ctx, memoryRepo.AdminPostArtistsCalendar, "POST", "/admin/artists-calendar", month)
+	if rr.Code != http.StatusSeeOther {
+		t.Errorf("removing a block: expected code %d but got %d", http.StatusSeeOther, rr.Code)
+	}
+	if flash := session.PopString(ctx, "flash"); flash != "Artist Calendar Updated" {
+		t.Errorf("removing a block: unexpected flash %q", flash)
+	}
+	if len(blocks()) != 0 {
+		t.Errorf("removing a block: expected no blocks but got %d", len(blocks()))
+	}
+}
+
+func TestAdminPostArtistBlocks(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	req, _ := http.NewRequest("POST", "/admin/artists-calendar/blocks", nil)
+	ctx := getContext(req)
+	start := time.Date(2050, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	rr := artistsCalendarRequest(ctx, memoryRepo.AdminPostArtistBlocks, "POST", "/admin/artists-calendar/blocks", url.Values{
+		"artist_id":   {"3"},
+		"block_start": {"2050-04-01"},
+		"block_end":   {"2050-04-03"},
+		"reason":      {"Touring"},
+		"year":        {"2050"},
+		"month":       {"4"},
+	})
+	if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/artists-calendar?y=2050&m=4" {
+		t.Errorf("got code %d and location %q", rr.Code, rr.Header().Get("Location"))
+	}
+	if flash := session.PopString(ctx, "flash"); flash != "1 block(s) added" {
+		t.Errorf("unexpected flash %q", flash)
+	}
+
+	restrictions, _ := memoryRepo.DB.GetRestrictionsForCurrentArtist(context.Background(), 3, start, start.AddDate(0, 0, 2))
+	if len(restrictions) != 1 || restrictions[0].Reason != "Touring" {
+		t.Errorf("expected one Touring block but got %v", restrictions)
+	}
+
+	rr = artistsCalendarRequest(ctx, memoryRepo.AdminPostArtistBlocks, "POST", "/admin/artists-calendar/blocks", url.Values{
+		"artist_id":   {"none"},
+		"block_start": {"2050-04-01"},
+	})
+	if rr.Code != http.StatusSeeOther || session.PopString(ctx, "error") == "" {
+		t.Errorf("expected an invalid artist to be turned away but got code %d", rr.Code)
+	}
+}
+
+var adminPostReservationStatusTests = []struct {
+	name             string
+	status           string
+	expectedLocation string
+}{
+	{
+		name:             "pending-to-confirmed",
+		status:           "confirmed",
+		expectedLocation: "/admin/reservations/cal/1/show?y=2050&m=01",
+	},
+	{
+		name:             "pending-to-completed-not-allowed",
+		status:           "completed",
+		expectedLocation: "/admin/reservations/cal/1/show?y=2050&m=01",
+	},
+	{
+		name:             "unknown-status",
+		status:           "processed",
+		expectedLocation: "/admin/reservations/cal/1/show?y=2050&m=01",
+	},
+}
+
+func TestAdminPostReservationStatus(t *testing.T) {
+	for _, e := range adminPostReservationStatusTests {
+		postedData := url.Values{}
+		postedData.Add("status", e.status)
+		postedData.Add("year", "2050")
+		postedData.Add("month", "01")
+
+		req, _ := http.NewRequest("POST", "/admin/reservations/cal/1/status", strings.NewReader(postedData.Encode()))
+		ctx := getContext(req)
+
+		routeContext := chi.NewRouteContext()
+		routeContext.URLParams.Add("src", "cal")
+		routeContext.URLParams.Add("id", "1")
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, routeContext)
+		req = req.WithContext(ctx)
+
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.AdminPostReservationStatus)
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusSeeOther {
+			t.Errorf("failed %s: expected code %d, but got %d", e.name, http.StatusSeeOther, rr.Code)
+		}
+
+		actualLoc, _ := rr.Result().Location()
+		if actualLoc.String() != e.expectedLocation {
+			t.Errorf("failed %s: expected location %s, but got location %s", e.name, e.expectedLocation, actualLoc.String())
+		}
+	}
+}
+
+var adminPostBookingStatusTests = []struct {
+	name                 string
+	bookingID            string
+	status               string
+	expectedResponseCode int
+	expectedFlash        string
+	expectedError        string
+}{
+	{
+		name:                 "pending-to-quoted",
+		bookingID:            "1",
+		status:               "quoted",
+		expectedResponseCode: http.StatusSeeOther,
+		expectedFlash:        "Booking is now marked as Quoted",
+	},
+	{
+		name:                 "pending-to-no-show-not-allowed",
+		bookingID:            "1",
+		status:               "no_show",
+		expectedResponseCode: http.StatusSeeOther,
+		expectedError:        "that status change is not allowed",
+	},
+	{
+		name:                 "database-update-fails",
+		bookingID:            "2",
+		status:               "confirmed",
+		expectedResponseCode: http.StatusInternalServerError,
+	},
+	{
+		name:                 "booking-not-found",
+		bookingID:            "3",
+		status:               "confirmed",
+		expectedResponseCode: http.StatusInternalServerError,
+	},
+}
+
+func TestAdminPostBookingStatus(t *testing.T) {
+	for _, e := range adminPostBookingStatusTests {
+		postedData := url.Values{}
+		postedData.Add("status", e.status)
+
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/admin/bookings/all/%s/status", e.bookingID), strings.NewReader(postedData.Encode()))
+		ctx := getContext(req)
+
+		routeContext := chi.NewRouteContext()
+		routeContext.URLParams.Add("src", "all")
+		routeContext.URLParams.Add("id", e.bookingID)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, routeContext)
+		req = req.WithContext(ctx)
+
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.AdminPostBookingStatus)
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != e.expectedResponseCode {
+			t.Errorf("failed %s: expected code %d, but got %d", e.name, e.expectedResponseCode, rr.Code)
+		}
+
+		if flash := session.GetString(ctx, "flash"); flash != e.expectedFlash {
+			t.Errorf("failed %s: expected flash %q, but got %q", e.name, e.expectedFlash, flash)
+		}
+
+		if errorMessage := session.GetString(ctx, "error"); errorMessage != e.expectedError {
+			t.Errorf("failed %s: expected error %q, but got %q", e.name, e.expectedError, errorMessage)
+		}
+	}
+}
+
+var adminDeleteReservationTests = []struct {
+	name                 string
+	queryParams          string
+	expectedResponseCode int
+	expectedLocation     string
+}{
+	{
+		name:                 "delete-reservation",
+		queryParams:          "",
+		expectedResponseCode: http.StatusSeeOther,
+		expectedLocation:     "",
+	},
+	{
+		name:                 "delete-reservation-back-to-cal",
+		queryParams:          "?y=2021&m=12",
+		expectedResponseCode: http.StatusSeeOther,
+		expectedLocation:     "",
+	},
+}
+
+func TestAdminDeleteReservation(t *testing.T) {
+	for _, e := range adminDeleteReservationTests {
 		req, _ := http.NewRequest("GET", fmt.Sprintf("/admin/delete-reservation/cal/1/do%s", e.queryParams), nil)
 		ctx := getContext(req)
 		req = req.WithContext(ctx)
@@ -794,6 +1274,216 @@ func TestAdminDeleteReservation(t *testing.T) {
 	}
 }
 
+// postArtistBookingTests is the test data for the PostArtistBooking handler test
+var postArtistBookingTests = []struct {
+	name                 string
+	artistID             string
+	postedData           url.Values
+	expectedResponseCode int
+	expectedLocation     string
+	expectedHTML         string
+	expectedError        string
+}{
+	{
+		name:     "valid-data",
+		artistID: "1",
+		postedData: url.Values{
+			"start_date":        {"2050-01-01"},
+			"end_date":          {"2050-01-02"},
+			"first_name":        {"Prosper"},
+			"last_name":         {"Atu"},
+			"email":             {"atu@prosper.com"},
+			"phone":             {"555-555-5555"},
+			"booking_option_id": {"1"},
+		},
+		expectedResponseCode: http.StatusSeeOther,
+		expectedLocation:     "/booking-summary",
+	},
+	{
+		name:     "invalid-artist-id",
+		artistID: "fish",
+		postedData: url.Values{
+			"start_date": {"2050-01-01"},
+			"end_date":   {"2050-01-02"},
+		},
+		expectedResponseCode: http.StatusSeeOther,
+		expectedLocation:     "/artists",
+	},
+	{
+		name:     "end-before-start",
+		artistID: "1",
+		postedData: url.Values{
+			"start_date":        {"2050-01-05"},
+			"end_date":          {"2050-01-02"},
+			"first_name":        {"Prosper"},
+			"last_name":         {"Atu"},
+			"email":             {"atu@prosper.com"},
+			"phone":             {"555-555-5555"},
+			"booking_option_id": {"1"},
+		},
+		expectedResponseCode: http.StatusOK,
+		expectedHTML:         `action="/artists/`,
+	},
+	{
+		name:     "unknown-booking-option",
+		artistID: "1",
+		postedData: url.Values{
+			"start_date":        {"2050-01-01"},
+			"end_date":          {"2050-01-02"},
+			"first_name":        {"Prosper"},
+			"last_name":         {"Atu"},
+			"email":             {"atu@prosper.com"},
+			"phone":             {"555-555-5555"},
+			"booking_option_id": {"99"},
+		},
+		expectedResponseCode: http.StatusOK,
+		expectedHTML:         `action="/artists/`,
+	},
+	{
+		name:     "database-insert-fails-booking",
+		artistID: "2",
+		postedData: url.Values{
+			"start_date":        {"2050-01-01"},
+			"end_date":          {"2050-01-02"},
+			"first_name":        {"Prosper"},
+			"last_name":         {"Atu"},
+			"email":             {"atu@prosper.com"},
+			"phone":             {"555-555-5555"},
+			"booking_option_id": {"1"},
+		},
+		expectedResponseCode: http.StatusSeeOther,
+		expectedLocation:     "/artists/2",
+	},
+	{
+		name:     "dates-just-booked",
+		artistID: "3",
+		postedData: url.Values{
+			"start_date":        {"2050-01-01"},
+			"end_date":          {"2050-01-02"},
+			"first_name":        {"Prosper"},
+			"last_name":         {"Atu"},
+			"email":             {"atu@prosper.com"},
+			"phone":             {"555-555-5555"},
+			"booking_option_id": {"1"},
+		},
+		expectedResponseCode: http.StatusSeeOther,
+		expectedLocation:     "/artists/3",
+		expectedError:        "was just booked for those dates",
+	},
+}
+
+// TestPostArtistBooking tests the PostArtistBooking handler
+func TestPostArtistBooking(t *testing.T) {
+	for _, e := range postArtistBookingTests {
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/artists/%s/book", e.artistID), strings.NewReader(e.postedData.Encode()))
+		ctx := getContext(req)
+
+		// add the chi url param the handler reads
+		routeContext := chi.NewRouteContext()
+		routeContext.URLParams.Add("id", e.artistID)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, routeContext)
+		req = req.WithContext(ctx)
+
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.PostArtistBooking)
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != e.expectedResponseCode {
+			t.Errorf("failed %s: expected code %d, but got %d", e.name, e.expectedResponseCode, rr.Code)
+		}
+
+		if e.expectedLocation != "" {
+			actualLoc, _ := rr.Result().Location()
+			if actualLoc.String() != e.expectedLocation {
+				t.Errorf("failed %s: expected location %s, but got location %s", e.name, e.expectedLocation, actualLoc.String())
+			}
+		}
+
+		if e.expectedHTML != "" {
+			html := rr.Body.String()
+			if !strings.Contains(html, e.expectedHTML) {
+				t.Errorf("failed %s: expected to find %s but did not", e.name, e.expectedHTML)
+			}
+		}
+
+		if e.expectedError != "" {
+			errorMessage := session.GetString(ctx, "error")
+			if !strings.Contains(errorMessage, e.expectedError) {
+				t.Errorf("failed %s: expected error containing %q, but got %q", e.name, e.expectedError, errorMessage)
+			}
+		}
+	}
+}
+
+// TestPostArtistBookingMemoryRepo books the same dates twice against the memory repo, the second
+// customer must be told the artist is not available
+func TestPostArtistBookingMemoryRepo(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+
+	postedData := url.Values{
+		"start_date":        {"2050-06-01"},
+		"end_date":          {"2050-06-02"},
+		"first_name":        {"Prosper"},
+		"last_name":         {"Atu"},
+		"email":             {"atu@prosper.com"},
+		"phone":             {"555-555-5555"},
+		"booking_option_id": {"1"},
+	}
+
+	expectedLocations := []string{"/booking-summary", "/artists/1"}
+
+	for i, expectedLocation := range expectedLocations {
+		req, _ := http.NewRequest("POST", "/artists/1/book", strings.NewReader(postedData.Encode()))
+		ctx := getContext(req)
+
+		routeContext := chi.NewRouteContext()
+		routeContext.URLParams.Add("id", "1")
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, routeContext)
+		req = req.WithContext(ctx)
+
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(memoryRepo.PostArtistBooking)
+		handler.ServeHTTP(rr, req)
+
+		actualLoc, _ := rr.Result().Location()
+		if rr.Code != http.StatusSeeOther || actualLoc.String() != expectedLocation {
+			t.Errorf("booking %d: expected a redirect to %s, but got code %d and location %s", i+1, expectedLocation, rr.Code, actualLoc)
+		}
+
+		if i == 1 && !strings.Contains(session.GetString(ctx, "error"), "is not available") {
+			t.Errorf("booking %d: expected the not available error, but got %q", i+1, session.GetString(ctx, "error"))
+		}
+	}
+
+	bookings, _ := memoryRepo.DB.AllBookings(context.Background())
+	booked := 0
+	for _, b := range bookings {
+		if b.Email == "atu@prosper.com" {
+			booked++
+		}
+	}
+	if booked != 1 {
+		t.Errorf("expected one booking to be stored, but found %d", booked)
+	}
+
+	// the confirmation and the admin notification are queued with both bodies
+	queued, _ := memoryRepo.DB.AllOutboxEmails(context.Background(), "")
+	subjects := map[string]bool{}
+	for _, email := range queued {
+		subjects[email.Subject] = true
+		if email.Content == "" || email.Text == "" {
+			t.Errorf("expected %q to have an HTML and a text body", email.Subject)
+		}
+	}
+	if len(queued) != 2 || !subjects["Booking Confirmation"] || !subjects["New Booking"] {
+		t.Errorf("expected the booking emails to be queued, but found %v", subjects)
+	}
+}
+
 func getContext(request *http.Request) context.Context {
 	ctx, err := session.Load(request.Context(), request.Header.Get("X-Session"))
 	if err != nil {
@@ -801,3 +1491,1357 @@ func getContext(request *http.Request) context.Context {
 	}
 	return ctx
 }
+
+// manageToken signs a manage link token for the test repo records
+func manageToken(t *testing.T, kind string, id int) string {
+	token, err := helpers.GenerateManageToken(kind, id, time.Now().AddDate(0, 2, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+var manageTests = []struct {
+	name                 string
+	kind                 string
+	id                   int
+	token                string
+	expectedResponseCode int
+	expectedHTML         string
+}{
+	{
+		name:                 "booking-outside-windows",
+		kind:                 "booking",
+		id:                   1,
+		expectedResponseCode: http.StatusOK,
+		expectedHTML:         `action="/manage/cancel"`,
+	},
+	{
+		name:                 "booking-inside-windows",
+		kind:                 "booking",
+		id:                   2,
+		expectedResponseCode: http.StatusOK,
+		expectedHTML:         "can be cancelled online up to 48 hours",
+	},
+	{
+		name:                 "reservation",
+		kind:                 "reservation",
+		id:                   1,
+		expectedResponseCode: http.StatusOK,
+		expectedHTML:         `action="/manage/reschedule"`,
+	},
+	{
+		name:                 "booking-not-found",
+		kind:                 "booking",
+		id:                   3,
+		expectedResponseCode: http.StatusSeeOther,
+	},
+	{
+		name:                 "unknown-kind",
+		kind:                 "room",
+		id:                   1,
+		expectedResponseCode: http.StatusSeeOther,
+	},
+	{
+		name:                 "tampered-token",
+		token:                "not-a-token",
+		expectedResponseCode: http.StatusSeeOther,
+	},
+}
+
+func TestManage(t *testing.T) {
+	for _, e := range manageTests {
+		token := e.token
+		if token == "" {
+			token = manageToken(t, e.kind, e.id)
+		}
+
+		req, _ := http.NewRequest("GET", "/manage?token="+url.QueryEscape(token), nil)
+		ctx := getContext(req)
+		req = req.WithContext(ctx)
+
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.Manage)
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != e.expectedResponseCode {
+			t.Errorf("failed %s: expected code %d, but got %d", e.name, e.expectedResponseCode, rr.Code)
+		}
+
+		if e.expectedHTML != "" && !strings.Contains(rr.Body.String(), e.expectedHTML) {
+			t.Errorf("failed %s: expected to find %s but did not", e.name, e.expectedHTML)
+		}
+	}
+}
+
+var postManageTests = []struct {
+	name          string
+	action        string
+	kind          string
+	id            int
+	postedData    url.Values
+	expectedFlash string
+	expectedError string
+}{
+	{
+		name:          "cancel-booking",
+		action:        "cancel",
+		kind:          "booking",
+		id:            1,
+		expectedFlash: "Your booking has been cancelled",
+	},
+	{
+		name:          "cancel-reservation",
+		action:        "cancel",
+		kind:          "reservation",
+		id:            1,
+		expectedFlash: "Your reservation has been cancelled",
+	},
+	{
+		name:          "cancel-inside-window",
+		action:        "cancel",
+		kind:          "booking",
+		id:            2,
+		expectedError: "This booking can no longer be cancelled online. Please, contact us",
+	},
+	{
+		name:          "reschedule-booking",
+		action:        "reschedule",
+		kind:          "booking",
+		id:            1,
+		postedData:    url.Values{"start_date": {"2050-01-01"}, "end_date": {"2050-01-02"}},
+		expectedFlash: "Your booking has been moved to 2050-01-01 - 2050-01-02",
+	},
+	{
+		name:          "reschedule-dates-taken",
+		action:        "reschedule",
+		kind:          "booking",
+		id:            1,
+		postedData:    url.Values{"start_date": {"2060-01-01"}, "end_date": {"2060-01-02"}},
+		expectedError: " is not available from 2060-01-01 to 2060-01-02. Please, choose other dates",
+	},
+	{
+		name:          "reschedule-end-before-start",
+		action:        "reschedule",
+		kind:          "booking",
+		id:            1,
+		postedData:    url.Values{"start_date": {"2050-01-02"}, "end_date": {"2050-01-01"}},
+		expectedError: "The end date can't be before the start date",
+	},
+	{
+		name:          "reschedule-into-window",
+		action:        "reschedule",
+		kind:          "booking",
+		id:            1,
+		postedData:    url.Values{"start_date": {time.Now().Format("2006-01-02")}, "end_date": {time.Now().AddDate(0, 0, 1).Format("2006-01-02")}},
+		expectedError: "The new start date must be at least 72 hours from now",
+	},
+	{
+		name:          "reschedule-inside-window",
+		action:        "reschedule",
+		kind:          "booking",
+		id:            2,
+		postedData:    url.Values{"start_date": {"2050-01-01"}, "end_date": {"2050-01-02"}},
+		expectedError: "This booking can no longer be rescheduled online. Please, contact us",
+	},
+}
+
+func TestPostManage(t *testing.T) {
+	for _, e := range postManageTests {
+		postedData := url.Values{}
+		for key, values := range e.postedData {
+			postedData[key] = values
+		}
+		postedData.Set("token", manageToken(t, e.kind, e.id))
+
+		req, _ := http.NewRequest("POST", "/manage/"+e.action, strings.NewReader(postedData.Encode()))
+		ctx := getContext(req)
+		req = req.WithContext(ctx)
+
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.PostManageCancel)
+		if e.action == "reschedule" {
+			handler = http.HandlerFunc(Repo.PostManageReschedule)
+		}
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusSeeOther {
+			t.Errorf("failed %s: expected code %d, but got %d", e.name, http.StatusSeeOther, rr.Code)
+		}
+
+		actualLoc, _ := rr.Result().Location()
+		if !strings.HasPrefix(actualLoc.String(), "/manage?token=") {
+			t.Errorf("failed %s: expected to go back to the manage page, but got location %s", e.name, actualLoc.String())
+		}
+
+		if flash := session.GetString(ctx, "flash"); flash != e.expectedFlash {
+			t.Errorf("failed %s: expected flash %q, but got %q", e.name, e.expectedFlash, flash)
+		}
+
+		if errorMessage := session.GetString(ctx, "error"); errorMessage != e.expectedError {
+			t.Errorf("failed %s: expected error %q, but got %q", e.name, e.expectedError, errorMessage)
+		}
+	}
+}
+
+func TestPostManageInvalidToken(t *testing.T) {
+	postedData := url.Values{}
+	postedData.Add("token", "not-a-token")
+
+	req, _ := http.NewRequest("POST", "/manage/cancel", strings.NewReader(postedData.Encode()))
+	ctx := getContext(req)
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Repo.PostManageCancel)
+	handler.ServeHTTP(rr, req)
+
+	actualLoc, _ := rr.Result().Location()
+	if rr.Code != http.StatusSeeOther || actualLoc.String() != "/" {
+		t.Errorf("expected a redirect home, but got code %d and location %s", rr.Code, actualLoc.String())
+	}
+}
+
+var adminPostResendEmailTests = []struct {
+	name                 string
+	emailID              string
+	expectedResponseCode int
+}{
+	{"resend", "1", http.StatusSeeOther},
+	{"database-update-fails", "2", http.StatusInternalServerError},
+	{"invalid-id", "fish", http.StatusInternalServerError},
+}
+
+func TestAdminPostResendEmail(t *testing.T) {
+	for _, e := range adminPostResendEmailTests {
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/admin/outbox/%s/resend", e.emailID), nil)
+		ctx := getContext(req)
+
+		routeContext := chi.NewRouteContext()
+		routeContext.URLParams.Add("id", e.emailID)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, routeContext)
+		req = req.WithContext(ctx)
+
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.AdminPostResendEmail)
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != e.expectedResponseCode {
+			t.Errorf("failed %s: expected code %d, but got %d", e.name, e.expectedResponseCode, rr.Code)
+		}
+
+		if e.expectedResponseCode == http.StatusSeeOther {
+			actualLoc, _ := rr.Result().Location()
+			if actualLoc.String() != "/admin/outbox" {
+				t.Errorf("failed %s: expected location /admin/outbox, but got location %s", e.name, actualLoc.String())
+			}
+		}
+	}
+}
+
+// recordingMailer keeps the messages it is asked to send, failing with err when it is set
+type recordingMailer struct {
+	sent []models.MailData
+	err  error
+}
+
+func (r *recordingMailer) Send(ctx context.Context, m models.MailData) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.sent = append(r.sent, m)
+	return nil
+}
+
+var adminPostTestEmailTests = []struct {
+	name             string
+	template         string
+	mailerErr        error
+	expectedLocation string
+	expectedFlash    string
+	expectedError    string
+}{
+	{"sent", "booking-confirmation", nil, "/admin/emails/booking-confirmation", "A test copy was sent to admin@musiqcity.com", ""},
+	{"mailer-fails", "booking-confirmation", errors.New("connection refused"), "/admin/emails/booking-confirmation", "", "Unable to send the test email: connection refused"},
+	{"unknown-template", "nope", nil, "/admin/emails", "", "That email template does not exist"},
+}
+
+func TestAdminPostTestEmail(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	defer func(m mailer.Mailer) { app.Mailer = m }(app.Mailer)
+
+	for _, e := range adminPostTestEmailTests {
+		testMailer := &recordingMailer{err: e.mailerErr}
+		app.Mailer = testMailer
+
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/admin/emails/%s/send", e.template), nil)
+		ctx := getContext(req)
+		session.Put(ctx, "user_id", 1)
+
+		routeContext := chi.NewRouteContext()
+		routeContext.URLParams.Add("name", e.template)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, routeContext)
+		req = req.WithContext(ctx)
+
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(memoryRepo.AdminPostTestEmail)
+		handler.ServeHTTP(rr, req)
+
+		actualLoc, _ := rr.Result().Location()
+		if rr.Code != http.StatusSeeOther || actualLoc.String() != e.expectedLocation {
+			t.Errorf("failed %s: expected a redirect to %s, but got code %d and location %s", e.name, e.expectedLocation, rr.Code, actualLoc)
+		}
+
+		if flash := session.GetString(ctx, "flash"); flash != e.expectedFlash {
+			t.Errorf("failed %s: expected flash %q, but got %q", e.name, e.expectedFlash, flash)
+		}
+		if errorMessage := session.GetString(ctx, "error"); errorMessage != e.expectedError {
+			t.Errorf("failed %s: expected error %q, but got %q", e.name, e.expectedError, errorMessage)
+		}
+
+		if e.expectedFlash != "" {
+			if len(testMailer.sent) != 1 || testMailer.sent[0].To != "admin@musiqcity.com" || testMailer.sent[0].Subject != "[Test] Booking Confirmation" {
+				t.Errorf("failed %s: expected one test copy to the admin, but got %+v", e.name, testMailer.sent)
+			}
+		}
+	}
+}
+
+var adminPostSettingsTests = []struct {
+	name                 string
+	postedData           url.Values
+	expectedResponseCode int
+	expectedHTML         string
+	expectedSender       string
+	expectedRecipients   []string
+}{
+	{
+		name: "valid",
+		postedData: url.Values{
+			"sender_name":                      {"MusiqCity Bookings"},
+			"sender_email":                     {"bookings@musiqcity.com"},
+			"reply_to":                         {"support@musiqcity.com"},
+			"admin_recipients_new_booking":     {"ops@musiqcity.com\r\nowner@musiqcity.com"},
+			"admin_recipients_new_reservation": {"ops@musiqcity.com"},
+			"admin_recipients_new_listing":     {""},
+		},
+		expectedResponseCode: http.StatusSeeOther,
+		expectedSender:       "bookings@musiqcity.com",
+		expectedRecipients:   []string{"ops@musiqcity.com", "owner@musiqcity.com"},
+	},
+	{
+		name: "invalid-recipient",
+		postedData: url.Values{
+			"sender_name":                  {"MusiqCity"},
+			"sender_email":                 {"bookings@musiqcity.com"},
+			"admin_recipients_new_booking": {"ops@musiqcity.com, ops"},
+		},
+		expectedResponseCode: http.StatusOK,
+		expectedHTML:         "is not a valid email address",
+		expectedSender:       settings.Defaults().SenderEmail,
+		expectedRecipients:   settings.Defaults().Recipients(settings.EventNewBooking),
+	},
+	{
+		name: "missing-sender",
+		postedData: url.Values{
+			"sender_name": {"MusiqCity"},
+		},
+		expectedResponseCode: http.StatusOK,
+		expectedHTML:         "This field is required",
+		expectedSender:       settings.Defaults().SenderEmail,
+		expectedRecipients:   settings.Defaults().Recipients(settings.EventNewBooking),
+	},
+	{
+		name: "database-update-fails",
+		postedData: url.Values{
+			"sender_name":  {"fail"},
+			"sender_email": {"bookings@musiqcity.com"},
+		},
+		expectedResponseCode: http.StatusInternalServerError,
+		expectedSender:       settings.Defaults().SenderEmail,
+		expectedRecipients:   settings.Defaults().Recipients(settings.EventNewBooking),
+	},
+}
+
+func TestAdminPostSettings(t *testing.T) {
+	defer app.Settings.Set(settings.Defaults())
+
+	for _, e := range adminPostSettingsTests {
+		app.Settings.Set(settings.Defaults())
+
+		req, _ := http.NewRequest("POST", "/admin/settings", strings.NewReader(e.postedData.Encode()))
+		ctx := getContext(req)
+		req = req.WithContext(ctx)
+
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.AdminPostSettings)
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != e.expectedResponseCode {
+			t.Errorf("failed %s: expected code %d, but got %d", e.name, e.expectedResponseCode, rr.Code)
+		}
+
+		if e.expectedHTML != "" && !strings.Contains(rr.Body.String(), e.expectedHTML) {
+			t.Errorf("failed %s: expected to find %q in the response", e.name, e.expectedHTML)
+		}
+
+		current := app.Settings.Get()
+		if current.SenderEmail != e.expectedSender {
+			t.Errorf("failed %s: expected the sender %s, but got %s", e.name, e.expectedSender, current.SenderEmail)
+		}
+		if !reflect.DeepEqual(current.Recipients(settings.EventNewBooking), e.expectedRecipients) {
+			t.Errorf("failed %s: expected the booking recipients %v, but got %v", e.name, e.expectedRecipients, current.Recipients(settings.EventNewBooking))
+		}
+	}
+}
+
+func TestAdminNotificationRecipients(t *testing.T) {
+	defer app.Settings.Set(settings.Defaults())
+
+	current := settings.Defaults()
+	current.SenderName = "MusiqCity Bookings"
+	current.ReplyTo = "support@musiqcity.com"
+	current.AdminRecipients[settings.EventNewBooking] = []string{"ops@musiqcity.com", "owner@musiqcity.com"}
+	app.Settings.Set(current)
+
+	memoryRepo := NewMemoryRepo(&app)
+	booking := models.Bookings{FirstName: "Ama", Email: "ama@example.com", StartDate: time.Now(), EndDate: time.Now()}
+
+	err := memoryRepo.enqueueEmails(context.Background(), memoryRepo.DB, bookingEmails(booking, models.Artist{Name: "DJ Kofi"})...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queued, _ := memoryRepo.DB.AllOutboxEmails(context.Background(), "")
+	var recipients []string
+	for _, email := range queued {
+		recipients = append(recipients, email.To)
+		if email.From != current.SenderEmail || email.FromName != "MusiqCity Bookings" || email.ReplyTo != "support@musiqcity.com" {
+			t.Errorf("expected the sender from the settings, but got %s <%s> replying to %s", email.FromName, email.From, email.ReplyTo)
+		}
+	}
+
+	// newest first
+	expected := []string{"owner@musiqcity.com", "ops@musiqcity.com", "ama@example.com"}
+	if !reflect.DeepEqual(recipients, expected) {
+		t.Errorf("expected the emails to go to %v, but got %v", expected, recipients)
+	}
+}
+
+// postForm posts data to handler with a new session and returns the response and the session context
+func postForm(handler http.HandlerFunc, target string, data url.Values) (*httptest.ResponseRecorder, context.Context) {
+	req, _ := http.NewRequest("POST", target, strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.RemoteAddr = "192.0.2.1:1234"
+	ctx := getContext(req)
+	req = req.WithContext(ctx)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	return rr, ctx
+}
+
+// linkToken returns the token of the link to path in the newest email made from template in the outbox
+func linkToken(t *testing.T, repo *Repository, template, path string) string {
+	queued, err := repo.DB.AllOutboxEmails(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var newest models.OutboxEmail
+	for _, email := range queued {
+		if email.Template == template && email.ID > newest.ID {
+			newest = email
+		}
+	}
+
+	_, link, found := strings.Cut(newest.Text, path+"?token=")
+	if !found {
+		t.Fatalf("expected a %s email with a link to %s in the outbox", template, path)
+	}
+
+	token, err := url.QueryUnescape(strings.Fields(link)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestPasswordReset(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	ctx := context.Background()
+
+	// a session the admin is logged in with somewhere else
+	otherCtx, _ := session.Load(ctx, "")
+	session.Put(otherCtx, "user_id", 1)
+	otherSession, _, err := session.Commit(otherCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr, sessionCtx := postForm(memoryRepo.PostForgotPassword, "/user/forgot-password", url.Values{"email": {dbrepo.MemoryAdminEmail}})
+	if location, _ := rr.Result().Location(); rr.Code != http.StatusSeeOther || location.String() != "/user/login" {
+		t.Fatalf("expected a redirect to /user/login but got code %d and location %v", rr.Code, location)
+	}
+	if flash := session.GetString(sessionCtx, "flash"); !strings.HasPrefix(flash, "If an account exists") {
+		t.Errorf("expected the generic confirmation but got %q", flash)
+	}
+
+	token := linkToken(t, memoryRepo, "password-reset", "/user/reset-password")
+
+	reset, err := memoryRepo.DB.GetPasswordReset(ctx, helpers.HashToken(token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reset.TokenHash == token {
+		t.Error("expected the token to be stored hashed")
+	}
+
+	req, _ := http.NewRequest("GET", "/user/reset-password?token="+url.QueryEscape(token), nil)
+	req = req.WithContext(getContext(req))
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(memoryRepo.ResetPassword).ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected the reset page but got code %d", rr.Code)
+	}
+
+	rr, _ = postForm(memoryRepo.PostResetPassword, "/user/reset-password", url.Values{
+		"token":            {token},
+		"password":         {"new-password"},
+		"confirm_password": {"other-password"},
+	})
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "The passwords do not match") {
+		t.Errorf("expected the form again with a mismatch error but got code %d", rr.Code)
+	}
+
+	rr, _ = postForm(memoryRepo.PostResetPassword, "/user/reset-password", url.Values{
+		"token":            {token},
+		"password":         {"new-password"},
+		"confirm_password": {"new-password"},
+	})
+	if location, _ := rr.Result().Location(); rr.Code != http.StatusSeeOther || location.String() != "/user/login" {
+		t.Fatalf("expected a redirect to /user/login but got code %d and location %v", rr.Code, location)
+	}
+
+	if _, err := memoryRepo.DB.Authenticate(ctx, dbrepo.MemoryAdminEmail, "new-password"); err != nil {
+		t.Errorf("expected the new password to work but got %v", err)
+	}
+	if _, err := memoryRepo.DB.Authenticate(ctx, dbrepo.MemoryAdminEmail, dbrepo.MemoryAdminPassword); err == nil {
+		t.Error("expected the old password to stop working")
+	}
+
+	otherCtx, _ = session.Load(ctx, otherSession)
+	if session.Exists(otherCtx, "user_id") {
+		t.Error("expected the other session to be logged out")
+	}
+
+	rr, sessionCtx = postForm(memoryRepo.PostResetPassword, "/user/reset-password", url.Values{
+		"token":            {token},
+		"password":         {"another-password"},
+		"confirm_password": {"another-password"},
+	})
+	if location, _ := rr.Result().Location(); rr.Code != http.StatusSeeOther || location.String() != "/user/forgot-password" {
+		t.Errorf("expected a used token to be turned away but got code %d and location %v", rr.Code, location)
+	}
+	if errorMessage := session.GetString(sessionCtx, "error"); !strings.Contains(errorMessage, "invalid or has expired") {
+		t.Errorf("expected an invalid link error but got %q", errorMessage)
+	}
+}
+
+func TestPasswordResetUnknownEmail(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+
+	rr, sessionCtx := postForm(memoryRepo.PostForgotPassword, "/user/forgot-password", url.Values{"email": {"nobody@example.com"}})
+	if location, _ := rr.Result().Location(); rr.Code != http.StatusSeeOther || location.String() != "/user/login" {
+		t.Errorf("expected a redirect to /user/login but got code %d and location %v", rr.Code, location)
+	}
+	if flash := session.GetString(sessionCtx, "flash"); !strings.HasPrefix(flash, "If an account exists") {
+		t.Errorf("expected the same confirmation as for an account but got %q", flash)
+	}
+
+	queued, _ := memoryRepo.DB.AllOutboxEmails(context.Background(), "")
+	if len(queued) != 0 {
+		t.Errorf("expected no email but got %d", len(queued))
+	}
+}
+
+func TestPasswordResetRateLimits(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+
+	for i := 0; i < maxLinkEmails+1; i++ {
+		postForm(memoryRepo.PostForgotPassword, "/user/forgot-password", url.Values{"email": {dbrepo.MemoryAdminEmail}})
+	}
+
+	queued, _ := memoryRepo.DB.AllOutboxEmails(context.Background(), "")
+	if len(queued) != maxLinkEmails {
+		t.Errorf("expected %d reset emails an hour but got %d", maxLinkEmails, len(queued))
+	}
+
+	for i := maxLinkEmails + 1; i < linkRequestLimit; i++ {
+		postForm(memoryRepo.PostForgotPassword, "/user/forgot-password", url.Values{"email": {"nobody@example.com"}})
+	}
+
+	rr, sessionCtx := postForm(memoryRepo.PostForgotPassword, "/user/forgot-password", url.Values{"email": {"nobody@example.com"}})
+	if location, _ := rr.Result().Location(); rr.Code != http.StatusSeeOther || location.String() != "/user/forgot-password" {
+		t.Errorf("expected the address to be turned away but got code %d and location %v", rr.Code, location)
+	}
+	if errorMessage := session.GetString(sessionCtx, "error"); !strings.HasPrefix(errorMessage, "Too many password reset requests") {
+		t.Errorf("expected a rate limit error but got %q", errorMessage)
+	}
+}
+
+// getWithToken requests target with token in the query and returns the response and the session context
+func getWithToken(handler http.HandlerFunc, target, token string) (*httptest.ResponseRecorder, context.Context) {
+	req, _ := http.NewRequest("GET", target+"?token="+url.QueryEscape(token), nil)
+	ctx := getContext(req)
+	req = req.WithContext(ctx)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	return rr, ctx
+}
+
+func TestEmailVerification(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	login := url.Values{"email": {"kwame@example.com"}, "password": {"secret-password"}}
+
+	rr, _ := postForm(memoryRepo.PostSignup, "/user/signup", url.Values{
+		"first_name": {"Kwame"},
+		"last_name":  {"Mensah"},
+		"email":      {"kwame@example.com"},
+		"password":   {"secret-password"},
+	})
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected the signup to redirect but got code %d", rr.Code)
+	}
+	firstToken := linkToken(t, memoryRepo, "verify-email", "/verify-email")
+
+	rr, sessionCtx := postForm(memoryRepo.PostLogin, "/user/login", login)
+	if location, _ := rr.Result().Location(); location.String() != "/user/resend-verification" || session.Exists(sessionCtx, "user_id") {
+		t.Errorf("expected an unverified user to be kept out but got location %v", location)
+	}
+
+	rr, _ = postForm(memoryRepo.PostResendVerification, "/user/resend-verification", url.Values{"email": {"kwame@example.com"}})
+	if location, _ := rr.Result().Location(); location.String() != "/user/login" {
+		t.Errorf("expected a redirect to /user/login but got %v", location)
+	}
+	secondToken := linkToken(t, memoryRepo, "verify-email", "/verify-email")
+	if secondToken == firstToken {
+		t.Fatal("expected a new verification link")
+	}
+
+	rr, sessionCtx = getWithToken(memoryRepo.VerifyUserEmail, "/verify-email", firstToken)
+	if location, _ := rr.Result().Location(); location.String() != "/user/resend-verification" {
+		t.Errorf("expected the replaced link to be turned away but got %v", location)
+	}
+	if errorMessage := session.GetString(sessionCtx, "error"); !strings.Contains(errorMessage, "invalid or has expired") {
+		t.Errorf("expected an invalid link error but got %q", errorMessage)
+	}
+
+	rr, _ = getWithToken(memoryRepo.VerifyUserEmail, "/verify-email", secondToken)
+	if location, _ := rr.Result().Location(); location.String() != "/user/login" {
+		t.Errorf("expected a redirect to /user/login but got %v", location)
+	}
+
+	user, err := memoryRepo.DB.GetUserByEmail(context.Background(), "kwame@example.com")
+	if err != nil || !user.Verified || user.Role != roles.Customer {
+		t.Errorf("expected a verified customer but got %+v, %v", user, err)
+	}
+
+	queued, _ := memoryRepo.DB.AllOutboxEmails(context.Background(), "")
+	verified := 0
+	for _, email := range queued {
+		if email.Template == "email-verified" {
+			verified++
+		}
+	}
+	if verified != 1 {
+		t.Errorf("expected one email-verified email but got %d", verified)
+	}
+
+	rr, _ = getWithToken(memoryRepo.VerifyUserEmail, "/verify-email", secondToken)
+	if location, _ := rr.Result().Location(); location.String() != "/user/resend-verification" {
+		t.Errorf("expected a used link to be turned away but got %v", location)
+	}
+
+	rr, sessionCtx = postForm(memoryRepo.PostLogin, "/user/login", login)
+	if location, _ := rr.Result().Location(); location.String() != "/" || session.GetInt(sessionCtx, "user_id") != user.ID {
+		t.Errorf("expected the verified user to be logged in but got location %v", location)
+	}
+}
+
+func TestResendVerificationVerifiedUser(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+
+	rr, sessionCtx := postForm(memoryRepo.PostResendVerification, "/user/resend-verification", url.Values{"email": {dbrepo.MemoryAdminEmail}})
+	if location, _ := rr.Result().Location(); location.String() != "/user/login" {
+		t.Errorf("expected a redirect to /user/login but got %v", location)
+	}
+	if flash := session.GetString(sessionCtx, "flash"); !strings.Contains(flash, "expires in 48 hours") {
+		t.Errorf("expected the confirmation to say when the link expires but got %q", flash)
+	}
+
+	queued, _ := memoryRepo.DB.AllOutboxEmails(context.Background(), "")
+	if len(queued) != 0 {
+		t.Errorf("expected no email for a verified user but got %d", len(queued))
+	}
+}
+
+// postAdminUserForm posts data to an admin user handler for user id, as the seeded admin
+func postAdminUserForm(handler http.HandlerFunc, id int, data url.Values) (*httptest.ResponseRecorder, context.Context) {
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/admin/users/%d", id), strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	ctx := getContext(req)
+	session.Put(ctx, "user_id", 1)
+
+	routeContext := chi.NewRouteContext()
+	routeContext.URLParams.Add("id", strconv.Itoa(id))
+	req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, routeContext))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	return rr, ctx
+}
+
+func TestAdminUsers(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+
+	req, _ := http.NewRequest("GET", "/admin/users?q=STAFF", nil)
+	req = req.WithContext(getContext(req))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(memoryRepo.AdminUsers).ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+	if rr.Code != http.StatusOK || !strings.Contains(body, dbrepo.MemoryStaffEmail) || strings.Contains(body, dbrepo.MemoryAdminEmail) {
+		t.Errorf("expected only the staff user to match the search but got code %d", rr.Code)
+	}
+
+	req, _ = http.NewRequest("GET", "/admin/users/2", nil)
+	routeContext := chi.NewRouteContext()
+	routeContext.URLParams.Add("id", "2")
+	req = req.WithContext(context.WithValue(getContext(req), chi.RouteCtxKey, routeContext))
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(memoryRepo.AdminShowUser).ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Staff User") {
+		t.Errorf("expected the staff user's page but got code %d", rr.Code)
+	}
+
+	// the page lists what is linked to the account, not the guest bookings that only share its email
+	ctx := context.Background()
+	start := time.Now().AddDate(0, 2, 0)
+	guestID, _ := memoryRepo.DB.InsertBooking(ctx, models.Bookings{ArtistID: 1, Email: dbrepo.MemoryStaffEmail, StartDate: start, EndDate: start})
+	linkedID, _ := memoryRepo.DB.InsertBooking(ctx, models.Bookings{ArtistID: 2, UserID: 2, Email: "other@example.com", StartDate: start, EndDate: start})
+	reservationID, _ := memoryRepo.DB.InsertReservation(ctx, models.Reservation{RoomID: 1, UserID: 2, Email: "other@example.com", StartDate: start, EndDate: start.AddDate(0, 0, 1)})
+
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(memoryRepo.AdminShowUser).ServeHTTP(rr, req)
+	body = rr.Body.String()
+	if strings.Contains(body, fmt.Sprintf("/admin/bookings/all/%d/show", guestID)) {
+		t.Error("expected an unclaimed guest booking with the same email to be left out")
+	}
+	if !strings.Contains(body, fmt.Sprintf("/admin/bookings/all/%d/show", linkedID)) {
+		t.Error("expected the booking linked to the account to be listed")
+	}
+	if !strings.Contains(body, fmt.Sprintf("/admin/reservations/all/%d/show", reservationID)) {
+		t.Error("expected the room reservation linked to the account to be listed")
+	}
+
+	rr, _ = postAdminUserForm(memoryRepo.AdminShowUser, 99, nil)
+	if location, _ := rr.Result().Location(); location.String() != "/admin/users" {
+		t.Errorf("expected an unknown user to redirect to the users page but got %v", location)
+	}
+}
+
+func TestAdminPostUserRole(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	ctx := context.Background()
+
+	// a session the staff user is logged in with
+	staffCtx, _ := session.Load(ctx, "")
+	session.Put(staffCtx, "user_id", 2)
+	staffSession, _, err := session.Commit(staffCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, sessionCtx := postAdminUserForm(memoryRepo.AdminPostUserRole, 2, url.Values{"role": {"owner"}})
+	if session.GetString(sessionCtx, "error") == "" {
+		t.Error("expected an unknown role to be turned away")
+	}
+
+	_, sessionCtx = postAdminUserForm(memoryRepo.AdminPostUserRole, 2, url.Values{"role": {roles.ArtistManager}})
+	if flash := session.GetString(sessionCtx, "flash"); flash != "Staff User is now Artist Manager" {
+		t.Errorf("unexpected flash %q", flash)
+	}
+	if user, _ := memoryRepo.DB.GetUserByID(ctx, 2); user.Role != roles.ArtistManager {
+		t.Errorf("expected the role to change but got %q", user.Role)
+	}
+
+	staffCtx, _ = session.Load(ctx, staffSession)
+	if session.Exists(staffCtx, "user_id") {
+		t.Error("expected the user to be logged out so the new role applies")
+	}
+
+	_, sessionCtx = postAdminUserForm(memoryRepo.AdminPostUserRole, 1, url.Values{"role": {roles.Customer}})
+	if session.GetString(sessionCtx, "error") == "" {
+		t.Error("expected admins to be stopped from changing their own role")
+	}
+	if admin, _ := memoryRepo.DB.GetUserByID(ctx, 1); admin.Role != roles.Admin {
+		t.Errorf("expected the admin to keep their role but got %q", admin.Role)
+	}
+}
+
+func TestAdminUserAccess(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	login := url.Values{"email": {dbrepo.MemoryStaffEmail}, "password": {dbrepo.MemoryAdminPassword}}
+
+	// loginError returns the error a login as the staff user ends with, empty when they are logged in
+	loginError := func() string {
+		rr, sessionCtx := postForm(memoryRepo.PostLogin, "/user/login", login)
+		if location, _ := rr.Result().Location(); location.String() == "/" {
+			return ""
+		}
+		return session.GetString(sessionCtx, "error")
+	}
+
+	postAdminUserForm(memoryRepo.AdminPostUserDisabled, 2, url.Values{"disabled": {"true"}})
+	if message := loginError(); !strings.Contains(message, "disabled") {
+		t.Errorf("expected a disabled account to be turned away but got %q", message)
+	}
+
+	postAdminUserForm(memoryRepo.AdminPostUserDisabled, 2, url.Values{"disabled": {"false"}})
+	if message := loginError(); message != "" {
+		t.Errorf("expected an enabled account to log in but got %q", message)
+	}
+
+	_, sessionCtx := postAdminUserForm(memoryRepo.AdminPostUserLock, 2, url.Values{"hours": {"5"}})
+	if session.GetString(sessionCtx, "error") == "" {
+		t.Error("expected a lock length that isn't offered to be turned away")
+	}
+
+	postAdminUserForm(memoryRepo.AdminPostUserLock, 2, url.Values{"hours": {"24"}})
+	if message := loginError(); !strings.Contains(message, "locked until") {
+		t.Errorf("expected a locked account to be turned away but got %q", message)
+	}
+
+	postAdminUserForm(memoryRepo.AdminPostUserLock, 2, url.Values{"hours": {"0"}})
+	if message := loginError(); message != "" {
+		t.Errorf("expected an unlocked account to log in but got %q", message)
+	}
+
+	_, sessionCtx = postAdminUserForm(memoryRepo.AdminPostUserDisabled, 1, url.Values{"disabled": {"true"}})
+	if admin, _ := memoryRepo.DB.GetUserByID(context.Background(), 1); admin.Disabled() || session.GetString(sessionCtx, "error") == "" {
+		t.Error("expected admins to be stopped from disabling themselves")
+	}
+}
+
+func TestAdminPostUserPasswordReset(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	ctx := context.Background()
+
+	postAdminUserForm(memoryRepo.AdminPostUserPasswordReset, 2, nil)
+
+	if _, err := memoryRepo.DB.Authenticate(ctx, dbrepo.MemoryStaffEmail, dbrepo.MemoryAdminPassword); err == nil {
+		t.Error("expected the old password to stop working")
+	}
+
+	token := linkToken(t, memoryRepo, "password-reset", "/user/reset-password")
+	rr, _ := postForm(memoryRepo.PostResetPassword, "/user/reset-password", url.Values{
+		"token":            {token},
+		"password":         {"new-password"},
+		"confirm_password": {"new-password"},
+	})
+	if location, _ := rr.Result().Location(); location.String() != "/user/login" {
+		t.Fatalf("expected the emailed link to reset the password but got %v", location)
+	}
+
+	if _, err := memoryRepo.DB.Authenticate(ctx, dbrepo.MemoryStaffEmail, "new-password"); err != nil {
+		t.Errorf("expected the new password to work but got %v", err)
+	}
+}
+
+// userArtistRequest sends a request to an artist handler as userID, with the url params of the route
+func userArtistRequest(handler http.HandlerFunc, method string, userID int, params map[string]string, data url.Values) (*httptest.ResponseRecorder, context.Context) {
+	req, _ := http.NewRequest(method, "/dashboard/artist", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	ctx := getContext(req)
+	session.Put(ctx, "user_id", userID)
+
+	routeContext := chi.NewRouteContext()
+	for key, value := range params {
+		routeContext.URLParams.Add(key, value)
+	}
+	req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, routeContext))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	return rr, ctx
+}
+
+var artistFormData = url.Values{
+	"artist_name": {"The Accra Choir"},
+	"genres":      {"3", "6"},
+	"description": {"A twenty voice choir for weddings and services."},
+	"phone":       {"+233 200 000 0009"},
+	"email":       {"choir@example.com"},
+	"city":        {"Accra"},
+}
+
+func TestPostListService(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	ctx := context.Background()
+
+	rr, _ := userArtistRequest(memoryRepo.PostListService, "POST", 2, nil, url.Values{"artist_name": {"x"}})
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "is-invalid") {
+		t.Errorf("expected an invalid listing to show the form again but got code %d", rr.Code)
+	}
+
+	rr, _ = userArtistRequest(memoryRepo.PostListService, "POST", 2, nil, artistFormData)
+	if location, _ := rr.Result().Location(); rr.Code != http.StatusSeeOther || location.String() != "/dashboard/artist" {
+		t.Fatalf("expected a redirect to the user's artists but got code %d", rr.Code)
+	}
+
+	artists, err := memoryRepo.DB.AllArtistsByUserID(ctx, 2)
+	if err != nil || len(artists) != 1 {
+		t.Fatalf("expected the listing to belong to the user but got %d artists, %v", len(artists), err)
+	}
+	if artists[0].Name != "The Accra Choir" || artists[0].Status != listing.Pending {
+		t.Errorf("expected a pending listing but got %q, %q", artists[0].Name, artists[0].Status)
+	}
+
+	queued, _ := memoryRepo.DB.AllOutboxEmails(ctx, "")
+	if len(queued) == 0 {
+		t.Error("expected the admins to be notified about the listing")
+	}
+
+	draft := url.Values{"draft": {"true"}}
+	for key, value := range artistFormData {
+		draft[key] = value
+	}
+	userArtistRequest(memoryRepo.PostListService, "POST", 2, nil, draft)
+	if artists, _ = memoryRepo.DB.AllArtistsByUserID(ctx, 2); len(artists) != 2 || artists[1].Status != listing.Draft {
+		t.Errorf("expected the second listing to be kept as a draft but got %+v", artists)
+	}
+	if drafted, _ := memoryRepo.DB.AllOutboxEmails(ctx, ""); len(drafted) != len(queued) {
+		t.Error("expected no notification for a draft")
+	}
+}
+
+func TestUserArtist(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	ctx := context.Background()
+	kofi := map[string]string{"id": "3"}
+
+	rr, _ := userArtistRequest(memoryRepo.UserArtists, "GET", 3, nil, nil)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "DJ Kofi") {
+		t.Errorf("expected the owner's artists but got code %d", rr.Code)
+	}
+
+	// only the owner can see or change the artist
+	for _, handler := range []http.HandlerFunc{memoryRepo.UserArtist, memoryRepo.PostUserArtist, memoryRepo.UserArtistOptions, memoryRepo.UserArtistAvailability} {
+		rr, sessionCtx := userArtistRequest(handler, "POST", 1, kofi, artistFormData)
+		if location, _ := rr.Result().Location(); location == nil || location.String() != "/dashboard/artist" || session.GetString(sessionCtx, "error") == "" {
+			t.Errorf("expected someone else's artist to be turned away but got code %d", rr.Code)
+		}
+	}
+
+	rr, _ = userArtistRequest(memoryRepo.UserArtist, "GET", 3, kofi, nil)
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected the owner to see the profile but got code %d", rr.Code)
+	}
+
+	unknownGenre := url.Values{"genres": {"99"}}
+	for key, value := range artistFormData {
+		if key != "genres" {
+			unknownGenre[key] = value
+		}
+	}
+	rr, _ = userArtistRequest(memoryRepo.PostUserArtist, "POST", 3, kofi, unknownGenre)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Pick at least one genre") {
+		t.Errorf("expected a genre that doesn't exist to be turned away but got code %d", rr.Code)
+	}
+
+	userArtistRequest(memoryRepo.PostUserArtist, "POST", 3, kofi, artistFormData)
+	artist, _ := memoryRepo.DB.GetArtistByIDAnyStatus(ctx, 3)
+	if artist.Name != "The Accra Choir" || artist.UserID != 3 || artist.Status != listing.Pending {
+		t.Errorf("expected the profile to change, keep its owner and go back for review but got %q, %d, %q", artist.Name, artist.UserID, artist.Status)
+	}
+	if artist.Genres != "Classical, Jazz" || len(artist.GenreList) != 2 {
+		t.Errorf("expected the genres ticked but got %q", artist.Genres)
+	}
+
+	// saving an approved listing without changes leaves it approved
+	memoryRepo.DB.UpdateArtistStatus(ctx, 3, listing.Pending, listing.Approved, "")
+	userArtistRequest(memoryRepo.PostUserArtist, "POST", 3, kofi, artistFormData)
+	if artist, _ := memoryRepo.DB.GetArtistByIDAnyStatus(ctx, 3); artist.Status != listing.Approved {
+		t.Errorf("expected an unchanged listing to stay approved but got %q", artist.Status)
+	}
+}
+
+func TestUserArtistOptions(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	ctx := context.Background()
+	option := url.Values{"title": {"Wedding package"}, "price": {"900"}, "description": {"Ceremony and reception."}}
+
+	userArtistRequest(memoryRepo.PostUserArtistOptions, "POST", 3, map[string]string{"id": "3"}, option)
+	options, _ := memoryRepo.DB.AllArtistBookingOptions(ctx, 3)
+	if len(options) != 3 {
+		t.Fatalf("expected the new option to be added but got %d options", len(options))
+	}
+
+	userArtistRequest(memoryRepo.PostUserArtistOption, "POST", 3, map[string]string{"id": "3", "optionID": "5"}, option)
+	if updated, _ := memoryRepo.DB.GetBookingOptionByID(ctx, 5); updated.Title != "Wedding package" || updated.ArtistID != 3 {
+		t.Errorf("expected the option to change but got %q of artist %d", updated.Title, updated.ArtistID)
+	}
+
+	// option 1 belongs to another artist
+	rr, sessionCtx := userArtistRequest(memoryRepo.PostUserArtistOption, "POST", 3, map[string]string{"id": "3", "optionID": "1"}, option)
+	if location, _ := rr.Result().Location(); location.String() != "/dashboard/artist/3/options" || session.GetString(sessionCtx, "error") == "" {
+		t.Errorf("expected another artist's option to be turned away but got %v", location)
+	}
+	if other, _ := memoryRepo.DB.GetBookingOptionByID(ctx, 1); other.Title == "Wedding package" {
+		t.Error("expected another artist's option to be left alone")
+	}
+}
+
+func TestUserArtistAvailability(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	ctx := context.Background()
+	kofi := map[string]string{"id": "3"}
+	start := time.Now().AddDate(0, 2, 0)
+
+	_, sessionCtx := userArtistRequest(memoryRepo.PostUserArtistAvailability, "POST", 3, kofi, url.Values{
+		"block_start": {start.Format("2006-01-02")},
+		"reason":      {"Touring"},
+	})
+	if flash := session.GetString(sessionCtx, "flash"); flash != "1 block(s) added" {
+		t.Errorf("unexpected flash %q", flash)
+	}
+
+	restrictions, _ := memoryRepo.DB.GetRestrictionsForCurrentArtist(ctx, 3, start.AddDate(0, 0, -1), start.AddDate(0, 0, 1))
+	if len(restrictions) != 1 {
+		t.Fatalf("expected one block but got %d", len(restrictions))
+	}
+	blockID := strconv.Itoa(restrictions[0].ID)
+
+	rr, _ := userArtistRequest(memoryRepo.UserArtistAvailability, "GET", 3, kofi, nil)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Touring") {
+		t.Errorf("expected the block to be listed but got code %d", rr.Code)
+	}
+
+	// the block isn't one of artist 1's
+	_, sessionCtx = userArtistRequest(memoryRepo.PostUserDeleteArtistBlock, "POST", 3, map[string]string{"id": "1", "blockID": blockID}, nil)
+	if session.GetString(sessionCtx, "error") == "" {
+		t.Error("expected a block of someone else's artist to be turned away")
+	}
+
+	_, sessionCtx = userArtistRequest(memoryRepo.PostUserDeleteArtistBlock, "POST", 3, map[string]string{"id": "3", "blockID": blockID}, nil)
+	if flash := session.GetString(sessionCtx, "flash"); flash != "Block removed" {
+		t.Errorf("unexpected flash %q", flash)
+	}
+	if restrictions, _ := memoryRepo.DB.GetRestrictionsForCurrentArtist(ctx, 3, start.AddDate(0, 0, -1), start.AddDate(0, 0, 1)); len(restrictions) != 0 {
+		t.Errorf("expected the block to be removed but got %d", len(restrictions))
+	}
+}
+
+func TestArtistsPage(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+
+	var tests = []struct {
+		name     string
+		url      string
+		shown    []string
+		notShown []string
+	}{
+		{"everyone", "/artists", []string{"The Lagos Horns", "Ama Strings", "DJ Kofi", "3 artist(s) found"}, nil},
+		{"genre and city", "/artists?genre=afrobeat&city=lagos", []string{"The Lagos Horns", "DJ Kofi"}, []string{"Ama Strings"}},
+		{"text", "/artists?q=quartet", []string{"Ama Strings"}, []string{"DJ Kofi"}},
+		{"price band", "/artists?price=over-2500", []string{"No artists match your search"}, []string{"DJ Kofi"}},
+		{"invalid date is ignored", "/artists?date=soon", []string{"3 artist(s) found"}, nil},
+	}
+
+	for _, e := range tests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		req = req.WithContext(getContext(req))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(memoryRepo.ArtistsPage).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("%s: expected code 200 but got %d", e.name, rr.Code)
+			continue
+		}
+		for _, text := range e.shown {
+			if !strings.Contains(rr.Body.String(), text) {
+				t.Errorf("%s: expected %q on the page", e.name, text)
+			}
+		}
+		for _, text := range e.notShown {
+			if strings.Contains(rr.Body.String(), text) {
+				t.Errorf("%s: didn't expect %q on the page", e.name, text)
+			}
+		}
+	}
+
+	// a page past the last one, however far, goes to the last page
+	req, _ := http.NewRequest("GET", "/artists?city=lagos&page=9223372036854775807", nil)
+	req = req.WithContext(getContext(req))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(memoryRepo.ArtistsPage).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/artists?city=lagos&page=1" {
+		t.Errorf("expected a redirect to the last page but got %d to %q", rr.Code, rr.Header().Get("Location"))
+	}
+}
+
+func TestGenrePage(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+
+	rr, _ := userArtistRequest(memoryRepo.GenrePage, "GET", 0, map[string]string{"slug": "afrobeat"}, nil)
+	body := rr.Body.String()
+	if rr.Code != http.StatusOK || !strings.Contains(body, "DJ Kofi") || !strings.Contains(body, "Afrobeats") || strings.Contains(body, "Ama Strings") {
+		t.Errorf("expected the Afrobeat artists and aliases but got code %d", rr.Code)
+	}
+
+	rr, _ = userArtistRequest(memoryRepo.GenrePage, "GET", 0, map[string]string{"slug": "afro-beat"}, nil)
+	if location, _ := rr.Result().Location(); rr.Code != http.StatusMovedPermanently || location.String() != "/genres/afrobeat" {
+		t.Errorf("expected an alias to redirect to the genre but got code %d", rr.Code)
+	}
+
+	rr, sessionCtx := userArtistRequest(memoryRepo.GenrePage, "GET", 0, map[string]string{"slug": "polka"}, nil)
+	if location, _ := rr.Result().Location(); location == nil || location.String() != "/artists" || session.GetString(sessionCtx, "error") == "" {
+		t.Errorf("expected an unknown genre to redirect to the artists but got code %d", rr.Code)
+	}
+}
+
+func TestAdminGenres(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	ctx := context.Background()
+
+	rr, _ := userArtistRequest(memoryRepo.PostAdminGenres, "POST", 1, nil, url.Values{"name": {"hip-hop"}})
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "that genre or alias already exists") {
+		t.Errorf("expected another spelling of a genre to be turned away but got code %d", rr.Code)
+	}
+
+	userArtistRequest(memoryRepo.PostAdminGenres, "POST", 1, nil, url.Values{"name": {"Afro Pop"}, "aliases": {"Afropop, afro pop"}})
+	afroPop, err := memoryRepo.DB.GetGenreBySlug(ctx, "afropop")
+	if err != nil || afroPop.Name != "Afro Pop" || len(afroPop.Aliases) != 1 {
+		t.Fatalf("expected the genre to be added with one alias but got %+v, %v", afroPop, err)
+	}
+
+	userArtistRequest(memoryRepo.PostAdminGenre, "POST", 1, map[string]string{"id": "5"}, url.Values{"name": {"Hip-Hop"}, "aliases": {"Rap"}})
+	if artist, _ := memoryRepo.DB.GetArtistByID(ctx, 3); artist.Genres != "Afrobeat, Amapiano, Hip-Hop" {
+		t.Errorf("expected the artists to show the new name but got %q", artist.Genres)
+	}
+
+	id := strconv.Itoa(afroPop.ID)
+	rr, sessionCtx := userArtistRequest(memoryRepo.AdminPostMergeGenre, "POST", 1, map[string]string{"id": id}, url.Values{"into": {id}})
+	if session.GetString(sessionCtx, "error") == "" {
+		t.Errorf("expected a genre not to be merged into itself but got code %d", rr.Code)
+	}
+
+	userArtistRequest(memoryRepo.AdminPostMergeGenre, "POST", 1, map[string]string{"id": id}, url.Values{"into": {"1"}})
+	if genre, _ := memoryRepo.DB.GetGenreBySlug(ctx, "afro-pop"); genre.Name != "Afrobeat" {
+		t.Errorf("expected the merged genre to become an alias of Afrobeat but got %+v", genre)
+	}
+
+	userArtistRequest(memoryRepo.AdminPostDeleteGenre, "POST", 1, map[string]string{"id": "6"}, nil)
+	if artist, _ := memoryRepo.DB.GetArtistByID(ctx, 2); artist.Genres != "Classical" {
+		t.Errorf("expected the deleted genre to be taken off its artists but got %q", artist.Genres)
+	}
+}
+
+func TestSearchSuggest(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+
+	var tests = []struct {
+		q        string
+		expected []string
+	}{
+		{"horns", []string{"/artists/1"}},
+		{"afrobeat lagos", []string{"/artists/1", "/artists/3"}},
+		{"h", nil},
+		{"nobody", nil},
+	}
+
+	for _, e := range tests {
+		req, _ := http.NewRequest("GET", "/api/search/suggest?q="+url.QueryEscape(e.q), nil)
+		req = req.WithContext(getContext(req))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(memoryRepo.SearchSuggest).ServeHTTP(rr, req)
+
+		var j suggestJsonResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &j); err != nil {
+			t.Fatalf("%q: failed to parse json: %v", e.q, err)
+		}
+
+		var urls []string
+		for _, result := range j.Results {
+			urls = append(urls, result.URL)
+		}
+		if !j.Ok || !reflect.DeepEqual(urls, e.expected) {
+			t.Errorf("%q: expected %v but got %v", e.q, e.expected, urls)
+		}
+	}
+}
+
+func TestUserArtistBookings(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	ctx := context.Background()
+	start := time.Now().AddDate(0, 3, 0)
+
+	id, _ := memoryRepo.DB.InsertBooking(ctx, models.Bookings{
+		FirstName: "Ama",
+		LastName:  "Mensah",
+		Email:     "ama@example.com",
+		StartDate: start,
+		EndDate:   start,
+		ArtistID:  3,
+	})
+	booking := map[string]string{"id": "3", "bookingID": strconv.Itoa(id)}
+
+	rr, _ := userArtistRequest(memoryRepo.UserArtistBookings, "GET", 3, map[string]string{"id": "3"}, nil)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Ama Mensah") {
+		t.Errorf("expected the booking to be listed but got code %d", rr.Code)
+	}
+
+	// the seeded booking is for artist 1, so the owner of artist 3 can't answer it
+	_, sessionCtx := userArtistRequest(memoryRepo.PostUserArtistBookingStatus, "POST", 3, map[string]string{"id": "3", "bookingID": "1"}, url.Values{"decision": {"accept"}})
+	if session.GetString(sessionCtx, "error") != "That booking does not exist" {
+		t.Error("expected a booking of another artist to be turned away")
+	}
+
+	_, sessionCtx = userArtistRequest(memoryRepo.PostUserArtistBookingStatus, "POST", 1, booking, url.Values{"decision": {"accept"}})
+	if session.GetString(sessionCtx, "error") != "That artist does not exist" {
+		t.Error("expected someone who doesn't own the artist to be turned away")
+	}
+
+	_, sessionCtx = userArtistRequest(memoryRepo.PostUserArtistBookingStatus, "POST", 3, booking, url.Values{"decision": {"accept"}})
+	if flash := session.GetString(sessionCtx, "flash"); flash != fmt.Sprintf("Booking #%d was accepted", id) {
+		t.Errorf("unexpected flash %q", flash)
+	}
+
+	accepted, _ := memoryRepo.DB.GetBookingByID(ctx, id)
+	if accepted.Status != status.Confirmed {
+		t.Errorf("expected the booking to be confirmed but got %q", accepted.Status)
+	}
+
+	queued, _ := memoryRepo.DB.AllOutboxEmails(ctx, "")
+	emailed := false
+	for _, e := range queued {
+		emailed = emailed || e.To == "ama@example.com"
+	}
+	if !emailed {
+		t.Errorf("expected the customer to be emailed but got %+v", queued)
+	}
+
+	// a confirmed booking can't be declined from the dashboard
+	_, sessionCtx = userArtistRequest(memoryRepo.PostUserArtistBookingStatus, "POST", 3, booking, url.Values{"decision": {"decline"}})
+	if session.GetString(sessionCtx, "error") == "" {
+		t.Error("expected an answered booking not to be answered again")
+	}
+
+	rr, _ = userArtistRequest(memoryRepo.UserArtistAvailability, "GET", 3, map[string]string{"id": "3"}, nil)
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected the calendar to render but got code %d", rr.Code)
+	}
+}
+
+func TestUserBookings(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	ctx := context.Background()
+	start := time.Now().AddDate(0, 1, 0)
+
+	memoryRepo.DB.InsertBooking(ctx, models.Bookings{ArtistID: 2, BookingOptionID: 3, UserID: 3, StartDate: start, EndDate: start})
+	memoryRepo.DB.InsertBooking(ctx, models.Bookings{ArtistID: 2, UserID: 3, StartDate: time.Now().AddDate(0, -1, 0), EndDate: time.Now().AddDate(0, -1, 0)})
+	memoryRepo.DB.InsertBooking(ctx, models.Bookings{ArtistID: 1, UserID: 2, StartDate: start, EndDate: start})
+	memoryRepo.DB.InsertReservation(ctx, models.Reservation{RoomID: 1, UserID: 3, StartDate: start, EndDate: start.AddDate(0, 0, 1)})
+
+	rr, _ := userArtistRequest(memoryRepo.UserBookings, "GET", 3, nil, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200 but got %d", rr.Code)
+	}
+
+	body := rr.Body.String()
+	if strings.Count(body, "Ama Strings") != 2 || strings.Contains(body, "The Lagos Horns") {
+		t.Error("expected only the bookings of the logged in customer")
+	}
+	if !strings.Contains(body, "Room Reservations") || strings.Count(body, "Cancel or reschedule") != 2 {
+		t.Error("expected only the upcoming booking and reservation to be manageable")
+	}
+}
+
+func TestListingModeration(t *testing.T) {
+	memoryRepo := NewMemoryRepo(&app)
+	ctx := context.Background()
+
+	id, _ := memoryRepo.DB.CreateArtist(ctx, models.Artist{Name: "The Accra Choir", UserID: 3, Status: listing.Pending})
+	artist := map[string]string{"id": strconv.Itoa(id)}
+
+	// the public can't see the listing yet
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/artists/%d", id), nil)
+	req.RequestURI = fmt.Sprintf("/artists/%d", id)
+	req = req.WithContext(getContext(req))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(memoryRepo.SingleArtist).ServeHTTP(rr, req)
+	if location, _ := rr.Result().Location(); location == nil || location.String() != "/artists" {
+		t.Errorf("expected a pending artist to be hidden but got code %d", rr.Code)
+	}
+
+	rr, _ = userArtistRequest(memoryRepo.AdminPendingArtists, "GET", 1, nil, nil)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "The Accra Choir") || !strings.Contains(rr.Body.String(), dbrepo.MemoryArtistEmail) {
+		t.Errorf("expected the listing and its owner in the queue but got code %d", rr.Code)
+	}
+
+	_, sessionCtx := userArtistRequest(memoryRepo.AdminPostArtistStatus, "POST", 1, artist, url.Values{"status": {listing.Rejected}})
+	if session.GetString(sessionCtx, "error") == "" {
+		t.Error("expected a rejection without a reason to be turned away")
+	}
+
+	rr, _ = userArtistRequest(memoryRepo.AdminPostArtistStatus, "POST", 1, artist, url.Values{
+		"status": {listing.Rejected},
+		"reason": {"Please, add some photos."},
+		"queue":  {"true"},
+	})
+	if location, _ := rr.Result().Location(); location.String() != "/admin/artists/pending" {
+		t.Errorf("expected a redirect to the queue but got %v", location)
+	}
+
+	rejected, _ := memoryRepo.DB.GetArtistByIDAnyStatus(ctx, id)
+	if rejected.Status != listing.Rejected || rejected.StatusReason != "Please, add some photos." {
+		t.Errorf("expected the listing to be rejected with the reason but got %q, %q", rejected.Status, rejected.StatusReason)
+	}
+
+	queued, _ := memoryRepo.DB.AllOutboxEmails(ctx, "")
+	if len(queued) != 1 || queued[0].To != dbrepo.MemoryArtistEmail || !strings.Contains(queued[0].Text, "Please, add some photos.") {
+		t.Fatalf("expected the owner to be emailed the reason but got %+v", queued)
+	}
+
+	// the owner sends it again and it is approved
+	_, sessionCtx = userArtistRequest(memoryRepo.PostUserSubmitArtist, "POST", 3, artist, nil)
+	if flash := session.GetString(sessionCtx, "flash"); flash != "The Accra Choir was sent for review" {
+		t.Errorf("unexpected flash %q", flash)
+	}
+
+	_, sessionCtx = userArtistRequest(memoryRepo.PostUserSubmitArtist, "POST", 3, artist, nil)
+	if session.GetString(sessionCtx, "error") == "" {
+		t.Error("expected a pending listing not to be sent again")
+	}
+
+	userArtistRequest(memoryRepo.AdminPostArtistStatus, "POST", 1, artist, url.Values{"status": {listing.Approved}})
+	if approved, err := memoryRepo.DB.GetArtistByID(ctx, id); err != nil || approved.StatusReason != "" {
+		t.Errorf("expected the approved artist to be public but got %v, %q", err, approved.StatusReason)
+	}
+}
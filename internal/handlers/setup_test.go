@@ -12,11 +12,17 @@ import (
 	"time"
 
 	"github.com/aidisapp/musiqcity_v2/internal/config"
+	"github.com/aidisapp/musiqcity_v2/internal/emails"
+	"github.com/aidisapp/musiqcity_v2/internal/helpers"
+	"github.com/aidisapp/musiqcity_v2/internal/listing"
 	"github.com/aidisapp/musiqcity_v2/internal/models"
 	"github.com/aidisapp/musiqcity_v2/internal/render"
+	"github.com/aidisapp/musiqcity_v2/internal/roles"
+	"github.com/aidisapp/musiqcity_v2/internal/settings"
+	"github.com/aidisapp/musiqcity_v2/internal/status"
 	"github.com/alexedwards/scs/v2"
-	"github.com/go-chi/chi"
-	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/justinas/nosurf"
 )
 
@@ -25,10 +31,15 @@ var session *scs.SessionManager
 var pathToTemplates = "./../../templates"
 
 var functions = template.FuncMap{
-	"humanDate":  render.HumanDate,
-	"formatDate": render.FormatDate,
-	"iterate":    render.Iterate,
-	"add":        render.Add,
+	"humanDate":        render.HumanDate,
+	"formatDate":       render.FormatDate,
+	"iterate":          render.Iterate,
+	"add":              render.Add,
+	"truncate":         render.Truncate,
+	"statusLabel":      status.Label,
+	"roleLabel":        roles.Label,
+	"listingLabel":     listing.Label,
+	"canSubmitListing": listing.CanSubmit,
 }
 
 func TestMain(m *testing.M) {
@@ -38,10 +49,14 @@ func TestMain(m *testing.M) {
 	gob.Register(models.Room{})
 	gob.Register(models.Restriction{})
 	gob.Register(models.TodoList{})
+	gob.Register(models.Bookings{})
 	gob.Register(map[string]int{})
 
 	// change this to true when in production
 	app.InProduction = false
+	app.CancellationWindow = 48 * time.Hour
+	app.RescheduleWindow = 72 * time.Hour
+	app.ManageTokenSecret = []byte("a-test-secret-that-is-long-enough-to-sign-with")
 
 	infoLog := log.New(os.Stdout, "INFO\t", log.Ldate|log.Ltime)
 	app.InfoLog = infoLog
@@ -58,12 +73,6 @@ func TestMain(m *testing.M) {
 
 	app.Session = session
 
-	mailChannel := make(chan models.MailData)
-	app.MailChannel = mailChannel
-	defer close(mailChannel)
-
-	listenForMail()
-
 	tc, err := CreateTestTemplateCache()
 	if err != nil {
 		log.Fatal("cannot create template cache")
@@ -72,22 +81,23 @@ func TestMain(m *testing.M) {
 	app.TemplateCache = tc
 	app.UseCache = true
 
+	emailTemplates, err := emails.New("./../../email-template")
+	if err != nil {
+		log.Fatal("cannot parse the email templates: ", err)
+	}
+
+	app.Emails = emailTemplates
+	app.Settings = settings.NewCache(settings.Defaults())
+
 	repo := NewTestRepo(&app)
 	NewHandlers(repo)
 
 	render.NewRenderer(&app)
+	helpers.NewHelpers(&app)
 
 	os.Exit(m.Run())
 }
 
-func listenForMail() {
-	go func() {
-		for {
-			_ = <-app.MailChannel
-		}
-	}()
-}
-
 func getRoutes() http.Handler {
 	mux := chi.NewRouter()
 
@@ -106,12 +116,15 @@ func getRoutes() http.Handler {
 
 	mux.Get("/user/login", Repo.Login)
 	mux.Post("/user/login", Repo.PostLogin)
+	mux.Get("/user/forgot-password", Repo.ForgotPassword)
+	mux.Post("/user/forgot-password", Repo.PostForgotPassword)
+	mux.Get("/user/resend-verification", Repo.ResendVerification)
 	mux.Get("/user/logout", Repo.Logout)
 
-	mux.Get("/dashboard", Repo.AdminDashboard)
+	mux.Get("/admin/dashboard", Repo.AdminDashboard)
 
-	mux.Get("/reservations-calendar", Repo.AdminReservationsCalendar)
-	mux.Post("/reservations-calendar", Repo.AdminPostReservationsCalendar)
+	mux.Get("/admin/reservations-calendar", Repo.AdminReservationsCalendar)
+	mux.Post("/admin/reservations-calendar", Repo.AdminPostReservationsCalendar)
 
 	mux.Get("/admin/rooms", Repo.AdminAllRooms)
 	mux.Get("/admin/rooms/{id}", Repo.AdminSingleRoom)
@@ -120,15 +133,33 @@ func getRoutes() http.Handler {
 	mux.Post("/admin/rooms/new-room", Repo.PostAdminNewRoom)
 	mux.Get("/admin/delete-room/{id}", Repo.AdminDeleteRoom)
 
-	mux.Get("/admin/process-reservation/{src}/{id}/do", Repo.AdminProcessReservation)
+	mux.Get("/admin/reservations/{src}/{id}/show", Repo.AdminShowReservation)
+	mux.Post("/admin/reservations/{src}/{id}/status", Repo.AdminPostReservationStatus)
+	mux.Get("/admin/all-bookings", Repo.AdminAllBookings)
+	mux.Get("/admin/new-bookings", Repo.AdminNewBookings)
+	mux.Get("/admin/bookings/{src}/{id}/show", Repo.AdminShowBooking)
+	mux.Post("/admin/bookings/{src}/{id}/status", Repo.AdminPostBookingStatus)
 	mux.Get("/admin/delete-reservation/{src}/{id}/do", Repo.AdminDeleteReservation)
 
+	mux.Get("/admin/outbox", Repo.AdminOutbox)
+	mux.Post("/admin/outbox/{id}/resend", Repo.AdminPostResendEmail)
+
+	mux.Get("/admin/settings", Repo.AdminSettings)
+	mux.Post("/admin/settings", Repo.AdminPostSettings)
+
+	mux.Get("/admin/emails", Repo.AdminEmails)
+	mux.Get("/admin/emails/{name}", Repo.AdminEmailPreview)
+	mux.Post("/admin/emails/{name}/send", Repo.AdminPostTestEmail)
+
 	mux.Get("/admin/todo-list", Repo.AdminTodoList)
 	mux.Post("/admin/todo-list", Repo.PostAdminTodoList)
 	mux.Get("/admin/delete-todo/{id}", Repo.AdminDeleteTodo)
 
 	mux.Post("/admin/rooms/new-artist", Repo.PostAdminNewArtist)
 
+	mux.Get("/admin/artists-calendar", Repo.AdminArtistsCalendar)
+	mux.Post("/admin/artists-calendar", Repo.AdminPostArtistsCalendar)
+
 	fileServer := http.FileServer(http.Dir("./static/"))
 	mux.Handle("/static/*", http.StripPrefix("/static", fileServer))
 
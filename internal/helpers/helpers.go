@@ -1,16 +1,19 @@
 package helpers
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
-	"os"
 	"runtime/debug"
 	"time"
 
 	"github.com/aidisapp/musiqcity_v2/internal/config"
+	"github.com/aidisapp/musiqcity_v2/internal/roles"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/joho/godotenv"
 )
 
 var app *config.AppConfig
@@ -40,35 +43,111 @@ func IsAuthenticated(request *http.Request) bool {
 	return exists
 }
 
-// Check if a user is admin
-func IsAdmin(request *http.Request) bool {
-	accessLevel := app.Session.GetInt(request.Context(), "access_level")
-	return accessLevel == 3
+// Role returns the role of the logged in user, empty for guests
+func Role(request *http.Request) string {
+	return app.Session.GetString(request.Context(), "role")
 }
 
-func GenerateJWTToken(userID int) (string, error) {
-	err := godotenv.Load()
+// Check if the logged in user's role has permission
+func Can(request *http.Request, permission string) bool {
+	return roles.Can(Role(request), permission)
+}
+
+// Check if a user has verified their email
+func IsVerified(request *http.Request) bool {
+	return app.Session.GetBool(request.Context(), "verified")
+}
+
+// ErrInvalidManageToken is returned when a manage link has been tampered with or has expired
+var ErrInvalidManageToken = errors.New("this link is invalid or has expired")
+
+// MinManageTokenSecretLength is the shortest key manage links can be signed with
+const MinManageTokenSecretLength = 32
+
+// ErrWeakManageTokenSecret is returned when the key manage links are signed with is missing or too short
+var ErrWeakManageTokenSecret = fmt.Errorf("the manage link secret must be at least %d characters", MinManageTokenSecretLength)
+
+// CheckManageTokenSecret makes sure secret is long enough to sign manage links with
+func CheckManageTokenSecret(secret string) error {
+	if len(secret) < MinManageTokenSecretLength {
+		return ErrWeakManageTokenSecret
+	}
+
+	return nil
+}
+
+// GenerateManageToken signs a token that lets a customer cancel or reschedule one reservation or booking
+// without logging in. kind is either "reservation" or "booking"
+func GenerateManageToken(kind string, id int, expires time.Time) (string, error) {
+	secret, err := manageTokenSecret()
 	if err != nil {
-		log.Println("Error loading .env file")
+		return "", err
 	}
 
-	jwtToken := os.Getenv("JWTSECRET")
+	return signManageToken(secret, kind, id, expires)
+}
 
-	// Define the claims for the JWT token
+// signManageToken signs the claims of a manage token with secret
+func signManageToken(secret []byte, kind string, id int, expires time.Time) (string, error) {
 	claims := jwt.MapClaims{
-		"sub": userID,
-		"exp": time.Now().Add(time.Hour * 48).Unix(),
-		"iat": time.Now().Unix(),
+		"sub":     id,
+		"kind":    kind,
+		"purpose": "manage",
+		"exp":     expires.Unix(),
+		"iat":     time.Now().Unix(),
 	}
 
-	// Generate the JWT token
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	// Sign the token with a secret key
-	tokenString, err := token.SignedString([]byte(jwtToken))
-	if err != nil {
-		return "", err
+	return token.SignedString(secret)
+}
+
+// ParseManageToken verifies a token made by GenerateManageToken and returns the kind and id it was issued for
+func ParseManageToken(tokenString string) (string, int, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return manageTokenSecret()
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return "", 0, ErrInvalidManageToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != "manage" {
+		return "", 0, ErrInvalidManageToken
+	}
+
+	kind, _ := claims["kind"].(string)
+	id, _ := claims["sub"].(float64)
+	if kind == "" || id < 1 {
+		return "", 0, ErrInvalidManageToken
+	}
+
+	return kind, int(id), nil
+}
+
+// GenerateToken returns a random token for a password reset or email verification link, and the hash to store in its place
+func GenerateToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the hash a token made by GenerateToken is stored and looked up by
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// manageTokenSecret returns the key manage links are signed with, and never an empty or short one
+func manageTokenSecret() ([]byte, error) {
+	if err := CheckManageTokenSecret(string(app.ManageTokenSecret)); err != nil {
+		return nil, err
 	}
 
-	return tokenString, nil
+	return app.ManageTokenSecret, nil
 }
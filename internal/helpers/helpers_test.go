@@ -0,0 +1,74 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aidisapp/musiqcity_v2/internal/config"
+)
+
+func TestManageToken(t *testing.T) {
+	app = &config.AppConfig{ManageTokenSecret: []byte("the-secret-the-app-signs-manage-links-with")}
+
+	token, err := GenerateManageToken("booking", 7, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kind, id, err := ParseManageToken(token)
+	if err != nil || kind != "booking" || id != 7 {
+		t.Errorf("got %s %d %v, wanted booking 7", kind, id, err)
+	}
+
+	expired, err := GenerateManageToken("booking", 7, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := ParseManageToken(expired); err != ErrInvalidManageToken {
+		t.Errorf("expired token gave %v, wanted %v", err, ErrInvalidManageToken)
+	}
+}
+
+func TestManageTokenOtherKey(t *testing.T) {
+	var tests = []struct {
+		name   string
+		secret string
+	}{
+		{"different key", "someone-elses-secret-that-is-long-enough"},
+		{"empty key", ""},
+		{"short key", "short"},
+	}
+
+	for _, e := range tests {
+		// sign with the other key without going through the length check, the way an attacker would
+		forged, err := signManageToken([]byte(e.secret), "booking", 7, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		app = &config.AppConfig{ManageTokenSecret: []byte("the-secret-the-app-signs-manage-links-with")}
+		if _, _, err := ParseManageToken(forged); err != ErrInvalidManageToken {
+			t.Errorf("%s: token was accepted, got %v", e.name, err)
+		}
+	}
+}
+
+func TestManageTokenWeakSecret(t *testing.T) {
+	for _, secret := range []string{"", "short"} {
+		app = &config.AppConfig{ManageTokenSecret: []byte(secret)}
+
+		if _, err := GenerateManageToken("booking", 7, time.Now().Add(time.Hour)); err != ErrWeakManageTokenSecret {
+			t.Errorf("signing with %q gave %v, wanted %v", secret, err, ErrWeakManageTokenSecret)
+		}
+
+		forged, err := signManageToken([]byte(secret), "booking", 7, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, _, err := ParseManageToken(forged); err != ErrInvalidManageToken {
+			t.Errorf("token signed with %q was accepted, got %v", secret, err)
+		}
+	}
+}
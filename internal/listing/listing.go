@@ -0,0 +1,93 @@
+package listing
+
+import "errors"
+
+// States an artist listing can be in. Listings users submit wait for review and only approved ones are
+// shown to the public. The ones admins add are approved straight away
+const (
+	Draft     = "draft"
+	Pending   = "pending"
+	Approved  = "approved"
+	Rejected  = "rejected"
+	Suspended = "suspended"
+)
+
+var ErrUnknownStatus = errors.New("unknown listing status")
+var ErrInvalidTransition = errors.New("that listing status change is not allowed")
+
+// ErrStaleStatus is returned when the listing status changed in the database before the transition was saved
+var ErrStaleStatus = errors.New("the listing was changed by someone else, please reload and try again")
+
+// All lists every state, used for filters and select boxes
+var All = []string{Draft, Pending, Approved, Rejected, Suspended}
+
+var labels = map[string]string{
+	Draft:     "Draft",
+	Pending:   "Pending Review",
+	Approved:  "Approved",
+	Rejected:  "Rejected",
+	Suspended: "Suspended",
+}
+
+// transitions maps each state to the states it may move to. Owners send drafts and rejected listings for
+// review, admins do the rest
+var transitions = map[string][]string{
+	Draft:     {Pending},
+	Pending:   {Approved, Rejected},
+	Approved:  {Suspended},
+	Rejected:  {Pending},
+	Suspended: {Approved},
+}
+
+// Valid reports whether s is a known state
+func Valid(s string) bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// Label returns the human readable name of a state
+func Label(s string) string {
+	if label, ok := labels[s]; ok {
+		return label
+	}
+	return s
+}
+
+// Next returns the states a listing in state from may move to
+func Next(from string) []string {
+	return transitions[from]
+}
+
+// NeedsReason reports whether moving a listing to s needs a reason the owner is told about
+func NeedsReason(s string) bool {
+	return s == Rejected || s == Suspended
+}
+
+// CanSubmit reports whether the owner of a listing in state s can send it for review
+func CanSubmit(s string) bool {
+	return Transition(s, Pending) == nil
+}
+
+// AfterEdit returns the state a listing in state s moves to when its owner changes what the public sees.
+// An approved listing goes back for review, so that the changes are checked before they go live
+func AfterEdit(s string) string {
+	if s == Approved {
+		return Pending
+	}
+	return s
+}
+
+// Transition checks that moving from one state to another is allowed
+func Transition(from, to string) error {
+	if !Valid(from) || !Valid(to) {
+		return ErrUnknownStatus
+	}
+
+	for _, next := range transitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+
+	return ErrInvalidTransition
+}
@@ -0,0 +1,52 @@
+package listing
+
+import "testing"
+
+var transitionTests = []struct {
+	from     string
+	to       string
+	expected error
+}{
+	{Draft, Pending, nil},
+	{Pending, Approved, nil},
+	{Pending, Rejected, nil},
+	{Rejected, Pending, nil},
+	{Approved, Suspended, nil},
+	{Suspended, Approved, nil},
+	{Draft, Approved, ErrInvalidTransition},
+	{Rejected, Approved, ErrInvalidTransition},
+	{Approved, Pending, ErrInvalidTransition},
+	{Pending, Pending, ErrInvalidTransition},
+	{"live", Approved, ErrUnknownStatus},
+	{Pending, "live", ErrUnknownStatus},
+}
+
+func TestTransition(t *testing.T) {
+	for _, e := range transitionTests {
+		err := Transition(e.from, e.to)
+		if err != e.expected {
+			t.Errorf("%s -> %s: expected %v but got %v", e.from, e.to, e.expected, err)
+		}
+	}
+}
+
+func TestCanSubmit(t *testing.T) {
+	for _, s := range All {
+		expected := s == Draft || s == Rejected
+		if CanSubmit(s) != expected {
+			t.Errorf("%s: expected CanSubmit to be %v", s, expected)
+		}
+	}
+}
+
+func TestAfterEdit(t *testing.T) {
+	for _, s := range All {
+		expected := s
+		if s == Approved {
+			expected = Pending
+		}
+		if got := AfterEdit(s); got != expected {
+			t.Errorf("%s: expected %s but got %s", s, expected, got)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync/atomic"
+	"time"
+
+	"github.com/aidisapp/musiqcity_v2/internal/models"
+)
+
+// unsafeFileChars matches everything that shouldn't end up in an .eml file name
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9@._-]+`)
+
+// File writes every message to an .eml file instead of sending it. Mail clients open the files directly
+type File struct {
+	dir        string
+	senderName string
+	count      atomic.Int64
+}
+
+// NewFile returns a file mailer writing to dir, creating dir if it doesn't exist
+func NewFile(dir, senderName string) (*File, error) {
+	if dir == "" {
+		return nil, errors.New("the file mailer needs a directory")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &File{dir: dir, senderName: senderName}, nil
+}
+
+// Send writes one message to a new file named after the time and the recipient
+func (f *File) Send(ctx context.Context, m models.MailData) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	msg, err := message(m, f.senderName)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%03d-%s.eml", time.Now().Format("20060102-150405"), f.count.Add(1)%1000,
+		unsafeFileChars.ReplaceAllString(m.To, "_"))
+
+	return os.WriteFile(filepath.Join(f.dir, name), msg, 0o644)
+}
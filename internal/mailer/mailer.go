@@ -0,0 +1,173 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"time"
+
+	"github.com/aidisapp/musiqcity_v2/internal/models"
+)
+
+// Names of the mail backends New can build
+const (
+	BackendSendinblue = "sendinblue"
+	BackendSMTP       = "smtp"
+	BackendFile       = "file"
+)
+
+// ErrUnsafeStaging is returned by New when a staging mailer could reach real customers
+var ErrUnsafeStaging = errors.New("staging must use the file mailer or redirect all mail to one address")
+
+// Mailer sends one email
+type Mailer interface {
+	Send(ctx context.Context, m models.MailData) error
+}
+
+// Config selects and configures the mail backend
+type Config struct {
+	// Backend is one of BackendSendinblue, BackendSMTP or BackendFile
+	Backend string
+
+	// SenderName is shown next to the from address
+	SenderName string
+
+	SendinblueAPIKey string
+
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+
+	// Dir is where the file backend writes .eml files
+	Dir string
+
+	// RedirectTo, when set, receives every email instead of the real recipient
+	RedirectTo string
+
+	// Staging refuses any setup that could deliver mail to the real recipient
+	Staging bool
+}
+
+// New builds the mailer described by cfg. In staging every message goes to the file backend or to cfg.RedirectTo
+func New(cfg Config) (Mailer, error) {
+	if cfg.Staging && cfg.Backend != BackendFile && cfg.RedirectTo == "" {
+		return nil, ErrUnsafeStaging
+	}
+
+	var backend Mailer
+	var err error
+
+	switch cfg.Backend {
+	case BackendSendinblue:
+		backend, err = NewSendinblue(cfg.SendinblueAPIKey, cfg.SenderName)
+	case BackendSMTP:
+		backend, err = NewSMTP(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SenderName)
+	case BackendFile:
+		backend, err = NewFile(cfg.Dir, cfg.SenderName)
+	default:
+		err = fmt.Errorf("unknown mail backend %q", cfg.Backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.RedirectTo != "" {
+		return &redirectMailer{next: backend, to: cfg.RedirectTo}, nil
+	}
+
+	return backend, nil
+}
+
+// redirectMailer sends every message to one address, keeping the real recipient in the subject
+type redirectMailer struct {
+	next Mailer
+	to   string
+}
+
+func (r *redirectMailer) Send(ctx context.Context, m models.MailData) error {
+	m.Subject = fmt.Sprintf("[to %s] %s", m.To, m.Subject)
+	m.To = r.to
+
+	return r.next.Send(ctx, m)
+}
+
+// message builds the RFC 5322 message the SMTP and file backends write. A message with a plain-text body
+// is sent as multipart/alternative, so mail clients pick the part they can show
+func message(m models.MailData, senderName string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	from := m.From
+	if name := fromName(m, senderName); name != "" {
+		from = fmt.Sprintf("%s <%s>", mime.QEncoding.Encode("utf-8", name), m.From)
+	}
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", m.To)
+	if m.ReplyTo != "" {
+		fmt.Fprintf(&buf, "Reply-To: %s\r\n", m.ReplyTo)
+	}
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if m.Text == "" {
+		buf.WriteString("Content-Type: text/html; charset=\"utf-8\"\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+		if err := writeQuotedPrintable(&buf, m.Content); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", parts.Boundary())
+
+	// the preferred part goes last
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", m.Text},
+		{"text/html", m.Content},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=\"utf-8\""},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err = writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// fromName returns the name shown next to the from address, the message's own name before the mailer's
+func fromName(m models.MailData, senderName string) string {
+	if m.FromName != "" {
+		return m.FromName
+	}
+	return senderName
+}
+
+// writeQuotedPrintable writes body to w in the quoted-printable encoding
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
@@ -0,0 +1,243 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aidisapp/musiqcity_v2/internal/models"
+)
+
+var testMessage = models.MailData{
+	To:      "customer@example.com",
+	From:    "bookings@example.com",
+	Subject: "Booking Confirmation",
+	Content: "<p>Thank you for your booking</p>",
+}
+
+// readMail returns the only .eml file in dir
+func readMail(t *testing.T, dir string) string {
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one .eml file but found %v (%v)", files, err)
+	}
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+
+	m, err := New(Config{Backend: BackendFile, Dir: dir, SenderName: "MusiqCity"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = m.Send(context.Background(), testMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	mail := readMail(t, dir)
+	for _, expected := range []string{
+		"To: customer@example.com",
+		"From: MusiqCity <bookings@example.com>",
+		"Subject: Booking Confirmation",
+		"Content-Type: text/html",
+		"<p>Thank you for your booking</p>",
+	} {
+		if !strings.Contains(mail, expected) {
+			t.Errorf("expected the mail to contain %q but got:\n%s", expected, mail)
+		}
+	}
+}
+
+func TestMessageSender(t *testing.T) {
+	m := testMessage
+	m.FromName = "MusiqCity Bookings"
+	m.ReplyTo = "support@example.com"
+
+	data, err := message(m, "MusiqCity")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"From: MusiqCity Bookings <bookings@example.com>",
+		"Reply-To: support@example.com",
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected the mail to contain %q but got:\n%s", expected, data)
+		}
+	}
+}
+
+func TestMultipartMessage(t *testing.T) {
+	m := testMessage
+	m.Text = "Thank you for your booking\n"
+
+	data, err := message(m, "MusiqCity")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("expected a multipart/alternative message but got %q (%v)", mediaType, err)
+	}
+
+	var parts []string
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, _ := io.ReadAll(part)
+		parts = append(parts, fmt.Sprintf("%s %s", part.Header.Get("Content-Type"), body))
+	}
+
+	expected := []string{
+		`text/plain; charset="utf-8" Thank you for your booking` + "\r\n",
+		`text/html; charset="utf-8" <p>Thank you for your booking</p>`,
+	}
+	if !reflect.DeepEqual(parts, expected) {
+		t.Errorf("expected the parts %q but got %q", expected, parts)
+	}
+}
+
+func TestRedirectMailer(t *testing.T) {
+	dir := t.TempDir()
+
+	m, err := New(Config{Backend: BackendFile, Dir: dir, RedirectTo: "qa@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = m.Send(context.Background(), testMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	mail := readMail(t, dir)
+	if !strings.Contains(mail, "To: qa@example.com") || !strings.Contains(mail, "[to customer@example.com]") {
+		t.Errorf("expected the mail to be redirected but got:\n%s", mail)
+	}
+}
+
+func TestStagingConfig(t *testing.T) {
+	var tests = []struct {
+		name     string
+		config   Config
+		expected error
+	}{
+		{"smtp", Config{Backend: BackendSMTP, SMTPHost: "localhost", SMTPPort: "25", Staging: true}, ErrUnsafeStaging},
+		{"sendinblue", Config{Backend: BackendSendinblue, SendinblueAPIKey: "key", Staging: true}, ErrUnsafeStaging},
+		{"smtp-redirected", Config{Backend: BackendSMTP, SMTPHost: "localhost", SMTPPort: "25", Staging: true, RedirectTo: "qa@example.com"}, nil},
+		{"file", Config{Backend: BackendFile, Dir: t.TempDir(), Staging: true}, nil},
+	}
+
+	for _, e := range tests {
+		_, err := New(e.config)
+		if !errors.Is(err, e.expected) {
+			t.Errorf("%s: expected %v but got %v", e.name, e.expected, err)
+		}
+	}
+}
+
+// smtpSink accepts one SMTP conversation and sends the DATA it received on the returned channel
+func smtpSink(t *testing.T) (string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan string, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		reply("220 sink ready")
+
+		var data strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			switch command := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 sink")
+			case command == "DATA":
+				reply("354 go ahead")
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- data.String()
+				reply("250 queued")
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), received
+}
+
+func TestSMTPMailer(t *testing.T) {
+	addr, received := smtpSink(t)
+	host, port, _ := net.SplitHostPort(addr)
+
+	m, err := New(Config{Backend: BackendSMTP, SMTPHost: host, SMTPPort: port})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = m.Send(context.Background(), testMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	mail := <-received
+	if !strings.Contains(mail, "To: customer@example.com") || !strings.Contains(mail, "Thank you for your booking") {
+		t.Errorf("unexpected mail received:\n%s", mail)
+	}
+}
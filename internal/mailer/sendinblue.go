@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aidisapp/musiqcity_v2/internal/models"
+	sendinblue "github.com/sendinblue/APIv3-go-library/v2/lib"
+)
+
+// Sendinblue sends mail through the Sendinblue transactional email API
+type Sendinblue struct {
+	client     *sendinblue.APIClient
+	senderName string
+}
+
+// NewSendinblue creates the API client once, so sending doesn't set it up again for every message
+func NewSendinblue(apiKey, senderName string) (*Sendinblue, error) {
+	if apiKey == "" {
+		return nil, errors.New("the sendinblue mailer needs an API key")
+	}
+
+	cfg := sendinblue.NewConfiguration()
+	cfg.AddDefaultHeader("api-key", apiKey)
+	cfg.AddDefaultHeader("partner-key", apiKey)
+
+	return &Sendinblue{
+		client:     sendinblue.NewAPIClient(cfg),
+		senderName: senderName,
+	}, nil
+}
+
+// Send sends one message
+func (s *Sendinblue) Send(ctx context.Context, m models.MailData) error {
+	message := sendinblue.SendSmtpEmail{
+		Sender: &sendinblue.SendSmtpEmailSender{
+			Name:  fromName(m, s.senderName),
+			Email: m.From,
+		},
+		To: []sendinblue.SendSmtpEmailTo{
+			{
+				Email: m.To,
+			},
+		},
+		Subject:     m.Subject,
+		HtmlContent: m.Content,
+		TextContent: m.Text,
+	}
+
+	if m.ReplyTo != "" {
+		message.ReplyTo = &sendinblue.SendSmtpEmailReplyTo{Email: m.ReplyTo}
+	}
+
+	_, _, err := s.client.TransactionalEmailsApi.SendTransacEmail(ctx, message)
+	return err
+}
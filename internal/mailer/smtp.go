@@ -0,0 +1,101 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/smtp"
+	"time"
+
+	"github.com/aidisapp/musiqcity_v2/internal/models"
+)
+
+// SMTP sends mail to an SMTP server, which can be a local sink such as MailHog in development
+type SMTP struct {
+	addr       string
+	host       string
+	auth       smtp.Auth
+	senderName string
+}
+
+// NewSMTP returns an SMTP mailer. Credentials are optional, without them no AUTH is attempted
+func NewSMTP(host, port, username, password, senderName string) (*SMTP, error) {
+	if host == "" || port == "" {
+		return nil, errors.New("the smtp mailer needs a host and a port")
+	}
+
+	s := &SMTP{
+		addr:       net.JoinHostPort(host, port),
+		host:       host,
+		senderName: senderName,
+	}
+
+	if username != "" {
+		s.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return s, nil
+}
+
+// Send sends one message. The whole conversation with the server is bound by ctx
+func (s *SMTP) Send(ctx context.Context, m models.MailData) error {
+	msg, err := message(m, s.senderName)
+	if err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(30 * time.Second)
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return err
+		}
+	}
+
+	if s.auth != nil {
+		if err = client.Auth(s.auth); err != nil {
+			return err
+		}
+	}
+
+	if err = client.Mail(m.From); err != nil {
+		return err
+	}
+
+	if err = client.Rcpt(m.To); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
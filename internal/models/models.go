@@ -6,17 +6,65 @@ import (
 
 // User is the user model
 type User struct {
-	ID          int
-	FirstName   string
-	LastName    string
-	Email       string
-	Password    string
-	AccessLevel int
-	Token       string
+	ID        int
+	FirstName string
+	LastName  string
+	Email     string
+	Password  string
+	Role      string
+	// Verified is set once the user opens the link in their verification email
+	Verified bool
+	// DisabledAt is set when an admin disables the account, which then can't log in until it is enabled again
+	DisabledAt time.Time
+	// LockedUntil stops the account logging in until that time
+	LockedUntil time.Time
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
 
+// Disabled reports whether an admin has disabled the account
+func (u User) Disabled() bool {
+	return !u.DisabledAt.IsZero()
+}
+
+// Locked reports whether the account is locked at now
+func (u User) Locked(now time.Time) bool {
+	return now.Before(u.LockedUntil)
+}
+
+// PasswordReset is a request to reset a user's password. Only the hash of the emailed token is kept
+type PasswordReset struct {
+	ID        int
+	UserID    int
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Usable reports whether the reset can still be used at now
+func (p PasswordReset) Usable(now time.Time) bool {
+	return p.UsedAt.IsZero() && now.Before(p.ExpiresAt)
+}
+
+// EmailVerification is a link that verifies a user's email address. Only the hash of the emailed token is kept.
+// UsedAt is also set when a newer link replaces this one
+type EmailVerification struct {
+	ID        int
+	UserID    int
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Usable reports whether the link can still be used at now
+func (v EmailVerification) Usable(now time.Time) bool {
+	return v.UsedAt.IsZero() && now.Before(v.ExpiresAt)
+}
+
 // Room is the room model
 type Room struct {
 	ID          int
@@ -49,7 +97,20 @@ type Reservation struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	Room      Room
-	Processed int
+	Status    string
+	// UserID is the customer account the reservation belongs to, 0 for guest reservations nobody has claimed
+	UserID int
+}
+
+// StatusChange is one entry in the status history of a reservation or booking
+type StatusChange struct {
+	ID         int
+	FromStatus string
+	ToStatus   string
+	UserID     int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	User       User
 }
 
 // RoomRestriction is the room restriction model
@@ -60,6 +121,7 @@ type RoomRestriction struct {
 	RoomID        int
 	ReservationID int
 	RestrictionID int
+	Reason        string
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	Room          Room
@@ -69,13 +131,52 @@ type RoomRestriction struct {
 
 // Informations for sending mail
 type MailData struct {
-	To       string
-	From     string
+	To   string
+	From string
+	// FromName is shown next to From, the mailer's sender name is used when it is empty
+	FromName string
+	ReplyTo  string
 	Subject  string
 	Content  string
+	// Text is the plain-text body sent next to the HTML Content
+	Text     string
 	Template string
 }
 
+// OutboxEmail is an email waiting in, or delivered from, the outbox
+type OutboxEmail struct {
+	ID            int
+	To            string
+	From          string
+	FromName      string
+	ReplyTo       string
+	Subject       string
+	Content       string
+	Text          string
+	Template      string
+	Status        string
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	SentAt        time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// MailData returns the message the email was queued with
+func (e OutboxEmail) MailData() MailData {
+	return MailData{
+		To:       e.To,
+		From:     e.From,
+		FromName: e.FromName,
+		ReplyTo:  e.ReplyTo,
+		Subject:  e.Subject,
+		Content:  e.Content,
+		Text:     e.Text,
+		Template: e.Template,
+	}
+}
+
 // Informations for sending mail
 type TodoList struct {
 	ID        int
@@ -102,26 +203,93 @@ type Artist struct {
 	Logo          string
 	Banner        string
 	FeaturedImage string
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	// UserID is the user who owns the listing, 0 for artists added by admins without an owner
+	UserID int
+	Status string
+	// StatusReason tells the owner why the listing was rejected or suspended
+	StatusReason string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	// FromPrice is the price of the cheapest booking option, 0 when none has a price. Only SearchArtists sets it
+	FromPrice int
+	// GenreList is the genres picked for the artist and Genres their names joined for display. CreateArtist
+	// and UpdateArtist save GenreList and set Genres from it. Only GetArtistByID and GetArtistByIDAnyStatus
+	// fill GenreList in
+	GenreList []Genre
 }
 
-// Bookings model
-type Bookings struct {
-	ID        int
-	FirstName string
-	LastName  string
-	Email     string
-	Phone     string
-	StartDate time.Time
-	EndDate   time.Time
-	Processed int
-	ArtistID  int
-	Artist    Artist
+// HasGenre reports whether genre id is one of the artist's genres
+func (a Artist) HasGenre(id int) bool {
+	for _, genre := range a.GenreList {
+		if genre.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Genre is one of the genres admins manage and artists pick from. Aliases are other spellings of it,
+// which searches and genre pages accept too
+type Genre struct {
+	ID      int
+	Name    string
+	Slug    string
+	Aliases []string
+	// Artists is how many approved artists have the genre
+	Artists   int
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
+// ArtistSuggestion is an artist suggested by the search box. NameHTML and SnippetHTML are escaped HTML with
+// the words that matched in <mark> tags
+type ArtistSuggestion struct {
+	ID          int
+	Name        string
+	Genres      string
+	City        string
+	NameHTML    string
+	SnippetHTML string
+}
+
+// Bookings model
+type Bookings struct {
+	ID              int
+	FirstName       string
+	LastName        string
+	Email           string
+	Phone           string
+	StartDate       time.Time
+	EndDate         time.Time
+	Status          string
+	ArtistID        int
+	BookingOptionID int
+	EventLocation   string
+	Message         string
+	Artist          Artist
+	BookingOption   BookingOptions
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	// UserID is the customer account the booking belongs to, 0 for guest bookings nobody has claimed
+	UserID int
+}
+
+// ArtistRestriction is the artist restriction model
+type ArtistRestriction struct {
+	ID            int
+	StartDate     time.Time
+	EndDate       time.Time
+	ArtistID      int
+	BookingID     int
+	RestrictionID int
+	Reason        string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Artist        Artist
+	Booking       Bookings
+	Restriction   Restriction
+}
+
 // Booking options model
 type BookingOptions struct {
 	ID          int
@@ -14,4 +14,5 @@ type TemplateData struct {
 	Form            *forms.Form
 	IsAuthenticated int
 	IsAdmin         int
+	Permissions     map[string]bool
 }
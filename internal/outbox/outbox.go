@@ -0,0 +1,161 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/aidisapp/musiqcity_v2/internal/mailer"
+	"github.com/aidisapp/musiqcity_v2/internal/models"
+	"github.com/aidisapp/musiqcity_v2/internal/repository"
+)
+
+// Statuses of an outbox email. Failed emails stay pending until they run out of attempts and become dead
+const (
+	StatusPending = "pending"
+	StatusSent    = "sent"
+	StatusDead    = "dead"
+)
+
+// All lists every outbox status
+var All = []string{StatusPending, StatusSent, StatusDead}
+
+// Retry delays start at minBackoff and double after every failed attempt, up to maxBackoff
+const (
+	minBackoff = 30 * time.Second
+	maxBackoff = 6 * time.Hour
+)
+
+// Backoff returns how long to wait before retrying an email that has failed attempts times
+func Backoff(attempts int) time.Duration {
+	delay := minBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+// Dispatcher sends the emails in the outbox with a pool of workers
+type Dispatcher struct {
+	DB     repository.DatabaseRepo
+	Mailer mailer.Mailer
+
+	// Workers is how many emails are sent at the same time
+	Workers int
+	// MaxAttempts is how many times an email is tried before it is marked dead
+	MaxAttempts int
+	// PollInterval is how often the outbox is checked for due emails
+	PollInterval time.Duration
+	// SendTimeout bounds one attempt. It must be shorter than Lease
+	SendTimeout time.Duration
+	// Lease is how long a claimed email is hidden from other workers
+	Lease time.Duration
+
+	InfoLog  *log.Logger
+	ErrorLog *log.Logger
+}
+
+// NewDispatcher returns a dispatcher with the default pool settings
+func NewDispatcher(db repository.DatabaseRepo, m mailer.Mailer, infoLog, errorLog *log.Logger) *Dispatcher {
+	return &Dispatcher{
+		DB:           db,
+		Mailer:       m,
+		Workers:      4,
+		MaxAttempts:  8,
+		PollInterval: 2 * time.Second,
+		SendTimeout:  30 * time.Second,
+		Lease:        2 * time.Minute,
+		InfoLog:      infoLog,
+		ErrorLog:     errorLog,
+	}
+}
+
+// Run sends due emails until ctx is cancelled, then waits for the emails being sent to finish
+func (d *Dispatcher) Run(ctx context.Context) {
+	jobs := make(chan models.OutboxEmail)
+
+	var wg sync.WaitGroup
+	for i := 0; i < d.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for email := range jobs {
+				d.deliver(email)
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// keep claiming while full batches come back, so a backlog doesn't wait for the ticker
+		for d.dispatch(ctx, jobs) == d.Workers {
+		}
+
+		select {
+		case <-ctx.Done():
+			close(jobs)
+			wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch claims one batch of due emails and hands them to the workers. It returns how many were claimed
+func (d *Dispatcher) dispatch(ctx context.Context, jobs chan<- models.OutboxEmail) int {
+	emails, err := d.DB.ClaimDueEmails(ctx, d.Workers, d.Lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.ErrorLog.Println("claiming outbox emails:", err)
+		}
+		return 0
+	}
+
+	for _, email := range emails {
+		select {
+		case jobs <- email:
+		case <-ctx.Done():
+			// the lease runs out and the email is picked up again after a restart
+			return 0
+		}
+	}
+
+	return len(emails)
+}
+
+// deliver makes one attempt at sending an email and records the outcome
+func (d *Dispatcher) deliver(email models.OutboxEmail) {
+	// attempts in flight are allowed to finish when Run is stopped
+	sendCtx, cancel := context.WithTimeout(context.Background(), d.SendTimeout)
+	sendErr := d.Mailer.Send(sendCtx, email.MailData())
+	cancel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var err error
+	switch {
+	case sendErr == nil:
+		err = d.DB.MarkEmailSent(ctx, email.ID)
+		if err == nil {
+			d.InfoLog.Printf("sent email #%d %q to %s", email.ID, email.Subject, email.To)
+		}
+	case email.Attempts >= d.MaxAttempts:
+		err = d.DB.MarkEmailDead(ctx, email.ID, sendErr.Error())
+		d.ErrorLog.Printf("giving up on email #%d to %s after %d attempts: %v", email.ID, email.To, email.Attempts, sendErr)
+	default:
+		retryAt := time.Now().Add(Backoff(email.Attempts))
+		err = d.DB.MarkEmailFailed(ctx, email.ID, sendErr.Error(), retryAt)
+		d.ErrorLog.Printf("sending email #%d to %s failed, retrying at %s: %v", email.ID, email.To, retryAt.Format(time.RFC3339), sendErr)
+	}
+
+	if err != nil {
+		d.ErrorLog.Printf("recording the outcome of email #%d: %v", email.ID, err)
+	}
+}
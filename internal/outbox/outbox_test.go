@@ -0,0 +1,161 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aidisapp/musiqcity_v2/internal/config"
+	"github.com/aidisapp/musiqcity_v2/internal/models"
+	"github.com/aidisapp/musiqcity_v2/internal/outbox"
+	"github.com/aidisapp/musiqcity_v2/internal/repository"
+	"github.com/aidisapp/musiqcity_v2/internal/repository/dbrepo"
+)
+
+// fakeMailer records the emails it is given and fails with err when it is set
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []models.MailData
+	err  error
+}
+
+func (f *fakeMailer) Send(ctx context.Context, m models.MailData) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, m)
+	return nil
+}
+
+func TestBackoff(t *testing.T) {
+	var tests = []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, 6 * time.Hour},
+		{100, 6 * time.Hour},
+	}
+
+	for _, e := range tests {
+		if got := outbox.Backoff(e.attempts); got != e.expected {
+			t.Errorf("attempt %d: expected %s but got %s", e.attempts, e.expected, got)
+		}
+	}
+}
+
+// runDispatcher enqueues count emails and runs a dispatcher until none of them are due any more
+func runDispatcher(t *testing.T, m *fakeMailer, maxAttempts, count int) repository.DatabaseRepo {
+	db := dbrepo.NewMemoryRepo(&config.AppConfig{})
+	ctx := context.Background()
+
+	for i := 0; i < count; i++ {
+		if err := db.EnqueueEmail(ctx, models.MailData{To: "customer@example.com", Subject: "Booking Confirmation"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logger := log.New(io.Discard, "", 0)
+	d := outbox.NewDispatcher(db, m, logger, logger)
+	d.Workers = 2
+	d.MaxAttempts = maxAttempts
+	d.PollInterval = 10 * time.Millisecond
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		d.Run(runCtx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		emails, err := db.AllOutboxEmails(ctx, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		settled := 0
+		for _, email := range emails {
+			if email.Status != outbox.StatusPending || email.LastError != "" {
+				settled++
+			}
+		}
+		if settled == count {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("only %d of %d emails were attempted", settled, count)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	<-done
+
+	return db
+}
+
+func TestDispatcherSends(t *testing.T) {
+	m := &fakeMailer{}
+	db := runDispatcher(t, m, 3, 5)
+
+	if len(m.sent) != 5 {
+		t.Errorf("expected 5 emails to be sent but %d were", len(m.sent))
+	}
+
+	sent, _ := db.AllOutboxEmails(context.Background(), outbox.StatusSent)
+	if len(sent) != 5 {
+		t.Errorf("expected 5 emails to be marked sent but %d were", len(sent))
+	}
+	for _, email := range sent {
+		if email.Attempts != 1 || email.SentAt.IsZero() {
+			t.Errorf("email #%d: expected one attempt and a sent time but got %d attempts at %v", email.ID, email.Attempts, email.SentAt)
+		}
+	}
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	m := &fakeMailer{err: errors.New("connection refused")}
+	start := time.Now()
+	db := runDispatcher(t, m, 3, 1)
+
+	emails, _ := db.AllOutboxEmails(context.Background(), outbox.StatusPending)
+	if len(emails) != 1 {
+		t.Fatalf("expected the failed email to stay pending but got %v", emails)
+	}
+
+	email := emails[0]
+	if email.Attempts != 1 || email.LastError != "connection refused" {
+		t.Errorf("expected one failed attempt but got %d attempts with error %q", email.Attempts, email.LastError)
+	}
+	if email.NextAttemptAt.Before(start.Add(outbox.Backoff(1))) {
+		t.Errorf("expected the retry to wait at least %s but it is due at %v", outbox.Backoff(1), email.NextAttemptAt)
+	}
+}
+
+func TestDispatcherDeadLetters(t *testing.T) {
+	m := &fakeMailer{err: errors.New("mailbox unavailable")}
+	db := runDispatcher(t, m, 1, 2)
+
+	dead, _ := db.AllOutboxEmails(context.Background(), outbox.StatusDead)
+	if len(dead) != 2 {
+		t.Fatalf("expected 2 dead emails but got %d", len(dead))
+	}
+	for _, email := range dead {
+		if email.LastError != "mailbox unavailable" {
+			t.Errorf("email #%d: expected the last error to be kept but got %q", email.ID, email.LastError)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter allows each key a number of hits within a sliding window. It keeps its counts in memory,
+// so every server limits on its own
+type Limiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	hits   map[string][]time.Time
+
+	// lastSweep is when keys without recent hits were last dropped
+	lastSweep time.Time
+
+	// now is time.Now, replaced in tests
+	now func() time.Time
+}
+
+// New returns a limiter that allows limit hits per key in every window
+func New(limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		limit:  limit,
+		window: window,
+		hits:   make(map[string][]time.Time),
+		now:    time.Now,
+	}
+}
+
+// Allow records a hit for key and reports whether it is within the limit. Hits that are turned away
+// aren't counted, so a key can try again as soon as its oldest allowed hit leaves the window
+func (l *Limiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	since := now.Add(-l.window)
+
+	if now.Sub(l.lastSweep) > l.window {
+		for k, hits := range l.hits {
+			if len(recent(hits, since)) == 0 {
+				delete(l.hits, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	hits := recent(l.hits[key], since)
+	if len(hits) >= l.limit {
+		l.hits[key] = hits
+		return false
+	}
+
+	l.hits[key] = append(hits, now)
+	return true
+}
+
+// recent returns the hits after since. Hits are in the order they were made
+func recent(hits []time.Time, since time.Time) []time.Time {
+	for i, hit := range hits {
+		if hit.After(since) {
+			return hits[i:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllow(t *testing.T) {
+	now := time.Date(2050, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	l := New(2, time.Minute)
+	l.now = func() time.Time { return now }
+
+	if !l.Allow("a") || !l.Allow("a") {
+		t.Fatal("expected the first two hits to be allowed")
+	}
+	if l.Allow("a") {
+		t.Error("expected the third hit in the window to be turned away")
+	}
+	if !l.Allow("b") {
+		t.Error("expected another key to have its own limit")
+	}
+
+	now = now.Add(30 * time.Second)
+	if l.Allow("a") {
+		t.Error("expected the limit to hold until the first hit leaves the window")
+	}
+
+	now = now.Add(31 * time.Second)
+	if !l.Allow("a") {
+		t.Error("expected a hit once the first hits left the window")
+	}
+}
+
+func TestSweep(t *testing.T) {
+	now := time.Date(2050, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	l := New(1, time.Minute)
+	l.now = func() time.Time { return now }
+
+	l.Allow("a")
+	l.Allow("b")
+
+	now = now.Add(2 * time.Minute)
+	l.Allow("c")
+
+	if len(l.hits) != 1 {
+		t.Errorf("expected the old keys to be dropped but got %v", l.hits)
+	}
+}
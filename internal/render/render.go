@@ -10,16 +10,23 @@ import (
 	"time"
 
 	"github.com/aidisapp/musiqcity_v2/internal/config"
+	"github.com/aidisapp/musiqcity_v2/internal/listing"
 	"github.com/aidisapp/musiqcity_v2/internal/models"
+	"github.com/aidisapp/musiqcity_v2/internal/roles"
+	"github.com/aidisapp/musiqcity_v2/internal/status"
 	"github.com/justinas/nosurf"
 )
 
 var functions = template.FuncMap{
-	"humanDate":  HumanDate,
-	"formatDate": FormatDate,
-	"iterate":    Iterate,
-	"add":        Add,
-	"truncate":   Truncate,
+	"humanDate":        HumanDate,
+	"formatDate":       FormatDate,
+	"iterate":          Iterate,
+	"add":              Add,
+	"truncate":         Truncate,
+	"statusLabel":      status.Label,
+	"roleLabel":        roles.Label,
+	"listingLabel":     listing.Label,
+	"canSubmitListing": listing.CanSubmit,
 }
 
 var app *config.AppConfig
@@ -74,9 +81,11 @@ func AddDefaultData(templateData *models.TemplateData, r *http.Request) *models.
 		templateData.IsAuthenticated = 1
 	}
 
-	if app.Session.GetInt(r.Context(), "access_level") == 3 {
+	role := app.Session.GetString(r.Context(), "role")
+	if roles.CanAccessAdmin(role) {
 		templateData.IsAdmin = 1
 	}
+	templateData.Permissions = roles.Granted(role)
 
 	return templateData
 }
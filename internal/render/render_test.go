@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/aidisapp/musiqcity_v2/internal/models"
+	"github.com/aidisapp/musiqcity_v2/internal/roles"
 )
 
 func TestAddDefaultData(test *testing.T) {
@@ -24,6 +25,25 @@ func TestAddDefaultData(test *testing.T) {
 
 }
 
+func TestAddDefaultDataPermissions(test *testing.T) {
+	sessionRequest, err := getSession()
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	result := AddDefaultData(&models.TemplateData{}, sessionRequest)
+	if result.IsAdmin != 0 || len(result.Permissions) != 0 {
+		test.Errorf("expected a guest to have no permissions but got %v", result.Permissions)
+	}
+
+	session.Put(sessionRequest.Context(), "role", roles.Staff)
+
+	result = AddDefaultData(&models.TemplateData{}, sessionRequest)
+	if result.IsAdmin != 1 || !result.Permissions[roles.ManageBookings] || result.Permissions[roles.ManageSettings] {
+		test.Errorf("expected staff to manage bookings but not settings, got %v", result.Permissions)
+	}
+}
+
 func TestRenderTemplate(test *testing.T) {
 	pathToTemplates = "./../../templates"
 	tc, err := CreateTemplateCache()
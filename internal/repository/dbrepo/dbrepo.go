@@ -1,15 +1,27 @@
 package dbrepo
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/aidisapp/musiqcity_v2/internal/config"
 	"github.com/aidisapp/musiqcity_v2/internal/repository"
 )
 
+// dbtx is the part of *sql.DB and *sql.Tx the queries run through, so the same repo methods work
+// on the connection pool and inside a transaction
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
 type postgresDBRepo struct {
 	App *config.AppConfig
-	DB  *sql.DB
+	DB  dbtx
+
+	// conn is the pool new transactions start from. It is nil when the repo is already inside a transaction
+	conn *sql.DB
 }
 
 type testDBRepo struct {
@@ -19,8 +31,9 @@ type testDBRepo struct {
 
 func NewPostgresRepo(dbConnection *sql.DB, appConfig *config.AppConfig) repository.DatabaseRepo {
 	return &postgresDBRepo{
-		App: appConfig,
-		DB:  dbConnection,
+		App:  appConfig,
+		DB:   dbConnection,
+		conn: dbConnection,
 	}
 }
 
@@ -29,3 +42,12 @@ func NewTestRepo(appConfig *config.AppConfig) repository.DatabaseRepo {
 		App: appConfig,
 	}
 }
+
+// NewMemoryRepo returns a repository that keeps everything in memory, seeded with demo fixtures.
+// It is safe for concurrent use and loses its data when the process exits
+func NewMemoryRepo(appConfig *config.AppConfig) repository.DatabaseRepo {
+	return &memoryDBRepo{
+		App:   appConfig,
+		store: &memoryStore{data: seedMemoryData()},
+	}
+}
@@ -0,0 +1,2400 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aidisapp/musiqcity_v2/internal/config"
+	"github.com/aidisapp/musiqcity_v2/internal/genres"
+	"github.com/aidisapp/musiqcity_v2/internal/listing"
+	"github.com/aidisapp/musiqcity_v2/internal/models"
+	"github.com/aidisapp/musiqcity_v2/internal/outbox"
+	"github.com/aidisapp/musiqcity_v2/internal/repository"
+	"github.com/aidisapp/musiqcity_v2/internal/roles"
+	"github.com/aidisapp/musiqcity_v2/internal/status"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Login details of the users the memory repo is seeded with. They all have the same password, the staff
+// user can only manage bookings and the artist user owns the DJ Kofi listing
+const (
+	MemoryAdminEmail    = "admin@musiqcity.com"
+	MemoryStaffEmail    = "staff@musiqcity.com"
+	MemoryArtistEmail   = "artist@musiqcity.com"
+	MemoryAdminPassword = "password"
+)
+
+// memoryData holds every table of the memory repo. Rows are stored by value, but the slices in them
+// share their backing arrays, so a snapshot has to copy those as well
+type memoryData struct {
+	lastID map[string]int
+
+	users              map[int]models.User
+	passwordResets     map[int]models.PasswordReset
+	emailVerifications map[int]models.EmailVerification
+	rooms              map[int]models.Room
+	reservations       map[int]models.Reservation
+	roomRestrictions   map[int]models.RoomRestriction
+	todos              map[int]models.TodoList
+	artists            map[int]models.Artist
+	bookings           map[int]models.Bookings
+	artistRestrictions map[int]models.ArtistRestriction
+	bookingOptions     map[int]models.BookingOptions
+	outbox             map[int]models.OutboxEmail
+	settings           map[string]string
+	genres             map[int]models.Genre
+
+	// genre ids keyed by artist id, like the artist_genres table
+	artistGenres map[int][]int
+
+	// status changes keyed by reservation and booking id
+	reservationHistory map[int][]models.StatusChange
+	bookingHistory     map[int][]models.StatusChange
+}
+
+// memoryStore is the data shared by a memory repo and the repos it hands to WithTx
+type memoryStore struct {
+	mu   sync.RWMutex
+	data *memoryData
+}
+
+type memoryDBRepo struct {
+	App   *config.AppConfig
+	store *memoryStore
+
+	// inTx is set on the repo passed to WithTx callbacks, which already hold the write lock
+	inTx bool
+}
+
+// nextID returns the next id of a table, like a serial column
+func (d *memoryData) nextID(table string) int {
+	d.lastID[table]++
+	return d.lastID[table]
+}
+
+// clone returns a copy of the data that later writes won't change
+func (d *memoryData) clone() *memoryData {
+	return &memoryData{
+		lastID:             maps.Clone(d.lastID),
+		users:              maps.Clone(d.users),
+		passwordResets:     maps.Clone(d.passwordResets),
+		emailVerifications: maps.Clone(d.emailVerifications),
+		rooms:              maps.Clone(d.rooms),
+		reservations:       maps.Clone(d.reservations),
+		roomRestrictions:   maps.Clone(d.roomRestrictions),
+		todos:              maps.Clone(d.todos),
+		artists:            cloneRows(d.artists, cloneArtist),
+		bookings:           maps.Clone(d.bookings),
+		artistRestrictions: maps.Clone(d.artistRestrictions),
+		bookingOptions:     maps.Clone(d.bookingOptions),
+		outbox:             maps.Clone(d.outbox),
+		settings:           maps.Clone(d.settings),
+		genres:             cloneRows(d.genres, cloneGenre),
+		artistGenres:       cloneRows(d.artistGenres, slices.Clone[[]int]),
+		reservationHistory: cloneRows(d.reservationHistory, slices.Clone[[]models.StatusChange]),
+		bookingHistory:     cloneRows(d.bookingHistory, slices.Clone[[]models.StatusChange]),
+	}
+}
+
+// cloneRows copies a table whose rows hold slices, copying every row with copyRow
+func cloneRows[K comparable, V any](rows map[K]V, copyRow func(V) V) map[K]V {
+	c := make(map[K]V, len(rows))
+	for k, v := range rows {
+		c[k] = copyRow(v)
+	}
+	return c
+}
+
+// cloneGenre copies a genre with its own aliases
+func cloneGenre(genre models.Genre) models.Genre {
+	genre.Aliases = slices.Clone(genre.Aliases)
+	return genre
+}
+
+// cloneArtist copies an artist with its own genres
+func cloneArtist(artist models.Artist) models.Artist {
+	artist.GenreList = slices.Clone(artist.GenreList)
+	for i := range artist.GenreList {
+		artist.GenreList[i] = cloneGenre(artist.GenreList[i])
+	}
+	return artist
+}
+
+// read runs fn while holding the read lock
+func (m *memoryDBRepo) read(ctx context.Context, fn func(d *memoryData) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if !m.inTx {
+		m.store.mu.RLock()
+		defer m.store.mu.RUnlock()
+	}
+
+	return fn(m.store.data)
+}
+
+// write runs fn while holding the write lock
+func (m *memoryDBRepo) write(ctx context.Context, fn func(d *memoryData) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if !m.inTx {
+		m.store.mu.Lock()
+		defer m.store.mu.Unlock()
+	}
+
+	return fn(m.store.data)
+}
+
+// WithTx runs fn with a repository that holds the write lock for the whole call. Every change fn made
+// is thrown away when it returns an error. fn must only use the repo it is given, the outer repo would block
+func (m *memoryDBRepo) WithTx(ctx context.Context, fn func(repo repository.DatabaseRepo) error) error {
+	if m.inTx {
+		return fn(m)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	snapshot := m.store.data.clone()
+
+	err := fn(&memoryDBRepo{App: m.App, store: m.store, inTx: true})
+	if err != nil {
+		m.store.data = snapshot
+		return err
+	}
+
+	return nil
+}
+
+// day drops the time of day, dates are stored like postgres date columns
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// overlaps reports whether start to end shares at least one day with from to to, both inclusive
+func overlaps(start, end, from, to time.Time) bool {
+	return !day(start).After(day(to)) && !day(end).Before(day(from))
+}
+
+// roomTaken reports whether a restriction of the room overlaps the dates. Restrictions of the
+// reservation skipID are ignored
+func (d *memoryData) roomTaken(roomID int, start, end time.Time, skipID int) bool {
+	for _, r := range d.roomRestrictions {
+		if r.RoomID != roomID || (skipID > 0 && r.ReservationID == skipID) {
+			continue
+		}
+		if overlaps(start, end, r.StartDate, r.EndDate) {
+			return true
+		}
+	}
+	return false
+}
+
+// artistTaken reports whether a restriction of the artist overlaps the dates. Restrictions of the
+// booking skipID are ignored
+func (d *memoryData) artistTaken(artistID int, start, end time.Time, skipID int) bool {
+	for _, r := range d.artistRestrictions {
+		if r.ArtistID != artistID || (skipID > 0 && r.BookingID == skipID) {
+			continue
+		}
+		if overlaps(start, end, r.StartDate, r.EndDate) {
+			return true
+		}
+	}
+	return false
+}
+
+// addRoomRestriction inserts a room restriction, failing like the no overlap constraint would
+func (d *memoryData) addRoomRestriction(r models.RoomRestriction) error {
+	if _, ok := d.rooms[r.RoomID]; !ok {
+		return errors.New("room does not exist")
+	}
+
+	if d.roomTaken(r.RoomID, r.StartDate, r.EndDate, 0) {
+		return repository.ErrUnavailable
+	}
+
+	r.ID = d.nextID("room_restrictions")
+	r.StartDate = day(r.StartDate)
+	r.EndDate = day(r.EndDate)
+	r.CreatedAt = time.Now()
+	r.UpdatedAt = time.Now()
+	d.roomRestrictions[r.ID] = r
+
+	return nil
+}
+
+// addArtistRestriction inserts an artist restriction, failing like the no overlap constraint would
+func (d *memoryData) addArtistRestriction(r models.ArtistRestriction) error {
+	if _, ok := d.artists[r.ArtistID]; !ok {
+		return errors.New("artist does not exist")
+	}
+
+	if d.artistTaken(r.ArtistID, r.StartDate, r.EndDate, 0) {
+		return repository.ErrUnavailable
+	}
+
+	r.ID = d.nextID("artist_restrictions")
+	r.StartDate = day(r.StartDate)
+	r.EndDate = day(r.EndDate)
+	r.CreatedAt = time.Now()
+	r.UpdatedAt = time.Now()
+	d.artistRestrictions[r.ID] = r
+
+	return nil
+}
+
+// deleteReservation removes a reservation with its restrictions and history, like the cascading foreign keys
+func (d *memoryData) deleteReservation(id int) {
+	delete(d.reservations, id)
+	delete(d.reservationHistory, id)
+
+	for rid, r := range d.roomRestrictions {
+		if r.ReservationID == id {
+			delete(d.roomRestrictions, rid)
+		}
+	}
+}
+
+// reservationWithRoom returns a reservation with the room fields the postgres queries join in
+func (d *memoryData) reservationWithRoom(res models.Reservation) models.Reservation {
+	room := d.rooms[res.RoomID]
+	res.Room = models.Room{ID: room.ID, RoomName: room.RoomName}
+	return res
+}
+
+// bookingWithArtist returns a booking with the artist and option fields the postgres queries join in
+func (d *memoryData) bookingWithArtist(booking models.Bookings) models.Bookings {
+	artist := d.artists[booking.ArtistID]
+	booking.Artist = models.Artist{
+		ID:          artist.ID,
+		Name:        artist.Name,
+		Genres:      artist.Genres,
+		Description: artist.Description,
+		City:        artist.City,
+	}
+
+	option := d.bookingOptions[booking.BookingOptionID]
+	booking.BookingOption = models.BookingOptions{
+		ID:    booking.BookingOptionID,
+		Title: option.Title,
+		Price: option.Price,
+	}
+
+	return booking
+}
+
+// reservationsWhere returns the reservations keep accepts, earliest start date first
+func (d *memoryData) reservationsWhere(keep func(models.Reservation) bool) []models.Reservation {
+	var reservations []models.Reservation
+
+	for _, res := range d.reservations {
+		if keep(res) {
+			reservations = append(reservations, d.reservationWithRoom(res))
+		}
+	}
+
+	sort.Slice(reservations, func(i, j int) bool {
+		if reservations[i].StartDate.Equal(reservations[j].StartDate) {
+			return reservations[i].ID < reservations[j].ID
+		}
+		return reservations[i].StartDate.Before(reservations[j].StartDate)
+	})
+
+	return reservations
+}
+
+// bookingsWhere returns the bookings keep accepts, earliest start date first
+func (d *memoryData) bookingsWhere(keep func(models.Bookings) bool) []models.Bookings {
+	var bookings []models.Bookings
+
+	for _, booking := range d.bookings {
+		if keep(booking) {
+			bookings = append(bookings, d.bookingWithArtist(booking))
+		}
+	}
+
+	sort.Slice(bookings, func(i, j int) bool {
+		if bookings[i].StartDate.Equal(bookings[j].StartDate) {
+			return bookings[i].ID < bookings[j].ID
+		}
+		return bookings[i].StartDate.Before(bookings[j].StartDate)
+	})
+
+	return bookings
+}
+
+// optionsWhere returns the booking options keep accepts, oldest first
+func (d *memoryData) optionsWhere(keep func(models.BookingOptions) bool) []models.BookingOptions {
+	var options []models.BookingOptions
+
+	for _, option := range d.bookingOptions {
+		if keep(option) {
+			options = append(options, option)
+		}
+	}
+
+	sort.Slice(options, func(i, j int) bool {
+		return options[i].ID < options[j].ID
+	})
+
+	return options
+}
+
+// statusChange builds a history entry with the name of the user who made it
+func (d *memoryData) statusChange(table, from, to string, userID int) models.StatusChange {
+	user := d.users[userID]
+
+	return models.StatusChange{
+		ID:         d.nextID(table),
+		FromStatus: from,
+		ToStatus:   to,
+		UserID:     userID,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+		User:       models.User{ID: userID, FirstName: user.FirstName, LastName: user.LastName},
+	}
+}
+
+// Authenticate returns the user with email when testPassword is their password
+func (m *memoryDBRepo) Authenticate(ctx context.Context, email, testPassword string) (models.User, error) {
+	user, err := m.GetUserByEmail(ctx, email)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(testPassword))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return models.User{}, errors.New("incorrect password")
+	} else if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// AllUsers returns up to limit users whose name or email contains search, newest first, skipping the first
+// offset of them, and how many users match in all
+func (m *memoryDBRepo) AllUsers(ctx context.Context, search string, limit, offset int) ([]models.User, int, error) {
+	var users []models.User
+
+	search = strings.ToLower(search)
+
+	err := m.read(ctx, func(d *memoryData) error {
+		for _, u := range d.users {
+			name := strings.ToLower(u.FirstName + " " + u.LastName)
+			if strings.Contains(name, search) || strings.Contains(strings.ToLower(u.Email), search) {
+				users = append(users, u)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].ID > users[j].ID
+		}
+		return users[i].CreatedAt.After(users[j].CreatedAt)
+	})
+
+	total := len(users)
+	users = users[min(offset, total):min(offset+limit, total)]
+
+	return users, total, nil
+}
+
+// Check if a user exists via email
+func (m *memoryDBRepo) CheckIfUserEmailExist(ctx context.Context, email string) (bool, error) {
+	exists := false
+
+	err := m.read(ctx, func(d *memoryData) error {
+		for _, u := range d.users {
+			if u.Email == email {
+				exists = true
+			}
+		}
+		return nil
+	})
+
+	return exists, err
+}
+
+// Inserts a user, hashing the password like the postgres repo does
+func (m *memoryDBRepo) InsertUser(ctx context.Context, user models.User) (int, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), 10)
+	if err != nil {
+		return 0, err
+	}
+
+	var newUserID int
+
+	err = m.write(ctx, func(d *memoryData) error {
+		for _, u := range d.users {
+			if u.Email == user.Email {
+				return errors.New("a user with that email already exists")
+			}
+		}
+
+		user.ID = d.nextID("users")
+		user.Password = string(hashedPassword)
+		user.CreatedAt = time.Now()
+		user.UpdatedAt = time.Now()
+		d.users[user.ID] = user
+
+		newUserID = user.ID
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return newUserID, nil
+}
+
+// GetUserByID returns a user by id
+func (m *memoryDBRepo) GetUserByID(ctx context.Context, id int) (models.User, error) {
+	var user models.User
+
+	err := m.read(ctx, func(d *memoryData) error {
+		u, ok := d.users[id]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		user = u
+		return nil
+	})
+
+	return user, err
+}
+
+// UpdateUser updates a user
+func (m *memoryDBRepo) UpdateUser(ctx context.Context, user models.User) error {
+	return m.write(ctx, func(d *memoryData) error {
+		u, ok := d.users[user.ID]
+		if !ok {
+			return nil
+		}
+
+		u.FirstName = user.FirstName
+		u.LastName = user.LastName
+		u.Email = user.Email
+		u.Role = user.Role
+		u.UpdatedAt = time.Now()
+		d.users[u.ID] = u
+		return nil
+	})
+}
+
+// UpdateUserRole changes the role of a user
+func (m *memoryDBRepo) UpdateUserRole(ctx context.Context, id int, role string) error {
+	return m.updateUser(ctx, id, func(u *models.User) {
+		u.Role = role
+	})
+}
+
+// UpdatePassword sets the password of a user
+func (m *memoryDBRepo) UpdatePassword(ctx context.Context, id int, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 10)
+	if err != nil {
+		return err
+	}
+
+	return m.updateUser(ctx, id, func(u *models.User) {
+		u.Password = string(hashedPassword)
+	})
+}
+
+// SetUserDisabled disables or enables the account of a user. Disabling keeps the time it was first disabled
+func (m *memoryDBRepo) SetUserDisabled(ctx context.Context, id int, disabled bool) error {
+	return m.updateUser(ctx, id, func(u *models.User) {
+		if !disabled {
+			u.DisabledAt = time.Time{}
+		} else if u.DisabledAt.IsZero() {
+			u.DisabledAt = time.Now()
+		}
+	})
+}
+
+// LockUser stops a user logging in until the given time, the zero time unlocks them
+func (m *memoryDBRepo) LockUser(ctx context.Context, id int, until time.Time) error {
+	return m.updateUser(ctx, id, func(u *models.User) {
+		u.LockedUntil = until
+	})
+}
+
+// updateUser applies change to user id, if they exist
+func (m *memoryDBRepo) updateUser(ctx context.Context, id int, change func(u *models.User)) error {
+	return m.write(ctx, func(d *memoryData) error {
+		u, ok := d.users[id]
+		if !ok {
+			return nil
+		}
+
+		change(&u)
+		u.UpdatedAt = time.Now()
+		d.users[id] = u
+		return nil
+	})
+}
+
+// GetUserByEmail returns a user by email
+func (m *memoryDBRepo) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	var user models.User
+
+	err := m.read(ctx, func(d *memoryData) error {
+		for _, u := range d.users {
+			if u.Email == email {
+				user = u
+				return nil
+			}
+		}
+		return sql.ErrNoRows
+	})
+
+	return user, err
+}
+
+// InsertPasswordReset stores a password reset by the hash of its token
+func (m *memoryDBRepo) InsertPasswordReset(ctx context.Context, reset models.PasswordReset) error {
+	return m.write(ctx, func(d *memoryData) error {
+		for _, p := range d.passwordResets {
+			if p.TokenHash == reset.TokenHash {
+				return errors.New("a password reset with that token already exists")
+			}
+		}
+
+		reset.ID = d.nextID("password_resets")
+		reset.UsedAt = time.Time{}
+		reset.CreatedAt = time.Now()
+		reset.UpdatedAt = time.Now()
+		d.passwordResets[reset.ID] = reset
+		return nil
+	})
+}
+
+// CountPasswordResets returns how many resets were requested for userID since the given time
+func (m *memoryDBRepo) CountPasswordResets(ctx context.Context, userID int, since time.Time) (int, error) {
+	count := 0
+
+	err := m.read(ctx, func(d *memoryData) error {
+		for _, p := range d.passwordResets {
+			if p.UserID == userID && !p.CreatedAt.Before(since) {
+				count++
+			}
+		}
+		return nil
+	})
+
+	return count, err
+}
+
+// GetPasswordReset returns the reset with tokenHash, or ErrInvalidResetToken when it doesn't exist, has been used or has expired
+func (m *memoryDBRepo) GetPasswordReset(ctx context.Context, tokenHash string) (models.PasswordReset, error) {
+	var reset models.PasswordReset
+
+	err := m.read(ctx, func(d *memoryData) error {
+		var err error
+		reset, err = d.usablePasswordReset(tokenHash)
+		return err
+	})
+
+	return reset, err
+}
+
+// usablePasswordReset returns the reset with tokenHash, or ErrInvalidResetToken when it can't be used
+func (d *memoryData) usablePasswordReset(tokenHash string) (models.PasswordReset, error) {
+	for _, p := range d.passwordResets {
+		if p.TokenHash == tokenHash {
+			if !p.Usable(time.Now()) {
+				break
+			}
+			return p, nil
+		}
+	}
+
+	return models.PasswordReset{}, repository.ErrInvalidResetToken
+}
+
+// ResetPassword sets the password of the user the reset with tokenHash belongs to and uses up all of that user's open resets
+func (m *memoryDBRepo) ResetPassword(ctx context.Context, tokenHash, password string) (int, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 10)
+	if err != nil {
+		return 0, err
+	}
+
+	var userID int
+
+	err = m.write(ctx, func(d *memoryData) error {
+		reset, err := d.usablePasswordReset(tokenHash)
+		if err != nil {
+			return err
+		}
+
+		user, ok := d.users[reset.UserID]
+		if !ok {
+			return repository.ErrInvalidResetToken
+		}
+		user.Password = string(hashedPassword)
+		user.UpdatedAt = time.Now()
+		d.users[user.ID] = user
+
+		for id, p := range d.passwordResets {
+			if p.UserID == user.ID && p.UsedAt.IsZero() {
+				p.UsedAt = time.Now()
+				p.UpdatedAt = time.Now()
+				d.passwordResets[id] = p
+			}
+		}
+
+		userID = user.ID
+		return nil
+	})
+
+	return userID, err
+}
+
+// InsertEmailVerification stores a verification link by the hash of its token and revokes the user's earlier links
+func (m *memoryDBRepo) InsertEmailVerification(ctx context.Context, verification models.EmailVerification) error {
+	return m.write(ctx, func(d *memoryData) error {
+		for id, v := range d.emailVerifications {
+			if v.TokenHash == verification.TokenHash {
+				return errors.New("an email verification with that token already exists")
+			}
+			if v.UserID == verification.UserID && v.UsedAt.IsZero() {
+				v.UsedAt = time.Now()
+				v.UpdatedAt = time.Now()
+				d.emailVerifications[id] = v
+			}
+		}
+
+		verification.ID = d.nextID("email_verifications")
+		verification.UsedAt = time.Time{}
+		verification.CreatedAt = time.Now()
+		verification.UpdatedAt = time.Now()
+		d.emailVerifications[verification.ID] = verification
+		return nil
+	})
+}
+
+// CountEmailVerifications returns how many verification links were sent to userID since the given time
+func (m *memoryDBRepo) CountEmailVerifications(ctx context.Context, userID int, since time.Time) (int, error) {
+	count := 0
+
+	err := m.read(ctx, func(d *memoryData) error {
+		for _, v := range d.emailVerifications {
+			if v.UserID == userID && !v.CreatedAt.Before(since) {
+				count++
+			}
+		}
+		return nil
+	})
+
+	return count, err
+}
+
+// VerifyEmail verifies the user the link with tokenHash belongs to and uses the link up
+func (m *memoryDBRepo) VerifyEmail(ctx context.Context, tokenHash string) (int, error) {
+	var userID int
+
+	err := m.write(ctx, func(d *memoryData) error {
+		for id, v := range d.emailVerifications {
+			if v.TokenHash != tokenHash {
+				continue
+			}
+
+			user, ok := d.users[v.UserID]
+			if !ok || !v.Usable(time.Now()) {
+				break
+			}
+
+			user.Verified = true
+			user.UpdatedAt = time.Now()
+			d.users[user.ID] = user
+
+			v.UsedAt = time.Now()
+			v.UpdatedAt = time.Now()
+			d.emailVerifications[id] = v
+
+			userID = user.ID
+			return nil
+		}
+
+		return repository.ErrInvalidVerificationToken
+	})
+
+	return userID, err
+}
+
+// Inserts a reservation, new reservations always start out pending
+func (m *memoryDBRepo) InsertReservation(ctx context.Context, res models.Reservation) (int, error) {
+	var newID int
+
+	err := m.write(ctx, func(d *memoryData) error {
+		if _, ok := d.rooms[res.RoomID]; !ok {
+			return errors.New("room does not exist")
+		}
+
+		res.ID = d.nextID("reservations")
+		res.StartDate = day(res.StartDate)
+		res.EndDate = day(res.EndDate)
+		res.Status = status.Pending
+		res.Room = models.Room{}
+		res.CreatedAt = time.Now()
+		res.UpdatedAt = time.Now()
+		d.reservations[res.ID] = res
+
+		newID = res.ID
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return newID, nil
+}
+
+// InsertRoomRestriction inserts a room restriction. It returns ErrUnavailable when the dates overlap another restriction
+func (m *memoryDBRepo) InsertRoomRestriction(ctx context.Context, res models.RoomRestriction) error {
+	return m.write(ctx, func(d *memoryData) error {
+		return d.addRoomRestriction(models.RoomRestriction{
+			StartDate:     res.StartDate,
+			EndDate:       res.EndDate,
+			RoomID:        res.RoomID,
+			ReservationID: res.ReservationID,
+			RestrictionID: res.RestrictionID,
+		})
+	})
+}
+
+// SearchAvailabilityByDatesByRoomID returns true if availability exists for roomID, and false if no availability
+func (m *memoryDBRepo) SearchAvailabilityByDatesByRoomID(ctx context.Context, start, end time.Time, roomID int) (bool, error) {
+	available := false
+
+	err := m.read(ctx, func(d *memoryData) error {
+		available = !d.roomTaken(roomID, start, end, 0)
+		return nil
+	})
+
+	return available, err
+}
+
+// SearchAvailabilityForAllRooms returns a slice of available rooms, if any, for given date range
+func (m *memoryDBRepo) SearchAvailabilityForAllRooms(ctx context.Context, start, end time.Time) ([]models.Room, error) {
+	var rooms []models.Room
+
+	err := m.read(ctx, func(d *memoryData) error {
+		for _, room := range d.rooms {
+			if !d.roomTaken(room.ID, start, end, 0) {
+				rooms = append(rooms, room)
+			}
+		}
+		return nil
+	})
+
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].ID < rooms[j].ID
+	})
+
+	return rooms, err
+}
+
+// GetRoomByID gets a room by id
+func (m *memoryDBRepo) GetRoomByID(ctx context.Context, id int) (models.Room, error) {
+	var room models.Room
+
+	err := m.read(ctx, func(d *memoryData) error {
+		r, ok := d.rooms[id]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		room = r
+		return nil
+	})
+
+	return room, err
+}
+
+// AllReservations returns a slice of all reservations
+func (m *memoryDBRepo) AllReservations(ctx context.Context) ([]models.Reservation, error) {
+	var reservations []models.Reservation
+
+	err := m.read(ctx, func(d *memoryData) error {
+		reservations = d.reservationsWhere(func(models.Reservation) bool { return true })
+		return nil
+	})
+
+	return reservations, err
+}
+
+// AllNewReservations returns a slice of all pending reservations
+func (m *memoryDBRepo) AllNewReservations(ctx context.Context) ([]models.Reservation, error) {
+	var reservations []models.Reservation
+
+	err := m.read(ctx, func(d *memoryData) error {
+		reservations = d.reservationsWhere(func(res models.Reservation) bool { return res.Status == status.Pending })
+		return nil
+	})
+
+	return reservations, err
+}
+
+// GetReservationByID returns one reservation by ID
+func (m *memoryDBRepo) GetReservationByID(ctx context.Context, id int) (models.Reservation, error) {
+	var reservation models.Reservation
+
+	err := m.read(ctx, func(d *memoryData) error {
+		res, ok := d.reservations[id]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		reservation = d.reservationWithRoom(res)
+		return nil
+	})
+
+	return reservation, err
+}
+
+// UpdateReservation updates a reservation's customer details
+func (m *memoryDBRepo) UpdateReservation(ctx context.Context, u models.Reservation) error {
+	return m.write(ctx, func(d *memoryData) error {
+		res, ok := d.reservations[u.ID]
+		if !ok {
+			return nil
+		}
+
+		res.FirstName = u.FirstName
+		res.LastName = u.LastName
+		res.Email = u.Email
+		res.Phone = u.Phone
+		res.UpdatedAt = time.Now()
+		d.reservations[res.ID] = res
+		return nil
+	})
+}
+
+// DeleteReservation deletes one reservation by id
+func (m *memoryDBRepo) DeleteReservation(ctx context.Context, id int) error {
+	return m.write(ctx, func(d *memoryData) error {
+		d.deleteReservation(id)
+		return nil
+	})
+}
+
+// UpdateReservationStatus moves a reservation from one status to another and records the change in its history.
+// Cancelling a reservation also frees its dates on the rooms calendar
+func (m *memoryDBRepo) UpdateReservationStatus(ctx context.Context, id int, from, to string, userID int) error {
+	return m.write(ctx, func(d *memoryData) error {
+		res, ok := d.reservations[id]
+		if !ok || res.Status != from {
+			return status.ErrStaleStatus
+		}
+
+		res.Status = to
+		res.UpdatedAt = time.Now()
+		d.reservations[id] = res
+
+		change := d.statusChange("reservation_status_changes", from, to, userID)
+		d.reservationHistory[id] = append(d.reservationHistory[id], change)
+
+		if to == status.Cancelled {
+			for rid, r := range d.roomRestrictions {
+				if r.ReservationID == id {
+					delete(d.roomRestrictions, rid)
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetReservationStatusHistory returns the status changes of a reservation, oldest first
+func (m *memoryDBRepo) GetReservationStatusHistory(ctx context.Context, id int) ([]models.StatusChange, error) {
+	var changes []models.StatusChange
+
+	err := m.read(ctx, func(d *memoryData) error {
+		changes = append(changes, d.reservationHistory[id]...)
+		return nil
+	})
+
+	return changes, err
+}
+
+// RescheduleReservation moves a reservation and its room restriction to new dates.
+// It returns false without changing anything when the new dates clash with another reservation or block
+func (m *memoryDBRepo) RescheduleReservation(ctx context.Context, id int, startDate, endDate time.Time) (bool, error) {
+	available := false
+
+	err := m.write(ctx, func(d *memoryData) error {
+		res, ok := d.reservations[id]
+		if !ok {
+			return sql.ErrNoRows
+		}
+
+		if d.roomTaken(res.RoomID, startDate, endDate, id) {
+			return nil
+		}
+
+		res.StartDate = day(startDate)
+		res.EndDate = day(endDate)
+		res.UpdatedAt = time.Now()
+		d.reservations[id] = res
+
+		for rid, r := range d.roomRestrictions {
+			if r.ReservationID == id {
+				r.StartDate = res.StartDate
+				r.EndDate = res.EndDate
+				r.UpdatedAt = time.Now()
+				d.roomRestrictions[rid] = r
+			}
+		}
+
+		available = true
+		return nil
+	})
+
+	return available, err
+}
+
+// InsertBlockForRoom inserts an owner block for a room from startDate to endDate
+func (m *memoryDBRepo) InsertBlockForRoom(ctx context.Context, id int, startDate, endDate time.Time, reason string) error {
+	return m.write(ctx, func(d *memoryData) error {
+		return d.addRoomRestriction(models.RoomRestriction{
+			StartDate:     startDate,
+			EndDate:       endDate,
+			RoomID:        id,
+			RestrictionID: 2,
+			Reason:        reason,
+		})
+	})
+}
+
+// DeleteBlockByID deletes a room restriction
+func (m *memoryDBRepo) DeleteBlockByID(ctx context.Context, id int) error {
+	return m.write(ctx, func(d *memoryData) error {
+		delete(d.roomRestrictions, id)
+		return nil
+	})
+}
+
+// Get all rooms
+func (m *memoryDBRepo) AllRooms(ctx context.Context) ([]models.Room, error) {
+	var rooms []models.Room
+
+	err := m.read(ctx, func(d *memoryData) error {
+		for _, room := range d.rooms {
+			rooms = append(rooms, room)
+		}
+		return nil
+	})
+
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].RoomName < rooms[j].RoomName
+	})
+
+	return rooms, err
+}
+
+// UpdateRoom updates a room
+func (m *memoryDBRepo) UpdateRoom(ctx context.Context, room models.Room) error {
+	return m.write(ctx, func(d *memoryData) error {
+		r, ok := d.rooms[room.ID]
+		if !ok {
+			return nil
+		}
+
+		r.RoomName = room.RoomName
+		r.Price = room.Price
+		r.ImageSource = room.ImageSource
+		r.Description = room.Description
+		r.UpdatedAt = time.Now()
+		d.rooms[r.ID] = r
+		return nil
+	})
+}
+
+// Inserts a room
+func (m *memoryDBRepo) InsertRoom(ctx context.Context, room models.Room) error {
+	return m.write(ctx, func(d *memoryData) error {
+		room.ID = d.nextID("rooms")
+		room.CreatedAt = time.Now()
+		room.UpdatedAt = time.Now()
+		d.rooms[room.ID] = room
+		return nil
+	})
+}
+
+// DeleteRoom deletes a room with its reservations and restrictions
+func (m *memoryDBRepo) DeleteRoom(ctx context.Context, id int) error {
+	return m.write(ctx, func(d *memoryData) error {
+		delete(d.rooms, id)
+
+		for resID, res := range d.reservations {
+			if res.RoomID == id {
+				d.deleteReservation(resID)
+			}
+		}
+
+		for rid, r := range d.roomRestrictions {
+			if r.RoomID == id {
+				delete(d.roomRestrictions, rid)
+			}
+		}
+
+		return nil
+	})
+}
+
+// InsertTodoList inserts a new todo
+func (m *memoryDBRepo) InsertTodoList(ctx context.Context, todo models.TodoList) error {
+	return m.write(ctx, func(d *memoryData) error {
+		todo.ID = d.nextID("todo_list")
+		todo.CreatedAt = time.Now()
+		todo.UpdatedAt = time.Now()
+		d.todos[todo.ID] = todo
+		return nil
+	})
+}
+
+// GetTodoListByUserID gets all todo for a user by user_id
+func (m *memoryDBRepo) GetTodoListByUserID(ctx context.Context, id int) ([]models.TodoList, error) {
+	var todoList []models.TodoList
+
+	err := m.read(ctx, func(d *memoryData) error {
+		for _, todo := range d.todos {
+			if todo.UserID == id {
+				todoList = append(todoList, todo)
+			}
+		}
+		return nil
+	})
+
+	sort.Slice(todoList, func(i, j int) bool {
+		return todoList[i].ID < todoList[j].ID
+	})
+
+	return todoList, err
+}
+
+// DeleteTodo deletes a todo
+func (m *memoryDBRepo) DeleteTodo(ctx context.Context, id int) error {
+	return m.write(ctx, func(d *memoryData) error {
+		delete(d.todos, id)
+		return nil
+	})
+}
+
+// GetRestrictionsForCurrentRoom returns restrictions for a room by date range
+func (m *memoryDBRepo) GetRestrictionsForCurrentRoom(ctx context.Context, roomID int, start, end time.Time) ([]models.RoomRestriction, error) {
+	var restrictions []models.RoomRestriction
+
+	err := m.read(ctx, func(d *memoryData) error {
+		for _, r := range d.roomRestrictions {
+			if r.RoomID == roomID && overlaps(start, end, r.StartDate, r.EndDate) {
+				restrictions = append(restrictions, r)
+			}
+		}
+		return nil
+	})
+
+	sort.Slice(restrictions, func(i, j int) bool {
+		return restrictions[i].StartDate.Before(restrictions[j].StartDate)
+	})
+
+	return restrictions, err
+}
+
+// AllArtistsByStatus returns the artists whose listing is in status, or every artist when status is empty
+func (m *memoryDBRepo) AllArtistsByStatus(ctx context.Context, status string) ([]models.Artist, error) {
+	var artists []models.Artist
+
+	err := m.read(ctx, func(d *memoryData) error {
+		for _, artist := range d.artists {
+			if status == "" || artist.Status == status {
+				artists = append(artists, artist)
+			}
+		}
+		return nil
+	})
+
+	sort.Slice(artists, func(i, j int) bool {
+		return artists[i].ID < artists[j].ID
+	})
+
+	return artists, err
+}
+
+// AllArtistsByUserID returns the artists owned by a user, oldest first
+func (m *memoryDBRepo) AllArtistsByUserID(ctx context.Context, userID int) ([]models.Artist, error) {
+	var artists []models.Artist
+
+	err := m.read(ctx, func(d *memoryData) error {
+		for _, artist := range d.artists {
+			if artist.UserID == userID {
+				artists = append(artists, artist)
+			}
+		}
+		return nil
+	})
+
+	sort.Slice(artists, func(i, j int) bool {
+		return artists[i].ID < artists[j].ID
+	})
+
+	return artists, err
+}
+
+// Inserts a new artist with its genres and returns its id
+func (m *memoryDBRepo) CreateArtist(ctx context.Context, artist models.Artist) (int, error) {
+	err := m.write(ctx, func(d *memoryData) error {
+		artist.ID = d.nextID("artists")
+		artist.CreatedAt = time.Now()
+		artist.UpdatedAt = time.Now()
+		d.artists[artist.ID] = artist
+		return d.setArtistGenres(artist.ID, artist.GenreList)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return artist.ID, nil
+}
+
+// Get an approved artist by id
+func (m *memoryDBRepo) GetArtistByID(ctx context.Context, id int) (models.Artist, error) {
+	artist, err := m.GetArtistByIDAnyStatus(ctx, id)
+	if err == nil && artist.Status != listing.Approved {
+		return models.Artist{}, sql.ErrNoRows
+	}
+
+	return artist, err
+}
+
+// GetArtistByIDAnyStatus returns an artist whatever the status of its listing
+func (m *memoryDBRepo) GetArtistByIDAnyStatus(ctx context.Context, id int) (models.Artist, error) {
+	var artist models.Artist
+
+	err := m.read(ctx, func(d *memoryData) error {
+		a, ok := d.artists[id]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		artist = a
+		artist.GenreList = d.artistGenreList(id)
+		return nil
+	})
+
+	return artist, err
+}
+
+// UpdateArtistStatus moves the listing of an artist from one status to another, if nobody changed it since it was read
+func (m *memoryDBRepo) UpdateArtistStatus(ctx context.Context, id int, from, to, reason string) error {
+	return m.write(ctx, func(d *memoryData) error {
+		artist, ok := d.artists[id]
+		if !ok || artist.Status != from {
+			return listing.ErrStaleStatus
+		}
+
+		artist.Status = to
+		artist.StatusReason = reason
+		artist.UpdatedAt = time.Now()
+		d.artists[id] = artist
+		return nil
+	})
+}
+
+// UpdateArtist updates an artist and its genres
+func (m *memoryDBRepo) UpdateArtist(ctx context.Context, artist models.Artist) error {
+	return m.write(ctx, func(d *memoryData) error {
+		a, ok := d.artists[artist.ID]
+		if !ok {
+			return nil
+		}
+
+		// like the postgres update, the owner and status are left alone
+		artist.UserID = a.UserID
+		artist.Status = a.Status
+		artist.StatusReason = a.StatusReason
+		artist.CreatedAt = a.CreatedAt
+		artist.UpdatedAt = time.Now()
+		d.artists[artist.ID] = artist
+		return d.setArtistGenres(artist.ID, artist.GenreList)
+	})
+}
+
+// artistGenreList returns the genres of an artist by name
+func (d *memoryData) artistGenreList(artistID int) []models.Genre {
+	var list []models.Genre
+
+	for _, id := range d.artistGenres[artistID] {
+		list = append(list, d.genreWithCount(d.genres[id]))
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
+
+// setArtistGenres replaces the genres of an artist, failing like the foreign key would for unknown genres
+func (d *memoryData) setArtistGenres(artistID int, genreList []models.Genre) error {
+	var ids []int
+
+	for _, genre := range genreList {
+		if _, ok := d.genres[genre.ID]; !ok {
+			return errors.New("genre does not exist")
+		}
+		if !slices.Contains(ids, genre.ID) {
+			ids = append(ids, genre.ID)
+		}
+	}
+
+	d.artistGenres[artistID] = ids
+	d.refreshArtistGenres(artistID)
+
+	return nil
+}
+
+// refreshArtistGenres sets the Genres of an artist to the names of its genres, like the postgres genres column
+func (d *memoryData) refreshArtistGenres(artistID int) {
+	artist, ok := d.artists[artistID]
+	if !ok {
+		return
+	}
+
+	var names []string
+	for _, genre := range d.artistGenreList(artistID) {
+		names = append(names, genre.Name)
+	}
+
+	artist.Genres = strings.Join(names, ", ")
+	artist.GenreList = nil
+	d.artists[artistID] = artist
+}
+
+// genreArtistIDs returns the ids of the artists with a genre
+func (d *memoryData) genreArtistIDs(genreID int) []int {
+	var ids []int
+
+	for artistID, genreIDs := range d.artistGenres {
+		if slices.Contains(genreIDs, genreID) {
+			ids = append(ids, artistID)
+		}
+	}
+
+	return ids
+}
+
+// genreWithCount returns a copy of a genre with the number of approved artists that have it
+func (d *memoryData) genreWithCount(genre models.Genre) models.Genre {
+	genre = cloneGenre(genre)
+	genre.Artists = 0
+	for _, artistID := range d.genreArtistIDs(genre.ID) {
+		if d.artists[artistID].Status == listing.Approved {
+			genre.Artists++
+		}
+	}
+
+	return genre
+}
+
+// genreBySlug returns the genre with slug, or the genre with an alias with that slug
+func (d *memoryData) genreBySlug(slug string) (models.Genre, bool) {
+	for _, genre := range d.genres {
+		if genre.Slug == slug {
+			return genre, true
+		}
+		for _, alias := range genre.Aliases {
+			if genres.Slug(alias) == slug {
+				return genre, true
+			}
+		}
+	}
+
+	return models.Genre{}, false
+}
+
+// genreTaken reports whether the name or an alias of a genre has the slug of a genre or alias of another genre
+func (d *memoryData) genreTaken(genre models.Genre) bool {
+	for _, name := range append([]string{genre.Name}, genre.Aliases...) {
+		other, found := d.genreBySlug(genres.Slug(name))
+		if found && other.ID != genre.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// genreWords returns the names and aliases of the genres of an artist, which searches match like the genres
+func (d *memoryData) genreWords(artistID int) string {
+	var words []string
+
+	for _, id := range d.artistGenres[artistID] {
+		words = append(words, d.genres[id].Name)
+		words = append(words, d.genres[id].Aliases...)
+	}
+
+	return strings.Join(words, " ")
+}
+
+// AllGenres returns every genre by name
+func (m *memoryDBRepo) AllGenres(ctx context.Context) ([]models.Genre, error) {
+	var list []models.Genre
+
+	err := m.read(ctx, func(d *memoryData) error {
+		for _, genre := range d.genres {
+			list = append(list, d.genreWithCount(genre))
+		}
+		return nil
+	})
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list, err
+}
+
+// GetGenreByID returns a genre by id
+func (m *memoryDBRepo) GetGenreByID(ctx context.Context, id int) (models.Genre, error) {
+	var genre models.Genre
+
+	err := m.read(ctx, func(d *memoryData) error {
+		g, ok := d.genres[id]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		genre = d.genreWithCount(g)
+		return nil
+	})
+
+	return genre, err
+}
+
+// GetGenreBySlug returns the genre with slug, or the genre with an alias with that slug
+func (m *memoryDBRepo) GetGenreBySlug(ctx context.Context, slug string) (models.Genre, error) {
+	var genre models.Genre
+
+	err := m.read(ctx, func(d *memoryData) error {
+		g, found := d.genreBySlug(slug)
+		if !found {
+			return sql.ErrNoRows
+		}
+		genre = d.genreWithCount(g)
+		return nil
+	})
+
+	return genre, err
+}
+
+// CreateGenre inserts a genre with its aliases and returns its id
+func (m *memoryDBRepo) CreateGenre(ctx context.Context, genre models.Genre) (int, error) {
+	err := m.write(ctx, func(d *memoryData) error {
+		if d.genreTaken(genre) {
+			return repository.ErrGenreTaken
+		}
+
+		genre.ID = d.nextID("genres")
+		genre.Slug = genres.Slug(genre.Name)
+		genre.Artists = 0
+		genre.CreatedAt = time.Now()
+		genre.UpdatedAt = time.Now()
+		d.genres[genre.ID] = genre
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return genre.ID, nil
+}
+
+// UpdateGenre renames a genre and replaces its aliases, then refreshes the genres of its artists
+func (m *memoryDBRepo) UpdateGenre(ctx context.Context, genre models.Genre) error {
+	return m.write(ctx, func(d *memoryData) error {
+		g, ok := d.genres[genre.ID]
+		if !ok {
+			return nil
+		}
+		if d.genreTaken(genre) {
+			return repository.ErrGenreTaken
+		}
+
+		g.Name = genre.Name
+		g.Slug = genres.Slug(genre.Name)
+		g.Aliases = slices.Clone(genre.Aliases)
+		g.UpdatedAt = time.Now()
+		d.genres[g.ID] = g
+
+		for _, artistID := range d.genreArtistIDs(g.ID) {
+			d.refreshArtistGenres(artistID)
+		}
+		return nil
+	})
+}
+
+// MergeGenre moves the artists of genre id to genre into, keeps the name and aliases of id as aliases of into
+// and deletes genre id
+func (m *memoryDBRepo) MergeGenre(ctx context.Context, id, into int) error {
+	if id == into {
+		return errGenreMergedIntoItself
+	}
+
+	return m.write(ctx, func(d *memoryData) error {
+		from, ok := d.genres[id]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		target, ok := d.genres[into]
+		if !ok {
+			return errors.New("genre does not exist")
+		}
+
+		artistIDs := d.genreArtistIDs(id)
+		for _, artistID := range artistIDs {
+			genreIDs := slices.DeleteFunc(slices.Clone(d.artistGenres[artistID]), func(genreID int) bool {
+				return genreID == id
+			})
+			if !slices.Contains(genreIDs, into) {
+				genreIDs = append(genreIDs, into)
+			}
+			d.artistGenres[artistID] = genreIDs
+		}
+
+		delete(d.genres, id)
+
+		target.Aliases = append(slices.Clone(target.Aliases), from.Name)
+		target.Aliases = append(target.Aliases, from.Aliases...)
+		target.UpdatedAt = time.Now()
+		d.genres[into] = target
+
+		for _, artistID := range artistIDs {
+			d.refreshArtistGenres(artistID)
+		}
+		return nil
+	})
+}
+
+// DeleteGenre deletes a genre and takes it off its artists, like the cascading foreign key
+func (m *memoryDBRepo) DeleteGenre(ctx context.Context, id int) error {
+	return m.write(ctx, func(d *memoryData) error {
+		artistIDs := d.genreArtistIDs(id)
+		for _, artistID := range artistIDs {
+			d.artistGenres[artistID] = slices.DeleteFunc(slices.Clone(d.artistGenres[artistID]), func(genreID int) bool {
+				return genreID == id
+			})
+		}
+
+		delete(d.genres, id)
+
+		for _, artistID := range artistIDs {
+			d.refreshArtistGenres(artistID)
+		}
+		return nil
+	})
+}
+
+// AllBookings returns a slice of all bookings
+func (m *memoryDBRepo) AllBookings(ctx context.Context) ([]models.Bookings, error) {
+	var bookings []models.Bookings
+
+	err := m.read(ctx, func(d *memoryData) error {
+		bookings = d.bookingsWhere(func(models.Bookings) bool { return true })
+		return nil
+	})
+
+	return bookings, err
+}
+
+// AllNewBookings returns a slice of all pending bookings
+func (m *memoryDBRepo) AllNewBookings(ctx context.Context) ([]models.Bookings, error) {
+	return m.AllBookingsByStatus(ctx, status.Pending)
+}
+
+// AllBookingsByStatus returns a slice of all bookings in the given status
+func (m *memoryDBRepo) AllBookingsByStatus(ctx context.Context, bookingStatus string) ([]models.Bookings, error) {
+	var bookings []models.Bookings
+
+	err := m.read(ctx, func(d *memoryData) error {
+		bookings = d.bookingsWhere(func(b models.Bookings) bool { return b.Status == bookingStatus })
+		return nil
+	})
+
+	return bookings, err
+}
+
+// AllBookingsByArtistID returns the bookings of an artist, earliest start date first
+func (m *memoryDBRepo) AllBookingsByArtistID(ctx context.Context, artistID int) ([]models.Bookings, error) {
+	var bookings []models.Bookings
+
+	err := m.read(ctx, func(d *memoryData) error {
+		bookings = d.bookingsWhere(func(b models.Bookings) bool { return b.ArtistID == artistID })
+		return nil
+	})
+
+	return bookings, err
+}
+
+// AllBookingsByUserID returns the bookings of a customer account with the option booked, earliest start date first
+func (m *memoryDBRepo) AllBookingsByUserID(ctx context.Context, userID int) ([]models.Bookings, error) {
+	var bookings []models.Bookings
+
+	err := m.read(ctx, func(d *memoryData) error {
+		bookings = d.bookingsWhere(func(b models.Bookings) bool { return b.UserID == userID })
+		return nil
+	})
+
+	return bookings, err
+}
+
+// AllReservationsByUserID returns the room reservations of a customer account, earliest start date first
+func (m *memoryDBRepo) AllReservationsByUserID(ctx context.Context, userID int) ([]models.Reservation, error) {
+	var reservations []models.Reservation
+
+	err := m.read(ctx, func(d *memoryData) error {
+		reservations = d.reservationsWhere(func(res models.Reservation) bool { return res.UserID == userID })
+		return nil
+	})
+
+	return reservations, err
+}
+
+// ClaimBookings links the guest bookings and room reservations made with email to a customer account and
+// returns how many it linked
+func (m *memoryDBRepo) ClaimBookings(ctx context.Context, userID int, email string) (int, error) {
+	var claimed int
+
+	err := m.write(ctx, func(d *memoryData) error {
+		for id, booking := range d.bookings {
+			if booking.UserID == 0 && strings.EqualFold(booking.Email, email) {
+				booking.UserID = userID
+				booking.UpdatedAt = time.Now()
+				d.bookings[id] = booking
+				claimed++
+			}
+		}
+
+		for id, res := range d.reservations {
+			if res.UserID == 0 && strings.EqualFold(res.Email, email) {
+				res.UserID = userID
+				res.UpdatedAt = time.Now()
+				d.reservations[id] = res
+				claimed++
+			}
+		}
+		return nil
+	})
+
+	return claimed, err
+}
+
+// InsertBooking inserts a booking and the artist restriction that blocks its dates. Nothing is stored when
+// the dates are already taken
+func (m *memoryDBRepo) InsertBooking(ctx context.Context, booking models.Bookings) (int, error) {
+	var newID int
+
+	err := m.write(ctx, func(d *memoryData) error {
+		if _, ok := d.artists[booking.ArtistID]; !ok {
+			return errors.New("artist does not exist")
+		}
+
+		if d.artistTaken(booking.ArtistID, booking.StartDate, booking.EndDate, 0) {
+			return repository.ErrUnavailable
+		}
+
+		booking.ID = d.nextID("bookings")
+		booking.StartDate = day(booking.StartDate)
+		booking.EndDate = day(booking.EndDate)
+		booking.Status = status.Pending
+		booking.Artist = models.Artist{}
+		booking.BookingOption = models.BookingOptions{}
+		booking.CreatedAt = time.Now()
+		booking.UpdatedAt = time.Now()
+		d.bookings[booking.ID] = booking
+
+		newID = booking.ID
+
+		return d.addArtistRestriction(models.ArtistRestriction{
+			StartDate:     booking.StartDate,
+			EndDate:       booking.EndDate,
+			ArtistID:      booking.ArtistID,
+			BookingID:     booking.ID,
+			RestrictionID: 1,
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return newID, nil
+}
+
+// SearchAvailabilityByDatesByArtistID returns true if availability exists for artistID, and false if no availability
+func (m *memoryDBRepo) SearchAvailabilityByDatesByArtistID(ctx context.Context, start, end time.Time, artistID int) (bool, error) {
+	available := false
+
+	err := m.read(ctx, func(d *memoryData) error {
+		available = !d.artistTaken(artistID, start, end, 0)
+		return nil
+	})
+
+	return available, err
+}
+
+// SearchArtists returns the approved artists matching filter and how many match in all
+func (m *memoryDBRepo) SearchArtists(ctx context.Context, filter repository.ArtistFilter) ([]models.Artist, int, error) {
+	var artists []models.Artist
+
+	words := searchWords(filter.Query)
+	scores := make(map[int]int)
+
+	err := m.read(ctx, func(d *memoryData) error {
+		genre, found := d.genreBySlug(filter.Genre)
+		if filter.Genre != "" && !found {
+			return nil
+		}
+
+		for _, artist := range d.artists {
+			if artist.Status != listing.Approved {
+				continue
+			}
+			score, ok := searchScore(artist, d.genreWords(artist.ID), words)
+			if !ok {
+				continue
+			}
+			scores[artist.ID] = score
+			if filter.Genre != "" && !slices.Contains(d.artistGenres[artist.ID], genre.ID) {
+				continue
+			}
+			if filter.City != "" && !strings.EqualFold(artist.City, filter.City) {
+				continue
+			}
+
+			price, priced := d.fromPrice(artist.ID)
+			if filter.MinPrice > 0 && (!priced || price < filter.MinPrice) {
+				continue
+			}
+			if filter.MaxPrice > 0 && (!priced || price > filter.MaxPrice) {
+				continue
+			}
+
+			if !filter.AvailableFrom.IsZero() && d.artistTaken(artist.ID, filter.AvailableFrom, filter.AvailableTo, 0) {
+				continue
+			}
+
+			artist.FromPrice = price
+			artists = append(artists, artist)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.Slice(artists, func(i, j int) bool {
+		a, b := artists[i], artists[j]
+		switch filter.Sort {
+		case repository.SortNewest:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.After(b.CreatedAt)
+			}
+			return a.ID > b.ID
+		case repository.SortPrice:
+			// artists without a price go last
+			if (a.FromPrice == 0) != (b.FromPrice == 0) {
+				return b.FromPrice == 0
+			}
+			if a.FromPrice != b.FromPrice {
+				return a.FromPrice < b.FromPrice
+			}
+		case repository.SortRelevance:
+			if scores[a.ID] != scores[b.ID] {
+				return scores[a.ID] > scores[b.ID]
+			}
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+
+	total := len(artists)
+	if filter.Limit > 0 {
+		artists = artists[min(filter.Offset, total):min(filter.Offset+filter.Limit, total)]
+	} else {
+		artists = artists[min(filter.Offset, total):]
+	}
+
+	return artists, total, nil
+}
+
+// SuggestArtists returns up to limit approved artists matching q for the search box, most relevant first
+func (m *memoryDBRepo) SuggestArtists(ctx context.Context, q string, limit int) ([]models.ArtistSuggestion, error) {
+	var suggestions []models.ArtistSuggestion
+
+	words := searchWords(q)
+	if len(words) == 0 {
+		return suggestions, nil
+	}
+
+	artists, _, err := m.SearchArtists(ctx, repository.ArtistFilter{Query: q, Sort: repository.SortRelevance, Limit: limit})
+	if err != nil {
+		return suggestions, err
+	}
+
+	for _, artist := range artists {
+		suggestions = append(suggestions, models.ArtistSuggestion{
+			ID:          artist.ID,
+			Name:        artist.Name,
+			Genres:      artist.Genres,
+			City:        artist.City,
+			NameHTML:    markedHTML(highlightWords(artist.Name, words)),
+			SnippetHTML: markedHTML(highlightWords(artist.Description, words)),
+		})
+	}
+
+	return suggestions, nil
+}
+
+// searchScore reports whether every word starts a word of the artist, and scores the match the way the
+// weights of the postgres search_vector do: a name counts most, then genres, their aliases and the city,
+// then the description. genreWords are the names and aliases of the artist's genres
+func searchScore(artist models.Artist, genreWords string, words []string) (int, bool) {
+	fields := []struct {
+		words  []string
+		weight int
+	}{
+		{searchWords(artist.Name), 4},
+		{searchWords(genreWords + " " + artist.City), 2},
+		{searchWords(artist.Description), 1},
+	}
+
+	score := 0
+	for _, word := range words {
+		matched := false
+		for _, field := range fields {
+			for _, w := range field.words {
+				if strings.HasPrefix(w, word) {
+					score += field.weight
+					matched = true
+				}
+			}
+		}
+		if !matched {
+			return 0, false
+		}
+	}
+
+	return score, true
+}
+
+// highlightWords puts highlight markers around the words of text that start with one of words
+func highlightWords(text string, words []string) string {
+	var b strings.Builder
+
+	for _, field := range strings.Fields(text) {
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+
+		matched := false
+		for _, w := range searchWords(field) {
+			for _, word := range words {
+				matched = matched || strings.HasPrefix(w, word)
+			}
+		}
+
+		if matched {
+			b.WriteString(highlightStart + field + highlightStop)
+		} else {
+			b.WriteString(field)
+		}
+	}
+
+	return b.String()
+}
+
+// fromPrice returns the price of the cheapest booking option of an artist. Prices are free text, so the
+// first number in each one is taken as its price, like the postgres repository does
+func (d *memoryData) fromPrice(artistID int) (int, bool) {
+	cheapest, priced := 0, false
+
+	for _, option := range d.bookingOptions {
+		if option.ArtistID != artistID {
+			continue
+		}
+
+		digits := priceDigits.FindString(strings.ReplaceAll(option.Price, ",", ""))
+		price, err := strconv.Atoi(digits)
+		if err != nil {
+			continue
+		}
+
+		if !priced || price < cheapest {
+			cheapest, priced = price, true
+		}
+	}
+
+	return cheapest, priced
+}
+
+// priceDigits finds the first number in a price
+var priceDigits = regexp.MustCompile(`[0-9]+`)
+
+// GetRestrictionsForCurrentArtist returns restrictions for an artist by date range
+func (m *memoryDBRepo) GetRestrictionsForCurrentArtist(ctx context.Context, artistID int, start, end time.Time) ([]models.ArtistRestriction, error) {
+	var restrictions []models.ArtistRestriction
+
+	err := m.read(ctx, func(d *memoryData) error {
+		for _, r := range d.artistRestrictions {
+			if r.ArtistID == artistID && overlaps(start, end, r.StartDate, r.EndDate) {
+				restrictions = append(restrictions, r)
+			}
+		}
+		return nil
+	})
+
+	sort.Slice(restrictions, func(i, j int) bool {
+		return restrictions[i].StartDate.Before(restrictions[j].StartDate)
+	})
+
+	return restrictions, err
+}
+
+// GetBookingByID returns one booking by ID
+func (m *memoryDBRepo) GetBookingByID(ctx context.Context, id int) (models.Bookings, error) {
+	var booking models.Bookings
+
+	err := m.read(ctx, func(d *memoryData) error {
+		b, ok := d.bookings[id]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		booking = d.bookingWithArtist(b)
+		return nil
+	})
+
+	return booking, err
+}
+
+// UpdateBooking updates a booking's customer details
+func (m *memoryDBRepo) UpdateBooking(ctx context.Context, booking models.Bookings) error {
+	return m.write(ctx, func(d *memoryData) error {
+		b, ok := d.bookings[booking.ID]
+		if !ok {
+			return nil
+		}
+
+		b.FirstName = booking.FirstName
+		b.LastName = booking.LastName
+		b.Email = booking.Email
+		b.Phone = booking.Phone
+		b.EventLocation = booking.EventLocation
+		b.Message = booking.Message
+		b.UpdatedAt = time.Now()
+		d.bookings[b.ID] = b
+		return nil
+	})
+}
+
+// UpdateBookingStatus moves a booking from one status to another and records the change in its history.
+// Cancelling a booking also frees its dates on the artists calendar
+func (m *memoryDBRepo) UpdateBookingStatus(ctx context.Context, id int, from, to string, userID int) error {
+	return m.write(ctx, func(d *memoryData) error {
+		booking, ok := d.bookings[id]
+		if !ok || booking.Status != from {
+			return status.ErrStaleStatus
+		}
+
+		booking.Status = to
+		booking.UpdatedAt = time.Now()
+		d.bookings[id] = booking
+
+		change := d.statusChange("booking_status_changes", from, to, userID)
+		d.bookingHistory[id] = append(d.bookingHistory[id], change)
+
+		if to == status.Cancelled {
+			for rid, r := range d.artistRestrictions {
+				if r.BookingID == id {
+					delete(d.artistRestrictions, rid)
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetBookingStatusHistory returns the status changes of a booking, oldest first
+func (m *memoryDBRepo) GetBookingStatusHistory(ctx context.Context, id int) ([]models.StatusChange, error) {
+	var changes []models.StatusChange
+
+	err := m.read(ctx, func(d *memoryData) error {
+		changes = append(changes, d.bookingHistory[id]...)
+		return nil
+	})
+
+	return changes, err
+}
+
+// RescheduleBooking moves a booking and its artist restriction to new dates.
+// It returns false without changing anything when the new dates clash with another booking or block
+func (m *memoryDBRepo) RescheduleBooking(ctx context.Context, id int, startDate, endDate time.Time) (bool, error) {
+	available := false
+
+	err := m.write(ctx, func(d *memoryData) error {
+		booking, ok := d.bookings[id]
+		if !ok {
+			return sql.ErrNoRows
+		}
+
+		if d.artistTaken(booking.ArtistID, startDate, endDate, id) {
+			return nil
+		}
+
+		booking.StartDate = day(startDate)
+		booking.EndDate = day(endDate)
+		booking.UpdatedAt = time.Now()
+		d.bookings[id] = booking
+
+		for rid, r := range d.artistRestrictions {
+			if r.BookingID == id {
+				r.StartDate = booking.StartDate
+				r.EndDate = booking.EndDate
+				r.UpdatedAt = time.Now()
+				d.artistRestrictions[rid] = r
+			}
+		}
+
+		available = true
+		return nil
+	})
+
+	return available, err
+}
+
+// InsertBlockForArtist inserts an owner block for an artist from startDate to endDate
+func (m *memoryDBRepo) InsertBlockForArtist(ctx context.Context, id int, startDate, endDate time.Time, reason string) error {
+	return m.write(ctx, func(d *memoryData) error {
+		return d.addArtistRestriction(models.ArtistRestriction{
+			StartDate:     startDate,
+			EndDate:       endDate,
+			ArtistID:      id,
+			RestrictionID: 2,
+			Reason:        reason,
+		})
+	})
+}
+
+// DeleteArtistBlockByID deletes an artist restriction that is not tied to a booking
+func (m *memoryDBRepo) DeleteArtistBlockByID(ctx context.Context, id int) error {
+	return m.write(ctx, func(d *memoryData) error {
+		if r, ok := d.artistRestrictions[id]; ok && r.BookingID == 0 {
+			delete(d.artistRestrictions, id)
+		}
+		return nil
+	})
+}
+
+// Get all booking options
+func (m *memoryDBRepo) AllBookingOptions(ctx context.Context) ([]models.BookingOptions, error) {
+	var options []models.BookingOptions
+
+	err := m.read(ctx, func(d *memoryData) error {
+		options = d.optionsWhere(func(models.BookingOptions) bool { return true })
+		return nil
+	})
+
+	return options, err
+}
+
+// Get all booking options of an artist
+func (m *memoryDBRepo) AllArtistBookingOptions(ctx context.Context, id int) ([]models.BookingOptions, error) {
+	var options []models.BookingOptions
+
+	err := m.read(ctx, func(d *memoryData) error {
+		options = d.optionsWhere(func(o models.BookingOptions) bool { return o.ArtistID == id })
+		return nil
+	})
+
+	return options, err
+}
+
+// Inserts a new booking option
+func (m *memoryDBRepo) CreateBookingOption(ctx context.Context, option models.BookingOptions) error {
+	return m.write(ctx, func(d *memoryData) error {
+		option.ID = d.nextID("booking_options")
+		option.CreatedAt = time.Now()
+		option.UpdatedAt = time.Now()
+		d.bookingOptions[option.ID] = option
+		return nil
+	})
+}
+
+// Get a booking option by id
+func (m *memoryDBRepo) GetBookingOptionByID(ctx context.Context, id int) (models.BookingOptions, error) {
+	var option models.BookingOptions
+
+	err := m.read(ctx, func(d *memoryData) error {
+		o, ok := d.bookingOptions[id]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		option = o
+		return nil
+	})
+
+	return option, err
+}
+
+// UpdateBookingOption updates a booking option
+func (m *memoryDBRepo) UpdateBookingOption(ctx context.Context, option models.BookingOptions) error {
+	return m.write(ctx, func(d *memoryData) error {
+		o, ok := d.bookingOptions[option.ID]
+		if !ok {
+			return nil
+		}
+
+		o.Title = option.Title
+		o.Description = option.Description
+		o.Price = option.Price
+		o.ArtistID = option.ArtistID
+		o.UpdatedAt = time.Now()
+		d.bookingOptions[o.ID] = o
+		return nil
+	})
+}
+
+// EnqueueEmail adds a message to the outbox, due right away
+func (m *memoryDBRepo) EnqueueEmail(ctx context.Context, message models.MailData) error {
+	return m.write(ctx, func(d *memoryData) error {
+		email := models.OutboxEmail{
+			ID:            d.nextID("outbox_emails"),
+			To:            message.To,
+			From:          message.From,
+			FromName:      message.FromName,
+			ReplyTo:       message.ReplyTo,
+			Subject:       message.Subject,
+			Content:       message.Content,
+			Text:          message.Text,
+			Template:      message.Template,
+			Status:        outbox.StatusPending,
+			NextAttemptAt: time.Now(),
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+		d.outbox[email.ID] = email
+		return nil
+	})
+}
+
+// ClaimDueEmails takes up to limit due emails, counts the attempt and pushes their next attempt past the lease
+func (m *memoryDBRepo) ClaimDueEmails(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEmail, error) {
+	var emails []models.OutboxEmail
+
+	err := m.write(ctx, func(d *memoryData) error {
+		now := time.Now()
+
+		for _, email := range d.outbox {
+			if email.Status == outbox.StatusPending && !email.NextAttemptAt.After(now) {
+				emails = append(emails, email)
+			}
+		}
+
+		sort.Slice(emails, func(i, j int) bool {
+			if emails[i].NextAttemptAt.Equal(emails[j].NextAttemptAt) {
+				return emails[i].ID < emails[j].ID
+			}
+			return emails[i].NextAttemptAt.Before(emails[j].NextAttemptAt)
+		})
+
+		if len(emails) > limit {
+			emails = emails[:limit]
+		}
+
+		for i := range emails {
+			emails[i].Attempts++
+			emails[i].NextAttemptAt = now.Add(lease)
+			emails[i].UpdatedAt = now
+			d.outbox[emails[i].ID] = emails[i]
+		}
+
+		return nil
+	})
+
+	return emails, err
+}
+
+// updateEmail applies fn to an outbox email if it exists
+func (m *memoryDBRepo) updateEmail(ctx context.Context, id int, fn func(email *models.OutboxEmail)) error {
+	return m.write(ctx, func(d *memoryData) error {
+		email, ok := d.outbox[id]
+		if !ok {
+			return nil
+		}
+
+		fn(&email)
+		email.UpdatedAt = time.Now()
+		d.outbox[id] = email
+		return nil
+	})
+}
+
+// MarkEmailSent records that an email was delivered
+func (m *memoryDBRepo) MarkEmailSent(ctx context.Context, id int) error {
+	return m.updateEmail(ctx, id, func(email *models.OutboxEmail) {
+		email.Status = outbox.StatusSent
+		email.LastError = ""
+		email.SentAt = time.Now()
+	})
+}
+
+// MarkEmailFailed records a failed attempt and when to try again
+func (m *memoryDBRepo) MarkEmailFailed(ctx context.Context, id int, lastError string, retryAt time.Time) error {
+	return m.updateEmail(ctx, id, func(email *models.OutboxEmail) {
+		email.LastError = lastError
+		email.NextAttemptAt = retryAt
+	})
+}
+
+// MarkEmailDead stops retrying an email
+func (m *memoryDBRepo) MarkEmailDead(ctx context.Context, id int, lastError string) error {
+	return m.updateEmail(ctx, id, func(email *models.OutboxEmail) {
+		email.Status = outbox.StatusDead
+		email.LastError = lastError
+	})
+}
+
+// AllOutboxEmails returns the outbox emails, newest first. An empty status returns every status
+func (m *memoryDBRepo) AllOutboxEmails(ctx context.Context, emailStatus string) ([]models.OutboxEmail, error) {
+	var emails []models.OutboxEmail
+
+	err := m.read(ctx, func(d *memoryData) error {
+		for _, email := range d.outbox {
+			if emailStatus == "" || email.Status == emailStatus {
+				emails = append(emails, email)
+			}
+		}
+		return nil
+	})
+
+	sort.Slice(emails, func(i, j int) bool {
+		return emails[i].ID > emails[j].ID
+	})
+
+	return emails, err
+}
+
+// ResendEmail puts an email that was not sent back in the queue with a fresh set of attempts
+func (m *memoryDBRepo) ResendEmail(ctx context.Context, id int) error {
+	return m.updateEmail(ctx, id, func(email *models.OutboxEmail) {
+		if email.Status == outbox.StatusSent {
+			return
+		}
+
+		email.Status = outbox.StatusPending
+		email.Attempts = 0
+		email.NextAttemptAt = time.Now()
+	})
+}
+
+// AllSettings returns the saved settings by key
+func (m *memoryDBRepo) AllSettings(ctx context.Context) (map[string]string, error) {
+	var values map[string]string
+
+	err := m.read(ctx, func(d *memoryData) error {
+		values = maps.Clone(d.settings)
+		return nil
+	})
+
+	return values, err
+}
+
+// UpdateSettings saves values by key, adding the keys that don't exist yet
+func (m *memoryDBRepo) UpdateSettings(ctx context.Context, values map[string]string) error {
+	return m.write(ctx, func(d *memoryData) error {
+		maps.Copy(d.settings, values)
+		return nil
+	})
+}
+
+// seedMemoryData returns the fixtures the memory repo starts with: the seeded rooms, an admin, a staff and an artist user,
+// a few artists with booking options and one pending reservation and booking
+func seedMemoryData() *memoryData {
+	d := &memoryData{
+		lastID:             make(map[string]int),
+		users:              make(map[int]models.User),
+		passwordResets:     make(map[int]models.PasswordReset),
+		emailVerifications: make(map[int]models.EmailVerification),
+		rooms:              make(map[int]models.Room),
+		reservations:       make(map[int]models.Reservation),
+		roomRestrictions:   make(map[int]models.RoomRestriction),
+		todos:              make(map[int]models.TodoList),
+		artists:            make(map[int]models.Artist),
+		bookings:           make(map[int]models.Bookings),
+		artistRestrictions: make(map[int]models.ArtistRestriction),
+		bookingOptions:     make(map[int]models.BookingOptions),
+		outbox:             make(map[int]models.OutboxEmail),
+		settings:           make(map[string]string),
+		genres:             make(map[int]models.Genre),
+		artistGenres:       make(map[int][]int),
+		reservationHistory: make(map[int][]models.StatusChange),
+		bookingHistory:     make(map[int][]models.StatusChange),
+	}
+
+	now := time.Now()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(MemoryAdminPassword), 10)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, user := range []models.User{
+		{FirstName: "Admin", LastName: "User", Email: MemoryAdminEmail, Role: roles.Admin},
+		{FirstName: "Staff", LastName: "User", Email: MemoryStaffEmail, Role: roles.Staff},
+		{FirstName: "Kofi", LastName: "Boateng", Email: MemoryArtistEmail, Role: roles.Artist},
+	} {
+		user.ID = d.nextID("users")
+		user.Password = string(hashedPassword)
+		user.Verified = true
+		user.CreatedAt = now
+		user.UpdatedAt = now
+		d.users[user.ID] = user
+	}
+	artistOwnerID := d.lastID["users"]
+
+	rooms := []models.Room{
+		{RoomName: "Generals Suit", Price: "150", ImageSource: "/static/images/room-images/generals-quarters.png",
+			Description: "A quiet suite with a queen size bed, a desk and a view of the garden."},
+		{RoomName: "Luxery One", Price: "220", ImageSource: "/static/images/room-images/marjors-suite.png",
+			Description: "Our largest room, with a king size bed, a lounge area and a private balcony."},
+	}
+	for _, room := range rooms {
+		room.ID = d.nextID("rooms")
+		room.CreatedAt = now
+		room.UpdatedAt = now
+		d.rooms[room.ID] = room
+	}
+
+	for _, genre := range []models.Genre{
+		{Name: "Afrobeat", Aliases: []string{"Afrobeats", "Afro Beat"}},
+		{Name: "Amapiano"},
+		{Name: "Classical"},
+		{Name: "Highlife"},
+		{Name: "Hip Hop", Aliases: []string{"Rap"}},
+		{Name: "Jazz"},
+	} {
+		genre.ID = d.nextID("genres")
+		genre.Slug = genres.Slug(genre.Name)
+		genre.CreatedAt = now
+		genre.UpdatedAt = now
+		d.genres[genre.ID] = genre
+	}
+
+	// artists are given their genres by name, which are looked up by the slug of a genre or alias
+	artists := []models.Artist{
+		{Name: "The Lagos Horns", Genres: "Afrobeat, Highlife", City: "Lagos", Email: "horns@example.com",
+			Phone: "+234 800 000 0001", Description: "A seven piece brass band for weddings and parties."},
+		{Name: "Ama Strings", Genres: "Classical, Jazz", City: "Accra", Email: "ama@example.com",
+			Phone: "+233 200 000 0002", Description: "String quartet for ceremonies and dinners."},
+		{Name: "DJ Kofi", Genres: "Hip Hop, Afrobeats, Amapiano", City: "Lagos", Email: "kofi@example.com",
+			Phone: "+234 800 000 0003", Description: "Club and event DJ with his own sound system.", UserID: artistOwnerID},
+	}
+	for _, artist := range artists {
+		artist.ID = d.nextID("artists")
+		artist.Status = listing.Approved
+		artist.CreatedAt = now
+		artist.UpdatedAt = now
+		d.artists[artist.ID] = artist
+
+		for _, name := range genres.Split(artist.Genres) {
+			if genre, found := d.genreBySlug(genres.Slug(name)); found {
+				d.artistGenres[artist.ID] = append(d.artistGenres[artist.ID], genre.ID)
+			}
+		}
+		d.refreshArtistGenres(artist.ID)
+
+		for _, option := range []models.BookingOptions{
+			{Title: "Short set", Description: "Up to two hours of live music.", Price: "500"},
+			{Title: "Full event", Description: "Up to six hours of live music with breaks.", Price: "1200"},
+		} {
+			option.ID = d.nextID("booking_options")
+			option.ArtistID = artist.ID
+			option.CreatedAt = now
+			option.UpdatedAt = now
+			d.bookingOptions[option.ID] = option
+		}
+	}
+
+	start := day(now.AddDate(0, 0, 14))
+
+	reservation := models.Reservation{
+		ID:        d.nextID("reservations"),
+		FirstName: "Jane",
+		LastName:  "Doe",
+		Email:     "jane@example.com",
+		Phone:     "555-0100",
+		StartDate: start,
+		EndDate:   start.AddDate(0, 0, 2),
+		RoomID:    1,
+		Status:    status.Pending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	d.reservations[reservation.ID] = reservation
+	d.addRoomRestriction(models.RoomRestriction{
+		StartDate:     reservation.StartDate,
+		EndDate:       reservation.EndDate,
+		RoomID:        reservation.RoomID,
+		ReservationID: reservation.ID,
+		RestrictionID: 1,
+	})
+
+	booking := models.Bookings{
+		ID:              d.nextID("bookings"),
+		FirstName:       "John",
+		LastName:        "Smith",
+		Email:           "john@example.com",
+		Phone:           "555-0101",
+		StartDate:       start,
+		EndDate:         start,
+		Status:          status.Pending,
+		ArtistID:        1,
+		BookingOptionID: 1,
+		EventLocation:   "Victoria Island, Lagos",
+		Message:         "Wedding reception, about 150 guests.",
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	d.bookings[booking.ID] = booking
+	d.addArtistRestriction(models.ArtistRestriction{
+		StartDate:     booking.StartDate,
+		EndDate:       booking.EndDate,
+		ArtistID:      booking.ArtistID,
+		BookingID:     booking.ID,
+		RestrictionID: 1,
+	})
+
+	return d
+}
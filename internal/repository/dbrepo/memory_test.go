@@ -0,0 +1,763 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aidisapp/musiqcity_v2/internal/listing"
+	"github.com/aidisapp/musiqcity_v2/internal/models"
+	"github.com/aidisapp/musiqcity_v2/internal/repository"
+	"github.com/aidisapp/musiqcity_v2/internal/roles"
+	"github.com/aidisapp/musiqcity_v2/internal/status"
+)
+
+var ctx = context.Background()
+
+func date(s string) time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return t
+}
+
+func TestMemoryRoomAvailability(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	id, err := repo.InsertReservation(ctx, models.Reservation{RoomID: 1, StartDate: date("2050-01-10"), EndDate: date("2050-01-12")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.InsertRoomRestriction(ctx, models.RoomRestriction{RoomID: 1, ReservationID: id, RestrictionID: 1,
+		StartDate: date("2050-01-10"), EndDate: date("2050-01-12")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		start     string
+		end       string
+		available bool
+	}{
+		{"2050-01-01", "2050-01-09", true},
+		{"2050-01-08", "2050-01-10", false},
+		{"2050-01-11", "2050-01-11", false},
+		{"2050-01-12", "2050-01-15", false},
+		{"2050-01-13", "2050-01-15", true},
+	}
+
+	for _, e := range tests {
+		available, err := repo.SearchAvailabilityByDatesByRoomID(ctx, date(e.start), date(e.end), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if available != e.available {
+			t.Errorf("%s to %s: expected available %v but got %v", e.start, e.end, e.available, available)
+		}
+	}
+
+	rooms, _ := repo.SearchAvailabilityForAllRooms(ctx, date("2050-01-11"), date("2050-01-11"))
+	if len(rooms) != 1 || rooms[0].ID != 2 {
+		t.Errorf("expected only room 2 to be free but got %v", rooms)
+	}
+
+	err = repo.InsertBlockForRoom(ctx, 1, date("2050-01-12"), date("2050-01-14"), "painting")
+	if !errors.Is(err, repository.ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable for an overlapping block but got %v", err)
+	}
+}
+
+func TestMemoryInsertBooking(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	booking := models.Bookings{ArtistID: 2, StartDate: date("2050-03-01"), EndDate: date("2050-03-01")}
+
+	id, err := repo.InsertBooking(ctx, booking)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := repo.GetBookingByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Status != status.Pending || saved.Artist.ID != 2 {
+		t.Errorf("unexpected booking %+v", saved)
+	}
+
+	_, err = repo.InsertBooking(ctx, booking)
+	if !errors.Is(err, repository.ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable for a double booking but got %v", err)
+	}
+
+	bookings, _ := repo.AllBookings(ctx)
+	for _, b := range bookings {
+		if b.ID != id && b.ArtistID == 2 {
+			t.Errorf("the failed booking was stored: %+v", b)
+		}
+	}
+
+	artists, _, _ := repo.SearchArtists(ctx, repository.ArtistFilter{Genre: "jazz", AvailableFrom: date("2050-03-01"), AvailableTo: date("2050-03-01")})
+	if len(artists) != 0 {
+		t.Errorf("expected no free jazz artists but got %v", artists)
+	}
+}
+
+func TestMemoryInsertBookingConcurrently(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.InsertBooking(ctx, models.Bookings{ArtistID: 3, StartDate: date("2050-05-01"), EndDate: date("2050-05-02")})
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Errorf("expected exactly one booking to succeed but %d did", succeeded)
+	}
+}
+
+func TestMemoryWithTxRollsBack(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	err := repo.WithTx(ctx, func(tx repository.DatabaseRepo) error {
+		id, err := tx.InsertReservation(ctx, models.Reservation{RoomID: 2, StartDate: date("2050-02-01"), EndDate: date("2050-02-03")})
+		if err != nil {
+			return err
+		}
+
+		err = tx.InsertRoomRestriction(ctx, models.RoomRestriction{RoomID: 2, ReservationID: id, RestrictionID: 1,
+			StartDate: date("2050-02-01"), EndDate: date("2050-02-03")})
+		if err != nil {
+			return err
+		}
+
+		return tx.InsertBlockForRoom(ctx, 2, date("2050-02-02"), date("2050-02-02"), "")
+	})
+	if !errors.Is(err, repository.ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable but got %v", err)
+	}
+
+	available, _ := repo.SearchAvailabilityByDatesByRoomID(ctx, date("2050-02-01"), date("2050-02-03"), 2)
+	if !available {
+		t.Error("the restriction of the rolled back transaction was kept")
+	}
+
+	reservations, _ := repo.AllReservations(ctx)
+	for _, res := range reservations {
+		if res.RoomID == 2 {
+			t.Errorf("the reservation of the rolled back transaction was kept: %+v", res)
+		}
+	}
+}
+
+// reservationsByEmail returns the reservations made with email
+func reservationsByEmail(t *testing.T, repo repository.DatabaseRepo, email string) []models.Reservation {
+	reservations, err := repo.AllReservations(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var found []models.Reservation
+	for _, res := range reservations {
+		if res.Email == email {
+			found = append(found, res)
+		}
+	}
+	return found
+}
+
+func TestMemoryWithTxRestrictionFails(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+	if err := repo.InsertBlockForRoom(ctx, 2, date("2050-03-02"), date("2050-03-02"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	err := repo.WithTx(ctx, func(tx repository.DatabaseRepo) error {
+		id, err := tx.InsertReservation(ctx, models.Reservation{RoomID: 2, Email: "orphan@example.com", StartDate: date("2050-03-01"), EndDate: date("2050-03-03")})
+		if err != nil {
+			return err
+		}
+
+		return tx.InsertRoomRestriction(ctx, models.RoomRestriction{RoomID: 2, ReservationID: id, RestrictionID: 1,
+			StartDate: date("2050-03-01"), EndDate: date("2050-03-03")})
+	})
+	if !errors.Is(err, repository.ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable but got %v", err)
+	}
+
+	if orphans := reservationsByEmail(t, repo, "orphan@example.com"); len(orphans) != 0 {
+		t.Errorf("the reservation was kept without its restriction: %+v", orphans)
+	}
+}
+
+func TestMemoryCancelledContext(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	called := false
+	err := repo.WithTx(cancelled, func(tx repository.DatabaseRepo) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || called {
+		t.Errorf("expected the transaction not to start but got %v, called %v", err, called)
+	}
+
+	if _, err := repo.AllReservations(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a read to be aborted but got %v", err)
+	}
+
+	// cancelling half way through a transaction aborts the next step and throws away the earlier ones
+	running, cancel := context.WithCancel(ctx)
+	err = repo.WithTx(running, func(tx repository.DatabaseRepo) error {
+		id, err := tx.InsertReservation(running, models.Reservation{RoomID: 2, Email: "cancelled@example.com", StartDate: date("2050-03-01"), EndDate: date("2050-03-03")})
+		if err != nil {
+			return err
+		}
+
+		cancel()
+
+		return tx.InsertRoomRestriction(running, models.RoomRestriction{RoomID: 2, ReservationID: id, RestrictionID: 1,
+			StartDate: date("2050-03-01"), EndDate: date("2050-03-03")})
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the transaction to be aborted but got %v", err)
+	}
+
+	if kept := reservationsByEmail(t, repo, "cancelled@example.com"); len(kept) != 0 {
+		t.Errorf("the reservation of the cancelled transaction was kept: %+v", kept)
+	}
+}
+
+func TestMemoryWithTxRollsBackSlices(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+	hipHop, _ := repo.GetGenreBySlug(ctx, "hip-hop")
+	jazz, _ := repo.GetGenreBySlug(ctx, "jazz")
+	kofi, _ := repo.GetArtistByIDAnyStatus(ctx, 3)
+
+	id, _ := repo.InsertReservation(ctx, models.Reservation{RoomID: 2, StartDate: date("2050-02-01"), EndDate: date("2050-02-03")})
+	if err := repo.UpdateReservationStatus(ctx, id, status.Pending, status.Confirmed, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	failed := errors.New("failed after the changes")
+	err := repo.WithTx(ctx, func(tx repository.DatabaseRepo) error {
+		if err := tx.UpdateReservationStatus(ctx, id, status.Confirmed, status.Cancelled, 1); err != nil {
+			return err
+		}
+
+		// edit the rows in place, the way a later change to the repo could
+		d := tx.(*memoryDBRepo).store.data
+		d.reservationHistory[id][0].ToStatus = "edited"
+		d.genres[hipHop.ID].Aliases[0] = "edited"
+		d.artistGenres[3][0] = jazz.ID
+
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("expected the transaction to fail but got %v", err)
+	}
+
+	history, _ := repo.GetReservationStatusHistory(ctx, id)
+	if len(history) != 1 || history[0].ToStatus != status.Confirmed {
+		t.Errorf("the status history of the rolled back transaction was kept: %+v", history)
+	}
+
+	if genre, _ := repo.GetGenreByID(ctx, hipHop.ID); !slices.Equal(genre.Aliases, hipHop.Aliases) {
+		t.Errorf("the aliases of the rolled back transaction were kept: %v", genre.Aliases)
+	}
+
+	if artist, _ := repo.GetArtistByIDAnyStatus(ctx, 3); artist.Genres != kofi.Genres {
+		t.Errorf("the genres of the rolled back transaction were kept: %q", artist.Genres)
+	}
+}
+
+func TestMemoryStatusAndReschedule(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	first, _ := repo.InsertBooking(ctx, models.Bookings{ArtistID: 1, StartDate: date("2050-04-01"), EndDate: date("2050-04-01")})
+	second, _ := repo.InsertBooking(ctx, models.Bookings{ArtistID: 1, StartDate: date("2050-04-05"), EndDate: date("2050-04-05")})
+
+	moved, err := repo.RescheduleBooking(ctx, first, date("2050-04-05"), date("2050-04-06"))
+	if err != nil || moved {
+		t.Errorf("expected the reschedule onto another booking to fail but got %v, %v", moved, err)
+	}
+
+	moved, err = repo.RescheduleBooking(ctx, first, date("2050-04-01"), date("2050-04-02"))
+	if err != nil || !moved {
+		t.Errorf("expected a booking to move over its own dates but got %v, %v", moved, err)
+	}
+
+	err = repo.UpdateBookingStatus(ctx, second, status.Confirmed, status.Cancelled, 1)
+	if !errors.Is(err, status.ErrStaleStatus) {
+		t.Errorf("expected ErrStaleStatus but got %v", err)
+	}
+
+	err = repo.UpdateBookingStatus(ctx, second, status.Pending, status.Cancelled, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	available, _ := repo.SearchAvailabilityByDatesByArtistID(ctx, date("2050-04-05"), date("2050-04-05"), 1)
+	if !available {
+		t.Error("cancelling the booking did not free its dates")
+	}
+
+	history, _ := repo.GetBookingStatusHistory(ctx, second)
+	if len(history) != 1 || history[0].ToStatus != status.Cancelled || history[0].User.FirstName != "Admin" {
+		t.Errorf("unexpected history %+v", history)
+	}
+}
+
+func TestMemoryAuthenticate(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	user, err := repo.Authenticate(ctx, MemoryAdminEmail, MemoryAdminPassword)
+	if err != nil || user.ID != 1 || user.Role != roles.Admin || !user.Verified {
+		t.Errorf("expected the seeded admin to log in but got %+v, %v", user, err)
+	}
+
+	user, err = repo.Authenticate(ctx, MemoryStaffEmail, MemoryAdminPassword)
+	if err != nil || user.Role != roles.Staff {
+		t.Errorf("expected the seeded staff user to log in but got %+v, %v", user, err)
+	}
+
+	_, err = repo.Authenticate(ctx, MemoryAdminEmail, "wrong")
+	if err == nil {
+		t.Error("expected a wrong password to fail")
+	}
+
+	_, err = repo.InsertUser(ctx, models.User{Email: MemoryAdminEmail, Password: "secret"})
+	if err == nil {
+		t.Error("expected a duplicate email to fail")
+	}
+}
+
+func TestMemoryAllUsers(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	for i := 0; i < 3; i++ {
+		_, err := repo.InsertUser(ctx, models.User{FirstName: "Ama", LastName: "Mensah", Email: fmt.Sprintf("ama%d@example.com", i), Password: "secret"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	users, total, err := repo.AllUsers(ctx, "", 2, 0)
+	if err != nil || total != 6 || len(users) != 2 || users[0].Email != "ama2@example.com" {
+		t.Errorf("expected the newest 2 of 6 users but got %d of %d, %v", len(users), total, err)
+	}
+
+	users, total, _ = repo.AllUsers(ctx, "", 2, 5)
+	if total != 6 || len(users) != 1 || users[0].Email != MemoryAdminEmail {
+		t.Errorf("expected the last page to hold the admin but got %+v", users)
+	}
+
+	users, total, _ = repo.AllUsers(ctx, "ama MENSAH", 10, 0)
+	if total != 3 || len(users) != 3 {
+		t.Errorf("expected the search to match the full name case insensitively but got %d", total)
+	}
+
+	users, total, _ = repo.AllUsers(ctx, "ama", 10, 20)
+	if total != 3 || len(users) != 0 {
+		t.Errorf("expected an empty page past the end but got %d users", len(users))
+	}
+}
+
+func TestMemoryUserAccess(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	if err := repo.SetUserDisabled(ctx, 2, true); err != nil {
+		t.Fatal(err)
+	}
+	staff, _ := repo.GetUserByID(ctx, 2)
+	disabledAt := staff.DisabledAt
+	if !staff.Disabled() {
+		t.Error("expected the user to be disabled")
+	}
+
+	_ = repo.SetUserDisabled(ctx, 2, true)
+	if staff, _ = repo.GetUserByID(ctx, 2); !staff.DisabledAt.Equal(disabledAt) {
+		t.Error("expected disabling again to keep the time it was first disabled")
+	}
+
+	_ = repo.SetUserDisabled(ctx, 2, false)
+	if staff, _ = repo.GetUserByID(ctx, 2); staff.Disabled() {
+		t.Error("expected the user to be enabled")
+	}
+
+	_ = repo.LockUser(ctx, 2, time.Now().Add(time.Hour))
+	if staff, _ = repo.GetUserByID(ctx, 2); !staff.Locked(time.Now()) || staff.Locked(time.Now().Add(2*time.Hour)) {
+		t.Errorf("expected the user to be locked for an hour but got %v", staff.LockedUntil)
+	}
+
+	_ = repo.LockUser(ctx, 2, time.Time{})
+	if staff, _ = repo.GetUserByID(ctx, 2); staff.Locked(time.Now()) {
+		t.Error("expected the user to be unlocked")
+	}
+}
+
+func TestMemoryArtistOwners(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	id, err := repo.CreateArtist(ctx, models.Artist{Name: "The Accra Choir", UserID: 2, Status: listing.Pending})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	artists, _ := repo.AllArtistsByUserID(ctx, 2)
+	if len(artists) != 1 || artists[0].ID != id || artists[0].Status != listing.Pending {
+		t.Fatalf("expected the new listing to belong to the user but got %+v", artists)
+	}
+
+	artist := artists[0]
+	artist.Name = "Accra Choir"
+	artist.UserID = 1
+	artist.Status = listing.Approved
+	_ = repo.UpdateArtist(ctx, artist)
+	if artist, _ = repo.GetArtistByIDAnyStatus(ctx, id); artist.Name != "Accra Choir" || artist.UserID != 2 || artist.Status != listing.Pending {
+		t.Errorf("expected updates to keep the owner and status but got %d, %q", artist.UserID, artist.Status)
+	}
+
+	if artists, _ = repo.AllArtistsByUserID(ctx, 1); len(artists) != 0 {
+		t.Errorf("expected the admin to own no artists but got %d", len(artists))
+	}
+}
+
+func TestMemoryArtistStatus(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+	start := date("2050-03-01")
+
+	gospelID, _ := repo.CreateGenre(ctx, models.Genre{Name: "Gospel"})
+	id, _ := repo.CreateArtist(ctx, models.Artist{Name: "The Accra Choir", GenreList: []models.Genre{{ID: gospelID}}, Status: listing.Pending})
+
+	if _, err := repo.GetArtistByID(ctx, id); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected a pending artist to be hidden but got %v", err)
+	}
+	if artists, total, _ := repo.SearchArtists(ctx, repository.ArtistFilter{}); len(artists) != 3 || total != 3 {
+		t.Errorf("expected only the 3 approved artists but got %d", len(artists))
+	}
+	if artists, _, _ := repo.SearchArtists(ctx, repository.ArtistFilter{Genre: "gospel", AvailableFrom: start, AvailableTo: start}); len(artists) != 0 {
+		t.Errorf("expected a pending artist not to be searchable but got %d", len(artists))
+	}
+	if artists, _ := repo.AllArtistsByStatus(ctx, listing.Pending); len(artists) != 1 || artists[0].ID != id {
+		t.Errorf("expected the pending artist in the queue but got %+v", artists)
+	}
+
+	if err := repo.UpdateArtistStatus(ctx, id, listing.Draft, listing.Approved, ""); !errors.Is(err, listing.ErrStaleStatus) {
+		t.Errorf("expected a stale status error but got %v", err)
+	}
+
+	if err := repo.UpdateArtistStatus(ctx, id, listing.Pending, listing.Approved, ""); err != nil {
+		t.Fatal(err)
+	}
+	if artists, _, _ := repo.SearchArtists(ctx, repository.ArtistFilter{Genre: "gospel", AvailableFrom: start, AvailableTo: start}); len(artists) != 1 {
+		t.Errorf("expected the approved artist to be searchable but got %d", len(artists))
+	}
+}
+
+func TestMemorySearchArtists(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	// Ama Strings gets the cheapest option, and a newer artist without options is added
+	option, _ := repo.GetBookingOptionByID(ctx, 3)
+	option.Price = "GHS 250"
+	repo.UpdateBookingOption(ctx, option)
+	jazz, _ := repo.GetGenreBySlug(ctx, "jazz")
+	id, _ := repo.CreateArtist(ctx, models.Artist{Name: "Zed Brass", GenreList: []models.Genre{jazz}, City: "Accra", Status: listing.Approved})
+
+	var tests = []struct {
+		name     string
+		filter   repository.ArtistFilter
+		expected []int
+		total    int
+	}{
+		{"everyone by name", repository.ArtistFilter{}, []int{2, 3, 1, id}, 4},
+		{"text", repository.ArtistFilter{Query: "sound system"}, []int{3}, 1},
+		{"genre and city", repository.ArtistFilter{Genre: "jazz", City: "accra"}, []int{2, id}, 2},
+		{"genre alias", repository.ArtistFilter{Genre: "afrobeats"}, []int{3, 1}, 2},
+		{"unknown genre", repository.ArtistFilter{Genre: "polka"}, nil, 0},
+		{"price band", repository.ArtistFilter{MinPrice: 100, MaxPrice: 300}, []int{2}, 1},
+		{"newest", repository.ArtistFilter{Sort: repository.SortNewest, Limit: 1}, []int{id}, 4},
+		{"cheapest first", repository.ArtistFilter{Sort: repository.SortPrice}, []int{2, 3, 1, id}, 4},
+		{"second page", repository.ArtistFilter{Limit: 3, Offset: 3}, []int{id}, 4},
+	}
+
+	for _, e := range tests {
+		artists, total, err := repo.SearchArtists(ctx, e.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var ids []int
+		for _, artist := range artists {
+			ids = append(ids, artist.ID)
+		}
+		if !slices.Equal(ids, e.expected) || total != e.total {
+			t.Errorf("%s: expected %v of %d but got %v of %d", e.name, e.expected, e.total, ids, total)
+		}
+	}
+
+	start := date("2050-04-01")
+	repo.InsertBooking(ctx, models.Bookings{ArtistID: 2, StartDate: start, EndDate: start})
+
+	artists, _, _ := repo.SearchArtists(ctx, repository.ArtistFilter{Genre: "jazz", AvailableFrom: start, AvailableTo: start})
+	if len(artists) != 1 || artists[0].ID != id {
+		t.Errorf("expected the booked artist to be left out but got %+v", artists)
+	}
+	if artists, _, _ := repo.SearchArtists(ctx, repository.ArtistFilter{Query: "Ama"}); artists[0].FromPrice != 250 {
+		t.Errorf("expected the price of the cheapest option but got %d", artists[0].FromPrice)
+	}
+}
+
+func TestMemoryGenres(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	kofi, _ := repo.GetArtistByID(ctx, 3)
+	if kofi.Genres != "Afrobeat, Amapiano, Hip Hop" || len(kofi.GenreList) != 3 {
+		t.Fatalf("expected the seeded genres to be looked up by alias but got %q", kofi.Genres)
+	}
+
+	if _, err := repo.CreateGenre(ctx, models.Genre{Name: "afro-beat"}); !errors.Is(err, repository.ErrGenreTaken) {
+		t.Errorf("expected an alias to be taken but got %v", err)
+	}
+
+	// a spelling nobody added as an alias yet is merged in later
+	afroBeatsID, err := repo.CreateGenre(ctx, models.Genre{Name: "Afro Beats", Aliases: []string{"Afro Pop"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := repo.CreateArtist(ctx, models.Artist{Name: "Naija Vibes", GenreList: []models.Genre{{ID: afroBeatsID}}, Status: listing.Approved})
+
+	afrobeat, _ := repo.GetGenreBySlug(ctx, "afrobeat")
+	if err := repo.MergeGenre(ctx, afroBeatsID, afrobeat.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if genre, _ := repo.GetGenreBySlug(ctx, "afro-pop"); genre.ID != afrobeat.ID || genre.Artists != 3 {
+		t.Errorf("expected the merged aliases to lead to Afrobeat with 3 artists but got %+v", genre)
+	}
+	if artist, _ := repo.GetArtistByID(ctx, id); artist.Genres != "Afrobeat" {
+		t.Errorf("expected the artist to be moved to Afrobeat but got %q", artist.Genres)
+	}
+
+	hipHop, _ := repo.GetGenreBySlug(ctx, "hip-hop")
+	hipHop.Name = "Hip-Hop"
+	if err := repo.UpdateGenre(ctx, hipHop); err != nil {
+		t.Fatal(err)
+	}
+	if artists, _, _ := repo.SearchArtists(ctx, repository.ArtistFilter{Query: "rap"}); len(artists) != 1 || artists[0].Genres != "Afrobeat, Amapiano, Hip-Hop" {
+		t.Errorf("expected the renamed genre to be found by its alias but got %+v", artists)
+	}
+
+	jazz, _ := repo.GetGenreBySlug(ctx, "jazz")
+	repo.DeleteGenre(ctx, jazz.ID)
+	if artist, _ := repo.GetArtistByID(ctx, 2); artist.Genres != "Classical" {
+		t.Errorf("expected the deleted genre to be taken off the artist but got %q", artist.Genres)
+	}
+}
+
+func TestMemorySuggestArtists(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	suggestions, err := repo.SuggestArtists(ctx, "lag", 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 2 || suggestions[0].ID != 1 || suggestions[1].ID != 3 {
+		t.Fatalf("expected the artist named after Lagos before the one based there but got %+v", suggestions)
+	}
+	if suggestions[0].NameHTML != "The <mark>Lagos</mark> Horns" {
+		t.Errorf("unexpected name %q", suggestions[0].NameHTML)
+	}
+
+	// whole names are not stemmed, so one ending in y is found as typed, and so are stop words
+	tony, _ := repo.CreateArtist(ctx, models.Artist{Name: "Tony Kenny", Status: listing.Approved})
+	suggestions, _ = repo.SuggestArtists(ctx, "Tony Kenny", 8)
+	if len(suggestions) != 1 || suggestions[0].ID != tony {
+		t.Errorf("expected the artist to be found by their full name but got %+v", suggestions)
+	}
+	suggestions, _ = repo.SuggestArtists(ctx, "the", 8)
+	if len(suggestions) == 0 || suggestions[0].ID != 1 {
+		t.Errorf("expected a stop word to match the name as typed but got %+v", suggestions)
+	}
+
+	repo.CreateArtist(ctx, models.Artist{Name: "<b>Brass</b> & Co", Status: listing.Approved})
+	suggestions, _ = repo.SuggestArtists(ctx, "brass", 8)
+	if len(suggestions) != 2 || suggestions[0].NameHTML != "<mark>&lt;b&gt;Brass&lt;/b&gt;</mark> &amp; Co" {
+		t.Errorf("expected the name to be escaped but got %+v", suggestions)
+	}
+	if len(suggestions) == 2 && suggestions[1].SnippetHTML != "A seven piece <mark>brass</mark> band for weddings and parties." {
+		t.Errorf("expected the matching description to be highlighted but got %q", suggestions[1].SnippetHTML)
+	}
+}
+
+func TestMemoryArtistBookings(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	later, _ := repo.InsertBooking(ctx, models.Bookings{ArtistID: 3, StartDate: date("2050-05-10"), EndDate: date("2050-05-10")})
+	earlier, _ := repo.InsertBooking(ctx, models.Bookings{ArtistID: 3, StartDate: date("2050-05-01"), EndDate: date("2050-05-02")})
+
+	bookings, err := repo.AllBookingsByArtistID(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bookings) != 2 || bookings[0].ID != earlier || bookings[1].ID != later {
+		t.Errorf("expected the two bookings of artist 3, earliest first, but got %+v", bookings)
+	}
+}
+
+func TestMemoryClaimBookings(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	guest, _ := repo.InsertBooking(ctx, models.Bookings{ArtistID: 1, Email: "Artist@MusiqCity.com", StartDate: date("2050-06-01"), EndDate: date("2050-06-01")})
+	repo.InsertBooking(ctx, models.Bookings{ArtistID: 1, Email: "someone@example.com", StartDate: date("2050-06-05"), EndDate: date("2050-06-05")})
+	reservation, _ := repo.InsertReservation(ctx, models.Reservation{RoomID: 1, Email: MemoryArtistEmail, StartDate: date("2050-06-10"), EndDate: date("2050-06-11")})
+
+	claimed, err := repo.ClaimBookings(ctx, 3, MemoryArtistEmail)
+	if err != nil || claimed != 2 {
+		t.Fatalf("expected a booking and a reservation to be claimed but got %d, %v", claimed, err)
+	}
+
+	if claimed, _ := repo.ClaimBookings(ctx, 2, MemoryArtistEmail); claimed != 0 {
+		t.Errorf("expected a claimed booking not to be claimed again but got %d", claimed)
+	}
+
+	bookings, _ := repo.AllBookingsByUserID(ctx, 3)
+	if len(bookings) != 1 || bookings[0].ID != guest {
+		t.Errorf("expected only the guest booking made with the account email but got %+v", bookings)
+	}
+
+	reservations, _ := repo.AllReservationsByUserID(ctx, 3)
+	if len(reservations) != 1 || reservations[0].ID != reservation || reservations[0].Room.RoomName == "" {
+		t.Errorf("expected the guest reservation made with the account email but got %+v", reservations)
+	}
+}
+
+func TestMemorySettings(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	err := repo.UpdateSettings(ctx, map[string]string{"sender_name": "MusiqCity", "reply_to": ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.UpdateSettings(ctx, map[string]string{"sender_name": "MusiqCity Bookings"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := repo.AllSettings(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(values) != 2 || values["sender_name"] != "MusiqCity Bookings" || values["reply_to"] != "" {
+		t.Errorf("expected the updated settings, but got %v", values)
+	}
+}
+
+func TestMemoryPasswordReset(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	resets := []models.PasswordReset{
+		{UserID: 1, TokenHash: "expired", ExpiresAt: time.Now().Add(-time.Minute)},
+		{UserID: 1, TokenHash: "older", ExpiresAt: time.Now().Add(time.Hour)},
+		{UserID: 1, TokenHash: "newer", ExpiresAt: time.Now().Add(time.Hour)},
+	}
+	for _, reset := range resets {
+		if err := repo.InsertPasswordReset(ctx, reset); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if count, _ := repo.CountPasswordResets(ctx, 1, time.Now().Add(-time.Hour)); count != 3 {
+		t.Errorf("expected 3 recent resets but got %d", count)
+	}
+
+	if _, err := repo.GetPasswordReset(ctx, "expired"); !errors.Is(err, repository.ErrInvalidResetToken) {
+		t.Errorf("expected an expired reset to be invalid but got %v", err)
+	}
+	if _, err := repo.ResetPassword(ctx, "expired", "new-password"); !errors.Is(err, repository.ErrInvalidResetToken) {
+		t.Errorf("expected an expired reset to be turned away but got %v", err)
+	}
+
+	userID, err := repo.ResetPassword(ctx, "newer", "new-password")
+	if err != nil || userID != 1 {
+		t.Fatalf("expected the password of user 1 to be reset but got %d, %v", userID, err)
+	}
+
+	for _, tokenHash := range []string{"newer", "older"} {
+		if _, err := repo.ResetPassword(ctx, tokenHash, "another-password"); !errors.Is(err, repository.ErrInvalidResetToken) {
+			t.Errorf("expected %s to be used up but got %v", tokenHash, err)
+		}
+	}
+
+	if _, err := repo.Authenticate(ctx, MemoryAdminEmail, "new-password"); err != nil {
+		t.Errorf("expected the new password to work but got %v", err)
+	}
+}
+
+func TestMemoryEmailVerification(t *testing.T) {
+	repo := NewMemoryRepo(nil)
+
+	userID, err := repo.InsertUser(ctx, models.User{FirstName: "Kwame", Email: "kwame@example.com", Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tokenHash := range []string{"first", "second"} {
+		err := repo.InsertEmailVerification(ctx, models.EmailVerification{UserID: userID, TokenHash: tokenHash, ExpiresAt: time.Now().Add(time.Hour)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := repo.VerifyEmail(ctx, "first"); !errors.Is(err, repository.ErrInvalidVerificationToken) {
+		t.Errorf("expected the first link to be revoked by the second but got %v", err)
+	}
+
+	verifiedID, err := repo.VerifyEmail(ctx, "second")
+	if err != nil || verifiedID != userID {
+		t.Fatalf("expected user %d to be verified but got %d, %v", userID, verifiedID, err)
+	}
+
+	if user, _ := repo.GetUserByID(ctx, userID); !user.Verified {
+		t.Errorf("expected the user to be verified but got %+v", user)
+	}
+
+	if _, err := repo.VerifyEmail(ctx, "second"); !errors.Is(err, repository.ErrInvalidVerificationToken) {
+		t.Errorf("expected the link to work only once but got %v", err)
+	}
+
+	// an admin opening a verification link keeps their role
+	err = repo.InsertEmailVerification(ctx, models.EmailVerification{UserID: 1, TokenHash: "admin", ExpiresAt: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.VerifyEmail(ctx, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if admin, _ := repo.GetUserByID(ctx, 1); admin.Role != roles.Admin {
+		t.Errorf("expected the admin to keep their role but got %q", admin.Role)
+	}
+}
@@ -2,42 +2,71 @@ package dbrepo
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"log"
+	"strings"
 	"time"
 
+	"github.com/aidisapp/musiqcity_v2/internal/genres"
+	"github.com/aidisapp/musiqcity_v2/internal/listing"
 	"github.com/aidisapp/musiqcity_v2/internal/models"
+	"github.com/aidisapp/musiqcity_v2/internal/outbox"
+	"github.com/aidisapp/musiqcity_v2/internal/repository"
+	"github.com/aidisapp/musiqcity_v2/internal/status"
+	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// Authenticate authenticates a user
-func (repo *postgresDBRepo) Authenticate(email, testPassword string) (int, string, int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+// WithTx runs fn with a repository whose methods all share one transaction. The transaction is committed
+// when fn returns nil and rolled back otherwise
+func (m *postgresDBRepo) WithTx(ctx context.Context, fn func(repo repository.DatabaseRepo) error) error {
+	return m.withTx(ctx, func(tx *postgresDBRepo) error {
+		return fn(tx)
+	})
+}
+
+// withTx runs fn inside a transaction. A repo that is already part of a transaction joins it instead
+// of starting a new one, so multi-step methods can be used on their own or inside WithTx
+func (m *postgresDBRepo) withTx(ctx context.Context, fn func(tx *postgresDBRepo) error) error {
+	if m.conn == nil {
+		return fn(m)
+	}
+
+	tx, err := m.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	var id int
-	var hashedPassword string
-	var access_level int
+	err = fn(&postgresDBRepo{App: m.App, DB: tx})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
 
-	row := repo.DB.QueryRowContext(ctx, "SELECT id, password, access_level FROM users WHERE email = $1", email)
-	err := row.Scan(&id, &hashedPassword, &access_level)
+// Authenticate returns the user with email when testPassword is their password
+func (repo *postgresDBRepo) Authenticate(ctx context.Context, email, testPassword string) (models.User, error) {
+	user, err := repo.GetUserByEmail(ctx, email)
 	if err != nil {
-		return id, "", 0, err
+		return models.User{}, err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(testPassword))
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(testPassword))
 	if err == bcrypt.ErrMismatchedHashAndPassword {
-		return 0, "", 0, errors.New("incorrect password")
+		return models.User{}, errors.New("incorrect password")
 	} else if err != nil {
-		return 0, "", 0, err
+		return models.User{}, err
 	}
 
-	return id, hashedPassword, access_level, nil
+	return user, nil
 }
 
 // Check if a user exists in the database via email
-func (m *postgresDBRepo) CheckIfUserEmailExist(email string) (bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (m *postgresDBRepo) CheckIfUserEmailExist(ctx context.Context, email string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var numRows int
@@ -64,8 +93,8 @@ func (m *postgresDBRepo) CheckIfUserEmailExist(email string) (bool, error) {
 }
 
 // Inserts a user into the database
-func (m *postgresDBRepo) InsertUser(user models.User) (int, error) {
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (m *postgresDBRepo) InsertUser(ctx context.Context, user models.User) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var newUserID int
@@ -75,9 +104,9 @@ func (m *postgresDBRepo) InsertUser(user models.User) (int, error) {
 		return 0, err
 	}
 
-	query := `insert into users (first_name, last_name, email, password, access_level, created_at, updated_at) values ($1, $2, $3, $4, $5, $6, $7) returning id`
+	query := `insert into users (first_name, last_name, email, password, role, verified, created_at, updated_at) values ($1, $2, $3, $4, $5, $6, $7, $8) returning id`
 
-	err = m.DB.QueryRowContext(context, query, user.FirstName, user.LastName, user.Email, hashedPassword, user.AccessLevel, time.Now(), time.Now()).Scan(&newUserID)
+	err = m.DB.QueryRowContext(ctx, query, user.FirstName, user.LastName, user.Email, hashedPassword, user.Role, user.Verified, time.Now(), time.Now()).Scan(&newUserID)
 
 	if err != nil {
 		return 0, err
@@ -86,39 +115,87 @@ func (m *postgresDBRepo) InsertUser(user models.User) (int, error) {
 	return newUserID, nil
 }
 
-// UpdateUserAccessLevel updates a user access level in the database
-func (m *postgresDBRepo) UpdateUserAccessLevel(user models.User) error {
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// AllUsers returns up to limit users whose name or email contains search, newest first, skipping the first
+// offset of them, and how many users match in all
+func (repo *postgresDBRepo) AllUsers(ctx context.Context, search string, limit, offset int) ([]models.User, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	query := `
-		update users set access_level = $1, updated_at = $2 where id = $3
-	`
+	var users []models.User
+	var total int
 
-	_, err := m.DB.ExecContext(context, query, user.AccessLevel, time.Now(), user.ID)
+	pattern := "%" + likeEscaper.Replace(search) + "%"
+	where := `where $1 = '' or first_name || ' ' || last_name ilike $2 or email ilike $2`
 
+	err := repo.DB.QueryRowContext(ctx, `select count(id) from users `+where, search, pattern).Scan(&total)
 	if err != nil {
-		return err
+		return users, 0, err
 	}
 
-	return nil
+	query := `select ` + userColumns + ` from users ` + where + ` order by created_at desc, id desc limit $3 offset $4`
+
+	rows, err := repo.DB.QueryContext(ctx, query, search, pattern, limit, offset)
+	if err != nil {
+		return users, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUser(rows.Scan)
+		if err != nil {
+			return users, 0, err
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return users, 0, err
+	}
+
+	return users, total, nil
 }
 
-func (repo *postgresDBRepo) AllUsers() bool {
-	return true
+// likeEscaper escapes the wildcards of a like pattern, so a search matches them literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// userColumns are the columns scanUser reads, in order
+const userColumns = `id, first_name, last_name, email, password, role, verified, disabled_at, locked_until, created_at, updated_at`
+
+// scanUser scans the userColumns of a row into a user
+func scanUser(scan func(dest ...interface{}) error) (models.User, error) {
+	var user models.User
+	var disabledAt, lockedUntil sql.NullTime
+
+	err := scan(
+		&user.ID,
+		&user.FirstName,
+		&user.LastName,
+		&user.Email,
+		&user.Password,
+		&user.Role,
+		&user.Verified,
+		&disabledAt,
+		&lockedUntil,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	user.DisabledAt = disabledAt.Time
+	user.LockedUntil = lockedUntil.Time
+
+	return user, err
 }
 
 // Inserts a reservation into the database
-func (repo *postgresDBRepo) InsertReservation(res models.Reservation) (int, error) {
+func (repo *postgresDBRepo) InsertReservation(ctx context.Context, res models.Reservation) (int, error) {
 	// Close this transaction if unable to run this statement within 3 seconds
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var newID int
 
-	insertStatement := `insert into reservations (first_name, last_name, email, phone, start_date, end_date, room_id, created_at, updated_at) values ($1, $2, $3, $4, $5, $6, $7, $8, $9) returning id`
+	insertStatement := `insert into reservations (first_name, last_name, email, phone, start_date, end_date, room_id, user_id, created_at, updated_at) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) returning id`
 
-	err := repo.DB.QueryRowContext(context, insertStatement, res.FirstName, res.LastName, res.Email, res.Phone, res.StartDate, res.EndDate, res.RoomID, time.Now(), time.Now()).Scan(&newID)
+	err := repo.DB.QueryRowContext(ctx, insertStatement, res.FirstName, res.LastName, res.Email, res.Phone, res.StartDate, res.EndDate, res.RoomID, nullInt(res.UserID), time.Now(), time.Now()).Scan(&newID)
 
 	if err != nil {
 		return 0, err
@@ -128,24 +205,24 @@ func (repo *postgresDBRepo) InsertReservation(res models.Reservation) (int, erro
 }
 
 // InsertRoomRestriction inserts a room restriction into the database
-func (repo *postgresDBRepo) InsertRoomRestriction(res models.RoomRestriction) error {
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *postgresDBRepo) InsertRoomRestriction(ctx context.Context, res models.RoomRestriction) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	insertStatement := `insert into room_restrictions (start_date, end_date, room_id, reservation_id, created_at, updated_at, restriction_id) values($1, $2, $3, $4, $5, $6, $7)`
 
-	_, err := repo.DB.ExecContext(context, insertStatement, res.StartDate, res.EndDate, res.RoomID, res.ReservationID, time.Now(), time.Now(), res.RestrictionID)
+	_, err := repo.DB.ExecContext(ctx, insertStatement, res.StartDate, res.EndDate, res.RoomID, res.ReservationID, time.Now(), time.Now(), res.RestrictionID)
 
 	if err != nil {
-		return err
+		return unavailableErr(err)
 	}
 
 	return nil
 }
 
 // SearchAvailabilityByDatesByRoomID returns true if availability exists for roomID, and false if no availability
-func (repo *postgresDBRepo) SearchAvailabilityByDatesByRoomID(start, end time.Time, roomID int) (bool, error) {
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *postgresDBRepo) SearchAvailabilityByDatesByRoomID(ctx context.Context, start, end time.Time, roomID int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var numRows int
@@ -159,7 +236,7 @@ func (repo *postgresDBRepo) SearchAvailabilityByDatesByRoomID(start, end time.Ti
 			room_id = $1
 			and $2 <= end_date and $3 >= start_date;`
 
-	row := repo.DB.QueryRowContext(context, query, roomID, start, end)
+	row := repo.DB.QueryRowContext(ctx, query, roomID, start, end)
 	err := row.Scan(&numRows)
 	if err != nil {
 		return false, err
@@ -172,8 +249,8 @@ func (repo *postgresDBRepo) SearchAvailabilityByDatesByRoomID(start, end time.Ti
 }
 
 // SearchAvailabilityForAllRooms returns a slice of available rooms, if any, for given date range
-func (repo *postgresDBRepo) SearchAvailabilityForAllRooms(start, end time.Time) ([]models.Room, error) {
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *postgresDBRepo) SearchAvailabilityForAllRooms(ctx context.Context, start, end time.Time) ([]models.Room, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var rooms []models.Room
@@ -187,7 +264,7 @@ func (repo *postgresDBRepo) SearchAvailabilityForAllRooms(start, end time.Time)
 		(select room_id from room_restrictions rr where $1 <= rr.end_date and $2 >= rr.start_date);
 	`
 
-	rows, err := repo.DB.QueryContext(context, query, start, end)
+	rows, err := repo.DB.QueryContext(ctx, query, start, end)
 	if err != nil {
 		return rooms, err
 	}
@@ -212,8 +289,8 @@ func (repo *postgresDBRepo) SearchAvailabilityForAllRooms(start, end time.Time)
 }
 
 // Get all rooms
-func (m *postgresDBRepo) AllRooms() ([]models.Room, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (m *postgresDBRepo) AllRooms(ctx context.Context) ([]models.Room, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var rooms []models.Room
@@ -251,8 +328,8 @@ func (m *postgresDBRepo) AllRooms() ([]models.Room, error) {
 }
 
 // GetRoomByID gets a room by id
-func (repo *postgresDBRepo) GetRoomByID(id int) (models.Room, error) {
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *postgresDBRepo) GetRoomByID(ctx context.Context, id int) (models.Room, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var room models.Room
@@ -261,7 +338,7 @@ func (repo *postgresDBRepo) GetRoomByID(id int) (models.Room, error) {
 		select id, room_name, price, image_src, description, created_at, updated_at from rooms where id = $1
 	`
 
-	row := repo.DB.QueryRowContext(context, query, id)
+	row := repo.DB.QueryRowContext(ctx, query, id)
 	err := row.Scan(
 		&room.ID,
 		&room.RoomName,
@@ -280,8 +357,8 @@ func (repo *postgresDBRepo) GetRoomByID(id int) (models.Room, error) {
 }
 
 // UpdateRoom updates a room in the database
-func (m *postgresDBRepo) UpdateRoom(room models.Room) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (m *postgresDBRepo) UpdateRoom(ctx context.Context, room models.Room) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	query := `
@@ -306,13 +383,13 @@ func (m *postgresDBRepo) UpdateRoom(room models.Room) error {
 }
 
 // Inserts a room into the database
-func (repo *postgresDBRepo) InsertRoom(room models.Room) error {
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *postgresDBRepo) InsertRoom(ctx context.Context, room models.Room) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	query := `insert into rooms (room_name, price, image_src, description, created_at, updated_at) values ($1, $2, $3, $4, $5, $6)`
 
-	_, err := repo.DB.ExecContext(context, query, room.RoomName, room.Price, room.ImageSource, room.Description, time.Now(), time.Now())
+	_, err := repo.DB.ExecContext(ctx, query, room.RoomName, room.Price, room.ImageSource, room.Description, time.Now(), time.Now())
 
 	if err != nil {
 		return err
@@ -322,8 +399,8 @@ func (repo *postgresDBRepo) InsertRoom(room models.Room) error {
 }
 
 // DeleteRoom deletes a room
-func (m *postgresDBRepo) DeleteRoom(id int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (m *postgresDBRepo) DeleteRoom(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	query := `delete from rooms where id = $1`
@@ -338,49 +415,31 @@ func (m *postgresDBRepo) DeleteRoom(id int) error {
 }
 
 // GetUserByID returns a user by id
-func (repo *postgresDBRepo) GetUserByID(id int) (models.User, error) {
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *postgresDBRepo) GetUserByID(ctx context.Context, id int) (models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	query := `select id, first_name, last_name, email, password, access_level, created_at, updated_at
-			from users where id = $1`
-
-	row := repo.DB.QueryRowContext(context, query, id)
-
-	var user models.User
-	err := row.Scan(
-		&user.ID,
-		&user.FirstName,
-		&user.LastName,
-		&user.Email,
-		&user.Password,
-		&user.AccessLevel,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
-
-	if err != nil {
-		return user, err
-	}
+	query := `select ` + userColumns + ` from users where id = $1`
 
-	return user, nil
+	return scanUser(repo.DB.QueryRowContext(ctx, query, id).Scan)
 }
 
 // UpdateUser updates a user in the database
-func (repo *postgresDBRepo) UpdateUser(user models.User) error {
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *postgresDBRepo) UpdateUser(ctx context.Context, user models.User) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	query := `
-		update users set first_name = $1, last_name = $2, email = $3, access_level = $4, updated_at = $5
+		update users set first_name = $1, last_name = $2, email = $3, role = $4, updated_at = $5 where id = $6
 	`
 
-	_, err := repo.DB.ExecContext(context, query,
+	_, err := repo.DB.ExecContext(ctx, query,
 		user.FirstName,
 		user.LastName,
 		user.Email,
-		user.AccessLevel,
+		user.Role,
 		time.Now(),
+		user.ID,
 	)
 
 	if err != nil {
@@ -390,23 +449,272 @@ func (repo *postgresDBRepo) UpdateUser(user models.User) error {
 	return nil
 }
 
+// UpdateUserRole changes the role of a user
+func (repo *postgresDBRepo) UpdateUserRole(ctx context.Context, id int, role string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	_, err := repo.DB.ExecContext(ctx, `update users set role = $1, updated_at = $2 where id = $3`, role, time.Now(), id)
+
+	return err
+}
+
+// UpdatePassword sets the password of a user
+func (repo *postgresDBRepo) UpdatePassword(ctx context.Context, id int, password string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 10)
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.DB.ExecContext(ctx, `update users set password = $1, updated_at = $2 where id = $3`, hashedPassword, time.Now(), id)
+
+	return err
+}
+
+// SetUserDisabled disables or enables the account of a user. Disabling keeps the time it was first disabled
+func (repo *postgresDBRepo) SetUserDisabled(ctx context.Context, id int, disabled bool) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `update users set disabled_at = case when $1 then coalesce(disabled_at, $2) end, updated_at = $2 where id = $3`
+
+	_, err := repo.DB.ExecContext(ctx, query, disabled, time.Now(), id)
+
+	return err
+}
+
+// LockUser stops a user logging in until the given time, the zero time unlocks them
+func (repo *postgresDBRepo) LockUser(ctx context.Context, id int, until time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	lockedUntil := sql.NullTime{Time: until, Valid: !until.IsZero()}
+
+	_, err := repo.DB.ExecContext(ctx, `update users set locked_until = $1, updated_at = $2 where id = $3`, lockedUntil, time.Now(), id)
+
+	return err
+}
+
+// GetUserByEmail returns a user by email
+func (repo *postgresDBRepo) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select ` + userColumns + ` from users where email = $1`
+
+	return scanUser(repo.DB.QueryRowContext(ctx, query, email).Scan)
+}
+
+// InsertPasswordReset stores a password reset by the hash of its token
+func (m *postgresDBRepo) InsertPasswordReset(ctx context.Context, reset models.PasswordReset) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `insert into password_resets (user_id, token_hash, expires_at, created_at, updated_at) values ($1, $2, $3, $4, $4)`
+
+	_, err := m.DB.ExecContext(ctx, query, reset.UserID, reset.TokenHash, reset.ExpiresAt, time.Now())
+
+	return err
+}
+
+// CountPasswordResets returns how many resets were requested for userID since the given time
+func (m *postgresDBRepo) CountPasswordResets(ctx context.Context, userID int, since time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var count int
+
+	query := `select count(id) from password_resets where user_id = $1 and created_at >= $2`
+
+	err := m.DB.QueryRowContext(ctx, query, userID, since).Scan(&count)
+
+	return count, err
+}
+
+// GetPasswordReset returns the reset with tokenHash, or ErrInvalidResetToken when it doesn't exist, has been used or has expired
+func (m *postgresDBRepo) GetPasswordReset(ctx context.Context, tokenHash string) (models.PasswordReset, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return m.passwordReset(ctx, `
+		select id, user_id, token_hash, expires_at, used_at, created_at, updated_at
+		from password_resets where token_hash = $1`, tokenHash)
+}
+
+// passwordReset scans the reset returned by query, or returns ErrInvalidResetToken when it can't be used
+func (m *postgresDBRepo) passwordReset(ctx context.Context, query string, args ...interface{}) (models.PasswordReset, error) {
+	var reset models.PasswordReset
+	var usedAt sql.NullTime
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(
+		&reset.ID,
+		&reset.UserID,
+		&reset.TokenHash,
+		&reset.ExpiresAt,
+		&usedAt,
+		&reset.CreatedAt,
+		&reset.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return reset, repository.ErrInvalidResetToken
+	} else if err != nil {
+		return reset, err
+	}
+	reset.UsedAt = usedAt.Time
+
+	if !reset.Usable(time.Now()) {
+		return reset, repository.ErrInvalidResetToken
+	}
+
+	return reset, nil
+}
+
+// ResetPassword sets the password of the user the reset with tokenHash belongs to and uses up all of that
+// user's open resets, so neither this link nor any older one works again
+func (m *postgresDBRepo) ResetPassword(ctx context.Context, tokenHash, password string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 10)
+	if err != nil {
+		return 0, err
+	}
+
+	var userID int
+
+	err = m.withTx(ctx, func(tx *postgresDBRepo) error {
+		// the row lock makes a second request with the same token wait, and then find it used
+		reset, err := tx.passwordReset(ctx, `
+			select id, user_id, token_hash, expires_at, used_at, created_at, updated_at
+			from password_resets where token_hash = $1 for update`, tokenHash)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.DB.ExecContext(ctx, `update users set password = $1, updated_at = $2 where id = $3`,
+			hashedPassword, time.Now(), reset.UserID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.DB.ExecContext(ctx, `update password_resets set used_at = $1, updated_at = $1 where user_id = $2 and used_at is null`,
+			time.Now(), reset.UserID)
+		if err != nil {
+			return err
+		}
+
+		userID = reset.UserID
+		return nil
+	})
+
+	return userID, err
+}
+
+// InsertEmailVerification stores a verification link by the hash of its token and revokes the user's earlier links
+func (m *postgresDBRepo) InsertEmailVerification(ctx context.Context, verification models.EmailVerification) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return m.withTx(ctx, func(tx *postgresDBRepo) error {
+		_, err := tx.DB.ExecContext(ctx, `update email_verifications set used_at = $1, updated_at = $1 where user_id = $2 and used_at is null`,
+			time.Now(), verification.UserID)
+		if err != nil {
+			return err
+		}
+
+		query := `insert into email_verifications (user_id, token_hash, expires_at, created_at, updated_at) values ($1, $2, $3, $4, $4)`
+
+		_, err = tx.DB.ExecContext(ctx, query, verification.UserID, verification.TokenHash, verification.ExpiresAt, time.Now())
+
+		return err
+	})
+}
+
+// CountEmailVerifications returns how many verification links were sent to userID since the given time
+func (m *postgresDBRepo) CountEmailVerifications(ctx context.Context, userID int, since time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var count int
+
+	query := `select count(id) from email_verifications where user_id = $1 and created_at >= $2`
+
+	err := m.DB.QueryRowContext(ctx, query, userID, since).Scan(&count)
+
+	return count, err
+}
+
+// VerifyEmail verifies the user the link with tokenHash belongs to and uses the link up
+func (m *postgresDBRepo) VerifyEmail(ctx context.Context, tokenHash string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var userID int
+
+	err := m.withTx(ctx, func(tx *postgresDBRepo) error {
+		var verification models.EmailVerification
+		var usedAt sql.NullTime
+
+		query := `
+			select id, user_id, expires_at, used_at
+			from email_verifications where token_hash = $1 for update`
+
+		err := tx.DB.QueryRowContext(ctx, query, tokenHash).Scan(
+			&verification.ID,
+			&verification.UserID,
+			&verification.ExpiresAt,
+			&usedAt,
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrInvalidVerificationToken
+		} else if err != nil {
+			return err
+		}
+		verification.UsedAt = usedAt.Time
+
+		if !verification.Usable(time.Now()) {
+			return repository.ErrInvalidVerificationToken
+		}
+
+		_, err = tx.DB.ExecContext(ctx, `update users set verified = true, updated_at = $1 where id = $2`,
+			time.Now(), verification.UserID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.DB.ExecContext(ctx, `update email_verifications set used_at = $1, updated_at = $1 where id = $2`,
+			time.Now(), verification.ID)
+		if err != nil {
+			return err
+		}
+
+		userID = verification.UserID
+		return nil
+	})
+
+	return userID, err
+}
+
 // AllReservations returns a slice of all reservations
-func (repo *postgresDBRepo) AllReservations() ([]models.Reservation, error) {
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *postgresDBRepo) AllReservations(ctx context.Context) ([]models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var reservations []models.Reservation
 
 	query := `
 		select r.id, r.first_name, r.last_name, r.email, r.phone, r.start_date, 
-		r.end_date, r.room_id, r.created_at, r.updated_at, r.processed,
+		r.end_date, r.room_id, r.created_at, r.updated_at, r.status,
 		rm.id, rm.room_name
 		from reservations r
 		left join rooms rm on (r.room_id = rm.id)
 		order by r.start_date asc
 	`
 
-	rows, err := repo.DB.QueryContext(context, query)
+	rows, err := repo.DB.QueryContext(ctx, query)
 	if err != nil {
 		return reservations, err
 	}
@@ -425,7 +733,7 @@ func (repo *postgresDBRepo) AllReservations() ([]models.Reservation, error) {
 			&i.RoomID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.Processed,
+			&i.Status,
 			&i.Room.ID,
 			&i.Room.RoomName,
 		)
@@ -444,23 +752,23 @@ func (repo *postgresDBRepo) AllReservations() ([]models.Reservation, error) {
 }
 
 // AllNewReservations returns a slice of all reservations
-func (m *postgresDBRepo) AllNewReservations() ([]models.Reservation, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (m *postgresDBRepo) AllNewReservations(ctx context.Context) ([]models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var reservations []models.Reservation
 
 	query := `
 		select r.id, r.first_name, r.last_name, r.email, r.phone, r.start_date, 
-		r.end_date, r.room_id, r.created_at, r.updated_at, 
+		r.end_date, r.room_id, r.created_at, r.updated_at, r.status,
 		rm.id, rm.room_name
 		from reservations r
 		left join rooms rm on (r.room_id = rm.id)
-		where processed = 0
+		where r.status = $1
 		order by r.start_date asc
 	`
 
-	rows, err := m.DB.QueryContext(ctx, query)
+	rows, err := m.DB.QueryContext(ctx, query, status.Pending)
 	if err != nil {
 		return reservations, err
 	}
@@ -479,6 +787,7 @@ func (m *postgresDBRepo) AllNewReservations() ([]models.Reservation, error) {
 			&i.RoomID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Status,
 			&i.Room.ID,
 			&i.Room.RoomName,
 		)
@@ -497,15 +806,15 @@ func (m *postgresDBRepo) AllNewReservations() ([]models.Reservation, error) {
 }
 
 // GetReservationByID returns one reservation by ID
-func (m *postgresDBRepo) GetReservationByID(id int) (models.Reservation, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (m *postgresDBRepo) GetReservationByID(ctx context.Context, id int) (models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var reservation models.Reservation
 
 	query := `
 		select r.id, r.first_name, r.last_name, r.email, r.phone, r.start_date,
-		r.end_date, r.room_id, r.created_at, r.updated_at, r.processed, 
+		r.end_date, r.room_id, r.created_at, r.updated_at, r.status,
 		rm.id, rm.room_name
 		from reservations r
 		left join rooms rm on (r.room_id = rm.id)
@@ -525,7 +834,7 @@ func (m *postgresDBRepo) GetReservationByID(id int) (models.Reservation, error)
 		&reservation.RoomID,
 		&reservation.CreatedAt,
 		&reservation.UpdatedAt,
-		&reservation.Processed,
+		&reservation.Status,
 		&reservation.Room.ID,
 		&reservation.Room.RoomName,
 	)
@@ -538,8 +847,8 @@ func (m *postgresDBRepo) GetReservationByID(id int) (models.Reservation, error)
 }
 
 // UpdateReservation updates a reservation in the database
-func (m *postgresDBRepo) UpdateReservation(user models.Reservation) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (m *postgresDBRepo) UpdateReservation(ctx context.Context, user models.Reservation) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	query := `
@@ -564,8 +873,8 @@ func (m *postgresDBRepo) UpdateReservation(user models.Reservation) error {
 }
 
 // DeleteReservation deletes one reservation by id
-func (m *postgresDBRepo) DeleteReservation(id int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (m *postgresDBRepo) DeleteReservation(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	query := "delete from reservations where id = $1"
@@ -578,31 +887,185 @@ func (m *postgresDBRepo) DeleteReservation(id int) error {
 	return nil
 }
 
-// UpdateProcessedForReservation updates processed for a reservation by id
-func (m *postgresDBRepo) UpdateProcessedForReservation(id, processed int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// UpdateReservationStatus moves a reservation from one status to another and records the change in its history.
+// Cancelling a reservation also frees its dates on the rooms calendar
+func (m *postgresDBRepo) UpdateReservationStatus(ctx context.Context, id int, from, to string, userID int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return m.withTx(ctx, func(tx *postgresDBRepo) error {
+		// only update the row if nobody changed the status since it was read
+		result, err := tx.DB.ExecContext(ctx, "update reservations set status = $1, updated_at = $2 where id = $3 and status = $4",
+			to, time.Now(), id, from)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return status.ErrStaleStatus
+		}
+
+		query := `insert into reservation_status_changes (reservation_id, from_status, to_status, user_id, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6)`
+
+		_, err = tx.DB.ExecContext(ctx, query, id, from, to, nullInt(userID), time.Now(), time.Now())
+		if err != nil {
+			return err
+		}
+
+		if to == status.Cancelled {
+			_, err = tx.DB.ExecContext(ctx, "delete from room_restrictions where reservation_id = $1", id)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// RescheduleReservation moves a reservation and its room_restrictions row to new dates in one transaction.
+// It returns false without changing anything when the new dates clash with another reservation or block
+func (m *postgresDBRepo) RescheduleReservation(ctx context.Context, id int, startDate, endDate time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	query := "update reservations set processed = $1 where id = $2"
+	available := false
 
-	_, err := m.DB.ExecContext(ctx, query, processed, id)
+	err := m.withTx(ctx, func(tx *postgresDBRepo) error {
+		// lock the reservation so two reschedules of it can't run at the same time
+		var roomID int
+		err := tx.DB.QueryRowContext(ctx, "select room_id from reservations where id = $1 for update", id).Scan(&roomID)
+		if err != nil {
+			return err
+		}
+
+		var numRows int
+		query := `
+			select
+				count(id)
+			from
+				room_restrictions
+			where
+				room_id = $1
+				and (reservation_id is null or reservation_id <> $2)
+				and $3 <= end_date and $4 >= start_date;`
+
+		err = tx.DB.QueryRowContext(ctx, query, roomID, id, startDate, endDate).Scan(&numRows)
+		if err != nil {
+			return err
+		}
+
+		if numRows > 0 {
+			return nil
+		}
+
+		_, err = tx.DB.ExecContext(ctx, "update reservations set start_date = $1, end_date = $2, updated_at = $3 where id = $4",
+			startDate, endDate, time.Now(), id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.DB.ExecContext(ctx, "update room_restrictions set start_date = $1, end_date = $2, updated_at = $3 where reservation_id = $4",
+			startDate, endDate, time.Now(), id)
+		if err != nil {
+			return unavailableErr(err)
+		}
+
+		available = true
+		return nil
+	})
+
+	// somebody took the dates between the check and the update
+	if errors.Is(err, repository.ErrUnavailable) {
+		return false, nil
+	}
+
+	return available, err
+}
+
+// GetReservationStatusHistory returns the status changes of a reservation, oldest first
+func (m *postgresDBRepo) GetReservationStatusHistory(ctx context.Context, id int) ([]models.StatusChange, error) {
+	query := `
+		select c.id, c.from_status, c.to_status, coalesce(c.user_id, 0), c.created_at, c.updated_at,
+		coalesce(u.first_name, ''), coalesce(u.last_name, '')
+		from reservation_status_changes c
+		left join users u on (c.user_id = u.id)
+		where c.reservation_id = $1
+		order by c.created_at asc, c.id asc
+	`
+
+	return m.statusHistory(ctx, query, id)
+}
+
+// statusHistory runs a status history query and scans the rows
+func (m *postgresDBRepo) statusHistory(ctx context.Context, query string, id int) ([]models.StatusChange, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var changes []models.StatusChange
+
+	rows, err := m.DB.QueryContext(ctx, query, id)
 	if err != nil {
-		return err
+		return changes, err
 	}
+	defer rows.Close()
 
-	return nil
+	for rows.Next() {
+		var c models.StatusChange
+		err := rows.Scan(
+			&c.ID,
+			&c.FromStatus,
+			&c.ToStatus,
+			&c.UserID,
+			&c.CreatedAt,
+			&c.UpdatedAt,
+			&c.User.FirstName,
+			&c.User.LastName,
+		)
+		if err != nil {
+			return changes, err
+		}
+		c.User.ID = c.UserID
+		changes = append(changes, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return changes, err
+	}
+
+	return changes, nil
+}
+
+// unavailableErr turns a violation of the no overlap constraints on the restriction tables into ErrUnavailable
+func unavailableErr(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23P01" {
+		return repository.ErrUnavailable
+	}
+	return err
+}
+
+// nullInt stores zero ids as null so optional foreign keys stay valid
+func nullInt(i int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(i), Valid: i > 0}
 }
 
 // GetRestrictionsForCurrentRoom returns restrictions for a room by date range
-func (m *postgresDBRepo) GetRestrictionsForCurrentRoom(roomID int, start, end time.Time) ([]models.RoomRestriction, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (m *postgresDBRepo) GetRestrictionsForCurrentRoom(ctx context.Context, roomID int, start, end time.Time) ([]models.RoomRestriction, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var restrictions []models.RoomRestriction
 
 	query := `
-		select id, coalesce(reservation_id, 0), restriction_id, room_id, start_date, end_date
-		from room_restrictions where $1 < end_date and $2 >= start_date
+		select id, coalesce(reservation_id, 0), restriction_id, room_id, start_date, end_date, reason
+		from room_restrictions where $1 <= end_date and $2 >= start_date
 		and room_id = $3
 `
 
@@ -621,6 +1084,7 @@ func (m *postgresDBRepo) GetRestrictionsForCurrentRoom(roomID int, start, end ti
 			&r.RoomID,
 			&r.StartDate,
 			&r.EndDate,
+			&r.Reason,
 		)
 		if err != nil {
 			return nil, err
@@ -635,25 +1099,25 @@ func (m *postgresDBRepo) GetRestrictionsForCurrentRoom(roomID int, start, end ti
 	return restrictions, nil
 }
 
-// InsertBlockForRoom inserts a room restriction
-func (m *postgresDBRepo) InsertBlockForRoom(id int, startDate time.Time) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// InsertBlockForRoom inserts an owner block for a room from startDate to endDate
+func (m *postgresDBRepo) InsertBlockForRoom(ctx context.Context, id int, startDate, endDate time.Time, reason string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	query := `insert into room_restrictions (start_date, end_date, room_id, restriction_id,
-		created_at, updated_at) values ($1, $2, $3, $4, $5, $6)`
+	query := `insert into room_restrictions (start_date, end_date, room_id, restriction_id, reason,
+		created_at, updated_at) values ($1, $2, $3, $4, $5, $6, $7)`
 
-	_, err := m.DB.ExecContext(ctx, query, startDate, startDate, id, 2, time.Now(), time.Now())
+	_, err := m.DB.ExecContext(ctx, query, startDate, endDate, id, 2, reason, time.Now(), time.Now())
 	if err != nil {
 		log.Println(err)
-		return err
+		return unavailableErr(err)
 	}
 	return nil
 }
 
 // DeleteBlockByID deletes a room restriction
-func (m *postgresDBRepo) DeleteBlockByID(id int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (m *postgresDBRepo) DeleteBlockByID(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	query := `delete from room_restrictions where id = $1`
@@ -667,13 +1131,13 @@ func (m *postgresDBRepo) DeleteBlockByID(id int) error {
 }
 
 // InsertTodoList inserts a new todo list into the database
-func (repo *postgresDBRepo) InsertTodoList(todo models.TodoList) error {
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *postgresDBRepo) InsertTodoList(ctx context.Context, todo models.TodoList) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	query := `insert into todo_list (todo, user_id, created_at, updated_at) values($1, $2, $3, $4)`
 
-	_, err := repo.DB.ExecContext(context, query, todo.Todo, todo.UserID, time.Now(), time.Now())
+	_, err := repo.DB.ExecContext(ctx, query, todo.Todo, todo.UserID, time.Now(), time.Now())
 
 	if err != nil {
 		return err
@@ -683,8 +1147,8 @@ func (repo *postgresDBRepo) InsertTodoList(todo models.TodoList) error {
 }
 
 // GetTodoListByUserID gets all todo for a user by user_id
-func (m *postgresDBRepo) GetTodoListByUserID(id int) ([]models.TodoList, error) {
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (m *postgresDBRepo) GetTodoListByUserID(ctx context.Context, id int) ([]models.TodoList, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var todoList []models.TodoList
@@ -694,7 +1158,7 @@ func (m *postgresDBRepo) GetTodoListByUserID(id int) ([]models.TodoList, error)
 		order by created_at asc
 	`
 
-	rows, err := m.DB.QueryContext(context, query, id)
+	rows, err := m.DB.QueryContext(ctx, query, id)
 	if err != nil {
 		return todoList, err
 	}
@@ -724,8 +1188,8 @@ func (m *postgresDBRepo) GetTodoListByUserID(id int) ([]models.TodoList, error)
 }
 
 // DeleteTodo deletes a todo
-func (m *postgresDBRepo) DeleteTodo(id int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (m *postgresDBRepo) DeleteTodo(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	query := `delete from todo_list where id = $1`
@@ -741,44 +1205,74 @@ func (m *postgresDBRepo) DeleteTodo(id int) error {
 
 //  --------Recent---------- //
 
-// Get all artists
-func (m *postgresDBRepo) AllArtists() ([]models.Artist, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// AllArtistsByStatus returns the artists whose listing is in status, or every artist when status is empty
+func (m *postgresDBRepo) AllArtistsByStatus(ctx context.Context, status string) ([]models.Artist, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	var artists []models.Artist
+	query := `select ` + artistColumns + ` from artists where ($1 = '' or status = $1) order by created_at asc`
 
-	query := `select id, name, genres, description, phone, email, city, facebook, twitter, youtube, logo, banner, featured_image, created_at, updated_at from artists order by created_at asc`
+	return m.artists(ctx, query, status)
+}
 
-	rows, err := m.DB.QueryContext(ctx, query)
-	if err != nil {
-		return artists, err
-	}
-	defer rows.Close()
+// AllArtistsByUserID returns the artists owned by a user, oldest first
+func (m *postgresDBRepo) AllArtistsByUserID(ctx context.Context, userID int) ([]models.Artist, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
 
-	for rows.Next() {
-		var artist models.Artist
-		err := rows.Scan(
-			&artist.ID,
-			&artist.Name,
-			&artist.Genres,
-			&artist.Description,
-			&artist.Phone,
-			&artist.Email,
-			&artist.City,
-			&artist.Facebook,
-			&artist.Twitter,
-			&artist.Youtube,
-			&artist.Logo,
-			&artist.Banner,
-			&artist.FeaturedImage,
-			&artist.CreatedAt,
-			&artist.UpdatedAt,
-		)
-		if err != nil {
-			return artists, err
-		}
-		artists = append(artists, artist)
+	query := `select ` + artistColumns + ` from artists where user_id = $1 order by created_at asc`
+
+	return m.artists(ctx, query, userID)
+}
+
+// artistColumns are the columns scanArtist reads, in order
+const artistColumns = `id, name, genres, description, phone, email, city, facebook, twitter, youtube, logo, banner,
+	featured_image, coalesce(user_id, 0), status, status_reason, created_at, updated_at`
+
+// scanArtist scans the artistColumns of a row into an artist
+func scanArtist(scan func(dest ...interface{}) error) (models.Artist, error) {
+	var artist models.Artist
+
+	err := scan(
+		&artist.ID,
+		&artist.Name,
+		&artist.Genres,
+		&artist.Description,
+		&artist.Phone,
+		&artist.Email,
+		&artist.City,
+		&artist.Facebook,
+		&artist.Twitter,
+		&artist.Youtube,
+		&artist.Logo,
+		&artist.Banner,
+		&artist.FeaturedImage,
+		&artist.UserID,
+		&artist.Status,
+		&artist.StatusReason,
+		&artist.CreatedAt,
+		&artist.UpdatedAt,
+	)
+
+	return artist, err
+}
+
+// artists runs an artists query and scans the rows
+func (m *postgresDBRepo) artists(ctx context.Context, query string, args ...interface{}) ([]models.Artist, error) {
+	var artists []models.Artist
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return artists, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		artist, err := scanArtist(rows.Scan)
+		if err != nil {
+			return artists, err
+		}
+		artists = append(artists, artist)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -788,84 +1282,1064 @@ func (m *postgresDBRepo) AllArtists() ([]models.Artist, error) {
 	return artists, nil
 }
 
-// Inserts a new Artist into the database
-func (repo *postgresDBRepo) CreateArtist(artist models.Artist) error {
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// Inserts a new Artist with its genres into the database and returns its id
+func (repo *postgresDBRepo) CreateArtist(ctx context.Context, artist models.Artist) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newID int
+
+	var userID sql.NullInt64
+	if artist.UserID > 0 {
+		userID = sql.NullInt64{Int64: int64(artist.UserID), Valid: true}
+	}
+
+	err := repo.withTx(ctx, func(tx *postgresDBRepo) error {
+		query := `insert into artists (name, description, phone, email, city, facebook, twitter, youtube, logo, banner, featured_image, user_id, status, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) returning id`
+
+		err := tx.DB.QueryRowContext(ctx, query, artist.Name, artist.Description, artist.Phone, artist.Email, artist.City, artist.Facebook, artist.Twitter, artist.Youtube, artist.Logo, artist.Banner, artist.FeaturedImage, userID, artist.Status, time.Now(), time.Now()).Scan(&newID)
+		if err != nil {
+			return err
+		}
+
+		return tx.setArtistGenres(ctx, newID, artist.GenreList)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return newID, nil
+}
+
+// Get an approved artist by id
+func (repo *postgresDBRepo) GetArtistByID(ctx context.Context, id int) (models.Artist, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select ` + artistColumns + ` from artists where id = $1 and status = $2`
+
+	artist, err := scanArtist(repo.DB.QueryRowContext(ctx, query, id, listing.Approved).Scan)
+	if err != nil {
+		return artist, err
+	}
+
+	artist.GenreList, err = repo.artistGenres(ctx, artist.ID)
+	return artist, err
+}
+
+// GetArtistByIDAnyStatus returns an artist whatever the status of its listing
+func (repo *postgresDBRepo) GetArtistByIDAnyStatus(ctx context.Context, id int) (models.Artist, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select ` + artistColumns + ` from artists where id = $1`
+
+	artist, err := scanArtist(repo.DB.QueryRowContext(ctx, query, id).Scan)
+	if err != nil {
+		return artist, err
+	}
+
+	artist.GenreList, err = repo.artistGenres(ctx, artist.ID)
+	return artist, err
+}
+
+// UpdateArtistStatus moves the listing of an artist from one status to another, if nobody changed it since it was read
+func (repo *postgresDBRepo) UpdateArtistStatus(ctx context.Context, id int, from, to, reason string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	result, err := repo.DB.ExecContext(ctx, "update artists set status = $1, status_reason = $2, updated_at = $3 where id = $4 and status = $5",
+		to, reason, time.Now(), id, from)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return listing.ErrStaleStatus
+	}
+
+	return nil
+}
+
+// UpdateArtist updates an artist and its genres in the database
+func (m *postgresDBRepo) UpdateArtist(ctx context.Context, artist models.Artist) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return m.withTx(ctx, func(tx *postgresDBRepo) error {
+		query := `
+			update artists set name = $1, description = $2, phone = $3, email = $4, city = $5, facebook = $6, twitter = $7, youtube = $8, logo = $9, banner = $10, featured_image = $11, updated_at = $12
+			where id = $13
+		`
+
+		_, err := tx.DB.ExecContext(ctx, query,
+			artist.Name,
+			artist.Description,
+			artist.Phone,
+			artist.Email,
+			artist.City,
+			artist.Facebook,
+			artist.Twitter,
+			artist.Youtube,
+			artist.Logo,
+			artist.Banner,
+			artist.FeaturedImage,
+			time.Now(),
+			artist.ID,
+		)
+		if err != nil {
+			return err
+		}
+
+		return tx.setArtistGenres(ctx, artist.ID, artist.GenreList)
+	})
+}
+
+// artistGenres returns the genres of an artist by name
+func (m *postgresDBRepo) artistGenres(ctx context.Context, artistID int) ([]models.Genre, error) {
+	query := `select ` + genreColumns + ` from genres g
+		where g.id in (select genre_id from artist_genres where artist_id = $2) order by g.name`
+
+	return m.genres(ctx, query, listing.Approved, artistID)
+}
+
+// setArtistGenres replaces the genres of an artist. It must run in a transaction with the artist's other changes
+func (m *postgresDBRepo) setArtistGenres(ctx context.Context, artistID int, genreList []models.Genre) error {
+	_, err := m.DB.ExecContext(ctx, `delete from artist_genres where artist_id = $1`, artistID)
+	if err != nil {
+		return err
+	}
+
+	for _, genre := range genreList {
+		_, err = m.DB.ExecContext(ctx, `insert into artist_genres (artist_id, genre_id, created_at, updated_at) values ($1, $2, $3, $3)
+			on conflict (artist_id, genre_id) do nothing`, artistID, genre.ID, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	return m.refreshArtistGenres(ctx, []int{artistID})
+}
+
+// refreshArtistGenres sets the genres column of the artists with ids to the names of their genres, and recomputes
+// their search_vector. Names weigh most, then genres, their aliases and the city, then the description. The
+// migrations that added the search_vector and genres fill it the same way, when there were no aliases yet
+func (m *postgresDBRepo) refreshArtistGenres(ctx context.Context, ids []int) error {
+	query := `
+		update artists a set
+			genres = coalesce(g.names, ''),
+			search_vector = setweight(to_tsvector('simple', a.name), 'A') ||
+				setweight(to_tsvector('simple', coalesce(g.words, '') || ' ' || a.city), 'B') ||
+				setweight(to_tsvector('english', a.description), 'C')
+		from artists b
+		left join lateral (
+			select
+				string_agg(ge.name, ', ' order by ge.name) as names,
+				string_agg(ge.name || ' ' || coalesce((select string_agg(ga.name, ' ') from genre_aliases ga where ga.genre_id = ge.id), ''), ' ') as words
+			from artist_genres ag
+			join genres ge on ge.id = ag.genre_id
+			where ag.artist_id = b.id
+		) g on true
+		where b.id = a.id and a.id = any($1)
+	`
+
+	_, err := m.DB.ExecContext(ctx, query, pq.Array(ids))
+	return err
+}
+
+// genreArtistIDs returns the ids of the artists with a genre
+func (m *postgresDBRepo) genreArtistIDs(ctx context.Context, genreID int) ([]int, error) {
+	var ids []int
+
+	rows, err := m.DB.QueryContext(ctx, `select artist_id from artist_genres where genre_id = $1`, genreID)
+	if err != nil {
+		return ids, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// errGenreMergedIntoItself is returned by MergeGenre when both genres are the same
+var errGenreMergedIntoItself = errors.New("a genre can't be merged into itself")
+
+// genreColumns are the columns scanGenre reads, in order. The artists count needs the approved status as $1
+const genreColumns = `g.id, g.name, g.slug,
+	coalesce((select array_agg(ga.name order by ga.name) from genre_aliases ga where ga.genre_id = g.id), '{}'),
+	(select count(*) from artist_genres ag join artists a on a.id = ag.artist_id where ag.genre_id = g.id and a.status = $1),
+	g.created_at, g.updated_at`
+
+// scanGenre scans the genreColumns of a row into a genre
+func scanGenre(scan func(dest ...interface{}) error) (models.Genre, error) {
+	var genre models.Genre
+
+	err := scan(
+		&genre.ID,
+		&genre.Name,
+		&genre.Slug,
+		pq.Array(&genre.Aliases),
+		&genre.Artists,
+		&genre.CreatedAt,
+		&genre.UpdatedAt,
+	)
+
+	return genre, err
+}
+
+// genres runs a genres query and scans the rows
+func (m *postgresDBRepo) genres(ctx context.Context, query string, args ...interface{}) ([]models.Genre, error) {
+	var genres []models.Genre
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return genres, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		genre, err := scanGenre(rows.Scan)
+		if err != nil {
+			return genres, err
+		}
+		genres = append(genres, genre)
+	}
+
+	if err = rows.Err(); err != nil {
+		return genres, err
+	}
+
+	return genres, nil
+}
+
+// AllGenres returns every genre by name
+func (m *postgresDBRepo) AllGenres(ctx context.Context) ([]models.Genre, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return m.genres(ctx, `select `+genreColumns+` from genres g order by g.name`, listing.Approved)
+}
+
+// GetGenreByID returns a genre by id
+func (m *postgresDBRepo) GetGenreByID(ctx context.Context, id int) (models.Genre, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select ` + genreColumns + ` from genres g where g.id = $2`
+
+	return scanGenre(m.DB.QueryRowContext(ctx, query, listing.Approved, id).Scan)
+}
+
+// GetGenreBySlug returns the genre with slug, or the genre with an alias with that slug
+func (m *postgresDBRepo) GetGenreBySlug(ctx context.Context, slug string) (models.Genre, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select ` + genreColumns + ` from genres g
+		where g.slug = $2 or g.id = (select genre_id from genre_aliases where slug = $2)`
+
+	return scanGenre(m.DB.QueryRowContext(ctx, query, listing.Approved, slug).Scan)
+}
+
+// genreTaken reports whether the name or an alias of a genre has the slug of a genre or alias of another genre
+func (m *postgresDBRepo) genreTaken(ctx context.Context, genre models.Genre) (bool, error) {
+	slugs := []string{genres.Slug(genre.Name)}
+	for _, alias := range genre.Aliases {
+		slugs = append(slugs, genres.Slug(alias))
+	}
+
+	var count int
+
+	query := `
+		select count(*) from (
+			select id as genre_id, slug from genres
+			union all
+			select genre_id, slug from genre_aliases
+		) s where s.slug = any($1) and s.genre_id <> $2`
+
+	err := m.DB.QueryRowContext(ctx, query, pq.Array(slugs), genre.ID).Scan(&count)
+
+	return count > 0, err
+}
+
+// setGenreAliases replaces the aliases of a genre
+func (m *postgresDBRepo) setGenreAliases(ctx context.Context, genreID int, aliases []string) error {
+	_, err := m.DB.ExecContext(ctx, `delete from genre_aliases where genre_id = $1`, genreID)
+	if err != nil {
+		return err
+	}
+
+	for _, alias := range aliases {
+		_, err = m.DB.ExecContext(ctx, `insert into genre_aliases (genre_id, name, slug, created_at, updated_at) values ($1, $2, $3, $4, $4)`,
+			genreID, alias, genres.Slug(alias), time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateGenre inserts a genre with its aliases and returns its id
+func (m *postgresDBRepo) CreateGenre(ctx context.Context, genre models.Genre) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newID int
+
+	err := m.withTx(ctx, func(tx *postgresDBRepo) error {
+		taken, err := tx.genreTaken(ctx, genre)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return repository.ErrGenreTaken
+		}
+
+		err = tx.DB.QueryRowContext(ctx, `insert into genres (name, slug, created_at, updated_at) values ($1, $2, $3, $3) returning id`,
+			genre.Name, genres.Slug(genre.Name), time.Now()).Scan(&newID)
+		if err != nil {
+			return err
+		}
+
+		return tx.setGenreAliases(ctx, newID, genre.Aliases)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return newID, nil
+}
+
+// UpdateGenre renames a genre and replaces its aliases, then refreshes the genres of its artists
+func (m *postgresDBRepo) UpdateGenre(ctx context.Context, genre models.Genre) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return m.withTx(ctx, func(tx *postgresDBRepo) error {
+		taken, err := tx.genreTaken(ctx, genre)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return repository.ErrGenreTaken
+		}
+
+		_, err = tx.DB.ExecContext(ctx, `update genres set name = $1, slug = $2, updated_at = $3 where id = $4`,
+			genre.Name, genres.Slug(genre.Name), time.Now(), genre.ID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.setGenreAliases(ctx, genre.ID, genre.Aliases)
+		if err != nil {
+			return err
+		}
+
+		ids, err := tx.genreArtistIDs(ctx, genre.ID)
+		if err != nil {
+			return err
+		}
+
+		return tx.refreshArtistGenres(ctx, ids)
+	})
+}
+
+// MergeGenre moves the artists of genre id to genre into, keeps the name and aliases of id as aliases of into
+// and deletes genre id
+func (m *postgresDBRepo) MergeGenre(ctx context.Context, id, into int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if id == into {
+		return errGenreMergedIntoItself
+	}
+
+	return m.withTx(ctx, func(tx *postgresDBRepo) error {
+		from, err := tx.GetGenreByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		ids, err := tx.genreArtistIDs(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.DB.ExecContext(ctx, `
+			insert into artist_genres (artist_id, genre_id, created_at, updated_at)
+			select artist_id, $1, $2, $2 from artist_genres where genre_id = $3
+			on conflict (artist_id, genre_id) do nothing`, into, time.Now(), id)
+		if err != nil {
+			return err
+		}
+
+		// deleting the genre frees its slug and the slugs of its aliases, which go to into
+		_, err = tx.DB.ExecContext(ctx, `delete from genres where id = $1`, id)
+		if err != nil {
+			return err
+		}
+
+		for _, alias := range append([]string{from.Name}, from.Aliases...) {
+			_, err = tx.DB.ExecContext(ctx, `insert into genre_aliases (genre_id, name, slug, created_at, updated_at) values ($1, $2, $3, $4, $4)`,
+				into, alias, genres.Slug(alias), time.Now())
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.refreshArtistGenres(ctx, ids)
+	})
+}
+
+// DeleteGenre deletes a genre, which the foreign keys take off its artists, and refreshes their genres
+func (m *postgresDBRepo) DeleteGenre(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return m.withTx(ctx, func(tx *postgresDBRepo) error {
+		ids, err := tx.genreArtistIDs(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.DB.ExecContext(ctx, `delete from genres where id = $1`, id)
+		if err != nil {
+			return err
+		}
+
+		return tx.refreshArtistGenres(ctx, ids)
+	})
+}
+
+// AllBookingss returns a slice of all bookings
+func (repo *postgresDBRepo) AllBookings(ctx context.Context) ([]models.Bookings, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	query := `insert into artists (name, genres, description, phone, email, city, facebook, twitter, youtube, logo, banner, featured_image, created_at, updated_at) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+	var bookings []models.Bookings
+
+	query := `
+		select b.id, b.first_name, b.last_name, b.email, b.phone, b.start_date, 
+		b.end_date, b.status, b.artist_id, b.created_at, b.updated_at,
+		ar.id, ar.name, ar.genres, ar.description, ar.city
+		from bookings b
+		left join artists ar on (b.artist_id = ar.id)
+		order by b.start_date asc
+	`
+
+	rows, err := repo.DB.QueryContext(ctx, query)
+	if err != nil {
+		return bookings, err
+	}
+	defer rows.Close()
 
-	_, err := repo.DB.ExecContext(context, query, artist.Name, artist.Genres, artist.Description, artist.Phone, artist.Email, artist.City, artist.Facebook, artist.Twitter, artist.Youtube, artist.Logo, artist.Banner, artist.FeaturedImage, time.Now(), time.Now())
+	for rows.Next() {
+		var i models.Bookings
+		err := rows.Scan(
+			&i.ID,
+			&i.FirstName,
+			&i.LastName,
+			&i.Email,
+			&i.Phone,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Status,
+			&i.ArtistID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Artist.ID,
+			&i.Artist.Name,
+			&i.Artist.Genres,
+			&i.Artist.Description,
+			&i.Artist.City,
+		)
+
+		if err != nil {
+			return bookings, err
+		}
+		bookings = append(bookings, i)
+	}
+
+	if err = rows.Err(); err != nil {
+		return bookings, err
+	}
+
+	return bookings, nil
+}
+
+// AllNewBookings returns a slice of all pending Bookings
+func (m *postgresDBRepo) AllNewBookings(ctx context.Context) ([]models.Bookings, error) {
+	return m.AllBookingsByStatus(ctx, status.Pending)
+}
+
+// AllBookingsByStatus returns a slice of all bookings in the given status
+func (m *postgresDBRepo) AllBookingsByStatus(ctx context.Context, status string) ([]models.Bookings, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `
+		select b.id, b.first_name, b.last_name, b.email, b.phone, b.start_date, 
+		b.end_date, b.status, b.artist_id, b.created_at, b.updated_at,
+		ar.id, ar.name, ar.genres, ar.description, ar.city
+		from bookings b
+		left join artists ar on (b.artist_id = ar.id)
+		where b.status = $1
+		order by b.start_date asc
+	`
+
+	return m.bookingsWithArtist(ctx, query, status)
+}
+
+// AllBookingsByArtistID returns the bookings of an artist, earliest start date first
+func (m *postgresDBRepo) AllBookingsByArtistID(ctx context.Context, artistID int) ([]models.Bookings, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `
+		select b.id, b.first_name, b.last_name, b.email, b.phone, b.start_date,
+		b.end_date, b.status, b.artist_id, b.created_at, b.updated_at,
+		ar.id, ar.name, ar.genres, ar.description, ar.city
+		from bookings b
+		left join artists ar on (b.artist_id = ar.id)
+		where b.artist_id = $1
+		order by b.start_date asc, b.id asc
+	`
+
+	return m.bookingsWithArtist(ctx, query, artistID)
+}
+
+// AllBookingsByUserID returns the bookings of a customer account with the option booked, earliest start date first
+func (m *postgresDBRepo) AllBookingsByUserID(ctx context.Context, userID int) ([]models.Bookings, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var bookings []models.Bookings
+
+	query := `
+		select b.id, b.first_name, b.last_name, b.email, b.phone, b.start_date,
+		b.end_date, b.status, b.artist_id, coalesce(b.booking_option_id, 0), b.user_id,
+		b.created_at, b.updated_at,
+		ar.id, ar.name, ar.genres, ar.city,
+		coalesce(bo.title, ''), coalesce(bo.price, '')
+		from bookings b
+		left join artists ar on (b.artist_id = ar.id)
+		left join booking_options bo on (b.booking_option_id = bo.id)
+		where b.user_id = $1
+		order by b.start_date asc, b.id asc
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return bookings, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var i models.Bookings
+		err := rows.Scan(
+			&i.ID,
+			&i.FirstName,
+			&i.LastName,
+			&i.Email,
+			&i.Phone,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Status,
+			&i.ArtistID,
+			&i.BookingOptionID,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Artist.ID,
+			&i.Artist.Name,
+			&i.Artist.Genres,
+			&i.Artist.City,
+			&i.BookingOption.Title,
+			&i.BookingOption.Price,
+		)
+		if err != nil {
+			return bookings, err
+		}
+
+		i.BookingOption.ID = i.BookingOptionID
+		bookings = append(bookings, i)
+	}
+
+	if err = rows.Err(); err != nil {
+		return bookings, err
+	}
+
+	return bookings, nil
+}
+
+// AllReservationsByUserID returns the room reservations of a customer account, earliest start date first
+func (m *postgresDBRepo) AllReservationsByUserID(ctx context.Context, userID int) ([]models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var reservations []models.Reservation
+
+	query := `
+		select r.id, r.first_name, r.last_name, r.email, r.phone, r.start_date,
+		r.end_date, r.room_id, r.created_at, r.updated_at, r.status, r.user_id,
+		rm.id, rm.room_name
+		from reservations r
+		left join rooms rm on (r.room_id = rm.id)
+		where r.user_id = $1
+		order by r.start_date asc, r.id asc
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return reservations, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var i models.Reservation
+		err := rows.Scan(
+			&i.ID,
+			&i.FirstName,
+			&i.LastName,
+			&i.Email,
+			&i.Phone,
+			&i.StartDate,
+			&i.EndDate,
+			&i.RoomID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.UserID,
+			&i.Room.ID,
+			&i.Room.RoomName,
+		)
+		if err != nil {
+			return reservations, err
+		}
+		reservations = append(reservations, i)
+	}
+
+	if err = rows.Err(); err != nil {
+		return reservations, err
+	}
+
+	return reservations, nil
+}
+
+// ClaimBookings links the guest bookings and room reservations made with email to a customer account and
+// returns how many it linked. Only call it with an email the user has verified
+func (m *postgresDBRepo) ClaimBookings(ctx context.Context, userID int, email string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var claimed int64
+
+	err := m.withTx(ctx, func(tx *postgresDBRepo) error {
+		for _, table := range []string{"bookings", "reservations"} {
+			query := `update ` + table + ` set user_id = $1, updated_at = $2 where user_id is null and lower(email) = lower($3)`
+
+			result, err := tx.DB.ExecContext(ctx, query, userID, time.Now(), email)
+			if err != nil {
+				return err
+			}
+
+			n, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			claimed += n
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(claimed), nil
+}
+
+// bookingsWithArtist runs a bookings query that also selects a few columns of the artist, and scans the rows
+func (m *postgresDBRepo) bookingsWithArtist(ctx context.Context, query string, args ...interface{}) ([]models.Bookings, error) {
+	var bookings []models.Bookings
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return bookings, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var i models.Bookings
+		err := rows.Scan(
+			&i.ID,
+			&i.FirstName,
+			&i.LastName,
+			&i.Email,
+			&i.Phone,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Status,
+			&i.ArtistID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Artist.ID,
+			&i.Artist.Name,
+			&i.Artist.Genres,
+			&i.Artist.Description,
+			&i.Artist.City,
+		)
+
+		if err != nil {
+			return bookings, err
+		}
+		bookings = append(bookings, i)
+	}
+
+	if err = rows.Err(); err != nil {
+		return bookings, err
+	}
+
+	return bookings, nil
+}
+
+// InsertBooking inserts a booking and the artist restriction that blocks its dates in one transaction
+func (repo *postgresDBRepo) InsertBooking(ctx context.Context, booking models.Bookings) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newID int
+
+	var optionID sql.NullInt64
+	if booking.BookingOptionID > 0 {
+		optionID = sql.NullInt64{Int64: int64(booking.BookingOptionID), Valid: true}
+	}
+
+	var userID sql.NullInt64
+	if booking.UserID > 0 {
+		userID = sql.NullInt64{Int64: int64(booking.UserID), Valid: true}
+	}
+
+	err := repo.withTx(ctx, func(tx *postgresDBRepo) error {
+		insertStatement := `insert into bookings (first_name, last_name, email, phone, start_date, end_date, artist_id, booking_option_id, user_id, event_location, message, created_at, updated_at) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) returning id`
+
+		err := tx.DB.QueryRowContext(ctx, insertStatement, booking.FirstName, booking.LastName, booking.Email, booking.Phone, booking.StartDate, booking.EndDate, booking.ArtistID, optionID, userID, booking.EventLocation, booking.Message, time.Now(), time.Now()).Scan(&newID)
+		if err != nil {
+			return err
+		}
+
+		restrictionStatement := `insert into artist_restrictions (start_date, end_date, artist_id, booking_id, restriction_id, created_at, updated_at) values ($1, $2, $3, $4, $5, $6, $7)`
+
+		_, err = tx.DB.ExecContext(ctx, restrictionStatement, booking.StartDate, booking.EndDate, booking.ArtistID, newID, 1, time.Now(), time.Now())
+		return unavailableErr(err)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return newID, nil
+}
+
+// SearchAvailabilityByDatesByArtistID returns true if availability exists for artistID, and false if no availability
+func (repo *postgresDBRepo) SearchAvailabilityByDatesByArtistID(ctx context.Context, start, end time.Time, artistID int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var numRows int
+
+	query := `
+		select
+			count(id)
+		from
+			artist_restrictions
+		where
+			artist_id = $1
+			and $2 <= end_date and $3 >= start_date;`
+
+	row := repo.DB.QueryRowContext(ctx, query, artistID, start, end)
+	err := row.Scan(&numRows)
+	if err != nil {
+		return false, err
+	}
+
+	if numRows == 0 {
+		return true, nil
+	}
+	return false, nil
+}
+
+// SearchArtists returns the approved artists matching filter and how many match in all. The query is matched
+// against search_vector. Booking option prices are free text, so the first number in each one is taken as its price,
+// up to 18 digits so that it always fits a bigint
+func (repo *postgresDBRepo) SearchArtists(ctx context.Context, filter repository.ArtistFilter) ([]models.Artist, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var artists []models.Artist
+	var total int
+
+	var availableFrom, availableTo sql.NullTime
+	if !filter.AvailableFrom.IsZero() {
+		availableFrom = sql.NullTime{Time: filter.AvailableFrom, Valid: true}
+		availableTo = sql.NullTime{Time: filter.AvailableTo, Valid: true}
+	}
+
+	from := `
+		from artists a
+		left join (
+			select bo.artist_id, min(substring(replace(bo.price, ',', '') from '[0-9]{1,18}')::bigint) as from_price
+			from booking_options bo join artists x on (x.id = bo.artist_id and x.status = $1)
+			group by bo.artist_id
+		) p on (p.artist_id = a.id)
+		where a.status = $1
+		and (cardinality($2::text[]) = 0 or a.search_vector @@ ` + searchQuery("$2") + `)
+		and ($3 = '' or a.id in (
+			select ag.artist_id from artist_genres ag join genres g on g.id = ag.genre_id
+			where g.slug = $3 or g.id = (select genre_id from genre_aliases where slug = $3)))
+		and ($4 = '' or lower(a.city) = lower($4))
+		and ($5 = 0 or p.from_price >= $5)
+		and ($6 = 0 or p.from_price <= $6)
+		and ($7::date is null or a.id not in
+			(select artist_id from artist_restrictions ar where $7 <= ar.end_date and $8 >= ar.start_date))
+	`
+
+	args := []interface{}{
+		listing.Approved,
+		pq.Array(searchWords(filter.Query)),
+		filter.Genre,
+		filter.City,
+		filter.MinPrice,
+		filter.MaxPrice,
+		availableFrom, availableTo,
+	}
+
+	err := repo.DB.QueryRowContext(ctx, `select count(a.id) `+from, args...).Scan(&total)
+	if err != nil {
+		return artists, 0, err
+	}
+
+	orderBy := `a.name asc, a.id asc`
+	switch filter.Sort {
+	case repository.SortNewest:
+		orderBy = `a.created_at desc, a.id desc`
+	case repository.SortPrice:
+		orderBy = `p.from_price asc nulls last, a.name asc, a.id asc`
+	case repository.SortRelevance:
+		orderBy = `ts_rank(a.search_vector, ` + searchQuery("$2") + `) desc, a.name asc, a.id asc`
+	}
+
+	// a null limit returns every row
+	var limit sql.NullInt64
+	if filter.Limit > 0 {
+		limit = sql.NullInt64{Int64: int64(filter.Limit), Valid: true}
+	}
+
+	// the price subquery only adds artist_id and from_price, so the artist columns need no prefix
+	query := `select ` + artistColumns + `, coalesce(p.from_price, 0) ` + from + ` order by ` + orderBy + ` limit $9 offset $10`
+
+	rows, err := repo.DB.QueryContext(ctx, query, append(args, limit, filter.Offset)...)
+	if err != nil {
+		return artists, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var fromPrice int
+		artist, err := scanArtist(func(dest ...interface{}) error {
+			return rows.Scan(append(dest, &fromPrice)...)
+		})
+		if err != nil {
+			return artists, 0, err
+		}
+		artist.FromPrice = fromPrice
+		artists = append(artists, artist)
+	}
+
+	if err = rows.Err(); err != nil {
+		return artists, 0, err
+	}
+
+	return artists, total, nil
+}
+
+// SuggestArtists returns up to limit approved artists matching q for the search box, most relevant first
+func (repo *postgresDBRepo) SuggestArtists(ctx context.Context, q string, limit int) ([]models.ArtistSuggestion, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var suggestions []models.ArtistSuggestion
+
+	words := searchWords(q)
+	if len(words) == 0 {
+		return suggestions, nil
+	}
+
+	highlight := `StartSel=` + highlightStart + `, StopSel=` + highlightStop
+
+	query := `
+		select a.id, a.name, a.genres, a.city,
+			ts_headline('simple', a.name, q, 'HighlightAll=true, ` + highlight + `'),
+			ts_headline('english', coalesce(a.description, ''), q, 'MaxWords=15, MinWords=5, ` + highlight + `')
+		from artists a, ` + searchQuery("$1") + ` q
+		where a.status = $2 and a.search_vector @@ q
+		order by ts_rank(a.search_vector, q) desc, a.name asc
+		limit $3
+	`
+
+	rows, err := repo.DB.QueryContext(ctx, query, pq.Array(words), listing.Approved, limit)
+	if err != nil {
+		return suggestions, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s models.ArtistSuggestion
+		err := rows.Scan(&s.ID, &s.Name, &s.Genres, &s.City, &s.NameHTML, &s.SnippetHTML)
+		if err != nil {
+			return suggestions, err
+		}
+
+		s.NameHTML = markedHTML(s.NameHTML)
+		s.SnippetHTML = markedHTML(s.SnippetHTML)
+		suggestions = append(suggestions, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return suggestions, err
+	}
+
+	return suggestions, nil
+}
+
+// GetRestrictionsForCurrentArtist returns restrictions for an artist by date range
+func (m *postgresDBRepo) GetRestrictionsForCurrentArtist(ctx context.Context, artistID int, start, end time.Time) ([]models.ArtistRestriction, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var restrictions []models.ArtistRestriction
+
+	query := `
+		select id, coalesce(booking_id, 0), restriction_id, artist_id, start_date, end_date, reason
+		from artist_restrictions where $1 <= end_date and $2 >= start_date
+		and artist_id = $3
+		order by start_date asc
+`
+
+	rows, err := m.DB.QueryContext(ctx, query, start, end, artistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r models.ArtistRestriction
+		err := rows.Scan(
+			&r.ID,
+			&r.BookingID,
+			&r.RestrictionID,
+			&r.ArtistID,
+			&r.StartDate,
+			&r.EndDate,
+			&r.Reason,
+		)
+		if err != nil {
+			return nil, err
+		}
+		restrictions = append(restrictions, r)
+	}
 
-	if err != nil {
-		return err
+	if err = rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return restrictions, nil
 }
 
-// Get an artist by id
-func (repo *postgresDBRepo) GetArtistByID(id int) (models.Artist, error) {
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// GetBookingByID returns one booking by ID
+func (m *postgresDBRepo) GetBookingByID(ctx context.Context, id int) (models.Bookings, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	var artist models.Artist
+	var booking models.Bookings
 
 	query := `
-		select id, name, genres, description, phone, email, city, facebook, twitter, youtube, logo, banner, featured_image, created_at, updated_at from artists where id = $1
+		select b.id, b.first_name, b.last_name, b.email, b.phone, b.start_date,
+		b.end_date, b.status, b.artist_id, coalesce(b.booking_option_id, 0), b.event_location, b.message,
+		b.created_at, b.updated_at,
+		ar.id, ar.name, ar.genres, ar.city,
+		coalesce(bo.title, ''), coalesce(bo.price, '')
+		from bookings b
+		left join artists ar on (b.artist_id = ar.id)
+		left join booking_options bo on (b.booking_option_id = bo.id)
+		where b.id = $1
 	`
 
-	row := repo.DB.QueryRowContext(context, query, id)
+	row := m.DB.QueryRowContext(ctx, query, id)
+
 	err := row.Scan(
-		&artist.ID,
-		&artist.Name,
-		&artist.Genres,
-		&artist.Description,
-		&artist.Phone,
-		&artist.Email,
-		&artist.City,
-		&artist.Facebook,
-		&artist.Twitter,
-		&artist.Youtube,
-		&artist.Logo,
-		&artist.Banner,
-		&artist.FeaturedImage,
-		&artist.CreatedAt,
-		&artist.UpdatedAt,
+		&booking.ID,
+		&booking.FirstName,
+		&booking.LastName,
+		&booking.Email,
+		&booking.Phone,
+		&booking.StartDate,
+		&booking.EndDate,
+		&booking.Status,
+		&booking.ArtistID,
+		&booking.BookingOptionID,
+		&booking.EventLocation,
+		&booking.Message,
+		&booking.CreatedAt,
+		&booking.UpdatedAt,
+		&booking.Artist.ID,
+		&booking.Artist.Name,
+		&booking.Artist.Genres,
+		&booking.Artist.City,
+		&booking.BookingOption.Title,
+		&booking.BookingOption.Price,
 	)
 
 	if err != nil {
-		return artist, err
+		return booking, err
 	}
 
-	return artist, nil
+	booking.BookingOption.ID = booking.BookingOptionID
+
+	return booking, nil
 }
 
-// UpdateArtist updates an artist in the database
-func (m *postgresDBRepo) UpdateArtist(artist models.Artist) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// UpdateBooking updates a booking's customer details in the database
+func (m *postgresDBRepo) UpdateBooking(ctx context.Context, booking models.Bookings) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	query := `
-		update artists set name = $1, genres = $2, description = $3, phone = $4, email = $5, city = $6, facebook = $7, twitter = $8, youtube = $9, logo = $10, banner = $11, featured_image = $12, updated_at = $13
-		where id = $14		
+		update bookings set first_name = $1, last_name = $2, email = $3, phone = $4, event_location = $5, message = $6, updated_at = $7
+		where id = $8
 	`
 
 	_, err := m.DB.ExecContext(ctx, query,
-		artist.Name,
-		artist.Genres,
-		artist.Description,
-		artist.Phone,
-		artist.Email,
-		artist.City,
-		artist.Facebook,
-		artist.Twitter,
-		artist.Youtube,
-		artist.Logo,
-		artist.Banner,
-		artist.FeaturedImage,
+		booking.FirstName,
+		booking.LastName,
+		booking.Email,
+		booking.Phone,
+		booking.EventLocation,
+		booking.Message,
 		time.Now(),
-		artist.ID,
+		booking.ID,
 	)
 
 	if err != nil {
@@ -875,122 +2349,156 @@ func (m *postgresDBRepo) UpdateArtist(artist models.Artist) error {
 	return nil
 }
 
-// AllBookingss returns a slice of all bookings
-func (repo *postgresDBRepo) AllBookings() ([]models.Bookings, error) {
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// UpdateBookingStatus moves a booking from one status to another and records the change in its history.
+// Cancelling a booking also frees its dates on the artists calendar
+func (m *postgresDBRepo) UpdateBookingStatus(ctx context.Context, id int, from, to string, userID int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	var bookings []models.Bookings
+	return m.withTx(ctx, func(tx *postgresDBRepo) error {
+		// only update the row if nobody changed the status since it was read
+		result, err := tx.DB.ExecContext(ctx, "update bookings set status = $1, updated_at = $2 where id = $3 and status = $4",
+			to, time.Now(), id, from)
+		if err != nil {
+			return err
+		}
 
-	query := `
-		select b.id, b.first_name, b.last_name, b.email, b.phone, b.start_date, 
-		b.end_date, b.processed, b.artist_id, b.created_at, b.updated_at,
-		ar.id, ar.name, ar.genres, ar.description, ar.city
-		from bookings b
-		left join artists ar on (b.artist_id = ar.id)
-		order by b.start_date asc
-	`
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
 
-	rows, err := repo.DB.QueryContext(context, query)
-	if err != nil {
-		return bookings, err
-	}
-	defer rows.Close()
+		if rows == 0 {
+			return status.ErrStaleStatus
+		}
 
-	for rows.Next() {
-		var i models.Bookings
-		err := rows.Scan(
-			&i.ID,
-			&i.FirstName,
-			&i.LastName,
-			&i.Email,
-			&i.Phone,
-			&i.StartDate,
-			&i.EndDate,
-			&i.Processed,
-			&i.ArtistID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.Artist.ID,
-			&i.Artist.Name,
-			&i.Artist.Genres,
-			&i.Artist.Description,
-			&i.Artist.City,
-		)
+		query := `insert into booking_status_changes (booking_id, from_status, to_status, user_id, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6)`
 
+		_, err = tx.DB.ExecContext(ctx, query, id, from, to, nullInt(userID), time.Now(), time.Now())
 		if err != nil {
-			return bookings, err
+			return err
 		}
-		bookings = append(bookings, i)
-	}
 
-	if err = rows.Err(); err != nil {
-		return bookings, err
-	}
+		if to == status.Cancelled {
+			_, err = tx.DB.ExecContext(ctx, "delete from artist_restrictions where booking_id = $1", id)
+			if err != nil {
+				return err
+			}
+		}
 
-	return bookings, nil
+		return nil
+	})
 }
 
-// AllNewBookings returns a slice of all Bookings
-func (m *postgresDBRepo) AllNewBookings() ([]models.Bookings, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// RescheduleBooking moves a booking and its artist_restrictions row to new dates in one transaction.
+// It returns false without changing anything when the new dates clash with another booking or block
+func (m *postgresDBRepo) RescheduleBooking(ctx context.Context, id int, startDate, endDate time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	var bookings []models.Bookings
+	available := false
 
-	query := `
-		select b.id, b.first_name, b.last_name, b.email, b.phone, b.start_date, 
-		b.end_date, b.processed, b.artist_id, b.created_at, b.updated_at,
-		ar.id, ar.name, ar.genres, ar.description, ar.city
-		from bookings b
-		left join artists ar on (b.artist_id = ar.id)
-		where processed = 0
-		order by b.start_date asc
-	`
+	err := m.withTx(ctx, func(tx *postgresDBRepo) error {
+		// lock the booking so two reschedules of it can't run at the same time
+		var artistID int
+		err := tx.DB.QueryRowContext(ctx, "select artist_id from bookings where id = $1 for update", id).Scan(&artistID)
+		if err != nil {
+			return err
+		}
 
-	rows, err := m.DB.QueryContext(ctx, query)
-	if err != nil {
-		return bookings, err
-	}
-	defer rows.Close()
+		var numRows int
+		query := `
+			select
+				count(id)
+			from
+				artist_restrictions
+			where
+				artist_id = $1
+				and (booking_id is null or booking_id <> $2)
+				and $3 <= end_date and $4 >= start_date;`
+
+		err = tx.DB.QueryRowContext(ctx, query, artistID, id, startDate, endDate).Scan(&numRows)
+		if err != nil {
+			return err
+		}
 
-	for rows.Next() {
-		var i models.Bookings
-		err := rows.Scan(
-			&i.ID,
-			&i.FirstName,
-			&i.LastName,
-			&i.Email,
-			&i.Phone,
-			&i.StartDate,
-			&i.EndDate,
-			&i.Processed,
-			&i.ArtistID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.Artist.ID,
-			&i.Artist.Name,
-			&i.Artist.Genres,
-			&i.Artist.Description,
-			&i.Artist.City,
-		)
+		if numRows > 0 {
+			return nil
+		}
 
+		_, err = tx.DB.ExecContext(ctx, "update bookings set start_date = $1, end_date = $2, updated_at = $3 where id = $4",
+			startDate, endDate, time.Now(), id)
 		if err != nil {
-			return bookings, err
+			return err
 		}
-		bookings = append(bookings, i)
+
+		_, err = tx.DB.ExecContext(ctx, "update artist_restrictions set start_date = $1, end_date = $2, updated_at = $3 where booking_id = $4",
+			startDate, endDate, time.Now(), id)
+		if err != nil {
+			return unavailableErr(err)
+		}
+
+		available = true
+		return nil
+	})
+
+	// somebody took the dates between the check and the update
+	if errors.Is(err, repository.ErrUnavailable) {
+		return false, nil
 	}
 
-	if err = rows.Err(); err != nil {
-		return bookings, err
+	return available, err
+}
+
+// GetBookingStatusHistory returns the status changes of a booking, oldest first
+func (m *postgresDBRepo) GetBookingStatusHistory(ctx context.Context, id int) ([]models.StatusChange, error) {
+	query := `
+		select c.id, c.from_status, c.to_status, coalesce(c.user_id, 0), c.created_at, c.updated_at,
+		coalesce(u.first_name, ''), coalesce(u.last_name, '')
+		from booking_status_changes c
+		left join users u on (c.user_id = u.id)
+		where c.booking_id = $1
+		order by c.created_at asc, c.id asc
+	`
+
+	return m.statusHistory(ctx, query, id)
+}
+
+// InsertBlockForArtist inserts an owner block for an artist from startDate to endDate
+func (m *postgresDBRepo) InsertBlockForArtist(ctx context.Context, id int, startDate, endDate time.Time, reason string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `insert into artist_restrictions (start_date, end_date, artist_id, restriction_id, reason,
+		created_at, updated_at) values ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := m.DB.ExecContext(ctx, query, startDate, endDate, id, 2, reason, time.Now(), time.Now())
+	if err != nil {
+		log.Println(err)
+		return unavailableErr(err)
 	}
+	return nil
+}
 
-	return bookings, nil
+// DeleteArtistBlockByID deletes an artist restriction that is not tied to a booking
+func (m *postgresDBRepo) DeleteArtistBlockByID(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `delete from artist_restrictions where id = $1 and booking_id is null`
+
+	_, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	return nil
 }
 
 // Get all Booking Options
-func (m *postgresDBRepo) AllBookingOptions() ([]models.BookingOptions, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (m *postgresDBRepo) AllBookingOptions(ctx context.Context) ([]models.BookingOptions, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var options []models.BookingOptions
@@ -1028,8 +2536,8 @@ func (m *postgresDBRepo) AllBookingOptions() ([]models.BookingOptions, error) {
 }
 
 // Get all Booking Options
-func (m *postgresDBRepo) AllArtistBookingOptions(id int) ([]models.BookingOptions, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (m *postgresDBRepo) AllArtistBookingOptions(ctx context.Context, id int) ([]models.BookingOptions, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var options []models.BookingOptions
@@ -1067,13 +2575,13 @@ func (m *postgresDBRepo) AllArtistBookingOptions(id int) ([]models.BookingOption
 }
 
 // Inserts a new Boking Option into the database
-func (repo *postgresDBRepo) CreateBookingOption(option models.BookingOptions) error {
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *postgresDBRepo) CreateBookingOption(ctx context.Context, option models.BookingOptions) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	query := `insert into booking_options (title, description, price, artist_id, created_at, updated_at) values ($1, $2, $3, $4, $5, $6)`
 
-	_, err := repo.DB.ExecContext(context, query, option.Title, option.Description, option.Price, option.ArtistID, time.Now(), time.Now())
+	_, err := repo.DB.ExecContext(ctx, query, option.Title, option.Description, option.Price, option.ArtistID, time.Now(), time.Now())
 
 	if err != nil {
 		return err
@@ -1083,8 +2591,8 @@ func (repo *postgresDBRepo) CreateBookingOption(option models.BookingOptions) er
 }
 
 // Get a booking option by id
-func (repo *postgresDBRepo) GetBookingOptionByID(id int) (models.BookingOptions, error) {
-	context, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *postgresDBRepo) GetBookingOptionByID(ctx context.Context, id int) (models.BookingOptions, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var option models.BookingOptions
@@ -1093,7 +2601,7 @@ func (repo *postgresDBRepo) GetBookingOptionByID(id int) (models.BookingOptions,
 		select id, title, description, price, artist_id, created_at, updated_at from booking_options where id = $1
 	`
 
-	row := repo.DB.QueryRowContext(context, query, id)
+	row := repo.DB.QueryRowContext(ctx, query, id)
 	err := row.Scan(
 		&option.ID,
 		&option.Title,
@@ -1112,8 +2620,8 @@ func (repo *postgresDBRepo) GetBookingOptionByID(id int) (models.BookingOptions,
 }
 
 // UpdateBookingOption updates an option in the database
-func (m *postgresDBRepo) UpdateBookingOption(option models.BookingOptions) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (m *postgresDBRepo) UpdateBookingOption(ctx context.Context, option models.BookingOptions) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	query := `
@@ -1136,3 +2644,218 @@ func (m *postgresDBRepo) UpdateBookingOption(option models.BookingOptions) error
 
 	return nil
 }
+
+// EnqueueEmail adds a message to the outbox, due right away
+func (m *postgresDBRepo) EnqueueEmail(ctx context.Context, message models.MailData) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `insert into outbox_emails (to_email, from_email, from_name, reply_to, subject, content, text_content,
+		template, status, attempts, last_error, next_attempt_at, created_at, updated_at)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, 0, '', $10, $11, $12)`
+
+	_, err := m.DB.ExecContext(ctx, query, message.To, message.From, message.FromName, message.ReplyTo, message.Subject,
+		message.Content, message.Text, message.Template, outbox.StatusPending, time.Now(), time.Now(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ClaimDueEmails takes up to limit due emails, counts the attempt and pushes their next attempt past the lease.
+// Rows locked by another worker are skipped
+func (m *postgresDBRepo) ClaimDueEmails(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEmail, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `
+		update outbox_emails set attempts = attempts + 1, next_attempt_at = $1, updated_at = $2
+		where id in (
+			select id from outbox_emails
+			where status = $3 and next_attempt_at <= $2
+			order by next_attempt_at asc, id asc
+			limit $4
+			for update skip locked
+		)
+		returning id, to_email, from_email, from_name, reply_to, subject, content, text_content, template, status, attempts, last_error,
+		next_attempt_at, sent_at, created_at, updated_at
+	`
+
+	now := time.Now()
+
+	return m.outboxEmails(ctx, query, now.Add(lease), now, outbox.StatusPending, limit)
+}
+
+// MarkEmailSent records that an email was delivered
+func (m *postgresDBRepo) MarkEmailSent(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `update outbox_emails set status = $1, last_error = '', sent_at = $2, updated_at = $3 where id = $4`
+
+	_, err := m.DB.ExecContext(ctx, query, outbox.StatusSent, time.Now(), time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarkEmailFailed records a failed attempt and when to try again
+func (m *postgresDBRepo) MarkEmailFailed(ctx context.Context, id int, lastError string, retryAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `update outbox_emails set last_error = $1, next_attempt_at = $2, updated_at = $3 where id = $4`
+
+	_, err := m.DB.ExecContext(ctx, query, lastError, retryAt, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarkEmailDead stops retrying an email
+func (m *postgresDBRepo) MarkEmailDead(ctx context.Context, id int, lastError string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `update outbox_emails set status = $1, last_error = $2, updated_at = $3 where id = $4`
+
+	_, err := m.DB.ExecContext(ctx, query, outbox.StatusDead, lastError, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AllOutboxEmails returns the most recent outbox emails, newest first. An empty status returns every status
+func (m *postgresDBRepo) AllOutboxEmails(ctx context.Context, status string) ([]models.OutboxEmail, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `
+		select id, to_email, from_email, from_name, reply_to, subject, content, text_content, template, status, attempts, last_error,
+		next_attempt_at, sent_at, created_at, updated_at
+		from outbox_emails
+		where ($1 = '' or status = $1)
+		order by created_at desc, id desc
+		limit 500
+	`
+
+	return m.outboxEmails(ctx, query, status)
+}
+
+// ResendEmail puts an email that was not sent back in the queue with a fresh set of attempts
+func (m *postgresDBRepo) ResendEmail(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `update outbox_emails set status = $1, attempts = 0, next_attempt_at = $2, updated_at = $3
+		where id = $4 and status <> $5`
+
+	_, err := m.DB.ExecContext(ctx, query, outbox.StatusPending, time.Now(), time.Now(), id, outbox.StatusSent)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// outboxEmails runs an outbox query and scans the rows
+func (m *postgresDBRepo) outboxEmails(ctx context.Context, query string, args ...interface{}) ([]models.OutboxEmail, error) {
+	var emails []models.OutboxEmail
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return emails, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.OutboxEmail
+		var sentAt sql.NullTime
+		err := rows.Scan(
+			&e.ID,
+			&e.To,
+			&e.From,
+			&e.FromName,
+			&e.ReplyTo,
+			&e.Subject,
+			&e.Content,
+			&e.Text,
+			&e.Template,
+			&e.Status,
+			&e.Attempts,
+			&e.LastError,
+			&e.NextAttemptAt,
+			&sentAt,
+			&e.CreatedAt,
+			&e.UpdatedAt,
+		)
+		if err != nil {
+			return emails, err
+		}
+		e.SentAt = sentAt.Time
+		emails = append(emails, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return emails, err
+	}
+
+	return emails, nil
+}
+
+// AllSettings returns the saved settings by key
+func (m *postgresDBRepo) AllSettings(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	values := make(map[string]string)
+
+	rows, err := m.DB.QueryContext(ctx, `select key, value from settings`)
+	if err != nil {
+		return values, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key, value string
+		err := rows.Scan(&key, &value)
+		if err != nil {
+			return values, err
+		}
+		values[key] = value
+	}
+
+	if err = rows.Err(); err != nil {
+		return values, err
+	}
+
+	return values, nil
+}
+
+// UpdateSettings saves values by key, adding the keys that don't exist yet. Either every value is saved or none
+func (m *postgresDBRepo) UpdateSettings(ctx context.Context, values map[string]string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `
+		insert into settings (key, value, created_at, updated_at) values ($1, $2, $3, $3)
+		on conflict (key) do update set value = excluded.value, updated_at = excluded.updated_at
+	`
+
+	return m.withTx(ctx, func(tx *postgresDBRepo) error {
+		for key, value := range values {
+			_, err := tx.DB.ExecContext(ctx, query, key, value, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,322 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aidisapp/musiqcity_v2/internal/models"
+	"github.com/aidisapp/musiqcity_v2/internal/repository"
+	_ "github.com/lib/pq"
+)
+
+// testPostgres opens the database in DBURI, and skips the test when it isn't set
+func testPostgres(t *testing.T) *sql.DB {
+	dbURI := os.Getenv("DBURI")
+	if dbURI == "" {
+		t.Skip("DBURI is not set")
+	}
+
+	db, err := sql.Open("postgres", dbURI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := db.PingContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// fizzStatements returns the statements of a migration in the migrations folder. Only the sql and
+// drop_table commands used by the migrations tested here are understood
+func fizzStatements(t *testing.T, name string) []string {
+	content, err := os.ReadFile(filepath.Join("..", "..", "..", "migrations", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var statements []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "sql(") && strings.HasSuffix(line, ")"):
+			statement, err := strconv.Unquote(strings.TrimSuffix(strings.TrimPrefix(line, "sql("), ")"))
+			if err != nil {
+				t.Fatalf("%s: %v", line, err)
+			}
+			statements = append(statements, statement)
+		case strings.HasPrefix(line, "drop_table(") && strings.HasSuffix(line, ")"):
+			table, err := strconv.Unquote(strings.TrimSuffix(strings.TrimPrefix(line, "drop_table("), ")"))
+			if err != nil {
+				t.Fatalf("%s: %v", line, err)
+			}
+			statements = append(statements, "drop table "+table)
+		default:
+			t.Fatalf("%s: unknown fizz command", line)
+		}
+	}
+
+	return statements
+}
+
+// overlapTestTx starts a transaction that is rolled back when the test ends, with restriction tables of its
+// own in a scratch schema
+func overlapTestTx(t *testing.T, db *sql.DB) *sql.Tx {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+
+	for _, statement := range []string{
+		"create schema overlap_test",
+		"set local search_path to overlap_test, public",
+		`create table room_restrictions (id serial primary key, start_date date not null, end_date date not null,
+			room_id integer not null, reservation_id integer, restriction_id integer not null, reason varchar(255) not null default '',
+			created_at timestamp not null default now(), updated_at timestamp not null default now())`,
+		`create table artist_restrictions (id serial primary key, start_date date not null, end_date date not null,
+			artist_id integer not null, booking_id integer, restriction_id integer not null, reason varchar(255) not null default '',
+			created_at timestamp not null default now(), updated_at timestamp not null default now())`,
+	} {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return tx
+}
+
+// restrictionRow is what the overlap migration test compares of a restriction
+type restrictionRow struct {
+	ID        int
+	StartDate string
+	EndDate   string
+	Booked    bool
+	Reason    string
+}
+
+// restrictionRows returns the rows of a restrictions table, by start date
+func restrictionRows(t *testing.T, tx *sql.Tx, table, bookedColumn string) []restrictionRow {
+	rows, err := tx.QueryContext(ctx, `select id, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD'), `+
+		bookedColumn+` is not null, reason from `+table+` order by start_date, id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var restrictions []restrictionRow
+	for rows.Next() {
+		var r restrictionRow
+		if err := rows.Scan(&r.ID, &r.StartDate, &r.EndDate, &r.Booked, &r.Reason); err != nil {
+			t.Fatal(err)
+		}
+		restrictions = append(restrictions, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	return restrictions
+}
+
+// withoutIDs drops the ids, which the migration gives to the blocks it splits
+func withoutIDs(rows []restrictionRow) []restrictionRow {
+	var out []restrictionRow
+	for _, r := range rows {
+		r.ID = 0
+		out = append(out, r)
+	}
+	return out
+}
+
+func TestNoOverlapMigration(t *testing.T) {
+	db := testPostgres(t)
+	tx := overlapTestTx(t, db)
+
+	for _, statement := range []string{
+		`insert into artist_restrictions (start_date, end_date, artist_id, booking_id, restriction_id, reason) values
+			('2050-01-10', '2050-01-12', 1, 1, 1, ''),
+			('2050-01-01', '2050-01-05', 1, null, 2, 'Tour'),
+			('2050-01-04', '2050-01-08', 1, null, 2, 'Rest'),
+			('2050-01-11', '2050-01-15', 1, null, 2, 'Studio'),
+			('2050-02-01', '2050-02-02', 1, null, 2, 'Away'),
+			('2050-01-01', '2050-01-05', 2, null, 2, '')`,
+		`insert into room_restrictions (start_date, end_date, room_id, reservation_id, restriction_id, reason) values
+			('2050-01-10', '2050-01-12', 1, 1, 1, ''),
+			('2050-01-09', '2050-01-13', 1, null, 2, 'Repairs')`,
+	} {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	artistsBefore := restrictionRows(t, tx, "artist_restrictions", "booking_id")
+	roomsBefore := restrictionRows(t, tx, "room_restrictions", "reservation_id")
+
+	for _, statement := range fizzStatements(t, "20261016131502_add_no_overlap_constraints_to_restrictions.up.fizz") {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+
+	// overlapping blocks are merged, and blocks are trimmed to the days no booking holds
+	wantArtists := []restrictionRow{
+		{StartDate: "2050-01-01", EndDate: "2050-01-08", Reason: "Rest; Tour"},
+		{StartDate: "2050-01-01", EndDate: "2050-01-05", Reason: ""},
+		{StartDate: "2050-01-10", EndDate: "2050-01-12", Booked: true, Reason: ""},
+		{StartDate: "2050-01-13", EndDate: "2050-01-15", Reason: "Studio"},
+		{StartDate: "2050-02-01", EndDate: "2050-02-02", Reason: "Away"},
+	}
+	if got := withoutIDs(restrictionRows(t, tx, "artist_restrictions", "booking_id")); !reflect.DeepEqual(got, wantArtists) {
+		t.Errorf("artist restrictions after the migration:\n got %+v\nwant %+v", got, wantArtists)
+	}
+
+	wantRooms := []restrictionRow{
+		{StartDate: "2050-01-09", EndDate: "2050-01-09", Reason: "Repairs"},
+		{StartDate: "2050-01-10", EndDate: "2050-01-12", Booked: true, Reason: ""},
+		{StartDate: "2050-01-13", EndDate: "2050-01-13", Reason: "Repairs"},
+	}
+	if got := withoutIDs(restrictionRows(t, tx, "room_restrictions", "reservation_id")); !reflect.DeepEqual(got, wantRooms) {
+		t.Errorf("room restrictions after the migration:\n got %+v\nwant %+v", got, wantRooms)
+	}
+
+	// rolling back puts the original blocks back
+	for _, statement := range fizzStatements(t, "20261016131502_add_no_overlap_constraints_to_restrictions.down.fizz") {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+
+	if got := restrictionRows(t, tx, "artist_restrictions", "booking_id"); !reflect.DeepEqual(got, artistsBefore) {
+		t.Errorf("artist restrictions after rolling back:\n got %+v\nwant %+v", got, artistsBefore)
+	}
+	if got := restrictionRows(t, tx, "room_restrictions", "reservation_id"); !reflect.DeepEqual(got, roomsBefore) {
+		t.Errorf("room restrictions after rolling back:\n got %+v\nwant %+v", got, roomsBefore)
+	}
+}
+
+func TestNoOverlapMigrationBookingClash(t *testing.T) {
+	db := testPostgres(t)
+	tx := overlapTestTx(t, db)
+
+	_, err := tx.ExecContext(ctx, `insert into artist_restrictions (start_date, end_date, artist_id, booking_id, restriction_id) values
+		('2050-01-10', '2050-01-12', 1, 1, 1), ('2050-01-12', '2050-01-14', 1, 2, 1)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, statement := range fizzStatements(t, "20261016131502_add_no_overlap_constraints_to_restrictions.up.fizz") {
+		_, err = tx.ExecContext(ctx, statement)
+		if err != nil {
+			break
+		}
+	}
+
+	if err == nil || !strings.Contains(err.Error(), "hold the same artist on the same days") {
+		t.Errorf("expected the migration to stop at the double booking but got %v", err)
+	}
+}
+
+// testRoomID returns a room of the database in DBURI, which needs the migrations run
+func testRoomID(t *testing.T, db *sql.DB) int {
+	var roomID int
+	err := db.QueryRowContext(ctx, "select id from rooms order by id limit 1").Scan(&roomID)
+	if errors.Is(err, sql.ErrNoRows) {
+		t.Skip("the database has no rooms")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return roomID
+}
+
+// countReservations returns how many reservations were made with email, and deletes them when the test ends
+func countReservations(t *testing.T, db *sql.DB, email string) int {
+	t.Cleanup(func() { db.ExecContext(ctx, "delete from reservations where email = $1", email) })
+
+	var count int
+	if err := db.QueryRowContext(ctx, "select count(*) from reservations where email = $1", email).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+
+	return count
+}
+
+func TestPostgresWithTxRestrictionFails(t *testing.T) {
+	db := testPostgres(t)
+	repo := NewPostgresRepo(db, nil)
+	roomID := testRoomID(t, db)
+
+	err := repo.WithTx(ctx, func(tx repository.DatabaseRepo) error {
+		id, err := tx.InsertReservation(ctx, models.Reservation{RoomID: roomID, Email: "orphan@example.com", StartDate: date("2090-03-01"), EndDate: date("2090-03-03")})
+		if err != nil {
+			return err
+		}
+
+		// there is no restriction type -1, so the foreign key turns the insert down
+		return tx.InsertRoomRestriction(ctx, models.RoomRestriction{RoomID: roomID, ReservationID: id, RestrictionID: -1,
+			StartDate: date("2090-03-01"), EndDate: date("2090-03-03")})
+	})
+	if err == nil {
+		t.Fatal("expected the restriction insert to fail")
+	}
+
+	if count := countReservations(t, db, "orphan@example.com"); count != 0 {
+		t.Errorf("the reservation was kept without its restriction, found %d", count)
+	}
+}
+
+func TestPostgresCancelledContext(t *testing.T) {
+	db := testPostgres(t)
+	repo := NewPostgresRepo(db, nil)
+	roomID := testRoomID(t, db)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	called := false
+	err := repo.WithTx(cancelled, func(tx repository.DatabaseRepo) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || called {
+		t.Errorf("expected the transaction not to start but got %v, called %v", err, called)
+	}
+
+	if _, err := repo.AllReservations(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a query to be aborted but got %v", err)
+	}
+
+	// cancelling half way through a transaction aborts the next step and throws away the earlier ones
+	running, cancel := context.WithCancel(ctx)
+	err = repo.WithTx(running, func(tx repository.DatabaseRepo) error {
+		id, err := tx.InsertReservation(running, models.Reservation{RoomID: roomID, Email: "cancelled@example.com", StartDate: date("2090-03-01"), EndDate: date("2090-03-03")})
+		if err != nil {
+			return err
+		}
+
+		cancel()
+
+		return tx.InsertRoomRestriction(running, models.RoomRestriction{RoomID: roomID, ReservationID: id, RestrictionID: 1,
+			StartDate: date("2090-03-01"), EndDate: date("2090-03-03")})
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the transaction to be aborted but got %v", err)
+	}
+
+	if count := countReservations(t, db, "cancelled@example.com"); count != 0 {
+		t.Errorf("the reservation of the cancelled transaction was kept, found %d", count)
+	}
+}
@@ -0,0 +1,37 @@
+package dbrepo
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Markers the search highlights matched words with before they are turned into <mark> tags
+const (
+	highlightStart = "«"
+	highlightStop  = "»"
+)
+
+// searchWords splits a search box query into lower case words, dropping everything but letters and digits
+func searchWords(q string) []string {
+	return strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchQuery returns the SQL for the tsquery of the words in the text[] parameter param. Every word has to
+// match the start of a word of the artist, so results show up while the user is still typing. Names, genres
+// and the city are stored unstemmed, so a word matches them as typed, and matches the description once it is
+// stemmed the way the description was. Stop words only match as typed
+func searchQuery(param string) string {
+	return `to_tsquery('simple', (
+		select string_agg('(' || w || ':*' || coalesce(' | ' || (ts_lexize('english_stem', w))[1] || ':*C', '') || ')', ' & ')
+		from unnest(` + param + `::text[]) w))`
+}
+
+// markedHTML escapes text with highlight markers and turns the markers into <mark> tags
+func markedHTML(text string) string {
+	text = html.EscapeString(text)
+	text = strings.ReplaceAll(text, highlightStart, "<mark>")
+	return strings.ReplaceAll(text, highlightStop, "</mark>")
+}
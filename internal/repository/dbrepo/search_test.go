@@ -0,0 +1,32 @@
+package dbrepo
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSearchWords(t *testing.T) {
+	var tests = []struct {
+		q        string
+		expected []string
+	}{
+		{"Tony", []string{"tony"}},
+		{"  afro-beat  DJ ", []string{"afro", "beat", "dj"}},
+		{"brass & (horns)!", []string{"brass", "horns"}},
+		{"':* | !", []string{}},
+	}
+
+	for _, e := range tests {
+		if got := searchWords(e.q); !slices.Equal(got, e.expected) {
+			t.Errorf("%q: expected %q but got %q", e.q, e.expected, got)
+		}
+	}
+}
+
+func TestMarkedHTML(t *testing.T) {
+	got := markedHTML("The «Lagos» <Horns> & «Co»")
+	expected := "The <mark>Lagos</mark> &lt;Horns&gt; &amp; <mark>Co</mark>"
+	if got != expected {
+		t.Errorf("expected %q but got %q", expected, got)
+	}
+}
@@ -1,35 +1,42 @@
 package dbrepo
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"time"
 
 	"github.com/aidisapp/musiqcity_v2/internal/models"
+	"github.com/aidisapp/musiqcity_v2/internal/repository"
+	"github.com/aidisapp/musiqcity_v2/internal/roles"
+	"github.com/aidisapp/musiqcity_v2/internal/status"
 )
 
-func (repo *testDBRepo) AllUsers() bool {
-	return true
+// WithTx runs fn with the test repo, there is no transaction to share
+func (repo *testDBRepo) WithTx(ctx context.Context, fn func(repo repository.DatabaseRepo) error) error {
+	return fn(repo)
+}
+
+// AllUsers returns a page of users
+func (repo *testDBRepo) AllUsers(ctx context.Context, search string, limit, offset int) ([]models.User, int, error) {
+	var users []models.User
+	return users, 0, nil
 }
 
 // Check if a user exists in the database via email
-func (m *testDBRepo) CheckIfUserEmailExist(email string) (bool, error) {
+func (m *testDBRepo) CheckIfUserEmailExist(ctx context.Context, email string) (bool, error) {
 	return true, nil
 }
 
 // Inserts a user into the database
-func (m *testDBRepo) InsertUser(user models.User) (int, error) {
+func (m *testDBRepo) InsertUser(ctx context.Context, user models.User) (int, error) {
 	var newUserID int
 
 	return newUserID, nil
 }
 
-// UpdateUserAccessLevel updates a user access level in the database
-func (m *testDBRepo) UpdateUserAccessLevel(user models.User) error {
-	return nil
-}
-
 // Inserts a reservation into the database
-func (repo *testDBRepo) InsertReservation(res models.Reservation) (int, error) {
+func (repo *testDBRepo) InsertReservation(ctx context.Context, res models.Reservation) (int, error) {
 	// Fail test if the room_id == 2
 	if res.RoomID == 2 {
 		return 0, errors.New("failed to insert reservation")
@@ -38,194 +45,533 @@ func (repo *testDBRepo) InsertReservation(res models.Reservation) (int, error) {
 }
 
 // InsertRoomRestriction inserts a room restriction into the database
-func (repo *testDBRepo) InsertRoomRestriction(res models.RoomRestriction) error {
+func (repo *testDBRepo) InsertRoomRestriction(ctx context.Context, res models.RoomRestriction) error {
+	// Fail test if the room_id == 1000
+	if res.RoomID == 1000 {
+		return errors.New("failed to insert room restriction")
+	}
 	return nil
 }
 
 // SearchAvailabilityByDatesByRoomID returns true if availability exists for roomID, and false if no availability
-func (repo *testDBRepo) SearchAvailabilityByDatesByRoomID(start, end time.Time, roomID int) (bool, error) {
-	return false, nil
+func (repo *testDBRepo) SearchAvailabilityByDatesByRoomID(ctx context.Context, start, end time.Time, roomID int) (bool, error) {
+	// Fail the query if the start date is 2060-01-01
+	if start.Equal(time.Date(2060, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		return false, errors.New("failed to search availability")
+	}
+
+	// Nothing is available after 2049-12-31
+	return !start.After(time.Date(2049, 12, 31, 0, 0, 0, 0, time.UTC)), nil
 }
 
 // SearchAvailabilityForAllRooms returns a slice of available rooms, if any, for given date range
-func (repo *testDBRepo) SearchAvailabilityForAllRooms(start, end time.Time) ([]models.Room, error) {
+func (repo *testDBRepo) SearchAvailabilityForAllRooms(ctx context.Context, start, end time.Time) ([]models.Room, error) {
 	var rooms []models.Room
 
 	return rooms, nil
 }
 
 // GetRoomByID gets a room by id
-func (repo *testDBRepo) GetRoomByID(id int) (models.Room, error) {
+func (repo *testDBRepo) GetRoomByID(ctx context.Context, id int) (models.Room, error) {
 	var room models.Room
 
 	return room, nil
 }
 
 // GetUserByID returns a user by id
-func (repo *testDBRepo) GetUserByID(id int) (models.User, error) {
+func (repo *testDBRepo) GetUserByID(ctx context.Context, id int) (models.User, error) {
 	var user models.User
 
 	return user, nil
 }
 
 // UpdateUser updates a user in the database
-func (repo *testDBRepo) UpdateUser(user models.User) error {
+func (repo *testDBRepo) UpdateUser(ctx context.Context, user models.User) error {
+	return nil
+}
+
+// UpdateUserRole changes the role of a user
+func (repo *testDBRepo) UpdateUserRole(ctx context.Context, id int, role string) error {
+	return nil
+}
+
+// UpdatePassword sets the password of a user
+func (repo *testDBRepo) UpdatePassword(ctx context.Context, id int, password string) error {
+	return nil
+}
+
+// SetUserDisabled disables or enables the account of a user
+func (repo *testDBRepo) SetUserDisabled(ctx context.Context, id int, disabled bool) error {
+	return nil
+}
+
+// LockUser stops a user logging in until the given time
+func (repo *testDBRepo) LockUser(ctx context.Context, id int, until time.Time) error {
+	return nil
+}
+
+// GetUserByEmail returns a user by email
+func (repo *testDBRepo) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	user := models.User{ID: 1, Email: email}
+
+	return user, nil
+}
+
+// InsertPasswordReset stores a password reset
+func (repo *testDBRepo) InsertPasswordReset(ctx context.Context, reset models.PasswordReset) error {
+	return nil
+}
+
+// CountPasswordResets returns how many resets were requested for userID since the given time
+func (repo *testDBRepo) CountPasswordResets(ctx context.Context, userID int, since time.Time) (int, error) {
+	return 0, nil
+}
+
+// GetPasswordReset returns the reset with tokenHash
+func (repo *testDBRepo) GetPasswordReset(ctx context.Context, tokenHash string) (models.PasswordReset, error) {
+	reset := models.PasswordReset{UserID: 1, TokenHash: tokenHash, ExpiresAt: time.Now().Add(time.Hour)}
+
+	return reset, nil
+}
+
+// ResetPassword sets the password of the user the reset with tokenHash belongs to
+func (repo *testDBRepo) ResetPassword(ctx context.Context, tokenHash, password string) (int, error) {
+	return 1, nil
+}
+
+// InsertEmailVerification stores a verification link
+func (repo *testDBRepo) InsertEmailVerification(ctx context.Context, verification models.EmailVerification) error {
 	return nil
 }
 
-// Authenticate authenticates a user
-func (repo *testDBRepo) Authenticate(email, testPassword string) (int, string, int, error) {
-	return 1, "", 0, nil
+// CountEmailVerifications returns how many verification links were sent to userID since the given time
+func (repo *testDBRepo) CountEmailVerifications(ctx context.Context, userID int, since time.Time) (int, error) {
+	return 0, nil
+}
+
+// VerifyEmail verifies the user the link with tokenHash belongs to
+func (repo *testDBRepo) VerifyEmail(ctx context.Context, tokenHash string) (int, error) {
+	return 1, nil
+}
+
+// Authenticate authenticates a user, who has verified their email
+func (repo *testDBRepo) Authenticate(ctx context.Context, email, testPassword string) (models.User, error) {
+	// Fail test if the email is jack@nimble.com
+	if email == "jack@nimble.com" {
+		return models.User{}, errors.New("incorrect password")
+	}
+	return models.User{ID: 1, Role: roles.Customer, Verified: true}, nil
 }
 
 // AllReservations returns a slice of all reservations
-func (repo *testDBRepo) AllReservations() ([]models.Reservation, error) {
+func (repo *testDBRepo) AllReservations(ctx context.Context) ([]models.Reservation, error) {
 	var reservations []models.Reservation
 
 	return reservations, nil
 }
 
 // AllNewReservations returns a slice of all reservations
-func (m *testDBRepo) AllNewReservations() ([]models.Reservation, error) {
+func (m *testDBRepo) AllNewReservations(ctx context.Context) ([]models.Reservation, error) {
 	var reservations []models.Reservation
 
 	return reservations, nil
 }
 
 // GetReservationByID returns one reservation by ID
-func (m *testDBRepo) GetReservationByID(id int) (models.Reservation, error) {
-	var reservation models.Reservation
+func (m *testDBRepo) GetReservationByID(ctx context.Context, id int) (models.Reservation, error) {
+	reservation := models.Reservation{ID: id, Status: status.Pending, StartDate: time.Now().AddDate(0, 1, 0), EndDate: time.Now().AddDate(0, 1, 2)}
 
 	return reservation, nil
 }
 
 // UpdateReservation updates a reservation in the database
-func (m *testDBRepo) UpdateReservation(u models.Reservation) error {
+func (m *testDBRepo) UpdateReservation(ctx context.Context, u models.Reservation) error {
 	return nil
 }
 
 // DeleteReservation deletes one reservation by id
-func (m *testDBRepo) DeleteReservation(id int) error {
+func (m *testDBRepo) DeleteReservation(ctx context.Context, id int) error {
 	return nil
 }
 
-// UpdateProcessedForReservation updates processed for a reservation by id
-func (m *testDBRepo) UpdateProcessedForReservation(id, processed int) error {
+// UpdateReservationStatus moves a reservation from one status to another
+func (m *testDBRepo) UpdateReservationStatus(ctx context.Context, id int, from, to string, userID int) error {
 	return nil
 }
 
+// RescheduleReservation moves a reservation to new dates
+func (m *testDBRepo) RescheduleReservation(ctx context.Context, id int, startDate, endDate time.Time) (bool, error) {
+	return true, nil
+}
+
+// GetReservationStatusHistory returns the status changes of a reservation
+func (m *testDBRepo) GetReservationStatusHistory(ctx context.Context, id int) ([]models.StatusChange, error) {
+	var changes []models.StatusChange
+
+	return changes, nil
+}
+
 // Get all rooms
-func (m *testDBRepo) AllRooms() ([]models.Room, error) {
+func (m *testDBRepo) AllRooms(ctx context.Context) ([]models.Room, error) {
 	var rooms []models.Room
 
 	return rooms, nil
 }
 
 // Get the restrictions for a room
-func (m *testDBRepo) GetRestrictionsForCurrentRoom(roomID int, start, end time.Time) ([]models.RoomRestriction, error) {
+func (m *testDBRepo) GetRestrictionsForCurrentRoom(ctx context.Context, roomID int, start, end time.Time) ([]models.RoomRestriction, error) {
 	var restrictions []models.RoomRestriction
 
 	return restrictions, nil
 }
 
 // InsertBlockForRoom inserts a room restriction
-func (m *testDBRepo) InsertBlockForRoom(id int, startDate time.Time) error {
+func (m *testDBRepo) InsertBlockForRoom(ctx context.Context, id int, startDate, endDate time.Time, reason string) error {
 	return nil
 }
 
 // DeleteBlockByID deletes a room restriction
-func (m *testDBRepo) DeleteBlockByID(id int) error {
+func (m *testDBRepo) DeleteBlockByID(ctx context.Context, id int) error {
 	return nil
 }
 
 // UpdateRoom updates a room in the database
-func (m *testDBRepo) UpdateRoom(room models.Room) error {
+func (m *testDBRepo) UpdateRoom(ctx context.Context, room models.Room) error {
 	return nil
 }
 
 // Inserts a room into the database
-func (repo *testDBRepo) InsertRoom(room models.Room) error {
+func (repo *testDBRepo) InsertRoom(ctx context.Context, room models.Room) error {
 	return nil
 }
 
 // DeleteRoom deletes a room
-func (m *testDBRepo) DeleteRoom(id int) error {
+func (m *testDBRepo) DeleteRoom(ctx context.Context, id int) error {
 	return nil
 }
 
 // InsertTodoList inserts a new todo list into the database
-func (repo *testDBRepo) InsertTodoList(todo models.TodoList) error {
+func (repo *testDBRepo) InsertTodoList(ctx context.Context, todo models.TodoList) error {
 	return nil
 }
 
 // GetTodoListByUserID gets all todo for a user by user_id
-func (repo *testDBRepo) GetTodoListByUserID(id int) ([]models.TodoList, error) {
+func (repo *testDBRepo) GetTodoListByUserID(ctx context.Context, id int) ([]models.TodoList, error) {
 	var todoList []models.TodoList
 	return todoList, nil
 }
 
 // DeleteTodo deletes a todo
-func (m *testDBRepo) DeleteTodo(id int) error {
+func (m *testDBRepo) DeleteTodo(ctx context.Context, id int) error {
 	return nil
 }
 
 //  --------Recent---------- //
 
-func (m *testDBRepo) AllArtists() ([]models.Artist, error) {
+// SearchArtists returns the approved artists matching filter
+func (m *testDBRepo) SearchArtists(ctx context.Context, filter repository.ArtistFilter) ([]models.Artist, int, error) {
+	var artists []models.Artist
+	return artists, 0, nil
+}
+
+// SuggestArtists returns the approved artists matching q for the search box
+func (m *testDBRepo) SuggestArtists(ctx context.Context, q string, limit int) ([]models.ArtistSuggestion, error) {
+	var suggestions []models.ArtistSuggestion
+	return suggestions, nil
+}
+
+// AllArtistsByStatus returns the artists whose listing is in status
+func (m *testDBRepo) AllArtistsByStatus(ctx context.Context, status string) ([]models.Artist, error) {
+	var artists []models.Artist
+	return artists, nil
+}
+
+// AllArtistsByUserID returns the artists owned by a user
+func (m *testDBRepo) AllArtistsByUserID(ctx context.Context, userID int) ([]models.Artist, error) {
 	var artists []models.Artist
 	return artists, nil
 }
 
 // Inserts a new Artist into the database
-func (repo *testDBRepo) CreateArtist(artist models.Artist) error {
-	return nil
+func (repo *testDBRepo) CreateArtist(ctx context.Context, artist models.Artist) (int, error) {
+	return 1, nil
 }
 
-func (repo *testDBRepo) GetArtistByID(id int) (models.Artist, error) {
+func (repo *testDBRepo) GetArtistByID(ctx context.Context, id int) (models.Artist, error) {
 	var artist models.Artist
 
+	// There are no approved artists above 1000
+	if id > 1000 {
+		return artist, sql.ErrNoRows
+	}
+
+	return artist, nil
+}
+
+func (m *testDBRepo) UpdateArtist(ctx context.Context, artist models.Artist) error {
+	return nil
+}
+
+// GetArtistByIDAnyStatus returns an artist whatever the status of its listing
+func (m *testDBRepo) GetArtistByIDAnyStatus(ctx context.Context, id int) (models.Artist, error) {
+	var artist models.Artist
 	return artist, nil
 }
 
-func (m *testDBRepo) UpdateArtist(artist models.Artist) error {
+// UpdateArtistStatus moves the listing of an artist to a new status
+func (m *testDBRepo) UpdateArtistStatus(ctx context.Context, id int, from, to, reason string) error {
+	return nil
+}
+
+// AllGenres returns every genre
+func (m *testDBRepo) AllGenres(ctx context.Context) ([]models.Genre, error) {
+	var genres []models.Genre
+	return genres, nil
+}
+
+// GetGenreByID returns a genre by id
+func (m *testDBRepo) GetGenreByID(ctx context.Context, id int) (models.Genre, error) {
+	var genre models.Genre
+	return genre, nil
+}
+
+// GetGenreBySlug returns the genre with slug
+func (m *testDBRepo) GetGenreBySlug(ctx context.Context, slug string) (models.Genre, error) {
+	var genre models.Genre
+	return genre, nil
+}
+
+// CreateGenre inserts a genre
+func (m *testDBRepo) CreateGenre(ctx context.Context, genre models.Genre) (int, error) {
+	return 1, nil
+}
+
+// UpdateGenre updates a genre
+func (m *testDBRepo) UpdateGenre(ctx context.Context, genre models.Genre) error {
+	return nil
+}
+
+// MergeGenre merges a genre into another one
+func (m *testDBRepo) MergeGenre(ctx context.Context, id, into int) error {
+	return nil
+}
+
+// DeleteGenre deletes a genre
+func (m *testDBRepo) DeleteGenre(ctx context.Context, id int) error {
 	return nil
 }
 
 // AllBookingss returns a slice of all bookings
-func (repo *testDBRepo) AllBookings() ([]models.Bookings, error) {
+func (repo *testDBRepo) AllBookings(ctx context.Context) ([]models.Bookings, error) {
 	var bookings []models.Bookings
 	return bookings, nil
 }
 
 // AllNewBookings returns a slice of all Bookings
-func (m *testDBRepo) AllNewBookings() ([]models.Bookings, error) {
+func (m *testDBRepo) AllNewBookings(ctx context.Context) ([]models.Bookings, error) {
 	var bookings []models.Bookings
 	return bookings, nil
 }
 
+// AllBookingsByStatus returns a slice of all bookings in the given status
+func (m *testDBRepo) AllBookingsByStatus(ctx context.Context, status string) ([]models.Bookings, error) {
+	var bookings []models.Bookings
+	return bookings, nil
+}
+
+// AllBookingsByArtistID returns the bookings of an artist
+func (m *testDBRepo) AllBookingsByArtistID(ctx context.Context, artistID int) ([]models.Bookings, error) {
+	var bookings []models.Bookings
+	return bookings, nil
+}
+
+// AllBookingsByUserID returns the bookings of a customer account
+func (m *testDBRepo) AllBookingsByUserID(ctx context.Context, userID int) ([]models.Bookings, error) {
+	var bookings []models.Bookings
+	return bookings, nil
+}
+
+// AllReservationsByUserID returns the room reservations of a customer account
+func (m *testDBRepo) AllReservationsByUserID(ctx context.Context, userID int) ([]models.Reservation, error) {
+	var reservations []models.Reservation
+	return reservations, nil
+}
+
+// ClaimBookings links the guest bookings and room reservations made with email to a customer account
+func (m *testDBRepo) ClaimBookings(ctx context.Context, userID int, email string) (int, error) {
+	return 0, nil
+}
+
+// InsertBooking inserts a booking and its artist restriction
+func (repo *testDBRepo) InsertBooking(ctx context.Context, booking models.Bookings) (int, error) {
+	// Fail test if the artist_id == 2
+	if booking.ArtistID == 2 {
+		return 0, errors.New("failed to insert booking")
+	}
+
+	// Simulate someone else booking the dates first if the artist_id == 3
+	if booking.ArtistID == 3 {
+		return 0, repository.ErrUnavailable
+	}
+	return 1, nil
+}
+
+// SearchAvailabilityByDatesByArtistID returns true if availability exists for artistID, and false if no availability
+func (repo *testDBRepo) SearchAvailabilityByDatesByArtistID(ctx context.Context, start, end time.Time, artistID int) (bool, error) {
+	// Fail the query if the start date is 2060-01-01
+	if start.Equal(time.Date(2060, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		return false, errors.New("failed to search availability")
+	}
+
+	return true, nil
+}
+
+// GetRestrictionsForCurrentArtist returns restrictions for an artist by date range
+func (m *testDBRepo) GetRestrictionsForCurrentArtist(ctx context.Context, artistID int, start, end time.Time) ([]models.ArtistRestriction, error) {
+	var restrictions []models.ArtistRestriction
+
+	return restrictions, nil
+}
+
+// GetBookingByID returns one booking by ID
+func (m *testDBRepo) GetBookingByID(ctx context.Context, id int) (models.Bookings, error) {
+	booking := models.Bookings{ID: id, Status: status.Pending, StartDate: time.Now().AddDate(0, 1, 0), EndDate: time.Now().AddDate(0, 1, 2)}
+	if id > 2 {
+		return booking, errors.New("booking not found")
+	}
+
+	// booking 2 starts tomorrow, inside the cancellation window
+	if id == 2 {
+		booking.StartDate = time.Now().AddDate(0, 0, 1)
+		booking.EndDate = time.Now().AddDate(0, 0, 2)
+	}
+
+	return booking, nil
+}
+
+// UpdateBooking updates a booking in the database
+func (m *testDBRepo) UpdateBooking(ctx context.Context, booking models.Bookings) error {
+	return nil
+}
+
+// UpdateBookingStatus moves a booking from one status to another
+func (m *testDBRepo) UpdateBookingStatus(ctx context.Context, id int, from, to string, userID int) error {
+	// Fail test if the booking id == 2
+	if id == 2 {
+		return errors.New("failed to update booking status")
+	}
+	return nil
+}
+
+// RescheduleBooking moves a booking to new dates. Dates starting in 2060 are treated as taken
+func (m *testDBRepo) RescheduleBooking(ctx context.Context, id int, startDate, endDate time.Time) (bool, error) {
+	if startDate.Year() == 2060 {
+		return false, nil
+	}
+	return true, nil
+}
+
+// GetBookingStatusHistory returns the status changes of a booking
+func (m *testDBRepo) GetBookingStatusHistory(ctx context.Context, id int) ([]models.StatusChange, error) {
+	var changes []models.StatusChange
+
+	return changes, nil
+}
+
+// InsertBlockForArtist inserts an owner block for an artist
+func (m *testDBRepo) InsertBlockForArtist(ctx context.Context, id int, startDate, endDate time.Time, reason string) error {
+	return nil
+}
+
+// DeleteArtistBlockByID deletes an artist restriction
+func (m *testDBRepo) DeleteArtistBlockByID(ctx context.Context, id int) error {
+	return nil
+}
+
 // Get all Booking Options
-func (m *testDBRepo) AllBookingOptions() ([]models.BookingOptions, error) {
+func (m *testDBRepo) AllBookingOptions(ctx context.Context) ([]models.BookingOptions, error) {
 	var options []models.BookingOptions
 	return options, nil
 }
 
 // Get all Booking Options
-func (m *testDBRepo) AllArtistBookingOptions(id int) ([]models.BookingOptions, error) {
+func (m *testDBRepo) AllArtistBookingOptions(ctx context.Context, id int) ([]models.BookingOptions, error) {
 	var options []models.BookingOptions
+	options = append(options, models.BookingOptions{
+		ID:       1,
+		Title:    "Wedding Performance",
+		Price:    "500",
+		ArtistID: id,
+	})
 	return options, nil
 }
 
 // Inserts a new Boking Option into the database
-func (repo *testDBRepo) CreateBookingOption(option models.BookingOptions) error {
+func (repo *testDBRepo) CreateBookingOption(ctx context.Context, option models.BookingOptions) error {
 	return nil
 }
 
 // Get a booking option by id
-func (repo *testDBRepo) GetBookingOptionByID(id int) (models.BookingOptions, error) {
+func (repo *testDBRepo) GetBookingOptionByID(ctx context.Context, id int) (models.BookingOptions, error) {
 	var option models.BookingOptions
 	return option, nil
 }
 
-func (m *testDBRepo) UpdateBookingOption(option models.BookingOptions) error {
+func (m *testDBRepo) UpdateBookingOption(ctx context.Context, option models.BookingOptions) error {
+	return nil
+}
+
+// EnqueueEmail adds a message to the outbox
+func (m *testDBRepo) EnqueueEmail(ctx context.Context, message models.MailData) error {
+	return nil
+}
+
+// ClaimDueEmails takes up to limit due emails
+func (m *testDBRepo) ClaimDueEmails(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEmail, error) {
+	var emails []models.OutboxEmail
+
+	return emails, nil
+}
+
+// MarkEmailSent records that an email was delivered
+func (m *testDBRepo) MarkEmailSent(ctx context.Context, id int) error {
+	return nil
+}
+
+// MarkEmailFailed records a failed attempt and when to try again
+func (m *testDBRepo) MarkEmailFailed(ctx context.Context, id int, lastError string, retryAt time.Time) error {
+	return nil
+}
+
+// MarkEmailDead stops retrying an email
+func (m *testDBRepo) MarkEmailDead(ctx context.Context, id int, lastError string) error {
+	return nil
+}
+
+// AllOutboxEmails returns the outbox emails
+func (m *testDBRepo) AllOutboxEmails(ctx context.Context, status string) ([]models.OutboxEmail, error) {
+	var emails []models.OutboxEmail
+
+	return emails, nil
+}
+
+// ResendEmail puts an email back in the queue, failing for id 2
+func (m *testDBRepo) ResendEmail(ctx context.Context, id int) error {
+	if id == 2 {
+		return errors.New("failed to resend email")
+	}
+	return nil
+}
+
+// AllSettings returns no saved settings
+func (m *testDBRepo) AllSettings(ctx context.Context) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+// UpdateSettings saves settings, failing when the sender name is "fail"
+func (m *testDBRepo) UpdateSettings(ctx context.Context, values map[string]string) error {
+	if values["sender_name"] == "fail" {
+		return errors.New("failed to update settings")
+	}
 	return nil
 }
@@ -1,59 +1,197 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"github.com/aidisapp/musiqcity_v2/internal/models"
 )
 
+// ErrUnavailable is returned when a write would overlap dates that are already reserved, booked or blocked
+var ErrUnavailable = errors.New("those dates are no longer available")
+
+// ErrInvalidResetToken is returned when a password reset token doesn't exist, has been used or has expired
+var ErrInvalidResetToken = errors.New("this reset link is invalid or has expired")
+
+// ErrInvalidVerificationToken is returned when an email verification token doesn't exist, has been used,
+// has been replaced by a newer one or has expired
+var ErrInvalidVerificationToken = errors.New("this verification link is invalid or has expired")
+
+// ErrGenreTaken is returned when the name or an alias of a genre is a spelling of another genre or alias
+var ErrGenreTaken = errors.New("that genre or alias already exists")
+
+// Orders SearchArtists can return artists in
+const (
+	SortName   = "name"
+	SortNewest = "newest"
+	SortPrice  = "price"
+	// SortRelevance puts the artists that match the query best first
+	SortRelevance = "relevance"
+)
+
+// ArtistFilter narrows down and orders the artists SearchArtists returns. Zero values don't filter
+type ArtistFilter struct {
+	// Query is matched against the name, genres, city and description. Every word has to match the start
+	// of a word of the artist
+	Query string
+	// Genre is the slug of a genre or of one of its aliases, and City is matched against the whole city
+	Genre string
+	City  string
+	// MinPrice and MaxPrice bound the price of the cheapest booking option. Artists without a priced
+	// option are left out when either is set
+	MinPrice int
+	MaxPrice int
+	// AvailableFrom and AvailableTo keep the artists with nothing booked or blocked between them
+	AvailableFrom time.Time
+	AvailableTo   time.Time
+	// Sort is SortName, SortNewest, SortPrice or SortRelevance, by name when empty
+	Sort string
+	// Limit is the most artists returned, all of them when 0
+	Limit  int
+	Offset int
+}
+
 type DatabaseRepo interface {
-	AllUsers() bool
-	CheckIfUserEmailExist(email string) (bool, error)
-	InsertUser(user models.User) (int, error)
-	UpdateUserAccessLevel(user models.User) error
-
-	InsertReservation(res models.Reservation) (int, error)
-	InsertRoomRestriction(res models.RoomRestriction) error
-	SearchAvailabilityByDatesByRoomID(start, end time.Time, roomID int) (bool, error)
-	SearchAvailabilityForAllRooms(start, end time.Time) ([]models.Room, error)
-	GetRoomByID(id int) (models.Room, error)
-
-	GetUserByID(id int) (models.User, error)
-	UpdateUser(user models.User) error
-	Authenticate(email, testPassword string) (int, string, int, error)
-
-	AllReservations() ([]models.Reservation, error)
-	AllNewReservations() ([]models.Reservation, error)
-
-	GetReservationByID(id int) (models.Reservation, error)
-	UpdateReservation(u models.Reservation) error
-	DeleteReservation(id int) error
-	UpdateProcessedForReservation(id, processed int) error
-	InsertBlockForRoom(id int, startDate time.Time) error
-	DeleteBlockByID(id int) error
-
-	AllRooms() ([]models.Room, error)
-	UpdateRoom(room models.Room) error
-	InsertRoom(room models.Room) error
-	DeleteRoom(id int) error
-
-	InsertTodoList(todo models.TodoList) error
-	GetTodoListByUserID(id int) ([]models.TodoList, error)
-	DeleteTodo(id int) error
-
-	GetRestrictionsForCurrentRoom(roomID int, start, end time.Time) ([]models.RoomRestriction, error)
-
-	AllArtists() ([]models.Artist, error)
-	CreateArtist(artist models.Artist) error
-	GetArtistByID(id int) (models.Artist, error)
-	UpdateArtist(artist models.Artist) error
-
-	AllBookings() ([]models.Bookings, error)
-	AllNewBookings() ([]models.Bookings, error)
-
-	AllBookingOptions() ([]models.BookingOptions, error)
-	AllArtistBookingOptions(id int) ([]models.BookingOptions, error)
-	CreateBookingOption(option models.BookingOptions) error
-	GetBookingOptionByID(id int) (models.BookingOptions, error)
-	UpdateBookingOption(option models.BookingOptions) error
+	// WithTx runs fn with a repository whose methods all share one database transaction.
+	// The transaction is committed when fn returns nil and rolled back otherwise
+	WithTx(ctx context.Context, fn func(repo DatabaseRepo) error) error
+
+	// AllUsers returns up to limit users whose name or email contains search, newest first, skipping the
+	// first offset of them. It also returns how many users match in all
+	AllUsers(ctx context.Context, search string, limit, offset int) ([]models.User, int, error)
+	CheckIfUserEmailExist(ctx context.Context, email string) (bool, error)
+	InsertUser(ctx context.Context, user models.User) (int, error)
+
+	InsertReservation(ctx context.Context, res models.Reservation) (int, error)
+	InsertRoomRestriction(ctx context.Context, res models.RoomRestriction) error
+	SearchAvailabilityByDatesByRoomID(ctx context.Context, start, end time.Time, roomID int) (bool, error)
+	SearchAvailabilityForAllRooms(ctx context.Context, start, end time.Time) ([]models.Room, error)
+	GetRoomByID(ctx context.Context, id int) (models.Room, error)
+
+	GetUserByID(ctx context.Context, id int) (models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (models.User, error)
+	UpdateUser(ctx context.Context, user models.User) error
+	UpdateUserRole(ctx context.Context, id int, role string) error
+	// UpdatePassword sets the password of user id, hashing it like InsertUser does
+	UpdatePassword(ctx context.Context, id int, password string) error
+	// SetUserDisabled disables or enables the account of user id
+	SetUserDisabled(ctx context.Context, id int, disabled bool) error
+	// LockUser stops user id logging in until the given time. The zero time unlocks the account
+	LockUser(ctx context.Context, id int, until time.Time) error
+	// Authenticate returns the user with email when testPassword is their password
+	Authenticate(ctx context.Context, email, testPassword string) (models.User, error)
+
+	InsertPasswordReset(ctx context.Context, reset models.PasswordReset) error
+	// CountPasswordResets returns how many resets were requested for userID since the given time
+	CountPasswordResets(ctx context.Context, userID int, since time.Time) (int, error)
+	// GetPasswordReset returns the reset with tokenHash, or ErrInvalidResetToken when it can't be used
+	GetPasswordReset(ctx context.Context, tokenHash string) (models.PasswordReset, error)
+	// ResetPassword sets the password of the user the reset with tokenHash belongs to and uses up all of
+	// that user's open resets. It returns the user's id, or ErrInvalidResetToken when the reset can't be used
+	ResetPassword(ctx context.Context, tokenHash, password string) (int, error)
+
+	// InsertEmailVerification stores a verification link and revokes the user's earlier ones, so only the newest works
+	InsertEmailVerification(ctx context.Context, verification models.EmailVerification) error
+	// CountEmailVerifications returns how many verification links were sent to userID since the given time
+	CountEmailVerifications(ctx context.Context, userID int, since time.Time) (int, error)
+	// VerifyEmail verifies the user the link with tokenHash belongs to and uses the link up. It returns
+	// the user's id, or ErrInvalidVerificationToken when the link can't be used
+	VerifyEmail(ctx context.Context, tokenHash string) (int, error)
+
+	AllReservations(ctx context.Context) ([]models.Reservation, error)
+	AllNewReservations(ctx context.Context) ([]models.Reservation, error)
+
+	GetReservationByID(ctx context.Context, id int) (models.Reservation, error)
+	UpdateReservation(ctx context.Context, u models.Reservation) error
+	DeleteReservation(ctx context.Context, id int) error
+	UpdateReservationStatus(ctx context.Context, id int, from, to string, userID int) error
+	GetReservationStatusHistory(ctx context.Context, id int) ([]models.StatusChange, error)
+	RescheduleReservation(ctx context.Context, id int, startDate, endDate time.Time) (bool, error)
+	InsertBlockForRoom(ctx context.Context, id int, startDate, endDate time.Time, reason string) error
+	DeleteBlockByID(ctx context.Context, id int) error
+
+	AllRooms(ctx context.Context) ([]models.Room, error)
+	UpdateRoom(ctx context.Context, room models.Room) error
+	InsertRoom(ctx context.Context, room models.Room) error
+	DeleteRoom(ctx context.Context, id int) error
+
+	InsertTodoList(ctx context.Context, todo models.TodoList) error
+	GetTodoListByUserID(ctx context.Context, id int) ([]models.TodoList, error)
+	DeleteTodo(ctx context.Context, id int) error
+
+	GetRestrictionsForCurrentRoom(ctx context.Context, roomID int, start, end time.Time) ([]models.RoomRestriction, error)
+
+	// SearchArtists and GetArtistByID only return approved artists, the ones the public may see.
+	// SearchArtists returns up to filter.Limit matching artists, skipping the first filter.Offset of them,
+	// and how many artists match in all
+	SearchArtists(ctx context.Context, filter ArtistFilter) ([]models.Artist, int, error)
+	// SuggestArtists returns up to limit approved artists matching q for the search box, most relevant first
+	SuggestArtists(ctx context.Context, q string, limit int) ([]models.ArtistSuggestion, error)
+	// AllArtistsByStatus returns the artists whose listing is in status, or every artist when status is empty
+	AllArtistsByStatus(ctx context.Context, status string) ([]models.Artist, error)
+	AllArtistsByUserID(ctx context.Context, userID int) ([]models.Artist, error)
+	CreateArtist(ctx context.Context, artist models.Artist) (int, error)
+	GetArtistByID(ctx context.Context, id int) (models.Artist, error)
+	// GetArtistByIDAnyStatus returns an artist whatever the status of its listing, for admins and owners
+	GetArtistByIDAnyStatus(ctx context.Context, id int) (models.Artist, error)
+	UpdateArtist(ctx context.Context, artist models.Artist) error
+	// UpdateArtistStatus moves the listing of artist id from one status to another and saves the reason
+	// the owner is given. It returns listing.ErrStaleStatus when the listing is no longer in from
+	UpdateArtistStatus(ctx context.Context, id int, from, to, reason string) error
+
+	// AllGenres returns every genre by name
+	AllGenres(ctx context.Context) ([]models.Genre, error)
+	GetGenreByID(ctx context.Context, id int) (models.Genre, error)
+	// GetGenreBySlug returns the genre with slug, or the genre with an alias with that slug
+	GetGenreBySlug(ctx context.Context, slug string) (models.Genre, error)
+	// CreateGenre and UpdateGenre save a genre and its aliases, and return ErrGenreTaken when the name or
+	// an alias has the slug of another genre or alias
+	CreateGenre(ctx context.Context, genre models.Genre) (int, error)
+	UpdateGenre(ctx context.Context, genre models.Genre) error
+	// MergeGenre moves the artists of genre id to genre into, keeps the name and aliases of id as aliases
+	// of into and deletes genre id
+	MergeGenre(ctx context.Context, id, into int) error
+	// DeleteGenre deletes a genre and takes it off its artists
+	DeleteGenre(ctx context.Context, id int) error
+
+	AllBookings(ctx context.Context) ([]models.Bookings, error)
+	AllNewBookings(ctx context.Context) ([]models.Bookings, error)
+	AllBookingsByStatus(ctx context.Context, status string) ([]models.Bookings, error)
+	AllBookingsByArtistID(ctx context.Context, artistID int) ([]models.Bookings, error)
+	AllBookingsByUserID(ctx context.Context, userID int) ([]models.Bookings, error)
+	AllReservationsByUserID(ctx context.Context, userID int) ([]models.Reservation, error)
+	ClaimBookings(ctx context.Context, userID int, email string) (int, error)
+	InsertBooking(ctx context.Context, booking models.Bookings) (int, error)
+	SearchAvailabilityByDatesByArtistID(ctx context.Context, start, end time.Time, artistID int) (bool, error)
+	GetRestrictionsForCurrentArtist(ctx context.Context, artistID int, start, end time.Time) ([]models.ArtistRestriction, error)
+	GetBookingByID(ctx context.Context, id int) (models.Bookings, error)
+	UpdateBooking(ctx context.Context, booking models.Bookings) error
+	UpdateBookingStatus(ctx context.Context, id int, from, to string, userID int) error
+	GetBookingStatusHistory(ctx context.Context, id int) ([]models.StatusChange, error)
+	RescheduleBooking(ctx context.Context, id int, startDate, endDate time.Time) (bool, error)
+	InsertBlockForArtist(ctx context.Context, id int, startDate, endDate time.Time, reason string) error
+	DeleteArtistBlockByID(ctx context.Context, id int) error
+
+	AllBookingOptions(ctx context.Context) ([]models.BookingOptions, error)
+	AllArtistBookingOptions(ctx context.Context, id int) ([]models.BookingOptions, error)
+	CreateBookingOption(ctx context.Context, option models.BookingOptions) error
+	GetBookingOptionByID(ctx context.Context, id int) (models.BookingOptions, error)
+	UpdateBookingOption(ctx context.Context, option models.BookingOptions) error
+
+	// EnqueueEmail adds a message to the outbox. Inside WithTx it is only sent if the transaction commits
+	EnqueueEmail(ctx context.Context, message models.MailData) error
+	// ClaimDueEmails returns up to limit pending emails that are due and holds them for lease,
+	// so other workers skip them and they are retried if the worker dies
+	ClaimDueEmails(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEmail, error)
+	MarkEmailSent(ctx context.Context, id int) error
+	MarkEmailFailed(ctx context.Context, id int, lastError string, retryAt time.Time) error
+	MarkEmailDead(ctx context.Context, id int, lastError string) error
+	AllOutboxEmails(ctx context.Context, status string) ([]models.OutboxEmail, error)
+	ResendEmail(ctx context.Context, id int) error
+
+	// AllSettings returns the saved settings by key
+	AllSettings(ctx context.Context) (map[string]string, error)
+	// UpdateSettings saves values by key, adding the keys that don't exist yet
+	UpdateSettings(ctx context.Context, values map[string]string) error
 }
@@ -0,0 +1,80 @@
+package roles
+
+import "slices"
+
+// Roles a user can have. New users are customers
+const (
+	Customer      = "customer"
+	Artist        = "artist"
+	ArtistManager = "artist_manager"
+	Staff         = "staff"
+	Admin         = "admin"
+)
+
+// Permissions a role can be granted. Pages of the admin area each need one of them
+const (
+	ManageArtists  = "manage_artists"
+	ManageBookings = "manage_bookings"
+	ManageRooms    = "manage_rooms"
+	ViewReports    = "view_reports"
+	// ManageSettings covers the settings, outbox and email templates, and is kept for admins
+	ManageSettings = "manage_settings"
+	// ManageUsers covers changing roles and disabling, locking or resetting accounts, and is kept for admins
+	ManageUsers = "manage_users"
+)
+
+// All lists every role, from the least to the most trusted, used for select boxes
+var All = []string{Customer, Artist, ArtistManager, Staff, Admin}
+
+// Permissions lists every permission
+var Permissions = []string{ManageArtists, ManageBookings, ManageRooms, ViewReports, ManageSettings, ManageUsers}
+
+var labels = map[string]string{
+	Customer:      "Customer",
+	Artist:        "Artist",
+	ArtistManager: "Artist Manager",
+	Staff:         "Staff",
+	Admin:         "Admin",
+}
+
+// grants maps each role to the permissions it has. Admins have all of them
+var grants = map[string][]string{
+	Customer:      {},
+	Artist:        {},
+	ArtistManager: {ManageArtists},
+	Staff:         {ManageBookings, ViewReports},
+	Admin:         Permissions,
+}
+
+// Valid reports whether role is a known role
+func Valid(role string) bool {
+	_, ok := grants[role]
+	return ok
+}
+
+// Label returns the human readable name of a role
+func Label(role string) string {
+	if label, ok := labels[role]; ok {
+		return label
+	}
+	return role
+}
+
+// Can reports whether role has permission. Unknown roles have none
+func Can(role, permission string) bool {
+	return slices.Contains(grants[role], permission)
+}
+
+// Granted returns the permissions of role as a set, for templates to check
+func Granted(role string) map[string]bool {
+	granted := make(map[string]bool, len(grants[role]))
+	for _, permission := range grants[role] {
+		granted[permission] = true
+	}
+	return granted
+}
+
+// CanAccessAdmin reports whether role has any permission, and so can use the admin area
+func CanAccessAdmin(role string) bool {
+	return len(grants[role]) > 0
+}
@@ -0,0 +1,71 @@
+package roles
+
+import "testing"
+
+var canTests = []struct {
+	role       string
+	permission string
+	expected   bool
+}{
+	{Admin, ManageSettings, true},
+	{Admin, ManageArtists, true},
+	{Staff, ManageBookings, true},
+	{Staff, ManageRooms, false},
+	{Staff, ViewReports, true},
+	{Staff, ManageArtists, false},
+	{Staff, ManageSettings, false},
+	{Staff, ManageUsers, false},
+	{ArtistManager, ManageArtists, true},
+	{ArtistManager, ManageBookings, false},
+	{Artist, ManageArtists, false},
+	{Customer, ManageBookings, false},
+	{"", ManageBookings, false},
+	{"owner", ManageBookings, false},
+}
+
+func TestCan(t *testing.T) {
+	for _, e := range canTests {
+		if got := Can(e.role, e.permission); got != e.expected {
+			t.Errorf("%s can %s: expected %v but got %v", e.role, e.permission, e.expected, got)
+		}
+	}
+}
+
+func TestAdminHasEveryPermission(t *testing.T) {
+	for _, permission := range Permissions {
+		if !Can(Admin, permission) {
+			t.Errorf("expected admins to have %s", permission)
+		}
+	}
+}
+
+func TestEveryRoleIsValid(t *testing.T) {
+	for _, role := range All {
+		if !Valid(role) {
+			t.Errorf("%s is listed in All but has no grants entry", role)
+		}
+		if Label(role) == role {
+			t.Errorf("%s has no label", role)
+		}
+	}
+}
+
+func TestCanAccessAdmin(t *testing.T) {
+	for role, expected := range map[string]bool{Admin: true, Staff: true, ArtistManager: true, Artist: false, Customer: false, "": false} {
+		if got := CanAccessAdmin(role); got != expected {
+			t.Errorf("%q: expected %v but got %v", role, expected, got)
+		}
+	}
+}
+
+func TestGranted(t *testing.T) {
+	granted := Granted(Staff)
+	if !granted[ManageBookings] || granted[ManageSettings] {
+		t.Errorf("unexpected staff permissions %v", granted)
+	}
+
+	granted[ManageSettings] = true
+	if Can(Staff, ManageSettings) {
+		t.Error("expected changing the returned set to leave the role alone")
+	}
+}
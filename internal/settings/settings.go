@@ -0,0 +1,191 @@
+package settings
+
+import (
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Events admins can be notified about. Cancellations and reschedules go to the recipients of the same kind
+const (
+	EventNewReservation = "new_reservation"
+	EventNewBooking     = "new_booking"
+	EventNewListing     = "new_listing"
+)
+
+// Events lists every admin notification event
+var Events = []string{EventNewReservation, EventNewBooking, EventNewListing}
+
+// eventLabels describes each event on the settings page
+var eventLabels = map[string]string{
+	EventNewReservation: "New reservations, and reservation cancellations and reschedules",
+	EventNewBooking:     "New bookings, and booking cancellations and reschedules",
+	EventNewListing:     "New artist listings",
+}
+
+// Label describes event
+func Label(event string) string {
+	return eventLabels[event]
+}
+
+// Keys of the settings table
+const (
+	KeySenderName      = "sender_name"
+	KeySenderEmail     = "sender_email"
+	KeyReplyTo         = "reply_to"
+	keyRecipientPrefix = "admin_recipients_"
+)
+
+// RecipientsKey returns the settings key holding the admin recipients of event
+func RecipientsKey(event string) string {
+	return keyRecipientPrefix + event
+}
+
+// Settings are the values ops can change from the admin settings page without a deploy
+type Settings struct {
+	SenderName  string
+	SenderEmail string
+	// ReplyTo is where customer replies go, the sender address when it is empty
+	ReplyTo string
+	// AdminRecipients holds the addresses notified about each event
+	AdminRecipients map[string][]string
+}
+
+// Defaults returns the settings used before anything is saved
+func Defaults() Settings {
+	return Settings{
+		SenderName:  "MusiqCity",
+		SenderEmail: "prosperdevstack@gmail.com",
+		AdminRecipients: map[string][]string{
+			EventNewReservation: {"atu.prosper@gmail.com"},
+			EventNewBooking:     {"atu.prosper@gmail.com"},
+			EventNewListing:     {"atu.prosper@gmail.com"},
+		},
+	}
+}
+
+// FromValues builds settings from the rows of the settings table. Missing keys keep their default
+func FromValues(values map[string]string) Settings {
+	s := Defaults()
+
+	if v, ok := values[KeySenderName]; ok {
+		s.SenderName = v
+	}
+	if v, ok := values[KeySenderEmail]; ok && v != "" {
+		s.SenderEmail = v
+	}
+	if v, ok := values[KeyReplyTo]; ok {
+		s.ReplyTo = v
+	}
+
+	for _, event := range Events {
+		if v, ok := values[RecipientsKey(event)]; ok {
+			s.AdminRecipients[event] = SplitAddresses(v)
+		}
+	}
+
+	return s
+}
+
+// Values returns the rows of the settings table for s
+func (s Settings) Values() map[string]string {
+	values := map[string]string{
+		KeySenderName:  s.SenderName,
+		KeySenderEmail: s.SenderEmail,
+		KeyReplyTo:     s.ReplyTo,
+	}
+
+	for _, event := range Events {
+		values[RecipientsKey(event)] = strings.Join(s.AdminRecipients[event], ", ")
+	}
+
+	return values
+}
+
+// Recipients returns the addresses notified about event
+func (s Settings) Recipients(event string) []string {
+	return s.AdminRecipients[event]
+}
+
+// Validate returns a message for each key of s that holds an invalid address
+func (s Settings) Validate() map[string]string {
+	problems := make(map[string]string)
+
+	if _, err := mail.ParseAddress(s.SenderEmail); err != nil {
+		problems[KeySenderEmail] = "Enter a valid email address"
+	}
+
+	if s.ReplyTo != "" {
+		if _, err := mail.ParseAddress(s.ReplyTo); err != nil {
+			problems[KeyReplyTo] = "Enter a valid email address"
+		}
+	}
+
+	for _, event := range Events {
+		for _, address := range s.AdminRecipients[event] {
+			if _, err := mail.ParseAddress(address); err != nil {
+				problems[RecipientsKey(event)] = fmt.Sprintf("%q is not a valid email address", address)
+				break
+			}
+		}
+	}
+
+	return problems
+}
+
+// SplitAddresses splits a comma, semicolon or line separated list of addresses, dropping blanks and repeats
+func SplitAddresses(list string) []string {
+	fields := strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ';' || r == '\n' || r == '\r'
+	})
+
+	addresses := []string{}
+	for _, field := range fields {
+		address := strings.TrimSpace(field)
+		if address != "" && !slices.Contains(addresses, address) {
+			addresses = append(addresses, address)
+		}
+	}
+
+	return addresses
+}
+
+// Cache holds the current settings so handlers don't read the settings table on every request
+type Cache struct {
+	mu       sync.RWMutex
+	settings Settings
+}
+
+// NewCache returns a cache holding s
+func NewCache(s Settings) *Cache {
+	return &Cache{settings: s.clone()}
+}
+
+// Get returns a copy of the current settings
+func (c *Cache) Get() Settings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.settings.clone()
+}
+
+// Set replaces the current settings
+func (c *Cache) Set(s Settings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.settings = s.clone()
+}
+
+// clone copies s, so the cached recipient lists can't be changed through a copy
+func (s Settings) clone() Settings {
+	recipients := make(map[string][]string, len(s.AdminRecipients))
+	for event, addresses := range s.AdminRecipients {
+		recipients[event] = slices.Clone(addresses)
+	}
+	s.AdminRecipients = recipients
+
+	return s
+}
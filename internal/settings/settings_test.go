@@ -0,0 +1,90 @@
+package settings
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFromValues(t *testing.T) {
+	s := FromValues(map[string]string{
+		KeySenderEmail:                     "bookings@musiqcity.com",
+		KeyReplyTo:                         "support@musiqcity.com",
+		RecipientsKey(EventNewBooking):     "ops@musiqcity.com, owner@musiqcity.com",
+		RecipientsKey(EventNewReservation): "",
+	})
+
+	if s.SenderName != Defaults().SenderName {
+		t.Errorf("expected the default sender name but got %q", s.SenderName)
+	}
+	if s.SenderEmail != "bookings@musiqcity.com" || s.ReplyTo != "support@musiqcity.com" {
+		t.Errorf("expected the saved sender but got %q and %q", s.SenderEmail, s.ReplyTo)
+	}
+
+	var tests = []struct {
+		event    string
+		expected []string
+	}{
+		{EventNewBooking, []string{"ops@musiqcity.com", "owner@musiqcity.com"}},
+		{EventNewReservation, []string{}},
+		{EventNewListing, Defaults().Recipients(EventNewListing)},
+	}
+
+	for _, e := range tests {
+		if got := s.Recipients(e.event); !reflect.DeepEqual(got, e.expected) {
+			t.Errorf("%s: expected %v but got %v", e.event, e.expected, got)
+		}
+	}
+
+	// saving and loading again gives back the same settings
+	if again := FromValues(s.Values()); !reflect.DeepEqual(again, s) {
+		t.Errorf("expected %+v after a round trip but got %+v", s, again)
+	}
+}
+
+func TestSplitAddresses(t *testing.T) {
+	got := SplitAddresses(" a@example.com,b@example.com;\r\nc@example.com\n\n a@example.com ,")
+	expected := []string{"a@example.com", "b@example.com", "c@example.com"}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v but got %v", expected, got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := Defaults()
+	if problems := s.Validate(); len(problems) != 0 {
+		t.Errorf("expected the defaults to be valid but got %v", problems)
+	}
+
+	s.SenderEmail = "not an email"
+	s.ReplyTo = "also@not@valid"
+	s.AdminRecipients[EventNewListing] = []string{"ops@musiqcity.com", "ops"}
+
+	problems := s.Validate()
+	for _, key := range []string{KeySenderEmail, KeyReplyTo, RecipientsKey(EventNewListing)} {
+		if problems[key] == "" {
+			t.Errorf("expected a problem with %s but got %v", key, problems)
+		}
+	}
+	if len(problems) != 3 {
+		t.Errorf("expected 3 problems but got %v", problems)
+	}
+}
+
+func TestCacheCopies(t *testing.T) {
+	cache := NewCache(Defaults())
+
+	s := cache.Get()
+	s.AdminRecipients[EventNewBooking][0] = "changed@example.com"
+
+	if got := cache.Get().Recipients(EventNewBooking)[0]; got == "changed@example.com" {
+		t.Error("expected changing a copy to leave the cached settings alone")
+	}
+
+	s.SenderName = "New Name"
+	cache.Set(s)
+
+	if got := cache.Get().SenderName; got != "New Name" {
+		t.Errorf("expected the new sender name but got %q", got)
+	}
+}
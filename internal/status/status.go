@@ -0,0 +1,83 @@
+package status
+
+import "errors"
+
+// States a reservation or booking can be in
+const (
+	Pending     = "pending"
+	Quoted      = "quoted"
+	Confirmed   = "confirmed"
+	DepositPaid = "deposit_paid"
+	Completed   = "completed"
+	Cancelled   = "cancelled"
+	NoShow      = "no_show"
+)
+
+var ErrUnknownStatus = errors.New("unknown status")
+var ErrInvalidTransition = errors.New("that status change is not allowed")
+
+// ErrStaleStatus is returned when the status changed in the database before the transition was saved
+var ErrStaleStatus = errors.New("the status was changed by someone else, please reload and try again")
+
+// All lists every state in lifecycle order, used for filters and select boxes
+var All = []string{Pending, Quoted, Confirmed, DepositPaid, Completed, Cancelled, NoShow}
+
+var labels = map[string]string{
+	Pending:     "Pending",
+	Quoted:      "Quoted",
+	Confirmed:   "Confirmed",
+	DepositPaid: "Deposit Paid",
+	Completed:   "Completed",
+	Cancelled:   "Cancelled",
+	NoShow:      "No Show",
+}
+
+// transitions maps each state to the states it may move to. Completed, cancelled and no-show are final
+var transitions = map[string][]string{
+	Pending:     {Quoted, Confirmed, Cancelled},
+	Quoted:      {Confirmed, Cancelled},
+	Confirmed:   {DepositPaid, Completed, Cancelled, NoShow},
+	DepositPaid: {Completed, Cancelled, NoShow},
+	Completed:   {},
+	Cancelled:   {},
+	NoShow:      {},
+}
+
+// Valid reports whether s is a known state
+func Valid(s string) bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// Label returns the human readable name of a state
+func Label(s string) string {
+	if label, ok := labels[s]; ok {
+		return label
+	}
+	return s
+}
+
+// Next returns the states a record in state from may move to
+func Next(from string) []string {
+	return transitions[from]
+}
+
+// IsFinal reports whether no further transitions are possible from s
+func IsFinal(s string) bool {
+	return Valid(s) && len(transitions[s]) == 0
+}
+
+// Transition checks that moving from one state to another is allowed
+func Transition(from, to string) error {
+	if !Valid(from) || !Valid(to) {
+		return ErrUnknownStatus
+	}
+
+	for _, next := range transitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+
+	return ErrInvalidTransition
+}
@@ -0,0 +1,63 @@
+package status
+
+import "testing"
+
+var transitionTests = []struct {
+	from     string
+	to       string
+	expected error
+}{
+	{Pending, Quoted, nil},
+	{Pending, Confirmed, nil},
+	{Quoted, Confirmed, nil},
+	{Confirmed, DepositPaid, nil},
+	{DepositPaid, Completed, nil},
+	{Confirmed, NoShow, nil},
+	{Pending, Cancelled, nil},
+	{Pending, Completed, ErrInvalidTransition},
+	{Confirmed, Pending, ErrInvalidTransition},
+	{Completed, Cancelled, ErrInvalidTransition},
+	{Cancelled, Confirmed, ErrInvalidTransition},
+	{Pending, Pending, ErrInvalidTransition},
+	{"processed", Confirmed, ErrUnknownStatus},
+	{Pending, "done", ErrUnknownStatus},
+}
+
+func TestTransition(t *testing.T) {
+	for _, e := range transitionTests {
+		err := Transition(e.from, e.to)
+		if err != e.expected {
+			t.Errorf("%s -> %s: expected %v but got %v", e.from, e.to, e.expected, err)
+		}
+	}
+}
+
+func TestEveryStateHasTransitions(t *testing.T) {
+	for _, s := range All {
+		if !Valid(s) {
+			t.Errorf("%s is listed in All but has no transitions entry", s)
+		}
+
+		if Label(s) == s {
+			t.Errorf("%s has no label", s)
+		}
+
+		for _, next := range Next(s) {
+			if !Valid(next) {
+				t.Errorf("%s can move to unknown state %s", s, next)
+			}
+		}
+	}
+}
+
+func TestIsFinal(t *testing.T) {
+	for _, s := range []string{Completed, Cancelled, NoShow} {
+		if !IsFinal(s) {
+			t.Errorf("expected %s to be final", s)
+		}
+	}
+
+	if IsFinal(Pending) || IsFinal("unknown") {
+		t.Error("expected pending and unknown states not to be final")
+	}
+}